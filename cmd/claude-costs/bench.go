@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/store"
+)
+
+// bench parses the Claude directory as the summary would and reports how
+// fast, so slowness particular to a machine, such as a network home
+// directory or a virus scanner inspecting each file, can be told apart from
+// slow parsing
+func (a *app) bench() error {
+	var stats parser.ReadStats
+	start := time.Now()
+	if _, err := a.parse(time.Time{}, parser.WithReadStats(&stats)); err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	source := "the JSONL files"
+	if !a.cfg.NoCache && store.Exists(a.cfg.DB) {
+		source = "the index " + a.cfg.DB
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	megabytes := float64(stats.Bytes) / 1e6
+	seconds := elapsed.Seconds()
+
+	fmt.Printf("Read %d files, %.1f MB in %d lines, from %s\n", stats.Files, megabytes, stats.Lines, source)
+	fmt.Printf("Parsed in %s: %.1f MB/s, %.0f lines/s\n", elapsed.Round(time.Millisecond), megabytes/seconds, float64(stats.Lines)/seconds)
+	fmt.Printf("Memory from the OS: %.1f MB; %s %s/%s, GOMAXPROCS %d\n",
+		float64(mem.Sys)/1e6, runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.GOMAXPROCS(0))
+	fmt.Println("Compare with the benchmark results in the README; a second run reads files the OS has cached")
+	return nil
+}
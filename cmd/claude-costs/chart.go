@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/chart"
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// newChartCmd builds the chart subcommand
+func newChartCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chart",
+		Short: "Draw cost charts as PNG or SVG images",
+		Long: "chart draws one chart of the analysis window to an image file, for\n" +
+			"embedding in wikis and slide decks. The format follows the --out\n" +
+			"extension: .png or .svg.\n\n" +
+			"  daily-cost   cost per day over the window\n" +
+			"  model-mix    each model's share of cost\n" +
+			"  projects     the most expensive projects\n" +
+			"  hourly       messages by hour of the day\n\n" +
+			"Types: " + strings.Join(chart.Types, ", "),
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.chart() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.ChartType, "type", cfg.ChartType, "Chart to draw")
+	flags.StringVarP(&cfg.Output, "out", "o", "", "Image `file` to write (.png or .svg)")
+	flags.IntVar(&cfg.ChartWidth, "width", cfg.ChartWidth, "Image width in pixels")
+	flags.IntVar(&cfg.ChartHeight, "height", cfg.ChartHeight, "Image height in pixels")
+
+	return cmd
+}
+
+// chart renders the requested chart to the output file
+func (a *app) chart() (err error) {
+	if a.cfg.Output == "" {
+		return errors.New("chart needs --out FILE")
+	}
+	format, err := chart.FormatFor(a.cfg.Output)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := a.analyze(time.Time{})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(a.cfg.Output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	opts := chart.Options{Width: a.cfg.ChartWidth, Height: a.cfg.ChartHeight}
+	if err := chart.Render(f, analysis, a.cfg.ChartType, format, opts); err != nil {
+		// Don't leave a truncated image behind
+		f.Close()
+		os.Remove(a.cfg.Output)
+		return err
+	}
+	a.logger.Debug("wrote chart", "type", a.cfg.ChartType, "file", a.cfg.Output)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/gitlog"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+)
+
+// countCommits counts the commits made in each project's directories since
+// the report window started, or since since when it's set. Projects without
+// a git repository are left out. A directory inside another of the
+// project's repositories counts each commit once.
+func (a *app) countCommits(analysis *claudecosts.Analysis, since time.Time) map[string]int {
+	until := a.cfg.Clock()
+	if since.IsZero() {
+		since = until.AddDate(0, 0, -a.cfg.Days)
+	}
+
+	ctx := context.Background()
+	commits := make(map[string]int)
+	for project, dirs := range calculator.New(analysis).GetProjectDirs() {
+		hashes := make(map[string]bool)
+		repo := false
+		for _, dir := range dirs {
+			found, err := gitlog.Commits(ctx, dir, since, until)
+			if errors.Is(err, gitlog.ErrNotRepo) {
+				continue
+			}
+			if errors.Is(err, exec.ErrNotFound) {
+				a.logger.Warn("git isn't installed, so commits can't be counted")
+				return nil
+			}
+			if err != nil {
+				a.logger.Warn("failed to count commits", "dir", dir, "error", err)
+				continue
+			}
+			repo = true
+			for _, hash := range found {
+				hashes[hash] = true
+			}
+		}
+		if repo {
+			commits[project] = len(hashes)
+		}
+	}
+	return commits
+}
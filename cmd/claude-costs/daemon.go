@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// newDaemonCmd builds the daemon subcommand
+func newDaemonCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep the SQLite index current as Claude Code writes its logs",
+		Long: "daemon tails the JSONL files in the Claude directory and stores new lines\n" +
+			"in the SQLite index (--db). Once the index exists, other commands read from\n" +
+			"it and only parse the lines in their window, and history is kept after\n" +
+			"Claude Code deletes old transcripts. Commands catch up on new lines\n" +
+			"themselves, so the daemon only keeps that work small.\n\n" +
+			"With --events, each new message is also sent to an event sink: a file or\n" +
+			"named pipe as JSON Lines, an http:// or https:// URL that each batch is\n" +
+			"POSTed to, kafka://BROKER[,BROKER...]/TOPIC, or nats://HOST:PORT/SUBJECT\n" +
+			"for a JetStream stream. Delivery is at least once: how far delivery has\n" +
+			"reached is saved next to --state, so after a restart the daemon sends what\n" +
+			"was logged while it was down. Messages logged before its first run aren't\n" +
+			"sent.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.daemon() }),
+	}
+	cmd.Flags().DurationVar(&cfg.Interval, "interval", cfg.Interval, "How often to check for new lines")
+	addEventsFlag(cmd.Flags(), cfg)
+	return cmd
+}
+
+// daemon ingests new lines every interval until interrupted
+func (a *app) daemon() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := store.Open(a.cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	forwarder, err := a.openEvents(state.EventsPath(a.cfg.StateFile))
+	if err != nil {
+		return err
+	}
+	if forwarder != nil {
+		defer forwarder.sink.Close()
+	}
+
+	a.logger.Info("indexing", "dir", a.cfg.ClaudeDir, "db", a.cfg.DB, "interval", a.cfg.Interval)
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := db.Ingest(ctx, a.cfg.ClaudeDir, a.findOptions()...)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			a.logger.Warn("failed to index some files", "error", err)
+		}
+		if stats.Lines > 0 {
+			a.logger.Info("indexed", "files", stats.Files, "lines", stats.Lines)
+		}
+		if forwarder != nil {
+			if err := a.forwardEvents(ctx, forwarder); err != nil && ctx.Err() == nil {
+				a.logger.Warn("failed to forward events", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+)
+
+// newDiffCmd builds the diff subcommand
+func newDiffCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Show what changed between two saved analyses",
+		Long: "diff compares two analyses saved by export --format analysis: total cost,\n" +
+			"sessions, and tokens, each project's cost, including projects that are new\n" +
+			"or gone, and shifts in each model's share of the cost. It reads only the\n" +
+			"two files, so week-over-week reviews can keep their analyses in a repo.\n" +
+			"--top limits the projects and models listed.",
+		Example: "  claude-costs diff reviews/2026-w41.analysis reviews/2026-w42.analysis",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.DiffFiles = args
+			return runE(cfg, func(a *app) error { return a.diff(args[0], args[1]) })(cmd, args)
+		},
+	}
+}
+
+// diff prints the changes from the analysis saved at oldPath to the one at
+// newPath
+func (a *app) diff(oldPath, newPath string) error {
+	old, err := readAnalysis(oldPath)
+	if err != nil {
+		return err
+	}
+	new, err := readAnalysis(newPath)
+	if err != nil {
+		return err
+	}
+	display.ShowDiff(os.Stdout, filepath.Base(oldPath), old, filepath.Base(newPath), new, a.cfg.Top)
+	return nil
+}
+
+// readAnalysis reads an analysis saved by export --format analysis
+func readAnalysis(path string) (*claudecosts.Analysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := claudecosts.UnmarshalAnalysis(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return analysis, nil
+}
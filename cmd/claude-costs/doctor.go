@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// newDoctorCmd builds the doctor subcommand
+func newDoctorCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration and Claude directory for problems",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctor(cmd, cfg, os.Stdout)
+		},
+	}
+}
+
+// doctor runs each check and prints its outcome. It keeps going after a
+// failure so every problem is listed at once.
+func doctor(cmd *cobra.Command, cfg *config.Config, w io.Writer) error {
+	failures := 0
+	ok := func(format string, args ...interface{}) { fmt.Fprintf(w, "✓ "+format+"\n", args...) }
+	warn := func(format string, args ...interface{}) { fmt.Fprintf(w, "⚠ "+format+"\n", args...) }
+	fail := func(format string, args ...interface{}) {
+		fmt.Fprintf(w, "✗ "+format+"\n", args...)
+		failures++
+	}
+
+	// Config file
+	if err := loadConfigFile(cmd, cfg); err != nil {
+		fail("config: %v", err)
+	} else if _, err := os.Stat(cfg.ConfigFile); err == nil {
+		ok("config file %s", cfg.ConfigFile)
+	} else {
+		ok("no config file at %s (optional)", cfg.ConfigFile)
+	}
+	if err := cfg.Validate(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fail("invalid configuration: %v", err)
+	}
+
+	// Claude directory and JSONL files
+	projectsDir := filepath.Join(cfg.ClaudeDir, "projects")
+	if info, err := os.Stat(cfg.ClaudeDir); err != nil || !info.IsDir() {
+		fail("Claude directory %s not found; set --claude-dir or claude_dir", cfg.ClaudeDir)
+		return fmt.Errorf("%d checks failed", failures)
+	}
+	ok("Claude directory %s", cfg.ClaudeDir)
+
+	files, newest := 0, time.Time{}
+	filepath.WalkDir(projectsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+		files++
+		if info, err := d.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if files == 0 {
+		fail("no JSONL files under %s", projectsDir)
+		return fmt.Errorf("%d checks failed", failures)
+	}
+	ok("%d JSONL files, newest modified %s", files, newest.Local().Format("2006-01-02 15:04"))
+
+	// Parse the analysis window
+	a := &app{cfg: cfg, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	analysis, err := a.parse(time.Time{})
+	switch {
+	case err != nil:
+		fail("parse: %v", err)
+	case analysis.EndDate.IsZero():
+		warn("no activity in the last %d days", cfg.Days)
+	default:
+		ok("%d sessions between %s and %s", len(analysis.Sessions),
+			analysis.StartDate.Local().Format("2006-01-02"), analysis.EndDate.Local().Format("2006-01-02"))
+	}
+
+	if analysis != nil {
+		if analysis.ParseErrors > 0 {
+			warn("%d malformed entries or unreadable files were skipped (see --log-level debug)", analysis.ParseErrors)
+		} else {
+			ok("no malformed entries")
+		}
+		if skew := analysis.ClockSkew; skew.Sessions > 0 {
+			warn("timestamps run backwards in %d sessions: %d entries out of order, %d before their parent (see --exclude-skewed)",
+				skew.Sessions, skew.OutOfOrder, skew.ParentAfterChild)
+		}
+		if analysis.MemoryLimited {
+			warn("parsing neared --max-memory, so response and turn times were skipped")
+		}
+		if len(analysis.DuplicateFiles) > 0 {
+			// Counted once either way, but the copies may be worth deleting
+			warn("%d session files copy others and were skipped:", len(analysis.DuplicateFiles))
+			for _, dup := range analysis.DuplicateFiles {
+				fmt.Fprintf(w, "    %s (copy of %s)\n", dup.Path, dup.Original)
+			}
+		}
+		if types := analysis.UnknownTypes(); len(types) > 0 {
+			// Cost is only ever in assistant entries, so these don't change
+			// the totals
+			counts := make([]string, len(types))
+			for i, typ := range types {
+				counts[i] = fmt.Sprintf("%s (%d)", typ, analysis.UnknownEntryTypes[typ])
+			}
+			ok("entries of other types skipped: %s", strings.Join(counts, ", "))
+		}
+
+		var unknown []string
+		for model := range analysis.Models {
+			if _, priced := models.ModelPricing[model]; !priced {
+				unknown = append(unknown, model)
+			}
+		}
+		sort.Strings(unknown)
+		if len(unknown) > 0 {
+			warn("no pricing for %s; priced at the default rates", strings.Join(unknown, ", "))
+		} else {
+			ok("pricing known for all models")
+		}
+	}
+
+	// State file for report --since-last-run
+	if runState, err := state.Load(cfg.StateFile); err != nil {
+		fail("%v", err)
+	} else if !runState.LastRun.IsZero() {
+		ok("last report --since-last-run covered up to %s", runState.LastRun.Local().Format("2006-01-02 15:04"))
+	}
+
+	// Index kept by the daemon command
+	if store.Exists(cfg.DB) {
+		if db, err := store.Open(cfg.DB); err != nil {
+			fail("%v", err)
+		} else {
+			if count, err := db.Count(); err != nil {
+				fail("index %s: %v", cfg.DB, err)
+			} else {
+				ok("index %s holds %d lines from %d files", cfg.DB, count.Lines, count.Files)
+			}
+			db.Close()
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d checks failed", failures)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newEstimateCmd builds the estimate subcommand
+func newEstimateCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "estimate",
+		Short: "Price a hypothetical request without reading any logs",
+		Long: "estimate prices the given token counts at --model's rates, as the rest of\n" +
+			"claude-costs would price a response with that usage. --model takes a full\n" +
+			"model ID or a prefix such as claude-opus-4, which means its newest snapshot.\n" +
+			"With --project, the first pricing rule matching that project applies.",
+		Example: "  claude-costs estimate --model claude-opus-4 --input-tokens 12000 --output-tokens 3000 --cache-read 200000",
+		Args:    cobra.NoArgs,
+		RunE:    runE(cfg, func(a *app) error { return a.estimate() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.EstimateModel, "model", cfg.EstimateModel, "Model ID, or a prefix of one")
+	flags.IntVar(&cfg.EstimateTokens.Input, "input-tokens", 0, "Uncached input `tokens`")
+	flags.IntVar(&cfg.EstimateTokens.Output, "output-tokens", 0, "Output `tokens`")
+	flags.IntVar(&cfg.EstimateTokens.CacheWrite, "cache-write", 0, "Cache write `tokens`")
+	flags.IntVar(&cfg.EstimateTokens.CacheRead, "cache-read", 0, "Cache read `tokens`")
+
+	return cmd
+}
+
+// estimate prints the cost of the requested tokens
+func (a *app) estimate() error {
+	// Validate has already checked the model resolves
+	model, _ := models.ResolveModel(a.cfg.EstimateModel)
+	tier := a.cfg.PricingRules.Match(a.cfg.Projects...).Tier(model, models.ModelPricing[model])
+	display.ShowEstimate(os.Stdout, model, tier, a.cfg.EstimateTokens)
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/events"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/state"
+)
+
+// maxPendingEvents bounds the events held while the sink is failing. Each
+// read takes only as many lines as there's room for events, so no more are
+// read once it's reached until the sink catches up.
+const maxPendingEvents = 100_000
+
+// eventForwarder sends each message appended to the logs to an event sink
+type eventForwarder struct {
+	sink    events.Sink
+	tail    *parser.Tail
+	primed  bool           // Whether the logs have been read once
+	pending []events.Event // Events not yet accepted by the sink
+	// checkpoint, when set, is where the tail's offsets are saved once
+	// everything read has been delivered
+	checkpoint string
+	unsaved    bool // Whether the tail has read past the saved offsets
+}
+
+// openEvents opens the --events sink, or returns nil without one. With a
+// checkpoint path, forwarding resumes from where a previous run's
+// deliveries reached.
+func (a *app) openEvents(checkpoint string) (*eventForwarder, error) {
+	if a.cfg.Events == "" {
+		return nil, nil
+	}
+	f := &eventForwarder{tail: parser.NewTail(time.Time{}, a.findOptions()...), checkpoint: checkpoint}
+	if checkpoint != "" {
+		saved, err := state.LoadEvents(checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if saved.Offsets != nil {
+			f.tail.Resume(saved.Offsets)
+			f.primed = true
+		}
+	}
+
+	sink, err := events.Open(a.cfg.Events)
+	if err != nil {
+		return nil, err
+	}
+	f.sink = sink
+	return f, nil
+}
+
+// forwardEvents reads the lines appended since the last call and sends
+// their messages. The first call without a checkpoint only reads to the end
+// of each file, so history isn't replayed. Events the sink rejects are sent
+// again next time; the tail is read no further than maxPendingEvents
+// allows, so nothing is dropped or checkpointed past undelivered events.
+func (a *app) forwardEvents(ctx context.Context, f *eventForwarder) error {
+	if room := maxPendingEvents - len(f.pending); room > 0 {
+		// Every message comes from a line of its own, so reading no more
+		// lines than there's room for keeps the events within the bound.
+		// History skipped by the first read isn't queued at all.
+		if f.primed {
+			f.tail.Limit(room)
+		}
+		if err := a.readEvents(f); err != nil {
+			return err
+		}
+	}
+
+	if err := f.sink.Write(ctx, f.pending); err != nil {
+		return err
+	}
+	f.pending = f.pending[:0]
+
+	if f.checkpoint != "" && f.unsaved {
+		if err := (&state.EventCheckpoint{Offsets: f.tail.Offsets()}).Save(f.checkpoint); err != nil {
+			return err
+		}
+		f.unsaved = false
+	}
+	return nil
+}
+
+// readEvents reads the lines appended to the tail and queues their messages
+func (a *app) readEvents(f *eventForwarder) error {
+	_, err := a.parseWith(time.Time{}, parser.WithLineSource(f.tail), parser.WithMessageHandler(func(m parser.Message) {
+		if f.primed {
+			f.pending = append(f.pending, events.Event{
+				Time:             m.Time,
+				ID:               m.ID,
+				SessionID:        m.SessionID,
+				Project:          m.Project,
+				Model:            m.Model,
+				Cost:             m.Cost,
+				InputTokens:      m.InputTokens,
+				OutputTokens:     m.OutputTokens,
+				CacheReadTokens:  m.CacheReadTokens,
+				CacheWriteTokens: m.CacheWriteTokens,
+			})
+		}
+	}))
+	if err != nil && !errors.Is(err, parser.ErrNoJSONLFiles) {
+		return err
+	}
+	if err == nil {
+		f.unsaved = true
+	}
+	f.primed = true
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/events"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+// recordingSink fails while err is set and otherwise records what it's sent
+type recordingSink struct {
+	err  error
+	sent int
+}
+
+func (s *recordingSink) Write(_ context.Context, evs []events.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent += len(evs)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestForwardEventsBackpressure(t *testing.T) {
+	sink := &recordingSink{err: errors.New("broker down")}
+	checkpoint := filepath.Join(t.TempDir(), "state-events.json")
+	// A full backlog means the tail isn't read, so it's never touched here
+	f := &eventForwarder{
+		sink:       sink,
+		primed:     true,
+		pending:    make([]events.Event, maxPendingEvents),
+		checkpoint: checkpoint,
+	}
+	a := &app{}
+
+	if err := a.forwardEvents(context.Background(), f); err == nil {
+		t.Fatal("forwardEvents succeeded with a failing sink")
+	}
+	if len(f.pending) != maxPendingEvents {
+		t.Errorf("pending = %d, want all %d kept", len(f.pending), maxPendingEvents)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint saved before delivery: %v", err)
+	}
+
+	sink.err = nil
+	if err := a.forwardEvents(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if sink.sent != maxPendingEvents || len(f.pending) != 0 {
+		t.Errorf("sent %d with %d pending, want %d with none", sink.sent, len(f.pending), maxPendingEvents)
+	}
+}
+
+func TestForwardEventsBacklogCap(t *testing.T) {
+	claudeDir := t.TempDir()
+	file := filepath.Join(claudeDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var lines strings.Builder
+	for _, uuid := range []string{"1", "2", "3"} {
+		lines.WriteString(`{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + time.Now().UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"s"}` + "\n")
+	}
+	if err := os.WriteFile(file, []byte(lines.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefault()
+	cfg.ClaudeDir = claudeDir
+	a := &app{cfg: cfg, logger: slog.New(slog.DiscardHandler)}
+	sink := &recordingSink{err: errors.New("broker down")}
+	// One event short of the cap, a burst of three only has room for one
+	f := &eventForwarder{
+		sink:    sink,
+		tail:    parser.NewTail(time.Time{}),
+		primed:  true,
+		pending: make([]events.Event, maxPendingEvents-1),
+	}
+
+	if err := a.forwardEvents(context.Background(), f); err == nil {
+		t.Fatal("forwardEvents succeeded with a failing sink")
+	}
+	if len(f.pending) != maxPendingEvents {
+		t.Errorf("pending = %d, want the cap of %d", len(f.pending), maxPendingEvents)
+	}
+
+	// The rest of the burst is read once the sink catches up
+	sink.err = nil
+	for range 2 {
+		if err := a.forwardEvents(context.Background(), f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sink.sent != maxPendingEvents+2 {
+		t.Errorf("sent %d, want %d", sink.sent, maxPendingEvents+2)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+)
+
+// Exit codes are a documented contract for scripts; don't renumber them
+const (
+	exitOK             = 0
+	exitError          = 1 // Any other failure, including invalid flags
+	exitBudgetExceeded = 2 // Spend is over the --fail-over cap
+	exitNoData         = 3 // No Claude directory, JSONL files, or activity in the window
+	exitParseErrors    = 4 // Malformed entries or unreadable files with --strict
+)
+
+const exitCodeHelp = `Exit codes:
+  0  OK
+  1  Error, such as an invalid flag or configuration
+  2  Budget exceeded (--fail-over)
+  3  No data: no Claude directory, JSONL files, or activity in the window
+  4  Parse errors (--strict)`
+
+// exitCode maps an error returned by the command to its exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, claudecosts.ErrBudgetExceeded):
+		return exitBudgetExceeded
+	case errors.Is(err, claudecosts.ErrNoData),
+		errors.Is(err, claudecosts.ErrNoJSONLFiles),
+		errors.Is(err, claudecosts.ErrNoClaudeDir):
+		return exitNoData
+	case errors.Is(err, claudecosts.ErrParsingFailed):
+		return exitParseErrors
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, 0},
+		{"other error", errors.New("boom"), 1},
+		{"invalid config", fmt.Errorf("%w: bad flag", claudecosts.ErrInvalidConfig), 1},
+		{"budget", fmt.Errorf("%w: over cap", claudecosts.ErrBudgetExceeded), 2},
+		{"no activity", fmt.Errorf("%w in the last 30 days", claudecosts.ErrNoData), 3},
+		{"no files", claudecosts.ErrNoJSONLFiles, 3},
+		{"no directory", fmt.Errorf("%w: /nope", claudecosts.ErrNoClaudeDir), 3},
+		{"strict", fmt.Errorf("%w: 2 malformed entries", claudecosts.ErrParsingFailed), 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
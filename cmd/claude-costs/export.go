@@ -0,0 +1,137 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/pdf"
+	"github.com/photostructure/go-claude-costs/internal/report"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+)
+
+// newExportCmd builds the export subcommand
+func newExportCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the analysis as JSON, JSON Lines, CSV, PDF, or iCalendar",
+		Long: "export writes the full report as JSON, or a single table with --table.\n" +
+			"CSV output is always a single table, daily by default. JSON Lines output\n" +
+			"is one object per row of a single table, sessions by default. PDF output\n" +
+			"is a printable report of the summary, every project, and charts. iCalendar\n" +
+			"(ics) output is one event per session, titled with its summary, for\n" +
+			"overlaying Claude usage on your calendar.\n\n" +
+			"--template renders the report through a Go text/template file instead,\n" +
+			"with the helpers currency, tokens, percent, and date. Fields use the Go\n" +
+			"names, e.g. {{currency .Totals.Cost}} or {{range .Projects}}{{.Name}}{{end}}.\n\n" +
+			"--query filters JSON output with a jq expression, without needing jq\n" +
+			"installed: --query '.projects[] | select(.cost > 10)'\n\n" +
+			"JSON output carries a schema_version, and --schema prints its JSON Schema.\n\n" +
+			"--format analysis saves the whole analysis, compressed, for --from-analysis\n" +
+			"to report on later without the logs, as from a CI artifact.\n\n" +
+			"Tables: " + strings.Join(report.Tables, ", "),
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.export() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.ExportFormat, "format", cfg.ExportFormat, "Output format: json, jsonl, csv, pdf, ics, or analysis")
+	flags.StringVar(&cfg.ExportTable, "table", "", "Export only this table")
+	flags.StringVar(&cfg.ExportTemplate, "template", "", "Render the report through this text/template `file` instead of --format")
+	flags.StringVar(&cfg.ExportQuery, "query", "", "Filter JSON output through this jq `expression`")
+	flags.BoolVar(&cfg.ExportSchema, "schema", false, "Print the JSON Schema of the JSON output and exit")
+	flags.StringVarP(&cfg.Output, "output", "o", "", "Write to `file` instead of stdout")
+	addBlockLengthFlag(flags, cfg)
+
+	return cmd
+}
+
+// export writes the report in the requested format
+func (a *app) export() (err error) {
+	if a.cfg.ExportSchema {
+		schema, err := report.JSONSchema()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(schema)
+		return err
+	}
+
+	// Parse first so a broken template or query fails before the slow part
+	var tmpl *template.Template
+	if a.cfg.ExportTemplate != "" {
+		if tmpl, err = report.ParseTemplate(a.cfg.ExportTemplate); err != nil {
+			return err
+		}
+	}
+	var query *report.Query
+	if a.cfg.ExportQuery != "" {
+		if query, err = report.ParseQuery(a.cfg.ExportQuery); err != nil {
+			return err
+		}
+	}
+
+	analysis, err := a.analyze(time.Time{})
+	if err != nil {
+		return err
+	}
+	r := report.New(analysis, a.cfg.Clock(), a.cfg.BlockLength)
+
+	var w io.Writer = os.Stdout
+	if a.cfg.Output != "" {
+		f, err := os.Create(a.cfg.Output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		w = f
+	}
+
+	if tmpl != nil {
+		return tmpl.Execute(w, r)
+	}
+
+	table := a.cfg.ExportTable
+	switch a.cfg.ExportFormat {
+	case "pdf":
+		return pdf.Write(w, analysis, r)
+	case "ics":
+		return r.WriteICS(w)
+	case "analysis":
+		data, err := claudecosts.MarshalAnalysis(analysis)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case "csv":
+		if table == "" {
+			table = "daily"
+		}
+		return r.WriteCSV(w, table)
+	case "jsonl":
+		if table == "" {
+			table = "sessions"
+		}
+		return r.WriteJSONL(w, table)
+	}
+
+	var v interface{} = r
+	if table != "" {
+		if v, err = r.Table(table); err != nil {
+			return err
+		}
+	}
+	if query != nil {
+		return query.Write(w, v)
+	}
+	return report.WriteJSON(w, v)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
+	"github.com/spf13/cobra"
+)
+
+// newGenFixtureCmd builds the gen-fixture subcommand
+func newGenFixtureCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-fixture",
+		Short: "Generate a synthetic Claude directory for benchmarks and bug reports",
+		Long: "gen-fixture writes project directories of synthetic session logs shaped\n" +
+			"like Claude Code's under --out, spread over the last --days. Point\n" +
+			"--claude-dir at the result to reproduce a problem without sharing real\n" +
+			"transcripts. The same --seed writes the same logs, apart from their dates,\n" +
+			"which end at --now.\n" +
+			"Go tests can generate the same trees with pkg/claudecosts/fixture.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.genFixture() }),
+	}
+
+	opts := &cfg.Fixture
+	flags := cmd.Flags()
+	flags.StringVarP(&cfg.Output, "out", "o", "", "Directory to write, as a Claude directory")
+	flags.Uint64Var(&opts.Seed, "seed", opts.Seed, "Random seed")
+	flags.IntVar(&opts.Projects, "projects", opts.Projects, "Number of projects")
+	flags.IntVar(&opts.Sessions, "sessions", opts.Sessions, "Sessions per project")
+	flags.StringVar(&cfg.FixtureSize, "size", "", "Write sessions until the logs total about this `size`, such as 1GB, instead of --sessions per project")
+	flags.IntVar(&opts.Turns, "turns", opts.Turns, "Prompts per session")
+	flags.StringSliceVar(&opts.Models, "models", opts.Models, "Models to pick from for each session")
+	flags.Float64Var(&opts.CacheHitRate, "cache-hit", opts.CacheHitRate, "Share of context read from the prompt cache, 0 to 1")
+	flags.Float64Var(&opts.MalformedRate, "malformed", opts.MalformedRate, "Share of lines written truncated, 0 to 1")
+
+	return cmd
+}
+
+// genFixture writes the synthetic tree and describes it
+func (a *app) genFixture() error {
+	if a.cfg.Output == "" {
+		return errors.New("gen-fixture needs --out")
+	}
+	opts := a.cfg.Fixture
+	opts.Days = a.cfg.Days
+	opts.End = a.cfg.Clock()
+	if a.cfg.FixtureSize != "" {
+		// Validated with the rest of the configuration
+		opts.Size, _ = config.ParseSize(a.cfg.FixtureSize)
+	}
+	summary, err := fixture.Generate(a.cfg.Output, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d sessions, %d lines (%d malformed) in %.1f MB, worth $%.2f at list prices\n",
+		summary.Files, summary.Lines, summary.Malformed, float64(summary.Bytes)/1e6, summary.Cost)
+	fmt.Printf("Analyze them with: claude-costs --claude-dir %s\n", a.cfg.Output)
+	return nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/logging"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/profiling"
+	"github.com/photostructure/go-claude-costs/internal/rules"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/internal/store"
+	"github.com/photostructure/go-claude-costs/internal/termimg"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// newRootCmd builds the claude-costs command. Run without a subcommand it
+// behaves like summary.
+func newRootCmd() *cobra.Command {
+	cfg := config.NewDefault()
+
+	cmd := &cobra.Command{
+		Use:   "claude-costs",
+		Short: "Analyze Claude Code usage costs and statistics",
+		Long: "claude-costs reads the JSONL metadata Claude Code stores locally and\n" +
+			"reports API-equivalent costs, token usage, and activity patterns.\n" +
+			"Without a subcommand it prints the summary.\n\n" +
+			exitCodeHelp,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// --check signals only through the exit code
+			cmd.SilenceErrors = cfg.Check
+		},
+		RunE: runE(cfg, func(a *app) error { return a.summary(false) }),
+	}
+
+	// Flags shared by every subcommand
+	flags := cmd.PersistentFlags()
+	flags.IntVarP(&cfg.Days, "days", "d", cfg.Days, "Number of days to analyze")
+	flags.BoolVarP(&cfg.Verbose, "verbose", "v", cfg.Verbose, "Show every row of ranked lists, the session table, and the detailed sections")
+	flags.IntVar(&cfg.Top, "top", cfg.Top, "Rows to show of ranked lists such as projects, sessions, and models (0 for all)")
+	flags.StringVarP(&cfg.ClaudeDir, "claude-dir", "c", cfg.ClaudeDir, "Path to Claude directory")
+	flags.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "Path to TOML config file")
+	flags.StringVar(&cfg.DB, "db", cfg.DB, "Path to the index `file` kept by the daemon command, used when it exists: SQLite, or JSON if it ends in .json")
+	flags.BoolVar(&cfg.NoCache, "no-cache", false, "Parse the JSONL files directly, ignoring the index")
+	flags.StringVar(&cfg.FromAnalysis, "from-analysis", "", "Report on an analysis saved by export --format analysis instead of parsing the logs")
+	flags.BoolVar(&cfg.NoSymlinks, "no-symlinks", false, "Skip symlinked project directories and files instead of following them")
+	flags.StringVar(&cfg.Profile, "profile", "", "Use the [profiles.`name`] section of the config file")
+	flags.StringArrayVar(&cfg.Projects, "project", nil, "Only analyze projects matching this pattern (repeatable)")
+	flags.StringVar(&cfg.Account, "account", "", "Only analyze this account, by name or ID")
+	flags.StringVar(&cfg.GroupBy, "group-by", cfg.GroupBy, "Break costs down by project or tag")
+	flags.Float64Var(&cfg.CacheAlert, "cache-alert", cfg.CacheAlert, "Warn when the latest day's cache hit rate falls below this percentage")
+	flags.StringArrayVar(&cfg.RejectionPatterns, "reject-pattern", nil, "Additional tool_result text marking a tool use as rejected (repeatable)")
+	flags.BoolVar(&cfg.Strict, "strict", false, "Fail when any line or file can't be parsed")
+	flags.BoolVar(&cfg.UTCDays, "utc-days", false, "Count daily totals by UTC day instead of local day")
+	flags.IntVar(&cfg.DayStartHour, "day-start-hour", 0, "Start days at this `hour` (0-23), so late-night sessions count toward the day before")
+	flags.StringVar(&cfg.Now, "now", "", "Report as of this RFC 3339 `time` instead of the current time, for reproducible output")
+
+	flags.DurationVar(&cfg.ResponseMin, "response-min", cfg.ResponseMin, "Discard response times shorter than this as outliers")
+	flags.DurationVar(&cfg.ResponseMax, "response-max", cfg.ResponseMax, "Discard response times of at least this as outliers (0 for no limit)")
+	flags.BoolVar(&cfg.ExcludeSkewed, "exclude-skewed", false, "Discard response and turn times involving entries timestamped out of order")
+	flags.StringVar(&cfg.MaxMemory, "max-memory", "", "Stay within this much memory, such as 2GiB, skipping response and turn times if parsing nears it")
+
+	flags.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Log level: debug, info, warn, or error")
+	flags.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "Log format: text or json")
+
+	flags.StringVar(&cfg.CPUProfile, "cpuprofile", "", "Write a CPU profile to `file`")
+	flags.StringVar(&cfg.MemProfile, "memprofile", "", "Write a heap profile to `file` on exit")
+	flags.StringVar(&cfg.TraceFile, "trace", "", "Write an execution trace to `file`")
+
+	// Bare claude-costs is an alias for summary, so it takes summary's flags
+	addSummaryFlags(cmd.Flags(), cfg)
+
+	cmd.AddCommand(
+		newSummaryCmd(cfg),
+		newReportCmd(cfg),
+		newDailyCmd(cfg),
+		newSessionsCmd(cfg),
+		newProjectsCmd(cfg),
+		newBlocksCmd(cfg),
+		newPlansCmd(cfg),
+		newWatchCmd(cfg),
+		newTopCmd(cfg),
+		newPromptSegmentCmd(cfg),
+		newEstimateCmd(cfg),
+		newTokensCmd(cfg),
+		newGenFixtureCmd(cfg),
+		newServeCmd(cfg),
+		newExportCmd(cfg),
+		newDoctorCmd(cfg),
+		newDaemonCmd(cfg),
+		newReindexCmd(cfg),
+		newPushCmd(cfg),
+		newChartCmd(cfg),
+		newDiffCmd(cfg),
+	)
+
+	return cmd
+}
+
+// addEventsFlag adds --events to the commands that forward new messages
+func addEventsFlag(flags *pflag.FlagSet, cfg *config.Config) {
+	flags.StringVar(&cfg.Events, "events", "", "Send each new message to this file, named pipe, http(s) URL, kafka://BROKERS/TOPIC, or nats://HOST/SUBJECT")
+}
+
+// addBlockLengthFlag adds --block-length to commands that show billing blocks
+func addBlockLengthFlag(flags *pflag.FlagSet, cfg *config.Config) {
+	flags.DurationVar(&cfg.BlockLength, "block-length", cfg.BlockLength, "Length of a billing block")
+}
+
+// runE adapts fn to a cobra RunE that loads the config file and sets up
+// logging and profiling first
+func runE(cfg *config.Config, fn func(a *app) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := loadConfigFile(cmd, cfg); err != nil {
+			return err
+		}
+		if err := checkFromAnalysis(cmd, cfg); err != nil {
+			return err
+		}
+		return runApp(cfg, fn)
+	}
+}
+
+// loadConfigFile applies the TOML config file, and the --profile section of
+// it, to cfg. Flags given on the command line override the file. The default
+// path may be absent.
+func loadConfigFile(cmd *cobra.Command, cfg *config.Config) error {
+	file, err := config.LoadFile(cfg.ConfigFile, !cmd.Flags().Changed("config"))
+	if err != nil {
+		return err
+	}
+	if file, err = file.Profile(cfg.Profile); err != nil {
+		return fmt.Errorf("%w: %s: %v", claudecosts.ErrInvalidConfig, cfg.ConfigFile, err)
+	}
+	cfg.ApplyFile(file, cmd.Flags().Changed)
+
+	// Each profile keeps its own --since-last-run high-water mark
+	if cfg.Profile != "" && !cmd.Flags().Changed("state") {
+		cfg.StateFile = state.ProfilePath(cfg.StateFile, cfg.Profile)
+	}
+	return nil
+}
+
+// checkFromAnalysis rejects --from-analysis with the flags that choose
+// what's parsed, since a saved analysis is reported as it was saved
+func checkFromAnalysis(cmd *cobra.Command, cfg *config.Config) error {
+	if cfg.FromAnalysis == "" {
+		return nil
+	}
+	switch {
+	case cmd.Flags().Changed("days"):
+		return fmt.Errorf("--from-analysis can't be combined with --days")
+	case len(cfg.Projects) > 0:
+		return fmt.Errorf("--from-analysis can't be combined with --project")
+	case cfg.Account != "":
+		return fmt.Errorf("--from-analysis can't be combined with --account")
+	}
+	return nil
+}
+
+// app is what a command needs once flags and the config file are applied
+type app struct {
+	cfg    *config.Config
+	logger *slog.Logger
+}
+
+// runApp sets up logging, validates cfg, and runs fn with profiling enabled
+func runApp(cfg *config.Config, fn func(a *app) error) (err error) {
+	logOutput := io.Writer(os.Stderr)
+	if cfg.Check {
+		logOutput = io.Discard
+	}
+	logger, err := logging.New(logOutput, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	if err := cfg.Validate(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s", claudecosts.ErrNoClaudeDir, cfg.ClaudeDir)
+		}
+		return fmt.Errorf("%w: %v", claudecosts.ErrInvalidConfig, err)
+	}
+	// The runtime collects garbage harder as the heap nears the limit, and
+	// the parser sheds what it can before reaching it
+	if limit, _ := cfg.MemoryLimit(); limit > 0 {
+		debug.SetMemoryLimit(limit)
+	}
+
+	prof, err := profiling.Start(profiling.Options{
+		CPUProfile: cfg.CPUProfile,
+		MemProfile: cfg.MemProfile,
+		Trace:      cfg.TraceFile,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if stopErr := prof.Stop(); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}()
+
+	return fn(&app{cfg: cfg, logger: logger})
+}
+
+// parse reads the Claude directory, through the index when it exists. When
+// since is set only newer entries are included.
+func (a *app) parse(since time.Time, extra ...parser.Option) (*claudecosts.Analysis, error) {
+	if a.cfg.FromAnalysis != "" {
+		return a.loadAnalysis(since, extra)
+	}
+	if !a.cfg.NoCache && store.Exists(a.cfg.DB) {
+		return a.parseIndex(since, extra...)
+	}
+	return a.parseWith(since, extra...)
+}
+
+// loadAnalysis reads a saved analysis. It's reported as it was saved, so
+// it can't be narrowed to entries since a time, and commands that need
+// more from the parse than the analysis can't use it.
+func (a *app) loadAnalysis(since time.Time, extra []parser.Option) (*claudecosts.Analysis, error) {
+	if !since.IsZero() {
+		return nil, fmt.Errorf("--from-analysis can't be combined with --since or --since-last-run")
+	}
+	if len(extra) > 0 {
+		return nil, fmt.Errorf("--from-analysis can't be used with this command")
+	}
+	return readAnalysis(a.cfg.FromAnalysis)
+}
+
+// parseIndex brings the index up to date with the Claude directory and
+// parses from it
+func (a *app) parseIndex(since time.Time, extra ...parser.Option) (*claudecosts.Analysis, error) {
+	db, err := store.Open(a.cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	stats, err := db.Ingest(context.Background(), a.cfg.ClaudeDir, a.findOptions()...)
+	if err != nil {
+		a.logger.Warn("failed to update index", "db", a.cfg.DB, "error", err)
+	}
+	a.logger.Debug("updated index", "db", a.cfg.DB, "files", stats.Files, "lines", stats.Lines)
+
+	return a.parseWith(since, append([]parser.Option{parser.WithLineSource(db)}, extra...)...)
+}
+
+// parseWith parses with the options from the configuration plus extra
+func (a *app) parseWith(since time.Time, extra ...parser.Option) (*claudecosts.Analysis, error) {
+	limit, _ := a.cfg.MemoryLimit()
+	opts := []parser.Option{
+		parser.WithLogger(a.logger),
+		parser.WithResponseTimeBounds(a.cfg.ResponseMin, a.cfg.ResponseMax),
+		parser.WithExcludeSkewed(a.cfg.ExcludeSkewed),
+		parser.WithMemoryLimit(limit),
+		parser.WithDayBoundary(a.cfg.DayBoundary()),
+		parser.WithRejectionPatterns(a.cfg.RejectionPatterns...),
+		parser.WithProjectAliases(a.cfg.Aliases),
+		parser.WithTagRules(a.cfg.TagRules),
+		parser.WithPricingRules(a.cfg.PricingRules),
+		parser.WithProjectFilter(a.cfg.Projects...),
+		parser.WithAccountNames(a.cfg.AccountNames),
+		parser.WithAccount(a.cfg.Account),
+		parser.WithFindOptions(a.findOptions()...),
+	}
+	if !since.IsZero() {
+		opts = append(opts, parser.WithSince(since))
+	}
+	if a.cfg.Now != "" {
+		opts = append(opts, parser.WithNow(a.cfg.Clock()))
+	}
+	opts = append(opts, extra...)
+	return parser.New(a.cfg.Days, a.cfg.ClaudeDir, opts...).ParseAll()
+}
+
+// findOptions configures how files are found in the Claude directory
+func (a *app) findOptions() []parser.FindOption {
+	if a.cfg.NoSymlinks {
+		return []parser.FindOption{parser.SkipSymlinks()}
+	}
+	return nil
+}
+
+// analyze is parse followed by checkAnalysis, for commands that report once
+func (a *app) analyze(since time.Time) (*claudecosts.Analysis, error) {
+	analysis, err := a.parse(since)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAnalysis(analysis, a.cfg, since); err != nil {
+		return nil, err
+	}
+	return analysis, nil
+}
+
+// checkAnalysis fails on parse errors in strict mode and when there is no
+// activity to report
+func checkAnalysis(analysis *claudecosts.Analysis, cfg *config.Config, since time.Time) error {
+	if cfg.Strict && analysis.ParseErrors > 0 {
+		return fmt.Errorf("%w: %d malformed entries or unreadable files", claudecosts.ErrParsingFailed, analysis.ParseErrors)
+	}
+	if analysis.EndDate.IsZero() {
+		if !since.IsZero() {
+			return fmt.Errorf("%w since %s", claudecosts.ErrNoData, since.Local().Format("2006-01-02 15:04"))
+		}
+		return fmt.Errorf("%w in the last %d days", claudecosts.ErrNoData, cfg.Days)
+	}
+	return nil
+}
+
+// newDisplay creates a Display configured from cfg
+func newDisplay(analysis *claudecosts.Analysis, cfg *config.Config) *display.Display {
+	verbosity := display.Normal
+	switch {
+	case cfg.Quiet:
+		verbosity = display.Quiet
+	case cfg.Verbose:
+		verbosity = display.Verbose
+	}
+	d := display.New(analysis, verbosity, cfg.ShowCache)
+	d.SetTop(cfg.Top)
+	d.SetCacheAlert(cfg.CacheAlert)
+	d.SetAnomalyThresholds(calculator.AnomalyThresholds{StdDevs: cfg.AnomalyStdDev, MedianMultiple: cfg.AnomalyMedian})
+	d.SetShowTools(cfg.ShowTools)
+	d.SetGroupBy(cfg.GroupBy)
+	d.SetBlockLength(cfg.BlockLength)
+	d.SetGraphics(graphicsProtocol(cfg.Graphics))
+	d.SetClock(cfg.Clock)
+	if cfg.BillingDay > 0 {
+		// Validate has already checked the plan exists
+		plan, _ := limits.Lookup(cfg.Plan, cfg.Plans)
+		d.SetPlan(cfg.Plan, plan)
+		d.SetBillingDay(cfg.BillingDay)
+	}
+	d.SetTimeSavedRates(calculator.TimeSavedRates{
+		PerToolAction: cfg.MinutesPerToolAction,
+		PerMessage:    cfg.MinutesPerMessage,
+		Per100Lines:   cfg.MinutesPer100Lines,
+	})
+	if cfg.FromAnalysis != "" {
+		d.SetClaudeDir(cfg.FromAnalysis)
+	} else {
+		d.SetClaudeDir(cfg.ClaudeDir)
+	}
+	if cfg.ShowHours || cfg.HoursFrom != "" || cfg.HoursTo != "" {
+		// Validate has already checked the range
+		from, to, _ := cfg.HoursRange(cfg.Clock())
+		d.SetShowHours(from, to)
+	}
+	if cfg.WorkHours != "" {
+		// Validate has already parsed these
+		if workHours, err := rules.ParseWorkHours(cfg.WorkHours, cfg.WorkDays); err == nil {
+			d.SetWorkHours(workHours)
+		}
+	}
+	return d
+}
+
+// graphicsProtocol resolves --graphics. auto only draws images when stdout
+// is a terminal that's known to support them.
+func graphicsProtocol(setting string) termimg.Protocol {
+	if setting != "auto" {
+		return termimg.Protocol(setting)
+	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return termimg.None
+	}
+	return termimg.Detect(os.Getenv)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromAnalysis_RejectsParseFlags(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "costs.analysis")
+	for _, flags := range [][]string{
+		{"--days", "7"},
+		{"--project", "api"},
+		{"--account", "work"},
+	} {
+		t.Run(flags[0], func(t *testing.T) {
+			cmd := newRootCmd()
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(append([]string{"--config", filepath.Join(t.TempDir(), "none.toml"), "--from-analysis", saved}, flags...))
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), flags[0]) {
+				t.Errorf("err = %v, want --from-analysis rejecting %s", err, flags[0])
+			}
+		})
+	}
+}
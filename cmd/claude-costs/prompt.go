@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// newPromptSegmentCmd builds the prompt-segment subcommand
+func newPromptSegmentCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt-segment",
+		Short: "Print today's cost as a colored segment for a shell prompt",
+		Long: "prompt-segment prints today's API value, such as $3.82, in green, turning\n" +
+			"yellow at --warn and red at --alert dollars. --format escapes the color\n" +
+			"for the prompt it's embedded in: ansi for starship and fish, zsh for zsh\n" +
+			"and powerlevel10k, bash for PS1, tmux for the status line, or plain.\n\n" +
+			"Only today's entries are counted, read through the index when the daemon\n" +
+			"keeps one, so it's quick enough to run for every prompt.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.promptSegment() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.PromptFormat, "format", cfg.PromptFormat, "Color escapes: ansi, zsh, bash, tmux, or plain")
+	flags.Float64Var(&cfg.PromptWarn, "warn", cfg.PromptWarn, "Turn yellow at this many `dollars` today (0 to disable)")
+	flags.Float64Var(&cfg.PromptAlert, "alert", cfg.PromptAlert, "Turn red at this many `dollars` today (0 to disable)")
+
+	return cmd
+}
+
+// promptSegment prints today's cost, colored by how close it is to the
+// thresholds. A day without activity prints $0.00 rather than failing.
+func (a *app) promptSegment() error {
+	now := a.cfg.Clock()
+	analysis, err := a.parse(a.cfg.DayBoundary().Start(now))
+	if err != nil {
+		return err
+	}
+
+	cost := analysis.TotalCost
+	level := prompt.LevelOf(cost, a.cfg.PromptWarn, a.cfg.PromptAlert)
+	fmt.Println(prompt.Segment(fmt.Sprintf("$%.2f", cost), level, prompt.Format(a.cfg.PromptFormat)))
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+// newPushCmd builds the push subcommand
+func newPushCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Write daily and per-project metrics to InfluxDB or Graphite",
+		Long: "push writes a claude_daily measurement for each day in the window (cost,\n" +
+			"messages, tokens, and cache hit rate) and a claude_project measurement with\n" +
+			"each project's cost that day. Points are timestamped at midnight, so pushing\n" +
+			"the same days again overwrites them; run it from cron to keep a dashboard\n" +
+			"current.\n\n" +
+			"InfluxDB 1.8+ takes --bucket database/retention-policy and\n" +
+			"--influx-token user:password. Destinations and tokens can be set in the\n" +
+			"config file's [push] section.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.push() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.Influx, "influx", "", "InfluxDB `URL` to write to")
+	flags.StringVar(&cfg.InfluxOrg, "org", "", "InfluxDB organization")
+	flags.StringVar(&cfg.InfluxBucket, "bucket", "", "InfluxDB bucket")
+	flags.StringVar(&cfg.InfluxToken, "influx-token", "", "InfluxDB API `token`")
+	flags.StringVar(&cfg.Graphite, "graphite", "", "Graphite plaintext listener to write to, e.g. localhost:2003")
+	flags.StringVar(&cfg.GraphitePrefix, "graphite-prefix", cfg.GraphitePrefix, "Prefix of Graphite metric paths")
+
+	return cmd
+}
+
+// push writes the window's measurements to each configured destination
+func (a *app) push() error {
+	if a.cfg.Influx == "" && a.cfg.Graphite == "" {
+		return errors.New("push needs --influx or --graphite")
+	}
+
+	analysis, err := a.analyze(time.Time{})
+	if err != nil {
+		return err
+	}
+	points := metrics.Points(analysis)
+	ctx := context.Background()
+
+	if a.cfg.Influx != "" {
+		dest := metrics.Influx{
+			URL:    a.cfg.Influx,
+			Org:    a.cfg.InfluxOrg,
+			Bucket: a.cfg.InfluxBucket,
+			Token:  a.cfg.InfluxToken,
+		}
+		if err := metrics.WriteInflux(ctx, http.DefaultClient, dest, points); err != nil {
+			return err
+		}
+		a.logger.Info("pushed to InfluxDB", "points", len(points), "bucket", a.cfg.InfluxBucket)
+	}
+	if a.cfg.Graphite != "" {
+		if err := metrics.WriteGraphite(ctx, a.cfg.Graphite, a.cfg.GraphitePrefix, points); err != nil {
+			return err
+		}
+		a.logger.Info("pushed to Graphite", "points", len(points), "addr", a.cfg.Graphite)
+	}
+	return nil
+}
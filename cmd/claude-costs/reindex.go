@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/store"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+)
+
+// newReindexCmd builds the reindex subcommand
+func newReindexCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the SQLite index and verify it against a full parse",
+		Long: "reindex rebuilds the SQLite index (--db) from the JSONL files, then checks\n" +
+			"that every line is stored and that the index gives the same totals as\n" +
+			"parsing the files directly. Lines of transcripts that were deleted can't\n" +
+			"be read again and are kept.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.reindex(os.Stdout) }),
+	}
+}
+
+// reindex rebuilds the index and reports any discrepancies with the files
+func (a *app) reindex(w io.Writer) error {
+	ctx := context.Background()
+
+	db, err := store.Open(a.cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := db.Reindex(ctx, a.cfg.ClaudeDir, a.findOptions()...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ indexed %d lines from %d files into %s\n", stats.Lines, stats.Files, a.cfg.DB)
+
+	discrepancies := 0
+	lineDiffs, err := db.Verify(ctx, a.cfg.ClaudeDir, a.findOptions()...)
+	if err != nil {
+		return err
+	}
+	for _, d := range lineDiffs {
+		fmt.Fprintf(w, "✗ %s has %d lines, the index %d\n", d.Path, d.FileLines, d.IndexLines)
+	}
+	discrepancies += len(lineDiffs)
+
+	files, err := parser.FindFiles(a.cfg.ClaudeDir, a.findOptions()...)
+	if err != nil {
+		return err
+	}
+	direct, err := a.parseWith(time.Time{})
+	if err != nil {
+		return err
+	}
+	indexed, err := a.parseWith(time.Time{}, parser.WithLineSource(diskFiles{db, files}))
+	if err != nil {
+		return err
+	}
+	totalDiffs := compareAnalyses(direct, indexed)
+	for _, diff := range totalDiffs {
+		fmt.Fprintf(w, "✗ %s\n", diff)
+	}
+	discrepancies += len(totalDiffs)
+
+	if discrepancies > 0 {
+		return fmt.Errorf("index doesn't match the logs: %d discrepancies", discrepancies)
+	}
+	fmt.Fprintf(w, "✓ index matches a full parse of the last %d days\n", a.cfg.Days)
+	return nil
+}
+
+// diskFiles limits a store to the files currently on disk, so a parse
+// through it can be compared with a parse of the files
+type diskFiles struct {
+	*store.Store
+	files []string
+}
+
+// Files returns the files on disk
+func (d diskFiles) Files(string) ([]string, error) {
+	return d.files, nil
+}
+
+// compareAnalyses describes each total that differs between a parse of the
+// files and a parse of the index
+func compareAnalyses(direct, indexed *claudecosts.Analysis) []string {
+	var diffs []string
+	ints := []struct {
+		name            string
+		direct, indexed int
+	}{
+		{"sessions", len(direct.Sessions), len(indexed.Sessions)},
+		{"input tokens", direct.TotalInputTokens, indexed.TotalInputTokens},
+		{"output tokens", direct.TotalOutputTokens, indexed.TotalOutputTokens},
+		{"cache read tokens", direct.TotalCacheRead, indexed.TotalCacheRead},
+		{"cache write tokens", direct.TotalCacheWrite, indexed.TotalCacheWrite},
+		{"parse errors", direct.ParseErrors, indexed.ParseErrors},
+	}
+	for _, total := range ints {
+		if total.direct != total.indexed {
+			diffs = append(diffs, fmt.Sprintf("%s: %d from the files, %d from the index", total.name, total.direct, total.indexed))
+		}
+	}
+
+	// Costs are sums of floats, which may be added in a different order
+	if math.Abs(direct.TotalCost-indexed.TotalCost) > 1e-6 {
+		diffs = append(diffs, fmt.Sprintf("cost: $%.6f from the files, $%.6f from the index", direct.TotalCost, indexed.TotalCost))
+	}
+	return diffs
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+)
+
+func TestCompareAnalyses(t *testing.T) {
+	direct := &claudecosts.Analysis{
+		Sessions:          map[string]*models.SessionStats{"a": {}, "b": {}},
+		TotalCost:         1.25,
+		TotalOutputTokens: 500,
+	}
+	same := &claudecosts.Analysis{
+		Sessions:          map[string]*models.SessionStats{"a": {}, "b": {}},
+		TotalCost:         1.25 + 1e-12,
+		TotalOutputTokens: 500,
+	}
+	if diffs := compareAnalyses(direct, same); len(diffs) != 0 {
+		t.Errorf("matching analyses: diffs = %q, want none", diffs)
+	}
+
+	missing := &claudecosts.Analysis{
+		Sessions:          map[string]*models.SessionStats{"a": {}},
+		TotalCost:         1.00,
+		TotalOutputTokens: 500,
+	}
+	if diffs := compareAnalyses(direct, missing); len(diffs) != 2 {
+		t.Errorf("diffs = %q, want sessions and cost", diffs)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// newServeCmd builds the serve subcommand
+func newServeCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the analysis as JSON over HTTP",
+		Long: "serve re-reads the Claude directory every --interval and serves the result:\n\n" +
+			"  GET /healthz        liveness and last update time\n" +
+			"  GET /openapi.json   OpenAPI description of the API\n" +
+			"  GET /api/summary    the full report\n" +
+			"  GET /api/{table}    daily, projects, sessions, models, or blocks\n" +
+			"  GET /ws             WebSocket of new messages and cost deltas as they're found\n" +
+			"  POST /api/refresh   re-read the Claude directory now (not with --read-only)\n\n" +
+			"With --grpc-addr, the same queries and a stream of events are also served\n" +
+			"over gRPC; see proto/claudecosts/v1/claudecosts.proto.\n\n" +
+			"Browsers may open /ws only from the same origin or a --ws-origin.\n" +
+			"With --pprof, runtime profiles are served under /debug/pprof/.\n\n" +
+			"Before listening beyond localhost, require a --token or --basic-auth and\n" +
+			"serve HTTPS with --tls-cert and --tls-key. --tls-client-ca also requires\n" +
+			"client certificates. These can be set in the config file's [serve] section,\n" +
+			"which keeps secrets out of the process list.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.serve() }),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.Addr, "addr", cfg.Addr, "Address to listen on")
+	flags.DurationVar(&cfg.Interval, "interval", cfg.Interval, "How often to re-read the Claude directory")
+	flags.BoolVar(&cfg.Pprof, "pprof", false, "Serve runtime profiles under /debug/pprof/")
+	flags.StringArrayVar(&cfg.WSOrigins, "ws-origin", nil, "Allow browsers on this host `pattern` to open /ws, e.g. *.example.com (repeatable)")
+	flags.StringVar(&cfg.GRPCAddr, "grpc-addr", "", "Also serve the gRPC API on this address")
+	flags.StringVar(&cfg.Token, "token", "", "Require this bearer `token`")
+	flags.StringVar(&cfg.BasicAuth, "basic-auth", "", "Require basic auth with these `user:password` credentials")
+	flags.StringVar(&cfg.TLSCert, "tls-cert", "", "Serve HTTPS with this certificate `file`")
+	flags.StringVar(&cfg.TLSKey, "tls-key", "", "Private key `file` for --tls-cert")
+	flags.StringVar(&cfg.TLSClientCA, "tls-client-ca", "", "Require client certificates signed by this CA `file` (mutual TLS)")
+	flags.BoolVar(&cfg.ReadOnly, "read-only", false, "Disable endpoints that change state, such as POST /api/refresh")
+	addBlockLengthFlag(flags, cfg)
+
+	return cmd
+}
+
+// serve runs the HTTP server until interrupted
+func (a *app) serve() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		srv       *server.Server
+		refreshMu sync.Mutex
+	)
+	// The ticker and POST /api/refresh may both ask at once
+	refresh := func() {
+		refreshMu.Lock()
+		defer refreshMu.Unlock()
+		analysis, err := a.parse(time.Time{})
+		if err != nil {
+			a.logger.Warn("failed to refresh analysis", "error", err)
+			return
+		}
+		srv.Update(analysis)
+	}
+
+	basicUser, basicPassword, _ := strings.Cut(a.cfg.BasicAuth, ":")
+	srv = server.New(server.Options{
+		Logger:        a.logger,
+		BlockLength:   a.cfg.BlockLength,
+		Pprof:         a.cfg.Pprof,
+		WSOrigins:     a.cfg.WSOrigins,
+		Token:         a.cfg.Token,
+		BasicUser:     basicUser,
+		BasicPassword: basicPassword,
+		Refresh:       refresh,
+		ReadOnly:      a.cfg.ReadOnly,
+	})
+	refresh()
+
+	tlsConfig, err := server.TLSConfig(a.cfg.TLSCert, a.cfg.TLSKey, a.cfg.TLSClientCA)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", a.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+
+	go func() {
+		ticker := time.NewTicker(a.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	secure := tlsConfig != nil
+	authenticated := a.cfg.Token != "" || a.cfg.BasicAuth != "" || a.cfg.TLSClientCA != ""
+	const unauthenticated = "serving without authentication beyond localhost; set --token or --basic-auth"
+
+	if a.cfg.GRPCAddr != "" {
+		grpcListener, err := net.Listen("tcp", a.cfg.GRPCAddr)
+		if err != nil {
+			return err
+		}
+		if !authenticated && !isLoopback(grpcListener.Addr()) {
+			a.logger.Warn(unauthenticated, "addr", grpcListener.Addr().String())
+		}
+		grpcServer := srv.GRPCServer(tlsConfig)
+		go func() {
+			<-ctx.Done()
+			// Streams only end when their clients go away, so don't wait long
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				grpcServer.Stop()
+			}
+		}()
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				a.logger.Error("gRPC server failed", "error", err)
+			}
+		}()
+		a.logger.Info("serving gRPC", "addr", grpcListener.Addr().String())
+	}
+
+	if !authenticated && !isLoopback(listener.Addr()) {
+		a.logger.Warn(unauthenticated, "addr", listener.Addr().String())
+	}
+	a.logger.Info("serving", "addr", listener.Addr().String(), "tls", secure,
+		"auth", authenticated, "read_only", a.cfg.ReadOnly, "pprof", a.cfg.Pprof)
+
+	if secure {
+		// The certificate is already in TLSConfig
+		err = httpServer.ServeTLS(listener, "", "")
+	} else {
+		err = httpServer.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopback reports whether addr only accepts local connections
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/invoice"
+	"github.com/photostructure/go-claude-costs/internal/notify"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newSummaryCmd builds the summary subcommand, the full report
+func newSummaryCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Print the full cost and activity report (the default)",
+		Args:  cobra.NoArgs,
+		RunE:  runE(cfg, func(a *app) error { return a.summary(false) }),
+	}
+	addSummaryFlags(cmd.Flags(), cfg)
+	return cmd
+}
+
+// newReportCmd builds the report subcommand, which is meant to be run from
+// cron: with --since-last-run it covers only activity since the previous run
+func newReportCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print a report, optionally covering only activity since the last run",
+		Long: "report prints the same summary as claude-costs. With --since-last-run it\n" +
+			"remembers the newest entry reported and next time covers only newer\n" +
+			"activity, e.g. \"what you spent since yesterday's email\". The first run\n" +
+			"covers the --days window.\n\n" +
+			"With --slack-webhook or --discord-webhook it also posts a compact summary\n" +
+			"of one day (yesterday by default) to the webhook.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.summary(true) }),
+	}
+
+	flags := cmd.Flags()
+	addSummaryFlags(flags, cfg)
+	flags.BoolVar(&cfg.SinceLastRun, "since-last-run", false, "Only report activity since the previous --since-last-run")
+	flags.StringVar(&cfg.StateFile, "state", cfg.StateFile, "Path to the state `file` holding the last run's high-water mark")
+	flags.StringVar(&cfg.SlackWebhook, "slack-webhook", "", "Post the daily summary to this Slack incoming webhook `url`")
+	flags.StringVar(&cfg.DiscordWebhook, "discord-webhook", "", "Post the daily summary to this Discord webhook `url`")
+	flags.StringVar(&cfg.NotifyDate, "notify-date", cfg.NotifyDate, "Day the webhook summary covers: today, yesterday, or YYYY-MM-DD")
+
+	return cmd
+}
+
+// addSummaryFlags adds the flags of the summary report
+func addSummaryFlags(flags *pflag.FlagSet, cfg *config.Config) {
+	flags.BoolVarP(&cfg.Quiet, "quiet", "q", cfg.Quiet, "Print only the total on one line, for shell prompts and scripts")
+	flags.BoolVar(&cfg.OneLine, "oneline", false, "Print today's, the window's, and the current block's cost on one line for a shell prompt")
+	flags.BoolVar(&cfg.Bench, "bench", false, "Time parsing the Claude directory and report its throughput instead of the summary")
+	flags.BoolVar(&cfg.ShowCache, "cache", cfg.ShowCache, "Show detailed cache statistics")
+	flags.BoolVar(&cfg.ShowTools, "tools", cfg.ShowTools, "Show detailed tool usage statistics")
+	flags.BoolVar(&cfg.Commits, "commits", false, "Run git log in each project directory and show cost per commit")
+
+	flags.StringVar(&cfg.Format, "format", cfg.Format, "Output format: text, or gha for GitHub Actions annotations and job summary")
+	flags.BoolVar(&cfg.ShowHours, "hours", false, "Show cost and tokens by hour of day")
+	flags.StringVar(&cfg.HoursFrom, "hours-from", "", "Limit --hours to activity from this `day`: YYYY-MM-DD, today, yesterday, or a weekday such as monday")
+	flags.StringVar(&cfg.HoursTo, "hours-to", "", "Limit --hours to activity through this `day`")
+	flags.StringVar(&cfg.WorkHours, "work-hours", "", "Split cost between these working `hours`, e.g. 09:00-18:00, and off hours")
+	flags.StringVar(&cfg.WorkDays, "work-days", cfg.WorkDays, "Working `days` for --work-hours, e.g. mon-fri or mon,wed,fri")
+	flags.Float64Var(&cfg.AnomalyStdDev, "anomaly-stddev", cfg.AnomalyStdDev, "Flag days and sessions costing this many standard deviations above the trailing mean (0 to disable)")
+	flags.Float64Var(&cfg.AnomalyMedian, "anomaly-median", cfg.AnomalyMedian, "Flag days and sessions costing more than this many times the trailing median (0 to disable)")
+	flags.StringVar(&cfg.Graphics, "graphics", cfg.Graphics, "Draw charts as inline images: auto, kitty, iterm, sixel, or none for text")
+	flags.Float64Var(&cfg.FailOver, "fail-over", 0, "Exit with an error when API value exceeds this many `dollars`")
+	flags.BoolVar(&cfg.Check, "check", false, "Print nothing and report only through the exit code")
+
+	flags.StringVar(&cfg.InvoiceFile, "invoice", "", "Reconcile against an Anthropic cost CSV `file`")
+	flags.Float64Var(&cfg.InvoiceTolerance, "invoice-tolerance", cfg.InvoiceTolerance, "Flag days where local and invoiced cost differ by more than this percentage")
+}
+
+// summary prints the report. When notify is set, the daily summary is also
+// posted to the configured webhooks.
+func (a *app) summary(notify bool) error {
+	cfg := a.cfg
+	if cfg.OneLine {
+		return a.oneLine()
+	}
+	if cfg.Bench {
+		return a.bench()
+	}
+
+	var runState *state.State
+	var since time.Time
+	if cfg.SinceLastRun {
+		var err error
+		if runState, err = state.Load(cfg.StateFile); err != nil {
+			return err
+		}
+		since = runState.LastRun
+	}
+
+	analysis, err := a.analyze(since)
+	if err != nil {
+		return err
+	}
+	if cfg.Check {
+		return checkBudget(analysis, cfg)
+	}
+
+	if runState != nil {
+		return reportSinceLastRun(analysis, cfg, runState)
+	}
+
+	if cfg.InvoiceFile != "" {
+		return reconcile(analysis, cfg)
+	}
+
+	d := newDisplay(analysis, cfg)
+	if cfg.Commits {
+		d.SetCommits(a.countCommits(analysis, since))
+	}
+	if cfg.Format == "gha" {
+		if err := d.ShowGitHubActions(cfg.FailOver, os.Getenv("GITHUB_STEP_SUMMARY")); err != nil {
+			return err
+		}
+	} else {
+		d.ShowAll()
+	}
+
+	if notify {
+		if err := postWebhooks(analysis, cfg); err != nil {
+			return err
+		}
+	}
+
+	return checkBudget(analysis, cfg)
+}
+
+// oneLine prints the status line for shell prompts. It's read through the
+// index when the daemon keeps one, and a window without activity prints
+// zeros rather than failing, so the prompt never shows an error for it.
+func (a *app) oneLine() error {
+	analysis, err := a.parse(time.Time{})
+	if err != nil {
+		return err
+	}
+	if a.cfg.Strict && analysis.ParseErrors > 0 {
+		return fmt.Errorf("%w: %d malformed entries or unreadable files", claudecosts.ErrParsingFailed, analysis.ParseErrors)
+	}
+	newDisplay(analysis, a.cfg).ShowOneLine(a.cfg.Clock(), a.cfg.Days, a.cfg.BlockLength)
+	return nil
+}
+
+// checkBudget fails when spend is over the --fail-over cap
+func checkBudget(analysis *claudecosts.Analysis, cfg *config.Config) error {
+	if cfg.FailOver > 0 && analysis.TotalCost > cfg.FailOver {
+		return fmt.Errorf("%w: $%.2f API value is over the $%.2f cap", claudecosts.ErrBudgetExceeded, analysis.TotalCost, cfg.FailOver)
+	}
+	return nil
+}
+
+// postWebhooks posts the daily summary to each configured webhook
+func postWebhooks(analysis *claudecosts.Analysis, cfg *config.Config) error {
+	day, err := cfg.NotifyDay(cfg.Clock())
+	if err != nil {
+		return err
+	}
+	summary := calculator.New(analysis).GetDailySummary(day)
+
+	webhooks := []struct {
+		name   string
+		url    string
+		format notify.Formatter
+	}{
+		{"Slack", cfg.SlackWebhook, notify.Slack},
+		{"Discord", cfg.DiscordWebhook, notify.Discord},
+	}
+	for _, webhook := range webhooks {
+		if webhook.url == "" {
+			continue
+		}
+		if err := notify.Post(context.Background(), http.DefaultClient, webhook.url, webhook.format, summary); err != nil {
+			return fmt.Errorf("%s: %w", webhook.name, err)
+		}
+		slog.Debug("posted daily summary", "webhook", webhook.name, "date", day)
+	}
+	return nil
+}
+
+// reportSinceLastRun shows activity newer than the saved high-water mark and
+// advances the mark to the newest entry shown
+func reportSinceLastRun(analysis *claudecosts.Analysis, cfg *config.Config, runState *state.State) error {
+	d := newDisplay(analysis, cfg)
+	d.SetSince(runState.LastRun)
+	d.ShowAll()
+
+	runState.LastRun = analysis.EndDate
+	if err := runState.Save(cfg.StateFile); err != nil {
+		return err
+	}
+	return checkBudget(analysis, cfg)
+}
+
+// reconcile compares the analysis against the invoice CSV
+func reconcile(analysis *claudecosts.Analysis, cfg *config.Config) error {
+	f, err := os.Open(cfg.InvoiceFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	items, err := invoice.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cfg.InvoiceFile, err)
+	}
+
+	display.ShowReconciliation(os.Stdout, invoice.Reconcile(analysis, items, cfg.InvoiceTolerance))
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/tokens"
+	"github.com/spf13/cobra"
+)
+
+// newTokensCmd builds the tokens subcommand
+func newTokensCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tokens PATH...",
+		Short: "Estimate the tokens and cost of files as context",
+		Long: "tokens estimates how many tokens the text files at the given paths would\n" +
+			"take as context, and what sending them costs at each model's rates.\n" +
+			"Directories are walked, skipping hidden files and directories such as\n" +
+			".git; binary files are skipped. Claude's tokenizer isn't published, so\n" +
+			"counts are an approximation, typically within 15%.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(cfg, func(a *app) error { return a.tokens(args) })(cmd, args)
+		},
+	}
+}
+
+// tokens counts the files at paths and prices them
+func (a *app) tokens(paths []string) error {
+	files, err := tokens.CountFiles(paths)
+	if err != nil {
+		return err
+	}
+	display.ShowTokens(os.Stdout, files, a.cfg.Top)
+	return nil
+}
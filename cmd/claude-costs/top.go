@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/live"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// newTopCmd builds the top subcommand
+func newTopCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "List the sessions active right now, refreshing live",
+		Long: "top lists the sessions with activity in the last --active span, busiest\n" +
+			"first: their cost so far, the model of their latest response, and output\n" +
+			"tokens per minute over the last five minutes. Only lines appended since the\n" +
+			"previous refresh are read, so it can refresh every few seconds.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.top() }),
+	}
+
+	flags := cmd.Flags()
+	flags.DurationVar(&cfg.TopInterval, "interval", cfg.TopInterval, "How often to refresh")
+	flags.DurationVar(&cfg.TopWindow, "active", cfg.TopWindow, "List sessions with activity within this long")
+
+	return cmd
+}
+
+// top redraws the active sessions every interval until interrupted
+func (a *app) top() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Sessions idle since before the window started can't become active
+	// without their files being written
+	tail := parser.NewTail(time.Now().Add(-a.cfg.TopWindow), a.findOptions()...)
+	tracker := live.NewTracker()
+
+	ticker := time.NewTicker(a.cfg.TopInterval)
+	defer ticker.Stop()
+
+	for {
+		analysis, err := a.parseWith(time.Time{}, parser.WithLineSource(tail), parser.WithMessageHandler(tracker.Add))
+		fmt.Print(clearScreen)
+		switch {
+		case errors.Is(err, parser.ErrNoJSONLFiles):
+			// Nothing was written since the last refresh
+		case err != nil:
+			fmt.Printf("claude-costs top: %v\n", err)
+		default:
+			for id, session := range analysis.Sessions {
+				tracker.SetTitle(id, session.Title)
+			}
+		}
+		now := time.Now()
+		display.ShowTop(os.Stdout, now, a.cfg.TopWindow, tracker.Active(now, a.cfg.TopWindow))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/spf13/cobra"
+)
+
+// newDailyCmd builds the daily subcommand
+func newDailyCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "daily",
+		Short: "Show cost, messages, and cache hit rate per day",
+		Args:  cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error {
+			analysis, err := a.analyze(time.Time{})
+			if err != nil {
+				return err
+			}
+			newDisplay(analysis, a.cfg).ShowDaily()
+			return nil
+		}),
+	}
+}
+
+// newSessionsCmd builds the sessions subcommand
+func newSessionsCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List sessions with their cost and duration",
+		Args:  cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error {
+			analysis, err := a.analyze(time.Time{})
+			if err != nil {
+				return err
+			}
+			newDisplay(analysis, a.cfg).ShowSessions(a.cfg.SessionSort, a.cfg.SessionSearch)
+			return nil
+		}),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.SessionSort, "sort", cfg.SessionSort, "Order sessions by time (newest first) or cost")
+	flags.IntVar(&cfg.Top, "limit", cfg.Top, "Number of sessions to show (0 for all)")
+	_ = flags.MarkDeprecated("limit", "use --top")
+	flags.StringVar(&cfg.SessionSearch, "search", "", "Only show sessions whose title or project contains these `words`")
+
+	return cmd
+}
+
+// newProjectsCmd builds the projects subcommand
+func newProjectsCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "projects",
+		Short: "Show costs by project, or by tag with --group-by tag",
+		Args:  cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error {
+			analysis, err := a.analyze(time.Time{})
+			if err != nil {
+				return err
+			}
+			newDisplay(analysis, a.cfg).ShowProjects()
+			return nil
+		}),
+	}
+}
+
+// newBlocksCmd builds the blocks subcommand
+func newBlocksCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blocks",
+		Short: "Show usage grouped into 5-hour billing blocks",
+		Long: "blocks groups activity into billing blocks. A block starts at the hour of\n" +
+			"the first message after the previous block ended and lasts --block-length.\n\n" +
+			"Finished blocks are also recorded next to the state file, so the most\n" +
+			"expensive blocks and the typical block's share of each plan's limits cover\n" +
+			"every block seen, not just those in the --days window.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.blocks() }),
+	}
+	addBlockLengthFlag(cmd.Flags(), cfg)
+	return cmd
+}
+
+// newPlansCmd builds the plans subcommand
+func newPlansCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plans",
+		Short: "Simulate your usage against each subscription plan's limits",
+		Long: "plans replays the activity in the --days window, minute by minute, against\n" +
+			"each plan's per-block limits and reports how many blocks would have been\n" +
+			"throttled, how many messages would have been refused, and how long you'd\n" +
+			"have waited for the reset. The built-in limits and prices are estimates;\n" +
+			"define your own under [plans.NAME] in the config file.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error {
+			analysis, err := a.analyze(time.Time{})
+			if err != nil {
+				return err
+			}
+			newDisplay(analysis, a.cfg).ShowPlans(a.cfg.BlockLength, a.cfg.Clock(), a.cfg.Plans)
+			return nil
+		}),
+	}
+	addBlockLengthFlag(cmd.Flags(), cfg)
+	return cmd
+}
+
+// peakBlocks is how many of the most expensive blocks blocks lists
+const peakBlocks = 5
+
+// blocks shows the blocks in the window, then the block history
+func (a *app) blocks() error {
+	analysis, err := a.analyze(time.Time{})
+	if err != nil {
+		return err
+	}
+	now := a.cfg.Clock()
+	newDisplay(analysis, a.cfg).ShowBlocks(a.cfg.BlockLength, now)
+
+	history, err := a.recordBlocks(analysis, now)
+	if err != nil {
+		a.logger.Warn("failed to update block history", "error", err)
+		return nil
+	}
+	summary := calculator.SummarizeBlocks(history, peakBlocks)
+	display.ShowBlockHistory(os.Stdout, a.cfg.BlockLength, summary, limits.Fits(history, a.cfg.Plans))
+	return nil
+}
+
+// recordBlocks adds the analysis's finished blocks to the block history and
+// returns every recorded block of the configured length. Blocks starting
+// within a block length of the window's start may have been cut short by
+// it, and filtered analyses don't describe whole blocks, so neither is
+// recorded.
+func (a *app) recordBlocks(analysis *claudecosts.Analysis, now time.Time) ([]calculator.Block, error) {
+	path := state.BlocksPath(a.cfg.StateFile)
+	if path == "" {
+		return nil, nil
+	}
+	history, err := state.LoadBlocks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	length := a.cfg.BlockLength
+	if len(a.cfg.Projects) == 0 && a.cfg.Account == "" {
+		windowStart := now.AddDate(0, 0, -a.cfg.Days)
+		var finished []state.Block
+		for _, block := range calculator.New(analysis).GetBlocks(length, now) {
+			if block.Active || block.Start.Before(windowStart.Add(length)) {
+				continue
+			}
+			finished = append(finished, state.Block{
+				Start:             block.Start,
+				Length:            length,
+				Messages:          block.Messages,
+				Tokens:            block.Tokens,
+				InputOutputTokens: block.InputOutputTokens,
+				Cost:              block.Cost,
+			})
+		}
+		if history.Merge(finished) > 0 {
+			if err := history.Save(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var blocks []calculator.Block
+	for _, block := range history.Blocks {
+		if block.Length != length {
+			continue
+		}
+		blocks = append(blocks, calculator.Block{
+			Start:             block.Start,
+			End:               block.Start.Add(block.Length),
+			Messages:          block.Messages,
+			Tokens:            block.Tokens,
+			InputOutputTokens: block.InputOutputTokens,
+			Cost:              block.Cost,
+		})
+	}
+	return blocks, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/config"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/statsd"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// newWatchCmd builds the watch subcommand
+func newWatchCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Show today's spend and the current billing block, refreshing live",
+		Long: "watch shows today's spend and the current billing block: when it started,\n" +
+			"what it has used, and a countdown to its reset. Usage is compared against\n" +
+			"the --plan rate limits and projected to the end of the block at the current\n" +
+			"rate. The built-in limits are estimates; define your own under [plans.NAME]\n" +
+			"in the config file.\n\n" +
+			"With --statsd, each new message is also counted on a StatsD or DogStatsD\n" +
+			"server: messages, cost, and input, output, cache read, and cache write\n" +
+			"tokens, tagged with model and project. Messages already logged when watch\n" +
+			"starts aren't counted.\n\n" +
+			"With --events, each new message is also sent to an event sink: a file or\n" +
+			"named pipe as JSON Lines, an http:// or https:// URL that each batch is\n" +
+			"POSTed to, kafka://BROKER[,BROKER...]/TOPIC, or nats://HOST:PORT/SUBJECT.",
+		Args: cobra.NoArgs,
+		RunE: runE(cfg, func(a *app) error { return a.watch() }),
+	}
+
+	flags := cmd.Flags()
+	flags.DurationVar(&cfg.Interval, "interval", cfg.Interval, "How often to refresh")
+	flags.StringVar(&cfg.Plan, "plan", cfg.Plan, "Subscription plan to estimate rate limits against: pro, max5, max20, or one from the config file")
+	flags.StringVar(&cfg.StatsD, "statsd", "", "Send per-message counters to this StatsD `host:port`, e.g. localhost:8125")
+	flags.StringVar(&cfg.StatsDPrefix, "statsd-prefix", cfg.StatsDPrefix, "Prefix of StatsD metric names")
+	flags.StringVar(&cfg.StatsDFormat, "statsd-format", cfg.StatsDFormat, "StatsD line format: dogstatsd (tags) or statsd (tags folded into names)")
+	addEventsFlag(flags, cfg)
+	addBlockLengthFlag(flags, cfg)
+
+	return cmd
+}
+
+// watch redraws the live view every interval until interrupted
+func (a *app) watch() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	plan, err := limits.Lookup(a.cfg.Plan, a.cfg.Plans)
+	if err != nil {
+		return err
+	}
+
+	var emitter *statsdEmitter
+	if a.cfg.StatsD != "" {
+		client, err := statsd.Dial(a.cfg.StatsD, a.cfg.StatsDPrefix, a.cfg.StatsDFormat)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		emitter = &statsdEmitter{client: client}
+	}
+
+	forwarder, err := a.openEvents("")
+	if err != nil {
+		return err
+	}
+	if forwarder != nil {
+		defer forwarder.sink.Close()
+	}
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		var opts []parser.Option
+		var messages []parser.Message
+		if emitter != nil {
+			opts = append(opts, parser.WithMessageHandler(func(m parser.Message) { messages = append(messages, m) }))
+		}
+
+		analysis, err := a.parse(time.Time{}, opts...)
+		fmt.Print(clearScreen)
+		if err != nil {
+			fmt.Printf("claude-costs watch: %v\n", err)
+		} else {
+			d := newDisplay(analysis, a.cfg)
+			d.SetPlan(a.cfg.Plan, plan)
+			d.ShowWatch(time.Now(), a.cfg.BlockLength)
+
+			if emitter != nil {
+				if err := emitter.emit(messages); err != nil {
+					fmt.Printf("claude-costs watch: %v\n", err)
+				}
+			}
+		}
+		if forwarder != nil {
+			if err := a.forwardEvents(ctx, forwarder); err != nil {
+				fmt.Printf("claude-costs watch: %v\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// statsdEmitter counts the messages each refresh finds that the previous
+// one didn't
+type statsdEmitter struct {
+	client *statsd.Client
+	seen   map[string]bool // IDs from the previous refresh; nil before the first
+}
+
+// emit sends counters for new messages. The first call only records the
+// messages already logged, so history isn't replayed as a spike.
+func (e *statsdEmitter) emit(messages []parser.Message) error {
+	first := e.seen == nil
+	seen := make(map[string]bool, len(messages))
+	for _, m := range messages {
+		seen[m.ID] = true
+		if first || e.seen[m.ID] {
+			continue
+		}
+
+		tags := map[string]string{"model": m.Model, "project": m.Project}
+		counters := []struct {
+			name  string
+			value float64
+		}{
+			{"messages", 1},
+			{"cost", m.Cost},
+			{"input_tokens", float64(m.InputTokens)},
+			{"output_tokens", float64(m.OutputTokens)},
+			{"cache_read_tokens", float64(m.CacheReadTokens)},
+			{"cache_write_tokens", float64(m.CacheWriteTokens)},
+		}
+		for _, counter := range counters {
+			if counter.value == 0 {
+				continue
+			}
+			if err := e.client.Count(counter.name, counter.value, tags); err != nil {
+				return err
+			}
+		}
+	}
+	e.seen = seen
+	return e.client.Flush()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/statsd"
+)
+
+func TestStatsdEmitter(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	client, err := statsd.Dial(server.LocalAddr().String(), "claude", statsd.DogStatsD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	e := &statsdEmitter{client: client}
+
+	old := parser.Message{ID: "a", Model: "opus", Project: "app", Cost: 1, OutputTokens: 10}
+	// The first refresh only records what's already logged
+	if err := e.emit([]parser.Message{old}); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := parser.Message{ID: "b", Model: "sonnet", Project: "app", Cost: 0.5, InputTokens: 20}
+	if err := e.emit([]parser.Message{old, fresh}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "claude.messages:1|c|#model:sonnet,project:app\n" +
+		"claude.cost:0.5|c|#model:sonnet,project:app\n" +
+		"claude.input_tokens:20|c|#model:sonnet,project:app"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("sent\n%s\nwant\n%s", got, want)
+	}
+
+	// Nothing new: nothing sent
+	if err := e.emit([]parser.Message{old, fresh}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, _, err := server.ReadFrom(buf); err == nil {
+		t.Errorf("sent %q for a refresh without new messages", strings.TrimSpace(string(buf[:n])))
+	}
+}
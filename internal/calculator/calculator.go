@@ -1,7 +1,10 @@
 package calculator
 
 import (
+	"math"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/photostructure/go-claude-costs/internal/models"
@@ -45,47 +48,46 @@ func (s *Statistics) GetCacheHitRate() float64 {
 	return float64(s.analysis.TotalCacheRead) / float64(totalInput) * 100
 }
 
-// GetResponseTimeStats calculates response time statistics
-func (s *Statistics) GetResponseTimeStats() ResponseTimeStats {
-	stats := ResponseTimeStats{}
-
-	if len(s.analysis.ResponseTimes) == 0 {
-		return stats
-	}
-
-	// Convert to seconds and sort
-	times := make([]float64, len(s.analysis.ResponseTimes))
-	for i, d := range s.analysis.ResponseTimes {
-		times[i] = d.Seconds()
+// GetCacheHitRateAlert reports whether the most recent day's cache hit rate
+// fell below threshold (a percentage). It returns the day and its rate.
+func (s *Statistics) GetCacheHitRateAlert(threshold float64) (alert bool, day DailyData) {
+	trend := s.GetDailyTrend()
+	for i := len(trend) - 1; i >= 0; i-- {
+		if trend[i].HasCacheData {
+			return trend[i].CacheHitRate < threshold, trend[i]
+		}
 	}
-	sort.Float64s(times)
-
-	// Calculate statistics
-	stats.Count = len(times)
-	stats.Min = times[0]
-	stats.Max = times[len(times)-1]
-	stats.P50 = percentile(times, 50)
-	stats.P90 = percentile(times, 90)
-	stats.P95 = percentile(times, 95)
-	stats.P99 = percentile(times, 99)
+	return false, DailyData{}
+}
 
-	// Calculate average
-	sum := 0.0
-	for _, t := range times {
-		sum += t
-	}
-	stats.Average = sum / float64(len(times))
+// GetResponseTimeStats calculates response time statistics
+func (s *Statistics) GetResponseTimeStats() ResponseTimeStats {
+	return durationStats(s.analysis.ResponseTimes)
+}
 
-	return stats
+// GetTurnTimeStats calculates per-turn latency statistics: wall-clock time
+// from prompt to final reply, and the same with tool execution removed
+func (s *Statistics) GetTurnTimeStats() (wall, model ResponseTimeStats) {
+	return durationStats(s.analysis.TurnTimes), durationStats(s.analysis.TurnModelTimes)
 }
 
 // GetTopProjects returns the top N projects by cost
 func (s *Statistics) GetTopProjects(limit int) []ProjectSummary {
 	projects := make([]ProjectSummary, 0, len(s.analysis.Projects))
 
+	// Aliases can gather several directories into one project
+	dirs := make(map[string][]string)
+	for _, session := range s.analysis.Sessions {
+		if session.ProjectDir != "" && !slices.Contains(dirs[session.Project], session.ProjectDir) {
+			dirs[session.Project] = append(dirs[session.Project], session.ProjectDir)
+		}
+	}
+
 	for name, proj := range s.analysis.Projects {
+		slices.Sort(dirs[name])
 		summary := ProjectSummary{
 			Name:             name,
+			Dirs:             dirs[name],
 			Cost:             proj.Cost,
 			Sessions:         proj.Sessions,
 			InputTokens:      proj.InputTokens,
@@ -93,6 +95,7 @@ func (s *Statistics) GetTopProjects(limit int) []ProjectSummary {
 			CacheReadTokens:  proj.CacheReadTokens,
 			CacheWriteTokens: proj.CacheWriteTokens,
 			ActiveDays:       len(proj.ActiveDays),
+			TokensPerMinute:  tokensPerMinute(proj.OutputTokens, len(proj.ActiveMinutes)),
 		}
 
 		// Calculate average response time for project
@@ -102,14 +105,18 @@ func (s *Statistics) GetTopProjects(limit int) []ProjectSummary {
 				sum += rt
 			}
 			summary.AvgResponseTime = sum / time.Duration(len(proj.ResponseTimes))
+			summary.P50ResponseTime, summary.P90ResponseTime = durationPercentiles(proj.ResponseTimes)
 		}
 
 		projects = append(projects, summary)
 	}
 
-	// Sort by cost descending
+	// Sort by cost descending, then name so ties keep their order
 	sort.Slice(projects, func(i, j int) bool {
-		return projects[i].Cost > projects[j].Cost
+		if projects[i].Cost != projects[j].Cost {
+			return projects[i].Cost > projects[j].Cost
+		}
+		return projects[i].Name < projects[j].Name
 	})
 
 	// Return top N
@@ -119,6 +126,39 @@ func (s *Statistics) GetTopProjects(limit int) []ProjectSummary {
 	return projects
 }
 
+// GetTagSummaries returns costs grouped by session tag, most expensive first.
+// Sessions with several tags count toward each of them.
+func (s *Statistics) GetTagSummaries() []TagSummary {
+	byTag := make(map[string]*TagSummary)
+
+	for _, session := range s.analysis.Sessions {
+		for _, tag := range session.Tags {
+			summary := byTag[tag]
+			if summary == nil {
+				summary = &TagSummary{Tag: tag}
+				byTag[tag] = summary
+			}
+			summary.Cost += session.Cost
+			summary.Sessions++
+			summary.Tokens += session.InputTokens + session.OutputTokens +
+				session.CacheReadTokens + session.CacheWriteTokens
+		}
+	}
+
+	tags := make([]TagSummary, 0, len(byTag))
+	for _, summary := range byTag {
+		tags = append(tags, *summary)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Cost != tags[j].Cost {
+			return tags[i].Cost > tags[j].Cost
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags
+}
+
 // GetHourlyDistribution returns activity distribution by hour
 func (s *Statistics) GetHourlyDistribution() []HourlyData {
 	data := make([]HourlyData, 24)
@@ -130,10 +170,91 @@ func (s *Statistics) GetHourlyDistribution() []HourlyData {
 			data[hour].Cost = activity.Cost
 		}
 	}
+	days := make([]map[string]bool, 24)
+	for minute := range s.analysis.Minutes {
+		t := time.Unix(minute*60, 0)
+		days[t.Hour()] = addDay(days[t.Hour()], t)
+	}
+	for hour := range data {
+		data[hour].Days = len(days[hour])
+	}
+
+	return data
+}
+
+// GetWeekdayDistribution returns messages and cost by local day of the
+// week, Monday first, with the number of each weekday that had activity
+func (s *Statistics) GetWeekdayDistribution() []WeekdayData {
+	data := make([]WeekdayData, 7)
+	days := make([]map[string]bool, 7)
+	for i := range data {
+		data[i].Weekday = time.Weekday((i + 1) % 7)
+	}
+	for minute, activity := range s.analysis.Minutes {
+		t := time.Unix(minute*60, 0)
+		i := (int(t.Weekday()) + 6) % 7
+		data[i].Messages += activity.MessageCount
+		data[i].Cost += activity.Cost
+		days[i] = addDay(days[i], t)
+	}
+	for i := range data {
+		data[i].Days = len(days[i])
+	}
+	return data
+}
+
+// addDay adds t's local date to days, creating it if needed
+func addDay(days map[string]bool, t time.Time) map[string]bool {
+	if days == nil {
+		days = make(map[string]bool)
+	}
+	days[t.Format("2006-01-02")] = true
+	return days
+}
+
+// GetHourlyCosts returns messages, cost, and tokens by local hour of day for
+// activity in [from, to). A zero from or to leaves that end open.
+func (s *Statistics) GetHourlyCosts(from, to time.Time) []HourlyData {
+	data := make([]HourlyData, 24)
+	for hour := range data {
+		data[hour].Hour = hour
+	}
+
+	days := make([]map[string]bool, 24)
+	for minute, activity := range s.analysis.Minutes {
+		t := time.Unix(minute*60, 0)
+		if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+			continue
+		}
+		hour := &data[t.Hour()]
+		hour.Messages += activity.MessageCount
+		hour.Cost += activity.Cost
+		hour.Tokens += activity.Tokens
+		days[t.Hour()] = addDay(days[t.Hour()], t)
+	}
+	for hour := range data {
+		data[hour].Days = len(days[hour])
+	}
 
 	return data
 }
 
+// GetWorkSplit divides messages and cost between working hours, as decided
+// by isWork for each minute with activity, and off hours
+func (s *Statistics) GetWorkSplit(isWork func(time.Time) bool) WorkSplit {
+	var split WorkSplit
+	for minute, activity := range s.analysis.Minutes {
+		if isWork(time.Unix(minute*60, 0)) {
+			split.WorkMessages += activity.MessageCount
+			split.WorkCost += activity.Cost
+		} else {
+			split.OffMessages += activity.MessageCount
+			split.OffCost += activity.Cost
+		}
+	}
+	return split
+}
+
 // GetDailyTrend returns daily activity trend
 func (s *Statistics) GetDailyTrend() []DailyData {
 	// Get all dates
@@ -150,22 +271,344 @@ func (s *Statistics) GetDailyTrend() []DailyData {
 		if activity, ok := s.analysis.DailyActivity[date]; ok {
 			trend[i].Messages = activity.MessageCount
 			trend[i].Cost = activity.Cost
+			trend[i].CacheHitRate = activity.CacheHitRate()
+			trend[i].HasCacheData = activity.InputTokens+activity.CacheReadTokens+activity.CacheWriteTokens > 0
+			trend[i].Models = dailyModels(activity)
 		}
 	}
+	rollingAverages(trend)
 
 	return trend
 }
 
-// GetModelDistribution returns model usage distribution
-func (s *Statistics) GetModelDistribution() []ModelUsage {
+// rollingAverages sets each day's 7- and 30-day average cost. Days without
+// activity count as zero, and windows are shortened so the days before the
+// first in trend don't drag its averages down.
+func rollingAverages(trend []DailyData) {
+	if len(trend) == 0 {
+		return
+	}
+	first, err := time.ParseInLocation("2006-01-02", trend[0].Date, time.UTC)
+	if err != nil {
+		return
+	}
+	// Day numbers since the first day, so windows span calendar days
+	days := make([]int, len(trend))
+	for i, day := range trend {
+		date, err := time.ParseInLocation("2006-01-02", day.Date, time.UTC)
+		if err != nil {
+			return
+		}
+		days[i] = int(date.Sub(first).Hours() / 24)
+	}
+
+	average := func(i, window int) float64 {
+		sum := 0.0
+		for j := i; j >= 0 && days[i]-days[j] < window; j-- {
+			sum += trend[j].Cost
+		}
+		return sum / float64(min(window, days[i]+1))
+	}
+	for i := range trend {
+		trend[i].Avg7 = average(i, 7)
+		trend[i].Avg30 = average(i, 30)
+	}
+}
+
+// trendMinDays is the fewest calendar days fit with a trend line; the week
+// over week comparison needs two full weeks
+const trendMinDays = 7
+
+// GetCostTrend fits a line to the daily cost over the calendar days from
+// the first active day to the last, counting idle days as zero. It reports
+// false when there are fewer than trendMinDays.
+func (s *Statistics) GetCostTrend() (CostTrend, bool) {
+	costs := s.calendarCosts()
+	if len(costs) < trendMinDays {
+		return CostTrend{}, false
+	}
+
+	trend := CostTrend{Days: len(costs)}
+	trend.Slope, trend.Intercept, trend.R2 = linearFit(costs)
+	if len(costs) >= 14 {
+		recent, prior := costs[len(costs)-7:], costs[len(costs)-14:len(costs)-7]
+		for i := range 7 {
+			trend.ThisWeek += recent[i]
+			trend.LastWeek += prior[i]
+		}
+		trend.RecentSlope, _, _ = linearFit(recent)
+		trend.PriorSlope, _, _ = linearFit(prior)
+	}
+	return trend, true
+}
+
+// calendarCosts returns the cost of every calendar day from the first
+// active day to the last, oldest first
+func (s *Statistics) calendarCosts() []float64 {
+	daily := s.GetDailyTrend()
+	if len(daily) == 0 {
+		return nil
+	}
+	first, err := time.ParseInLocation("2006-01-02", daily[0].Date, time.UTC)
+	if err != nil {
+		return nil
+	}
+	var costs []float64
+	for _, day := range daily {
+		date, err := time.ParseInLocation("2006-01-02", day.Date, time.UTC)
+		if err != nil {
+			continue
+		}
+		index := int(date.Sub(first).Hours() / 24)
+		for len(costs) <= index {
+			costs = append(costs, 0)
+		}
+		costs[index] += day.Cost
+	}
+	return costs
+}
+
+// linearFit fits ys, at x = 0, 1, 2..., with a least-squares line and
+// reports how much of their variance it explains as r2. Constant ys are
+// fit exactly.
+func linearFit(ys []float64) (slope, intercept, r2 float64) {
+	n := float64(len(ys))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range ys {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	intercept = (sumY - slope*sumX) / n
+
+	mean := sumY / n
+	var ssRes, ssTot float64
+	for i, y := range ys {
+		fit := intercept + slope*float64(i)
+		ssRes += (y - fit) * (y - fit)
+		ssTot += (y - mean) * (y - mean)
+	}
+	if ssTot == 0 {
+		return slope, intercept, 1
+	}
+	return slope, intercept, 1 - ssRes/ssTot
+}
+
+// Anomalies compare each cost to the ones before it: the trailing window of
+// up to anomalyWindow days or sessions, once there are at least
+// anomalyHistory of them
+const (
+	anomalyWindow  = 30
+	anomalyHistory = 7
+)
+
+// GetAnomalies returns the active days and the sessions whose cost spiked
+// above the ones before them, oldest first. A limit above zero keeps the
+// most recent limit of each.
+func (s *Statistics) GetAnomalies(t AnomalyThresholds, limit int) (days, sessions []Anomaly) {
+	var history []float64
+	for _, day := range s.GetDailyTrend() {
+		if day.Messages == 0 {
+			continue
+		}
+		if a, ok := t.check(history, day.Cost); ok {
+			a.Date = day.Date
+			days = append(days, a)
+		}
+		history = append(history, day.Cost)
+	}
+
+	all := s.GetSessions("time", 0)
+	history = nil
+	for i := len(all) - 1; i >= 0; i-- {
+		session := all[i]
+		if a, ok := t.check(history, session.Cost); ok {
+			a.Date = s.analysis.DayBoundary.Date(session.Start)
+			a.SessionID = session.SessionID
+			a.Title = session.Title
+			a.Project = session.Project
+			sessions = append(sessions, a)
+		}
+		history = append(history, session.Cost)
+	}
+	if limit > 0 {
+		days = days[max(0, len(days)-limit):]
+		sessions = sessions[max(0, len(sessions)-limit):]
+	}
+	return days, sessions
+}
+
+// check reports whether cost is an anomaly against the trailing window of
+// history
+func (t AnomalyThresholds) check(history []float64, cost float64) (Anomaly, bool) {
+	if len(history) < anomalyHistory {
+		return Anomaly{}, false
+	}
+	window := history[max(0, len(history)-anomalyWindow):]
+
+	sorted := append([]float64(nil), window...)
+	sort.Float64s(sorted)
+	mean := 0.0
+	for _, v := range window {
+		mean += v
+	}
+	mean /= float64(len(window))
+	variance := 0.0
+	for _, v := range window {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(window)))
+
+	a := Anomaly{Cost: cost, Median: Percentile(sorted, 50)}
+	if stdDev > 0 {
+		a.StdDevs = (cost - mean) / stdDev
+	}
+	if a.Median > 0 {
+		a.Multiple = cost / a.Median
+	}
+	spiked := (t.StdDevs > 0 && stdDev > 0 && a.StdDevs > t.StdDevs) ||
+		(t.MedianMultiple > 0 && a.Median > 0 && a.Multiple > t.MedianMultiple)
+	return a, spiked
+}
+
+// dailyModels returns each model's share of a day's cost, most expensive
+// first
+func dailyModels(activity *models.DailyActivity) []DailyModel {
+	dayModels := make([]DailyModel, 0, len(activity.ModelCosts))
+	for model, cost := range activity.ModelCosts {
+		m := DailyModel{Model: model, Cost: cost}
+		if activity.Cost > 0 {
+			m.Percentage = cost / activity.Cost * 100
+		}
+		dayModels = append(dayModels, m)
+	}
+	sort.Slice(dayModels, func(i, j int) bool {
+		if dayModels[i].Cost != dayModels[j].Cost {
+			return dayModels[i].Cost > dayModels[j].Cost
+		}
+		return dayModels[i].Model < dayModels[j].Model
+	})
+	return dayModels
+}
+
+// GetAccounts returns cost per account, highest first
+func (s *Statistics) GetAccounts() []AccountSummary {
+	accounts := make([]AccountSummary, 0, len(s.analysis.Accounts))
+	for name, stats := range s.analysis.Accounts {
+		summary := AccountSummary{
+			Name:     name,
+			Cost:     stats.Cost,
+			Sessions: len(stats.SessionIDs),
+			Messages: stats.MessageCount,
+			Tokens:   stats.TotalTokens,
+		}
+		if s.analysis.TotalCost > 0 {
+			summary.Percentage = stats.Cost / s.analysis.TotalCost * 100
+		}
+		accounts = append(accounts, summary)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Cost != accounts[j].Cost {
+			return accounts[i].Cost > accounts[j].Cost
+		}
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts
+}
+
+// GetOpusDowngrades returns, for each day with Opus use, how many minutes
+// ran on Opus and how many ran on Sonnet after a session had switched away
+// from Opus, oldest first
+func (s *Statistics) GetOpusDowngrades() []DowngradeDay {
+	var days []DowngradeDay
+	for date, activity := range s.analysis.DailyActivity {
+		if len(activity.OpusMinutes) == 0 && len(activity.DowngradedMinutes) == 0 {
+			continue
+		}
+		days = append(days, DowngradeDay{
+			Date:              date,
+			OpusMinutes:       len(activity.OpusMinutes),
+			DowngradedMinutes: len(activity.DowngradedMinutes),
+			DowngradedCost:    activity.DowngradedCost,
+			Switches:          activity.ModelSwitches,
+		})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// GetDailySummary returns the cost of one day (YYYY-MM-DD) with its top
+// project and session and the previous calendar day's cost for comparison
+func (s *Statistics) GetDailySummary(date string) DailySummary {
+	summary := DailySummary{Date: date}
+
+	if day, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+		previous := day.AddDate(0, 0, -1).Format("2006-01-02")
+		if activity, ok := s.analysis.DailyActivity[previous]; ok {
+			summary.PreviousCost = activity.Cost
+		}
+	}
+
+	activity, ok := s.analysis.DailyActivity[date]
+	if !ok {
+		return summary
+	}
+	summary.Cost = activity.Cost
+	summary.Messages = activity.MessageCount
+	summary.TopProject, summary.TopProjectCost = topCost(activity.ProjectCosts)
+	summary.TopSession, summary.TopSessionCost = topCost(activity.SessionCosts)
+	if session, ok := s.analysis.Sessions[summary.TopSession]; ok {
+		summary.TopSessionProject = session.Project
+	}
+
+	return summary
+}
+
+// topCost returns the key with the highest cost, breaking ties by name
+func topCost(costs map[string]float64) (string, float64) {
+	var top string
+	var max float64
+	for key, cost := range costs {
+		if top == "" || cost > max || (cost == max && key < top) {
+			top, max = key, cost
+		}
+	}
+	return top, max
+}
+
+// GetModelDistribution returns model usage distribution for the limit most
+// used models. A limit of zero returns them all.
+func (s *Statistics) GetModelDistribution(limit int) []ModelUsage {
+	return s.modelDistribution(nil, limit)
+}
+
+// GetForegroundModelDistribution returns model usage distribution without
+// Claude Code's background Haiku calls, so they don't crowd the models doing
+// the work; see GetBackgroundTasks. A limit of zero returns all models.
+func (s *Statistics) GetForegroundModelDistribution(limit int) []ModelUsage {
+	return s.modelDistribution(s.analysis.Background.Messages, limit)
+}
+
+// modelDistribution returns model usage distribution, less the excluded
+// responses per model, for up to limit models
+func (s *Statistics) modelDistribution(excluded map[string]int, limit int) []ModelUsage {
 	models := make([]ModelUsage, 0, len(s.analysis.ModelUsage))
+	counts := make(map[string]int, len(s.analysis.ModelUsage))
 	total := 0
 
-	for _, count := range s.analysis.ModelUsage {
-		total += count
+	for model, count := range s.analysis.ModelUsage {
+		if count -= excluded[model]; count > 0 {
+			counts[model] = count
+			total += count
+		}
 	}
 
-	for model, count := range s.analysis.ModelUsage {
+	for model, count := range counts {
 		usage := ModelUsage{
 			Model:      model,
 			Count:      count,
@@ -174,20 +617,626 @@ func (s *Statistics) GetModelDistribution() []ModelUsage {
 		if total > 0 {
 			usage.Percentage = float64(count) / float64(total) * 100
 		}
+		if stats, ok := s.analysis.Models[model]; ok {
+			usage.TokensPerMinute = tokensPerMinute(stats.OutputTokens, len(stats.ActiveMinutes))
+		}
+		if times := s.analysis.ModelResponseTimes[model]; len(times) > 0 {
+			usage.P50ResponseTime, usage.P90ResponseTime = durationPercentiles(times)
+		}
 		models = append(models, usage)
 	}
 
-	// Sort by count descending
+	// Sort by count descending, then name so ties keep their order
 	sort.Slice(models, func(i, j int) bool {
-		return models[i].Count > models[j].Count
+		if models[i].Count != models[j].Count {
+			return models[i].Count > models[j].Count
+		}
+		return models[i].Model < models[j].Model
 	})
 
+	if limit > 0 && len(models) > limit {
+		models = models[:limit]
+	}
 	return models
 }
 
+// GetBackgroundTasks returns Claude Code's background Haiku calls and the
+// responses of subagents running on Haiku, with their shares of total cost
+func (s *Statistics) GetBackgroundTasks() (background, subagents TaskShare) {
+	share := func(stats models.TaskStats) TaskShare {
+		t := TaskShare{Messages: stats.Count(), Cost: stats.Cost}
+		if s.analysis.TotalCost > 0 {
+			t.Share = stats.Cost / s.analysis.TotalCost * 100
+		}
+		return t
+	}
+	return share(s.analysis.Background), share(s.analysis.HaikuSubagents)
+}
+
+// GetCategories returns the cost of each response category with any
+// responses, in the order of models.Categories
+func (s *Statistics) GetCategories() []CategoryShare {
+	var categories []CategoryShare
+	for _, name := range models.Categories {
+		stats := s.analysis.ByCategory[name]
+		if stats == nil {
+			continue
+		}
+		c := CategoryShare{Category: name, Messages: stats.Messages, Tokens: stats.Tokens, Cost: stats.Cost}
+		if s.analysis.TotalCost > 0 {
+			c.Share = stats.Cost / s.analysis.TotalCost * 100
+		}
+		categories = append(categories, c)
+	}
+	return categories
+}
+
+// GetSessions returns per-session summaries sorted by sortBy: "cost" for most
+// expensive first, otherwise most recent first. A limit of zero returns all.
+func (s *Statistics) GetSessions(sortBy string, limit int) []SessionSummary {
+	sessions := make([]SessionSummary, 0, len(s.analysis.Sessions))
+
+	for id, session := range s.analysis.Sessions {
+		sessions = append(sessions, SessionSummary{
+			SessionID:      id,
+			Project:        session.Project,
+			ProjectDir:     session.ProjectDir,
+			GitBranch:      session.GitBranch,
+			Title:          session.Title,
+			Tags:           session.Tags,
+			Start:          session.StartTime,
+			End:            session.EndTime,
+			Cost:           session.Cost,
+			Messages:       session.MessageCount,
+			Tokens:         session.InputTokens + session.OutputTokens + session.CacheReadTokens + session.CacheWriteTokens,
+			InputTokens:    session.InputTokens + session.CacheReadTokens + session.CacheWriteTokens,
+			OutputTokens:   session.OutputTokens,
+			ActiveMinutes:  len(session.ActiveMinutes),
+			ContextTokens:  session.ContextTokens,
+			RepeatedTokens: session.RepeatedTokens,
+			RepeatedCost:   session.RepeatedCost,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if sortBy == "cost" && a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if !a.Start.Equal(b.Start) {
+			return a.Start.After(b.Start)
+		}
+		return a.SessionID < b.SessionID
+	})
+
+	if limit > 0 && len(sessions) > limit {
+		return sessions[:limit]
+	}
+	return sessions
+}
+
+// GetContextOverhead totals the context re-sent across sessions, and
+// returns up to limit sessions, most re-sent cost first. A limit of zero
+// returns them all.
+func (s *Statistics) GetContextOverhead(limit int) (total SessionSummary, sessions []SessionSummary) {
+	for _, session := range s.GetSessions("time", 0) {
+		total.ContextTokens += session.ContextTokens
+		total.RepeatedTokens += session.RepeatedTokens
+		total.RepeatedCost += session.RepeatedCost
+		if session.RepeatedTokens > 0 {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].RepeatedCost > sessions[j].RepeatedCost })
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return total, sessions
+}
+
+// Sessions that read at least LowYieldRatio input tokens, cache reads and
+// writes included, per output token are context-heavy and low-yield
+const LowYieldRatio = 500
+
+// GetLowYieldSessions returns the sessions whose input dwarfs their output,
+// most input per output token first. A limit of zero returns them all.
+func (s *Statistics) GetLowYieldSessions(limit int) []SessionSummary {
+	var sessions []SessionSummary
+	for _, session := range s.GetSessions("time", 0) {
+		if session.InputOutputRatio() >= LowYieldRatio {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].InputOutputRatio() > sessions[j].InputOutputRatio() })
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions
+}
+
+// Opus sessions with at most SimplePrompts prompts and SimpleOutputPerPrompt
+// output tokens per prompt are short, simple exchanges that a cheaper model
+// could likely have handled
+const (
+	SimplePrompts         = 3
+	SimpleOutputPerPrompt = 1_000
+)
+
+// The models short Opus sessions are priced at instead
+const (
+	sonnetModel = "claude-sonnet-4-20250514"
+	haikuModel  = "claude-3-5-haiku-20241022"
+)
+
+// GetRightSizing returns the short, simple sessions that used Opus, with
+// what their Opus responses would have cost on Sonnet and Haiku, most
+// savings first, and their totals. A limit of zero returns them all.
+func (s *Statistics) GetRightSizing(limit int) (total RightSizing, sessions []RightSizing) {
+	for id, session := range s.analysis.Sessions {
+		if session.OpusCost == 0 || session.Prompts > SimplePrompts ||
+			session.OpusTokens.Output > max(session.Prompts, 1)*SimpleOutputPerPrompt {
+			continue
+		}
+		r := RightSizing{
+			SessionID:    id,
+			Project:      session.Project,
+			Title:        session.Title,
+			Prompts:      session.Prompts,
+			OutputTokens: session.OpusTokens.Output,
+			OpusCost:     session.OpusCost,
+			SonnetCost:   session.OpusTokens.CostAt(models.ModelPricing[sonnetModel]),
+			HaikuCost:    session.OpusTokens.CostAt(models.ModelPricing[haikuModel]),
+		}
+		sessions = append(sessions, r)
+		total.Prompts += r.Prompts
+		total.OutputTokens += r.OutputTokens
+		total.OpusCost += r.OpusCost
+		total.SonnetCost += r.SonnetCost
+		total.HaikuCost += r.HaikuCost
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if si, sj := sessions[i].SonnetSavings(), sessions[j].SonnetSavings(); si != sj {
+			return si > sj
+		}
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return total, sessions
+}
+
+// GetBlocks groups activity into billing blocks of the given length, oldest
+// first. A block starts at the hour of the first message after the previous
+// block ended. The block containing now is marked active.
+func (s *Statistics) GetBlocks(length time.Duration, now time.Time) []Block {
+	minutes := make([]int64, 0, len(s.analysis.Minutes))
+	for minute := range s.analysis.Minutes {
+		minutes = append(minutes, minute)
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i] < minutes[j] })
+
+	var blocks []Block
+	for _, minute := range minutes {
+		t := time.Unix(minute*60, 0)
+		if len(blocks) == 0 || !t.Before(blocks[len(blocks)-1].End) {
+			start := t.Truncate(time.Hour)
+			blocks = append(blocks, Block{Start: start, End: start.Add(length)})
+		}
+
+		block := &blocks[len(blocks)-1]
+		activity := s.analysis.Minutes[minute]
+		block.LastActivity = t
+		block.Messages += activity.MessageCount
+		block.Tokens += activity.Tokens
+		block.InputOutputTokens += activity.InputOutputTokens
+		block.Cost += activity.Cost
+	}
+
+	if n := len(blocks); n > 0 && !now.Before(blocks[n-1].Start) && now.Before(blocks[n-1].End) {
+		blocks[n-1].Active = true
+	}
+
+	for _, event := range s.analysis.LimitEvents {
+		i := sort.Search(len(blocks), func(i int) bool { return event.Time.Before(blocks[i].End) })
+		if i == len(blocks) || event.Time.Before(blocks[i].Start) {
+			continue
+		}
+		if event.Kind == models.UsageLimit {
+			blocks[i].UsageLimits++
+		} else {
+			blocks[i].RateLimits++
+		}
+	}
+	return blocks
+}
+
+// SummarizeBlocks describes a history of finished billing blocks: the peaks
+// most expensive, and the typical block's cost and tokens
+func SummarizeBlocks(blocks []Block, peaks int) BlockSummary {
+	summary := BlockSummary{Blocks: len(blocks)}
+	if len(blocks) == 0 {
+		return summary
+	}
+	summary.Since = blocks[0].Start
+
+	costs := make([]float64, len(blocks))
+	tokens := make([]float64, len(blocks))
+	for i, block := range blocks {
+		costs[i] = block.Cost
+		tokens[i] = float64(block.InputOutputTokens)
+		if block.Start.Before(summary.Since) {
+			summary.Since = block.Start
+		}
+	}
+	sort.Float64s(costs)
+	sort.Float64s(tokens)
+	summary.MedianCost, summary.P90Cost = Percentile(costs, 50), Percentile(costs, 90)
+	summary.MedianTokens, summary.P90Tokens = int(Percentile(tokens, 50)), int(Percentile(tokens, 90))
+
+	summary.Peaks = append([]Block(nil), blocks...)
+	sort.SliceStable(summary.Peaks, func(i, j int) bool { return summary.Peaks[i].Cost > summary.Peaks[j].Cost })
+	if len(summary.Peaks) > peaks {
+		summary.Peaks = summary.Peaks[:peaks]
+	}
+	return summary
+}
+
+// GetLimitHits counts usage limit and rate limit events per day and per
+// billing block of the given length
+func (s *Statistics) GetLimitHits(blockLength time.Duration, now time.Time) LimitSummary {
+	summary := LimitSummary{ActiveDays: len(s.analysis.DailyActivity)}
+
+	days := make(map[string]*LimitDay)
+	for _, event := range s.analysis.LimitEvents {
+		date := s.analysis.DayBoundary.Date(event.Time)
+		day := days[date]
+		if day == nil {
+			day = &LimitDay{Date: date}
+			days[date] = day
+		}
+		if event.Kind == models.UsageLimit {
+			summary.UsageLimits++
+			day.UsageLimits++
+		} else {
+			summary.RateLimits++
+			day.RateLimits++
+		}
+	}
+	for _, day := range days {
+		summary.Days = append(summary.Days, *day)
+	}
+	sort.Slice(summary.Days, func(i, j int) bool { return summary.Days[i].Date < summary.Days[j].Date })
+
+	for _, block := range s.GetBlocks(blockLength, now) {
+		summary.Blocks++
+		if block.UsageLimits > 0 {
+			summary.BlocksHit++
+		}
+	}
+	return summary
+}
+
+// GetTokenVelocity returns output tokens generated per active minute overall
+func (s *Statistics) GetTokenVelocity() float64 {
+	return tokensPerMinute(s.analysis.TotalOutputTokens, len(s.analysis.ActiveMinutes))
+}
+
+// GetSessionVelocities returns per-session token velocity for sessions with at
+// least minMinutes of activity, slowest first. Slow sessions with many active
+// minutes are often stuck in retry loops.
+func (s *Statistics) GetSessionVelocities(minMinutes, limit int) []SessionVelocity {
+	sessions := make([]SessionVelocity, 0, len(s.analysis.Sessions))
+
+	for id, session := range s.analysis.Sessions {
+		minutes := len(session.ActiveMinutes)
+		if minutes == 0 || minutes < minMinutes {
+			continue
+		}
+		sessions = append(sessions, SessionVelocity{
+			SessionID:       id,
+			Project:         session.Project,
+			Cost:            session.Cost,
+			OutputTokens:    session.OutputTokens,
+			ActiveMinutes:   minutes,
+			TokensPerMinute: tokensPerMinute(session.OutputTokens, minutes),
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].TokensPerMinute != sessions[j].TokensPerMinute {
+			return sessions[i].TokensPerMinute < sessions[j].TokensPerMinute
+		}
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+
+	if limit > 0 && len(sessions) > limit {
+		return sessions[:limit]
+	}
+	return sessions
+}
+
+// GetCacheROI returns per-project prompt caching costs and savings, with the
+// projects paying the most net cache-write tax first
+func (s *Statistics) GetCacheROI() []CacheROI {
+	rois := make([]CacheROI, 0, len(s.analysis.Projects))
+
+	for name, proj := range s.analysis.Projects {
+		if proj.CacheWriteTokens == 0 && proj.CacheReadTokens == 0 {
+			continue
+		}
+		roi := CacheROI{
+			Project:      name,
+			WriteCost:    proj.Cache.WriteCost,
+			WritePremium: proj.Cache.WritePremium,
+			ReadSavings:  proj.Cache.ReadSavings,
+			Net:          proj.Cache.Net(),
+		}
+		if proj.Cache.WritePremium > 0 {
+			roi.ROI = proj.Cache.ReadSavings / proj.Cache.WritePremium
+		}
+		rois = append(rois, roi)
+	}
+
+	sort.Slice(rois, func(i, j int) bool {
+		if rois[i].Net != rois[j].Net {
+			return rois[i].Net < rois[j].Net
+		}
+		return rois[i].Project < rois[j].Project
+	})
+
+	return rois
+}
+
+// GetImageCosts returns the projects whose sessions included images, by the
+// input cost of the turns that carried them, most expensive first
+func (s *Statistics) GetImageCosts() []ImageCost {
+	costs := make([]ImageCost, 0)
+	for name, proj := range s.analysis.Projects {
+		if proj.Images.Images == 0 {
+			continue
+		}
+		costs = append(costs, ImageCost{
+			Project:     name,
+			Images:      proj.Images.Images,
+			Turns:       proj.Images.Turns,
+			InputCost:   proj.Images.InputCost,
+			ProjectCost: proj.Cost,
+		})
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].InputCost != costs[j].InputCost {
+			return costs[i].InputCost > costs[j].InputCost
+		}
+		return costs[i].Project < costs[j].Project
+	})
+	return costs
+}
+
+// GetInterruptions returns the projects whose turns were interrupted or
+// abandoned, by the cost of those turns, most expensive first, and their
+// totals across all projects
+func (s *Statistics) GetInterruptions() (total Interruptions, projects []Interruptions) {
+	for name, proj := range s.analysis.Projects {
+		i := Interruptions{
+			Project:     name,
+			Turns:       proj.Interrupts.Turns,
+			Interrupted: proj.Interrupts.Interrupted,
+			Abandoned:   proj.Interrupts.Abandoned,
+			Cost:        proj.Interrupts.Cost,
+			ProjectCost: proj.Cost,
+		}
+		total.Turns += i.Turns
+		total.Interrupted += i.Interrupted
+		total.Abandoned += i.Abandoned
+		total.Cost += i.Cost
+		total.ProjectCost += i.ProjectCost
+		if i.Interrupted+i.Abandoned > 0 {
+			projects = append(projects, i)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Cost != projects[j].Cost {
+			return projects[i].Cost > projects[j].Cost
+		}
+		return projects[i].Project < projects[j].Project
+	})
+	return total, projects
+}
+
+// GetFileExtensions returns file tool activity by extension for up to limit
+// extensions, most active first. An empty project summarizes all projects;
+// a limit of zero returns all extensions.
+func (s *Statistics) GetFileExtensions(project string, limit int) []ExtensionSummary {
+	byExt := s.analysis.FileExtensions
+	if project != "" {
+		proj, ok := s.analysis.Projects[project]
+		if !ok {
+			return nil
+		}
+		byExt = proj.FileExtensions
+	}
+
+	totalChanges := 0
+	for _, stats := range byExt {
+		totalChanges += stats.Edits + stats.Writes
+	}
+
+	summaries := make([]ExtensionSummary, 0, len(byExt))
+	for ext, stats := range byExt {
+		summary := ExtensionSummary{
+			Extension: ext,
+			Reads:     stats.Reads,
+			Edits:     stats.Edits,
+			Writes:    stats.Writes,
+			Total:     stats.Reads + stats.Edits + stats.Writes,
+		}
+		if totalChanges > 0 {
+			summary.ChangeShare = float64(stats.Edits+stats.Writes) / float64(totalChanges) * 100
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Total != summaries[j].Total {
+			return summaries[i].Total > summaries[j].Total
+		}
+		return summaries[i].Extension < summaries[j].Extension
+	})
+
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+	return summaries
+}
+
+// GetToolStats returns tool usage and outcomes by tool, most rejected first.
+// An empty project summarizes all projects.
+func (s *Statistics) GetToolStats(project string) []ToolSummary {
+	byTool := s.analysis.Tools
+	if project != "" {
+		proj, ok := s.analysis.Projects[project]
+		if !ok {
+			return nil
+		}
+		byTool = proj.Tools
+	}
+
+	tools := make([]ToolSummary, 0, len(byTool))
+	for name, stats := range byTool {
+		tools = append(tools, newToolSummary(name, *stats))
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Rejected != tools[j].Rejected {
+			return tools[i].Rejected > tools[j].Rejected
+		}
+		if tools[i].Uses != tools[j].Uses {
+			return tools[i].Uses > tools[j].Uses
+		}
+		return tools[i].Tool < tools[j].Tool
+	})
+
+	return tools
+}
+
+// GetProjectRejections returns tool usage totals per project for projects
+// with any rejections, highest rejection rate first
+func (s *Statistics) GetProjectRejections() []ToolSummary {
+	projects := []ToolSummary{}
+
+	for name, proj := range s.analysis.Projects {
+		var total models.ToolStats
+		for _, stats := range proj.Tools {
+			total.Uses += stats.Uses
+			total.Rejected += stats.Rejected
+			total.Errors += stats.Errors
+			total.RejectedCost += stats.RejectedCost
+		}
+		if total.Rejected > 0 {
+			projects = append(projects, newToolSummary(name, total))
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].RejectionRate != projects[j].RejectionRate {
+			return projects[i].RejectionRate > projects[j].RejectionRate
+		}
+		return projects[i].Tool < projects[j].Tool
+	})
+
+	return projects
+}
+
+// GetRejectedCost returns the cost of the responses that proposed rejected
+// tool calls
+func (s *Statistics) GetRejectedCost() float64 {
+	total := 0.0
+	for _, stats := range s.analysis.Tools {
+		total += stats.RejectedCost
+	}
+	return total
+}
+
+// GetRejectedPatterns returns the most rejected tool patterns, such as
+// "Bash git" or "Edit .go"
+func (s *Statistics) GetRejectedPatterns(limit int) []PatternCount {
+	patterns := make([]PatternCount, 0, len(s.analysis.RejectedPatterns))
+	for pattern, count := range s.analysis.RejectedPatterns {
+		patterns = append(patterns, PatternCount{Pattern: pattern, Count: count})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patterns[i].Pattern < patterns[j].Pattern
+	})
+
+	if limit > 0 && len(patterns) > limit {
+		return patterns[:limit]
+	}
+	return patterns
+}
+
+// GetBashCommands returns Bash tool usage for up to limit commands, most
+// used first. A limit of zero returns them all.
+func (s *Statistics) GetBashCommands(limit int) []BashCommandSummary {
+	commands := make([]BashCommandSummary, 0, len(s.analysis.BashCommands))
+
+	for command, stats := range s.analysis.BashCommands {
+		summary := BashCommandSummary{
+			Command:  command,
+			Count:    stats.Count,
+			Failures: stats.Failures,
+			Rejected: stats.Rejected,
+		}
+		if stats.Count > 0 {
+			summary.FailureRate = float64(stats.Failures) / float64(stats.Count) * 100
+		}
+		commands = append(commands, summary)
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		if commands[i].Count != commands[j].Count {
+			return commands[i].Count > commands[j].Count
+		}
+		return commands[i].Command < commands[j].Command
+	})
+
+	if limit > 0 && len(commands) > limit {
+		commands = commands[:limit]
+	}
+	return commands
+}
+
 // Helper functions
 
-func percentile(sorted []float64, p float64) float64 {
+func newToolSummary(name string, stats models.ToolStats) ToolSummary {
+	summary := ToolSummary{
+		Tool:         name,
+		Uses:         stats.Uses,
+		Rejected:     stats.Rejected,
+		Errors:       stats.Errors,
+		RejectedCost: stats.RejectedCost,
+	}
+	if stats.Uses > 0 {
+		summary.RejectionRate = float64(stats.Rejected) / float64(stats.Uses) * 100
+	}
+	return summary
+}
+
+func tokensPerMinute(tokens, minutes int) float64 {
+	if minutes == 0 {
+		return 0
+	}
+	return float64(tokens) / float64(minutes)
+}
+
+// Percentile returns the pth percentile of sorted values, interpolating
+// between neighbors
+func Percentile(sorted []float64, p float64) float64 {
 	if len(sorted) == 0 {
 		return 0
 	}
@@ -204,6 +1253,50 @@ func percentile(sorted []float64, p float64) float64 {
 	return sorted[lower]*(1-weight) + sorted[upper]*weight
 }
 
+// durationStats summarizes durations in seconds
+func durationStats(durations []time.Duration) ResponseTimeStats {
+	stats := ResponseTimeStats{}
+
+	if len(durations) == 0 {
+		return stats
+	}
+
+	// Convert to seconds and sort
+	times := make([]float64, len(durations))
+	for i, d := range durations {
+		times[i] = d.Seconds()
+	}
+	sort.Float64s(times)
+
+	// Calculate statistics
+	stats.Count = len(times)
+	stats.Min = times[0]
+	stats.Max = times[len(times)-1]
+	stats.P50 = Percentile(times, 50)
+	stats.P90 = Percentile(times, 90)
+	stats.P95 = Percentile(times, 95)
+	stats.P99 = Percentile(times, 99)
+
+	// Calculate average
+	sum := 0.0
+	for _, t := range times {
+		sum += t
+	}
+	stats.Average = sum / float64(len(times))
+
+	return stats
+}
+
+// durationPercentiles returns the p50 and p90 of the given durations
+func durationPercentiles(durations []time.Duration) (p50, p90 time.Duration) {
+	times := make([]float64, len(durations))
+	for i, d := range durations {
+		times[i] = float64(d)
+	}
+	sort.Float64s(times)
+	return time.Duration(Percentile(times, 50)), time.Duration(Percentile(times, 90))
+}
+
 // Data structures for statistics
 
 type ResponseTimeStats struct {
@@ -219,6 +1312,7 @@ type ResponseTimeStats struct {
 
 type ProjectSummary struct {
 	Name             string
+	Dirs             []string // Encoded directories under projects/, sorted
 	Cost             float64
 	Sessions         int
 	InputTokens      int
@@ -227,22 +1321,412 @@ type ProjectSummary struct {
 	CacheWriteTokens int
 	ActiveDays       int
 	AvgResponseTime  time.Duration
+	P50ResponseTime  time.Duration
+	P90ResponseTime  time.Duration
+	TokensPerMinute  float64
+}
+
+// InputOutputRatio returns the input tokens, cache reads and writes included,
+// read per output token; lower is more efficient
+func (p ProjectSummary) InputOutputRatio() float64 {
+	return inputOutputRatio(p.InputTokens+p.CacheReadTokens+p.CacheWriteTokens, p.OutputTokens)
+}
+
+// inputOutputRatio returns input per output token, or zero without output
+func inputOutputRatio(input, output int) float64 {
+	if output == 0 {
+		return 0
+	}
+	return float64(input) / float64(output)
+}
+
+type ToolSummary struct {
+	Tool          string // Tool name, or project name in per-project summaries
+	Uses          int
+	Rejected      int
+	Errors        int
+	RejectionRate float64
+	RejectedCost  float64 // Of the responses that proposed the rejected calls
+}
+
+type PatternCount struct {
+	Pattern string
+	Count   int
+}
+
+type BashCommandSummary struct {
+	Command     string
+	Count       int
+	Failures    int
+	Rejected    int
+	FailureRate float64
+}
+
+type ExtensionSummary struct {
+	Extension   string
+	Reads       int
+	Edits       int
+	Writes      int
+	Total       int
+	ChangeShare float64 // Percentage of all edits and writes
+}
+
+type CacheROI struct {
+	Project      string
+	WriteCost    float64
+	WritePremium float64
+	ReadSavings  float64
+	Net          float64
+	ROI          float64 // ReadSavings per dollar of WritePremium
+}
+
+// ImageCost is the input spend of one project's turns with images
+type ImageCost struct {
+	Project     string
+	Images      int
+	Turns       int
+	InputCost   float64
+	ProjectCost float64
+}
+
+// Interruptions are a project's turns that the user interrupted or that
+// ended their session abruptly
+type Interruptions struct {
+	Project     string
+	Turns       int
+	Interrupted int
+	Abandoned   int
+	Cost        float64 // Of the interrupted and abandoned turns
+	ProjectCost float64
+}
+
+// Rate returns the percentage of turns interrupted or abandoned
+func (i Interruptions) Rate() float64 {
+	if i.Turns == 0 {
+		return 0
+	}
+	return float64(i.Interrupted+i.Abandoned) / float64(i.Turns) * 100
+}
+
+// Share returns the percentage of the project's cost spent on those turns
+func (i Interruptions) Share() float64 {
+	if i.ProjectCost == 0 {
+		return 0
+	}
+	return i.Cost / i.ProjectCost * 100
+}
+
+type SessionSummary struct {
+	Start         time.Time
+	End           time.Time
+	SessionID     string
+	Project       string
+	ProjectDir    string
+	GitBranch     string
+	Title         string
+	Tags          []string
+	Cost          float64
+	Messages      int
+	Tokens        int
+	InputTokens   int // Including cache reads and writes
+	OutputTokens  int
+	ActiveMinutes int
+	// ContextTokens are the input tokens of the session's responses, of
+	// which RepeatedTokens re-sent earlier context, at RepeatedCost
+	ContextTokens  int
+	RepeatedTokens int
+	RepeatedCost   float64
+}
+
+// RightSizing is a short Opus session priced at cheaper models
+type RightSizing struct {
+	SessionID    string
+	Project      string
+	Title        string
+	Prompts      int
+	OutputTokens int // Of the Opus responses
+	// OpusCost is what the Opus responses cost; SonnetCost and HaikuCost
+	// what the same tokens would have cost on those models
+	OpusCost   float64
+	SonnetCost float64
+	HaikuCost  float64
+}
+
+// SonnetSavings returns what running the Opus responses on Sonnet would have
+// saved
+func (r RightSizing) SonnetSavings() float64 {
+	return r.OpusCost - r.SonnetCost
+}
+
+// Matches reports whether every word of query appears in the session's
+// title or project, ignoring case
+func (s SessionSummary) Matches(query string) bool {
+	text := strings.ToLower(s.Title + "\n" + s.Project)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// InputOutputRatio returns the input tokens read per output token
+func (s SessionSummary) InputOutputRatio() float64 {
+	return inputOutputRatio(s.InputTokens, s.OutputTokens)
+}
+
+// ContextOverhead returns the percentage of input tokens that re-sent
+// earlier context
+func (s SessionSummary) ContextOverhead() float64 {
+	if s.ContextTokens == 0 {
+		return 0
+	}
+	return float64(s.RepeatedTokens) / float64(s.ContextTokens) * 100
+}
+
+type Block struct {
+	Start        time.Time
+	End          time.Time
+	LastActivity time.Time // Start of the last minute with activity
+	Cost         float64
+	Messages     int
+	Tokens       int
+	// InputOutputTokens excludes cache tokens
+	InputOutputTokens int
+	UsageLimits       int  // Usage limit messages during the block
+	RateLimits        int  // Rate limit errors during the block
+	Active            bool // The block contains the current time
+}
+
+// BlockSummary describes a history of billing blocks
+type BlockSummary struct {
+	Blocks int
+	Since  time.Time
+	Peaks  []Block // Most expensive first
+	// Typical usage; tokens exclude cache tokens
+	MedianCost, P90Cost     float64
+	MedianTokens, P90Tokens int
+}
+
+type AccountSummary struct {
+	Name       string
+	Cost       float64
+	Percentage float64 // Share of total cost
+	Sessions   int
+	Messages   int
+	Tokens     int
+}
+
+type DowngradeDay struct {
+	Date              string
+	OpusMinutes       int
+	DowngradedMinutes int // Sonnet minutes in sessions that had used Opus
+	DowngradedCost    float64
+	Switches          int
+}
+
+// Share returns the downgraded minutes as a percentage of all minutes meant
+// to run on Opus
+func (d DowngradeDay) Share() float64 {
+	total := d.OpusMinutes + d.DowngradedMinutes
+	if total == 0 {
+		return 0
+	}
+	return float64(d.DowngradedMinutes) / float64(total) * 100
+}
+
+type LimitDay struct {
+	Date        string
+	UsageLimits int
+	RateLimits  int
+}
+
+type LimitSummary struct {
+	Days        []LimitDay // Days with limit events, oldest first
+	UsageLimits int
+	RateLimits  int
+	ActiveDays  int // Days with any activity
+	BlocksHit   int // Billing blocks that reached the usage limit
+	Blocks      int
+}
+
+type SessionVelocity struct {
+	SessionID       string
+	Project         string
+	Cost            float64
+	OutputTokens    int
+	ActiveMinutes   int
+	TokensPerMinute float64
+}
+
+type TagSummary struct {
+	Tag      string
+	Cost     float64
+	Sessions int
+	Tokens   int
 }
 
 type HourlyData struct {
 	Hour     int
 	Messages int
 	Cost     float64
+	Tokens   int // Only set by GetHourlyCosts
+	Days     int // Days with activity in this hour
+}
+
+// AvgCost is the cost averaged over the days with activity in this hour,
+// so an hour reflects how busy it is rather than how many days it spans
+func (h HourlyData) AvgCost() float64 {
+	if h.Days == 0 {
+		return 0
+	}
+	return h.Cost / float64(h.Days)
+}
+
+// WeekdayData is activity on one day of the week
+type WeekdayData struct {
+	Weekday  time.Weekday
+	Messages int
+	Cost     float64
+	Days     int // Dates falling on this weekday with activity
+}
+
+// AvgCost is the cost averaged over the dates with activity on this weekday
+func (w WeekdayData) AvgCost() float64 {
+	if w.Days == 0 {
+		return 0
+	}
+	return w.Cost / float64(w.Days)
+}
+
+// WorkSplit is activity inside and outside working hours
+type WorkSplit struct {
+	WorkMessages int
+	WorkCost     float64
+	OffMessages  int
+	OffCost      float64
+}
+
+// CostTrend is a line fit to daily cost, and how the last two weeks compare
+type CostTrend struct {
+	// Slope is the change in daily cost per day, in USD
+	Slope     float64
+	Intercept float64
+	// R2 is the coefficient of determination, 0 to 1: how well the line
+	// fits
+	R2 float64
+	// Days is the number of calendar days fit
+	Days int
+	// ThisWeek and LastWeek are the cost of the latest 7 days and the 7
+	// before them, and RecentSlope and PriorSlope lines fit to each. All
+	// are zero when fewer than 14 days were fit.
+	ThisWeek    float64
+	LastWeek    float64
+	RecentSlope float64
+	PriorSlope  float64
+}
+
+// WeekChange returns ThisWeek as a multiple of LastWeek, or zero when
+// there was no spend last week
+func (t CostTrend) WeekChange() float64 {
+	if t.LastWeek == 0 {
+		return 0
+	}
+	return t.ThisWeek / t.LastWeek
+}
+
+// Reversed reports whether the trend changed direction between last week
+// and this week
+func (t CostTrend) Reversed() bool {
+	return (t.PriorSlope > 0 && t.RecentSlope < 0) || (t.PriorSlope < 0 && t.RecentSlope > 0)
+}
+
+// AnomalyThresholds set when a cost counts as a spike. Zero disables
+// either test.
+type AnomalyThresholds struct {
+	// StdDevs flags costs this many standard deviations above the
+	// trailing mean
+	StdDevs float64
+	// MedianMultiple flags costs more than this many times the trailing
+	// median
+	MedianMultiple float64
+}
+
+// Anomaly is a day or session whose cost spiked
+type Anomaly struct {
+	Date string
+	// SessionID, Title, and Project are empty for a whole day
+	SessionID string
+	Title     string
+	Project   string
+	Cost      float64
+	// Median is the trailing median cost, and StdDevs and Multiple how
+	// far Cost is above the trailing mean and median
+	Median   float64
+	StdDevs  float64
+	Multiple float64
 }
 
 type DailyData struct {
-	Date     string
+	Date         string
+	Messages     int
+	Cost         float64
+	CacheHitRate float64
+	HasCacheData bool
+	// Models are the models used that day, most expensive first
+	Models []DailyModel
+	// Avg7 and Avg30 are the average daily cost over the 7 and 30 days
+	// ending on Date
+	Avg7  float64
+	Avg30 float64
+}
+
+// DailyModel is one model's part of a day's cost
+type DailyModel struct {
+	Model      string
+	Cost       float64
+	Percentage float64 // Of the day's cost
+}
+
+type DailySummary struct {
+	Date              string
+	TopProject        string
+	TopSession        string
+	TopSessionProject string
+	Cost              float64
+	PreviousCost      float64
+	TopProjectCost    float64
+	TopSessionCost    float64
+	Messages          int
+}
+
+// Delta returns the change in cost from the previous day
+func (d DailySummary) Delta() float64 {
+	return d.Cost - d.PreviousCost
+}
+
+// CategoryShare is a response category's responses and their share of
+// total cost
+type CategoryShare struct {
+	Category string
 	Messages int
+	Tokens   int
 	Cost     float64
+	Share    float64 // Percentage of total cost
+}
+
+// TaskShare is a kind of task's responses and their share of total cost
+type TaskShare struct {
+	Messages int
+	Cost     float64
+	Share    float64 // Percentage of total cost
 }
 
 type ModelUsage struct {
-	Model      string
-	Count      int
-	Percentage float64
+	Model           string
+	Count           int
+	Percentage      float64
+	P50ResponseTime time.Duration
+	P90ResponseTime time.Duration
+	TokensPerMinute float64
 }
@@ -1,6 +1,11 @@
 package calculator
 
 import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -131,8 +136,8 @@ func TestPercentile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := percentile(tt.sorted, tt.p); got != tt.want {
-				t.Errorf("percentile() = %v, want %v", got, tt.want)
+			if got := Percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("Percentile() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -168,3 +173,1007 @@ func TestStatistics_GetResponseTimeStats(t *testing.T) {
 		t.Errorf("P50 = %v, want 3.0", stats.P50)
 	}
 }
+
+func TestStatistics_GetModelDistribution_ResponseTimes(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		ModelUsage: map[string]int{
+			"claude-opus-4-20250514":   3,
+			"claude-sonnet-4-20250514": 1,
+		},
+		ModelResponseTimes: map[string][]time.Duration{
+			"claude-opus-4-20250514": {3 * time.Second, 1 * time.Second, 2 * time.Second},
+		},
+	}
+
+	dist := New(analysis).GetModelDistribution(0)
+	if len(dist) != 2 {
+		t.Fatalf("got %d models, want 2", len(dist))
+	}
+
+	opus := dist[0]
+	if opus.Model != "claude-opus-4-20250514" {
+		t.Fatalf("first model = %s, want opus", opus.Model)
+	}
+	if opus.P50ResponseTime != 2*time.Second {
+		t.Errorf("P50ResponseTime = %v, want 2s", opus.P50ResponseTime)
+	}
+	if want := 2800 * time.Millisecond; opus.P90ResponseTime != want {
+		t.Errorf("P90ResponseTime = %v, want %v", opus.P90ResponseTime, want)
+	}
+
+	if sonnet := dist[1]; sonnet.P50ResponseTime != 0 {
+		t.Errorf("sonnet P50ResponseTime = %v, want 0 without samples", sonnet.P50ResponseTime)
+	}
+}
+
+func TestStatistics_GetSessionVelocities(t *testing.T) {
+	minutes := func(n int) map[int64]bool {
+		m := make(map[int64]bool, n)
+		for i := 0; i < n; i++ {
+			m[int64(i)] = true
+		}
+		return m
+	}
+
+	analysis := &models.CostAnalysis{
+		TotalOutputTokens: 3000,
+		ActiveMinutes:     minutes(20),
+		Sessions: map[string]*models.SessionStats{
+			"fast":   {OutputTokens: 2000, ActiveMinutes: minutes(10)},
+			"stuck":  {OutputTokens: 500, ActiveMinutes: minutes(10)},
+			"brief":  {OutputTokens: 500, ActiveMinutes: minutes(1)},
+			"silent": {},
+		},
+	}
+
+	s := New(analysis)
+	if got := s.GetTokenVelocity(); got != 150 {
+		t.Errorf("GetTokenVelocity() = %v, want 150", got)
+	}
+
+	velocities := s.GetSessionVelocities(5, 0)
+	if len(velocities) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(velocities))
+	}
+	if velocities[0].SessionID != "stuck" || velocities[0].TokensPerMinute != 50 {
+		t.Errorf("slowest = %+v, want stuck at 50 tok/min", velocities[0])
+	}
+	if velocities[1].SessionID != "fast" || velocities[1].TokensPerMinute != 200 {
+		t.Errorf("second = %+v, want fast at 200 tok/min", velocities[1])
+	}
+}
+
+func TestStatistics_GetCacheROI(t *testing.T) {
+	sonnet := models.ModelPricing["claude-sonnet-4-20250514"]
+	analysis := &models.CostAnalysis{
+		Projects: map[string]*models.ProjectStats{
+			// Writes a lot, never reads it back
+			"write-only": {
+				CacheWriteTokens: 1_000_000,
+				Cache:            models.NewCacheCosts(sonnet, 1_000_000, 0),
+			},
+			// Writes once, reads ten times
+			"reuses": {
+				CacheWriteTokens: 1_000_000,
+				CacheReadTokens:  10_000_000,
+				Cache:            models.NewCacheCosts(sonnet, 1_000_000, 10_000_000),
+			},
+			"uncached": {InputTokens: 1000},
+		},
+	}
+
+	rois := New(analysis).GetCacheROI()
+	if len(rois) != 2 {
+		t.Fatalf("got %d projects, want 2", len(rois))
+	}
+
+	worst := rois[0]
+	if worst.Project != "write-only" {
+		t.Fatalf("worst project = %s, want write-only", worst.Project)
+	}
+	if abs(worst.WritePremium-0.75) > 1e-9 || abs(worst.Net+0.75) > 1e-9 {
+		t.Errorf("write-only premium/net = %v/%v, want 0.75/-0.75", worst.WritePremium, worst.Net)
+	}
+
+	best := rois[1]
+	// 10M reads save $27 at sonnet rates against a $0.75 write premium
+	if abs(best.ReadSavings-27.0) > 1e-9 || abs(best.ROI-36.0) > 1e-9 {
+		t.Errorf("reuses savings/ROI = %v/%v, want 27/36", best.ReadSavings, best.ROI)
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestStatistics_GetCacheHitRateAlert(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 10, InputTokens: 100, CacheReadTokens: 900},
+			"2025-06-13": {MessageCount: 10, InputTokens: 600, CacheReadTokens: 400},
+			// Legacy costUSD entries carry no token data
+			"2025-06-14": {MessageCount: 3, Cost: 1.0},
+		},
+	}
+	s := New(analysis)
+
+	alert, day := s.GetCacheHitRateAlert(50)
+	if !alert {
+		t.Error("expected alert for 40% hit rate below 50% threshold")
+	}
+	if day.Date != "2025-06-13" || day.CacheHitRate != 40 {
+		t.Errorf("alert day = %s at %v%%, want 2025-06-13 at 40%%", day.Date, day.CacheHitRate)
+	}
+
+	if alert, _ := s.GetCacheHitRateAlert(30); alert {
+		t.Error("unexpected alert for 40% hit rate above 30% threshold")
+	}
+}
+
+func TestStatistics_GetDailySummary(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		Sessions: map[string]*models.SessionStats{
+			"s2": {Project: "api"},
+		},
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 4, Cost: 2.0},
+			"2025-06-13": {
+				MessageCount: 6,
+				Cost:         3.5,
+				ProjectCosts: map[string]float64{"web": 1.0, "api": 2.5},
+				SessionCosts: map[string]float64{"s1": 1.0, "s2": 1.5, "s3": 1.0},
+			},
+		},
+	}
+	s := New(analysis)
+
+	got := s.GetDailySummary("2025-06-13")
+	if got.Cost != 3.5 || got.PreviousCost != 2.0 || got.Delta() != 1.5 {
+		t.Errorf("cost = %v, previous = %v, delta = %v; want 3.5, 2.0, 1.5", got.Cost, got.PreviousCost, got.Delta())
+	}
+	if got.TopProject != "api" || got.TopProjectCost != 2.5 {
+		t.Errorf("top project = %s (%v), want api (2.5)", got.TopProject, got.TopProjectCost)
+	}
+	if got.TopSession != "s2" || got.TopSessionProject != "api" {
+		t.Errorf("top session = %s in %s, want s2 in api", got.TopSession, got.TopSessionProject)
+	}
+
+	if empty := s.GetDailySummary("2025-06-20"); empty.Cost != 0 || empty.TopProject != "" {
+		t.Errorf("inactive day = %+v, want zero cost and no top project", empty)
+	}
+}
+
+func TestStatistics_GetDailyTrend_Models(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 1},
+			"2025-06-13": {
+				MessageCount: 6,
+				Cost:         4.0,
+				ModelCosts:   map[string]float64{"sonnet": 1.0, "opus": 3.0, "unpriced": 0},
+			},
+		},
+	}
+
+	trend := New(analysis).GetDailyTrend()
+	if len(trend[0].Models) != 0 {
+		t.Errorf("day without models = %+v, want none", trend[0].Models)
+	}
+	want := []DailyModel{
+		{Model: "opus", Cost: 3.0, Percentage: 75},
+		{Model: "sonnet", Cost: 1.0, Percentage: 25},
+		{Model: "unpriced", Cost: 0, Percentage: 0},
+	}
+	if !reflect.DeepEqual(trend[1].Models, want) {
+		t.Errorf("models = %+v, want %+v", trend[1].Models, want)
+	}
+}
+
+func TestStatistics_GetDailyTrend_RollingAverages(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-01": {Cost: 7.0},
+			"2025-06-02": {Cost: 1.0},
+			// A gap of idle days, which count as zero
+			"2025-06-08": {Cost: 6.0},
+			"2025-07-10": {Cost: 3.0},
+		},
+	}
+
+	trend := New(analysis).GetDailyTrend()
+	want := []struct{ avg7, avg30 float64 }{
+		{7.0, 7.0},          // A one-day window on the first day
+		{4.0, 4.0},          // Two days
+		{1.0, 14.0 / 8},     // 06-02..06-08, and the eight days so far
+		{3.0 / 7, 3.0 / 30}, // 06-08 is outside both windows
+	}
+	for i, w := range want {
+		if abs(trend[i].Avg7-w.avg7) > 1e-9 || abs(trend[i].Avg30-w.avg30) > 1e-9 {
+			t.Errorf("%s averages = %v, %v, want %v, %v", trend[i].Date, trend[i].Avg7, trend[i].Avg30, w.avg7, w.avg30)
+		}
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	tests := []struct {
+		ys                   []float64
+		slope, intercept, r2 float64
+	}{
+		{[]float64{1, 3, 5, 7}, 2, 1, 1},
+		{[]float64{4, 4, 4}, 0, 4, 1},
+		{[]float64{0, 2, 0, 2}, 0.4, 0.4, 0.2},
+	}
+	for _, tt := range tests {
+		slope, intercept, r2 := linearFit(tt.ys)
+		if abs(slope-tt.slope) > 1e-9 || abs(intercept-tt.intercept) > 1e-9 || abs(r2-tt.r2) > 1e-9 {
+			t.Errorf("linearFit(%v) = %v, %v, %v, want %v, %v, %v", tt.ys, slope, intercept, r2, tt.slope, tt.intercept, tt.r2)
+		}
+	}
+}
+
+func TestStatistics_GetCostTrend(t *testing.T) {
+	// A week of spend falling by a dollar a day, then one rising by two,
+	// with an idle day between counted as zero
+	daily := map[string]*models.DailyActivity{}
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	costs := []float64{8, 7, 6, 5, 4, 3, 2, 0, 2, 4, 6, 8, 10, 12}
+	for i, cost := range costs {
+		if cost > 0 {
+			daily[start.AddDate(0, 0, i).Format("2006-01-02")] = &models.DailyActivity{MessageCount: 1, Cost: cost}
+		}
+	}
+
+	trend, ok := New(&models.CostAnalysis{DailyActivity: daily}).GetCostTrend()
+	if !ok {
+		t.Fatal("no trend over 14 days")
+	}
+	if trend.Days != 14 || trend.Slope <= 0 || trend.R2 <= 0 || trend.R2 >= 1 {
+		t.Errorf("trend = %+v, want a loose upward fit over 14 days", trend)
+	}
+	if trend.ThisWeek != 42 || trend.LastWeek != 35 || abs(trend.WeekChange()-1.2) > 1e-9 {
+		t.Errorf("weeks = %v then %v, want 35 then 42", trend.LastWeek, trend.ThisWeek)
+	}
+	if trend.PriorSlope != -1 || trend.RecentSlope != 2 || !trend.Reversed() {
+		t.Errorf("slopes = %v then %v, want a reversal from -1 to 2", trend.PriorSlope, trend.RecentSlope)
+	}
+
+	short := map[string]*models.DailyActivity{"2025-06-01": {Cost: 1}, "2025-06-06": {Cost: 2}}
+	if _, ok := New(&models.CostAnalysis{DailyActivity: short}).GetCostTrend(); ok {
+		t.Error("trend over 6 days, want none")
+	}
+}
+
+func TestStatistics_GetAnomalies(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{},
+		Sessions:      map[string]*models.SessionStats{},
+	}
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.Local)
+	// Ten ordinary days of one session each, then a runaway session, then
+	// a day that's only notable next to the runaway
+	costs := []float64{1.0, 1.2, 0.8, 1.1, 0.9, 1.0, 1.3, 0.7, 1.0, 1.1, 12.0, 2.0}
+	for i, cost := range costs {
+		day := start.AddDate(0, 0, i)
+		analysis.DailyActivity[day.Format("2006-01-02")] = &models.DailyActivity{MessageCount: 1, Cost: cost}
+		analysis.Sessions[fmt.Sprintf("s%02d", i)] = &models.SessionStats{Project: "app", StartTime: day, Cost: cost}
+	}
+	s := New(analysis)
+
+	days, sessions := s.GetAnomalies(AnomalyThresholds{StdDevs: 3, MedianMultiple: 5}, 0)
+	if len(days) != 1 || days[0].Date != "2025-06-11" {
+		t.Fatalf("days = %+v, want 2025-06-11", days)
+	}
+	if days[0].Median != 1.0 || days[0].Multiple != 12 || days[0].StdDevs < 3 {
+		t.Errorf("anomaly = %+v, want 12x the median of 1.0", days[0])
+	}
+	if len(sessions) != 1 || sessions[0].SessionID != "s10" || sessions[0].Project != "app" {
+		t.Errorf("sessions = %+v, want s10", sessions)
+	}
+	if days, _ := s.GetAnomalies(AnomalyThresholds{MedianMultiple: 1.5}, 1); len(days) != 1 || days[0].Date != "2025-06-12" {
+		t.Errorf("limit 1 = %+v, want only the latest, 2025-06-12", days)
+	}
+
+	// Each test can be disabled on its own
+	if days, _ := s.GetAnomalies(AnomalyThresholds{MedianMultiple: 15}, 0); len(days) != 0 {
+		t.Errorf("12x under a 15x threshold = %+v, want none", days)
+	}
+	if days, _ := s.GetAnomalies(AnomalyThresholds{StdDevs: 3}, 0); len(days) != 1 {
+		t.Errorf("standard deviations only = %+v, want one", days)
+	}
+	if days, sessions := s.GetAnomalies(AnomalyThresholds{}, 0); len(days)+len(sessions) != 0 {
+		t.Errorf("disabled = %+v, %+v, want none", days, sessions)
+	}
+}
+
+func TestStatistics_Limits(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		ModelUsage: map[string]int{"opus": 3, "sonnet": 2, "haiku": 1},
+		BashCommands: map[string]*models.BashCommandStats{
+			"git": {Count: 5}, "go": {Count: 3}, "npm": {Count: 1},
+		},
+		FileExtensions: map[string]*models.ExtensionStats{
+			".go": {Edits: 4}, ".md": {Reads: 2}, ".ts": {Writes: 1},
+		},
+	}
+	s := New(analysis)
+
+	if models := s.GetModelDistribution(2); len(models) != 2 || models[1].Model != "sonnet" {
+		t.Errorf("GetModelDistribution(2) = %+v, want opus and sonnet", models)
+	}
+	if commands := s.GetBashCommands(1); len(commands) != 1 || commands[0].Command != "git" {
+		t.Errorf("GetBashCommands(1) = %+v, want git", commands)
+	}
+	if extensions := s.GetFileExtensions("", 2); len(extensions) != 2 || extensions[1].Extension != ".md" {
+		t.Errorf("GetFileExtensions(2) = %+v, want .go and .md", extensions)
+	}
+	if all := s.GetBashCommands(0); len(all) != 3 {
+		t.Errorf("GetBashCommands(0) returned %d commands, want all 3", len(all))
+	}
+}
+
+func TestStatistics_GetAnomalies_NeedsHistory(t *testing.T) {
+	analysis := &models.CostAnalysis{DailyActivity: map[string]*models.DailyActivity{
+		"2025-06-01": {MessageCount: 1, Cost: 1.0},
+		"2025-06-02": {MessageCount: 1, Cost: 50.0},
+	}}
+	if days, _ := New(analysis).GetAnomalies(AnomalyThresholds{StdDevs: 3, MedianMultiple: 5}, 0); len(days) != 0 {
+		t.Errorf("days = %+v, want none without a week of history", days)
+	}
+}
+
+func TestStatistics_GetImageCosts(t *testing.T) {
+	analysis := &models.CostAnalysis{Projects: map[string]*models.ProjectStats{
+		"api":  {Cost: 10, Images: models.ImageStats{Images: 2, Turns: 1, InputCost: 1.5}},
+		"web":  {Cost: 20, Images: models.ImageStats{Images: 9, Turns: 4, InputCost: 6.0}},
+		"docs": {Cost: 5},
+	}}
+
+	costs := New(analysis).GetImageCosts()
+	want := []ImageCost{
+		{Project: "web", Images: 9, Turns: 4, InputCost: 6.0, ProjectCost: 20},
+		{Project: "api", Images: 2, Turns: 1, InputCost: 1.5, ProjectCost: 10},
+	}
+	if !reflect.DeepEqual(costs, want) {
+		t.Errorf("GetImageCosts() = %+v, want %+v", costs, want)
+	}
+}
+
+func TestStatistics_GetContextOverhead(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	analysis := &models.CostAnalysis{Sessions: map[string]*models.SessionStats{
+		"short": {StartTime: start, ContextTokens: 1000, RepeatedTokens: 200, RepeatedCost: 0.5},
+		"long":  {StartTime: start.Add(time.Hour), ContextTokens: 9000, RepeatedTokens: 8800, RepeatedCost: 4.0},
+		"fresh": {StartTime: start.Add(2 * time.Hour), ContextTokens: 500},
+	}}
+
+	total, sessions := New(analysis).GetContextOverhead(0)
+	if total.ContextTokens != 10_500 || total.RepeatedTokens != 9000 || total.RepeatedCost != 4.5 {
+		t.Errorf("total = %+v, want 9000 of 10500 tokens re-sent for $4.50", total)
+	}
+	if abs(total.ContextOverhead()-9000.0/10_500*100) > 1e-9 {
+		t.Errorf("ContextOverhead() = %v", total.ContextOverhead())
+	}
+	if len(sessions) != 2 || sessions[0].SessionID != "long" || sessions[1].SessionID != "short" {
+		t.Errorf("sessions = %+v, want long then short", sessions)
+	}
+	if _, sessions := New(analysis).GetContextOverhead(1); len(sessions) != 1 {
+		t.Errorf("limit 1 returned %d sessions", len(sessions))
+	}
+}
+
+func TestStatistics_GetTopProjects_Dirs(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		Projects: map[string]*models.ProjectStats{
+			"api": {Cost: 2},
+			"web": {Cost: 2},
+		},
+		Sessions: map[string]*models.SessionStats{
+			"s1": {Project: "api", ProjectDir: "-home-user-src-api-v2"},
+			"s2": {Project: "api", ProjectDir: "-home-user-src-api"},
+			"s3": {Project: "api", ProjectDir: "-home-user-src-api"},
+			"s4": {Project: "web", ProjectDir: "-home-user-src-web"},
+		},
+	}
+
+	projects := New(analysis).GetTopProjects(0)
+	if len(projects) != 2 || projects[0].Name != "api" || projects[1].Name != "web" {
+		t.Fatalf("projects = %+v, want api then web by name on equal cost", projects)
+	}
+	if want := []string{"-home-user-src-api", "-home-user-src-api-v2"}; !reflect.DeepEqual(projects[0].Dirs, want) {
+		t.Errorf("api Dirs = %v, want %v", projects[0].Dirs, want)
+	}
+}
+
+func TestStatistics_GetLowYieldSessions(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	analysis := &models.CostAnalysis{Sessions: map[string]*models.SessionStats{
+		"reader":  {StartTime: start, InputTokens: 1000, CacheReadTokens: 599_000, OutputTokens: 1000},
+		"worse":   {StartTime: start.Add(time.Hour), CacheReadTokens: 900_000, OutputTokens: 1000},
+		"writer":  {StartTime: start.Add(2 * time.Hour), InputTokens: 1000, CacheReadTokens: 50_000, OutputTokens: 2000},
+		"no-text": {StartTime: start.Add(3 * time.Hour), CacheReadTokens: 10_000},
+	}}
+
+	sessions := New(analysis).GetLowYieldSessions(0)
+	if len(sessions) != 2 || sessions[0].SessionID != "worse" || sessions[1].SessionID != "reader" {
+		t.Fatalf("sessions = %+v, want worse then reader", sessions)
+	}
+	if got := sessions[1].InputOutputRatio(); got != 600 {
+		t.Errorf("InputOutputRatio() = %v, want 600", got)
+	}
+	if sessions := New(analysis).GetLowYieldSessions(1); len(sessions) != 1 {
+		t.Errorf("limit 1 returned %d sessions", len(sessions))
+	}
+
+	project := ProjectSummary{InputTokens: 100, CacheReadTokens: 800, CacheWriteTokens: 100, OutputTokens: 50}
+	if got := project.InputOutputRatio(); got != 20 {
+		t.Errorf("ProjectSummary.InputOutputRatio() = %v, want 20", got)
+	}
+}
+
+func TestStatistics_GetRightSizing(t *testing.T) {
+	opus := models.TokenCounts{Input: 1000, Output: 800, CacheWrite: 10_000}
+	analysis := &models.CostAnalysis{Sessions: map[string]*models.SessionStats{
+		"quick":   {Prompts: 1, OpusCost: 0.5, OpusTokens: opus},
+		"wordy":   {Prompts: 1, OpusCost: 2, OpusTokens: models.TokenCounts{Output: 5000}},
+		"long":    {Prompts: 12, OpusCost: 9, OpusTokens: opus},
+		"sonnet":  {Prompts: 1, Cost: 0.1},
+		"unasked": {OpusCost: 0.2, OpusTokens: models.TokenCounts{Output: 100}},
+	}}
+
+	total, sessions := New(analysis).GetRightSizing(0)
+	if len(sessions) != 2 || sessions[0].SessionID != "quick" || sessions[1].SessionID != "unasked" {
+		t.Fatalf("sessions = %+v, want quick then unasked", sessions)
+	}
+	// 1K input at $3, 800 output at $15, and 10K cache writes at $3.75 per
+	// million on Sonnet 4
+	if want := 0.003 + 0.012 + 0.0375; abs(sessions[0].SonnetCost-want) > 1e-9 {
+		t.Errorf("SonnetCost = %v, want %v", sessions[0].SonnetCost, want)
+	}
+	if sessions[0].HaikuCost >= sessions[0].SonnetCost {
+		t.Errorf("HaikuCost = %v, want less than Sonnet", sessions[0].HaikuCost)
+	}
+	if abs(total.OpusCost-0.7) > 1e-9 {
+		t.Errorf("total OpusCost = %v, want 0.7", total.OpusCost)
+	}
+}
+
+func TestStatistics_GetBackgroundTasks(t *testing.T) {
+	haiku := "claude-3-5-haiku-20241022"
+	analysis := &models.CostAnalysis{
+		TotalCost:      10,
+		ModelUsage:     map[string]int{"claude-sonnet-4-20250514": 30, haiku: 70},
+		Background:     models.TaskStats{Messages: map[string]int{haiku: 60}, Cost: 0.5},
+		HaikuSubagents: models.TaskStats{Messages: map[string]int{haiku: 10}, Cost: 1},
+	}
+	stats := New(analysis)
+
+	models := stats.GetForegroundModelDistribution(0)
+	if len(models) != 2 || models[0].Count != 30 || models[1].Count != 10 {
+		t.Fatalf("foreground models = %+v, want 30 Sonnet and 10 Haiku", models)
+	}
+	if abs(models[0].Percentage-75) > 1e-9 {
+		t.Errorf("Sonnet share = %v, want 75", models[0].Percentage)
+	}
+	if all := stats.GetModelDistribution(0); all[0].Model != haiku || all[0].Count != 70 {
+		t.Errorf("all models = %+v, want the background calls counted", all)
+	}
+
+	background, subagents := stats.GetBackgroundTasks()
+	if background.Messages != 60 || abs(background.Share-5) > 1e-9 {
+		t.Errorf("background = %+v, want 60 calls and 5%%", background)
+	}
+	if subagents.Messages != 10 || abs(subagents.Share-10) > 1e-9 {
+		t.Errorf("subagents = %+v, want 10 responses and 10%%", subagents)
+	}
+}
+
+func TestStatistics_GetInterruptions(t *testing.T) {
+	analysis := &models.CostAnalysis{Projects: map[string]*models.ProjectStats{
+		"calm":   {Cost: 5, Interrupts: models.InterruptStats{Turns: 10}},
+		"edgy":   {Cost: 10, Interrupts: models.InterruptStats{Turns: 20, Interrupted: 4, Abandoned: 1, Cost: 2}},
+		"hectic": {Cost: 5, Interrupts: models.InterruptStats{Turns: 10, Interrupted: 5, Cost: 3}},
+	}}
+
+	total, projects := New(analysis).GetInterruptions()
+	if len(projects) != 2 || projects[0].Project != "hectic" || projects[1].Project != "edgy" {
+		t.Fatalf("projects = %+v, want hectic then edgy", projects)
+	}
+	if got := projects[1].Rate(); got != 25 {
+		t.Errorf("edgy Rate() = %v, want 25", got)
+	}
+	if got := projects[1].Share(); got != 20 {
+		t.Errorf("edgy Share() = %v, want 20", got)
+	}
+	if total.Turns != 40 || total.Interrupted != 9 || total.Abandoned != 1 || total.Share() != 25 {
+		t.Errorf("total = %+v, want 10 of 40 turns and 25%% of spend", total)
+	}
+}
+
+func TestStatistics_GetCategories(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		TotalCost: 8,
+		ByCategory: map[string]*models.CategoryStats{
+			models.CategoryReply:    {Messages: 10, Cost: 2},
+			models.CategoryToolCall: {Messages: 20, Cost: 6},
+		},
+	}
+
+	categories := New(analysis).GetCategories()
+	if len(categories) != 2 || categories[0].Category != models.CategoryToolCall || categories[1].Category != models.CategoryReply {
+		t.Fatalf("categories = %+v, want tool calls then replies", categories)
+	}
+	if categories[0].Share != 75 {
+		t.Errorf("tool call Share = %v, want 75", categories[0].Share)
+	}
+}
+
+func TestStatistics_GetHourlyCosts(t *testing.T) {
+	monday := time.Date(2025, 6, 9, 9, 30, 0, 0, time.Local)
+	minute := func(t time.Time) int64 { return t.Unix() / 60 }
+	analysis := &models.CostAnalysis{
+		Minutes: map[int64]*models.MinuteActivity{
+			minute(monday):                       {MessageCount: 2, Cost: 1.0, Tokens: 100},
+			minute(monday.Add(10 * time.Minute)): {MessageCount: 1, Cost: 0.5, Tokens: 50},
+			minute(monday.AddDate(0, 0, 1)):      {MessageCount: 1, Cost: 2.0, Tokens: 300},
+			minute(monday.Add(5 * time.Hour)):    {MessageCount: 1, Cost: 0.25, Tokens: 10},
+		},
+	}
+	s := New(analysis)
+
+	all := s.GetHourlyCosts(time.Time{}, time.Time{})
+	if len(all) != 24 {
+		t.Fatalf("got %d hours, want 24", len(all))
+	}
+	if got := all[9]; got.Messages != 4 || got.Cost != 3.5 || got.Tokens != 450 {
+		t.Errorf("09:00 = %+v, want 4 messages, $3.50, 450 tokens", got)
+	}
+	if got := all[14]; got.Messages != 1 || got.Cost != 0.25 {
+		t.Errorf("14:00 = %+v, want 1 message, $0.25", got)
+	}
+
+	// 09:00 was active on Monday and Tuesday
+	if got := all[9]; got.Days != 2 || got.AvgCost() != 1.75 {
+		t.Errorf("09:00 = %d days at %v, want 2 days at 1.75", got.Days, got.AvgCost())
+	}
+
+	// Only Monday
+	day := time.Date(2025, 6, 9, 0, 0, 0, 0, time.Local)
+	monday9 := s.GetHourlyCosts(day, day.AddDate(0, 0, 1))[9]
+	if monday9.Messages != 3 || monday9.Cost != 1.5 || monday9.Tokens != 150 {
+		t.Errorf("Monday 09:00 = %+v, want 3 messages, $1.50, 150 tokens", monday9)
+	}
+}
+
+func TestStatistics_GetWeekdayDistribution(t *testing.T) {
+	minute := func(t time.Time) int64 { return t.Unix() / 60 }
+	monday := time.Date(2025, 6, 9, 9, 0, 0, 0, time.Local)
+	analysis := &models.CostAnalysis{
+		Minutes: map[int64]*models.MinuteActivity{
+			// Two Mondays, one of them twice
+			minute(monday):                  {MessageCount: 1, Cost: 1},
+			minute(monday.Add(time.Hour)):   {MessageCount: 1, Cost: 2},
+			minute(monday.AddDate(0, 0, 7)): {MessageCount: 1, Cost: 3},
+			minute(monday.AddDate(0, 0, 6)): {MessageCount: 4, Cost: 8},
+			minute(monday.AddDate(0, 0, 2)): {MessageCount: 1, Cost: 0.5},
+		},
+	}
+	weekdays := New(analysis).GetWeekdayDistribution()
+	if len(weekdays) != 7 || weekdays[0].Weekday != time.Monday || weekdays[6].Weekday != time.Sunday {
+		t.Fatalf("weekdays = %+v, want Monday through Sunday", weekdays)
+	}
+	if got := weekdays[0]; got.Messages != 3 || got.Days != 2 || got.AvgCost() != 3 {
+		t.Errorf("Monday = %+v, want 3 messages over 2 days averaging 3", got)
+	}
+	if got := weekdays[6]; got.Days != 1 || got.AvgCost() != 8 {
+		t.Errorf("Sunday = %+v, want 1 day averaging 8", got)
+	}
+	if got := weekdays[1]; got.Days != 0 || got.AvgCost() != 0 {
+		t.Errorf("Tuesday = %+v, want no activity", got)
+	}
+}
+
+func TestStatistics_GetWorkSplit(t *testing.T) {
+	at := func(hour int) int64 { return time.Date(2025, 6, 13, hour, 0, 0, 0, time.Local).Unix() / 60 }
+	analysis := &models.CostAnalysis{
+		Minutes: map[int64]*models.MinuteActivity{
+			at(10): {MessageCount: 3, Cost: 1.5},
+			at(14): {MessageCount: 1, Cost: 0.5},
+			at(21): {MessageCount: 2, Cost: 1.0},
+		},
+	}
+	business := func(t time.Time) bool { return t.Hour() >= 9 && t.Hour() < 18 }
+
+	got := New(analysis).GetWorkSplit(business)
+	want := WorkSplit{WorkMessages: 4, WorkCost: 2.0, OffMessages: 2, OffCost: 1.0}
+	if got != want {
+		t.Errorf("split = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarizeBlocks(t *testing.T) {
+	base := time.Date(2025, 6, 13, 9, 0, 0, 0, time.UTC)
+	var blocks []Block
+	for i, cost := range []float64{4, 1, 9, 2, 3} {
+		blocks = append(blocks, Block{Start: base.Add(time.Duration(i) * 5 * time.Hour), Cost: cost, InputOutputTokens: int(cost) * 1000})
+	}
+
+	summary := SummarizeBlocks(blocks, 2)
+	if summary.Blocks != 5 || !summary.Since.Equal(base) {
+		t.Errorf("Blocks = %d since %v, want 5 since %v", summary.Blocks, summary.Since, base)
+	}
+	if len(summary.Peaks) != 2 || summary.Peaks[0].Cost != 9 || summary.Peaks[1].Cost != 4 {
+		t.Errorf("Peaks = %+v, want the $9 and $4 blocks", summary.Peaks)
+	}
+	if summary.MedianCost != 3 || summary.MedianTokens != 3000 {
+		t.Errorf("median = $%.2f, %d tokens; want $3.00, 3000", summary.MedianCost, summary.MedianTokens)
+	}
+
+	if empty := SummarizeBlocks(nil, 2); empty.Blocks != 0 || empty.Peaks != nil {
+		t.Errorf("no blocks = %+v, want zero", empty)
+	}
+}
+
+func TestStatistics_GetBlocks(t *testing.T) {
+	base := time.Date(2025, 6, 13, 9, 0, 0, 0, time.UTC)
+	minute := func(offset time.Duration) int64 { return base.Add(offset).Unix() / 60 }
+
+	analysis := &models.CostAnalysis{
+		Minutes: map[int64]*models.MinuteActivity{
+			minute(17 * time.Minute):              {MessageCount: 2, Cost: 1.0, Tokens: 100},
+			minute(4*time.Hour + 59*time.Minute):  {MessageCount: 1, Cost: 0.5, Tokens: 50},
+			minute(5*time.Hour + 30*time.Minute):  {MessageCount: 3, Cost: 2.0, Tokens: 300},
+			minute(12*time.Hour + 45*time.Minute): {MessageCount: 1, Cost: 0.25, Tokens: 10},
+		},
+	}
+	s := New(analysis)
+
+	blocks := s.GetBlocks(5*time.Hour, base.Add(13*time.Hour))
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want 3: %+v", len(blocks), blocks)
+	}
+
+	first := blocks[0]
+	if !first.Start.Equal(base) || first.Messages != 3 || first.Cost != 1.5 {
+		t.Errorf("first block = %+v, want start 09:00 with 3 messages and $1.50", first)
+	}
+	if second := blocks[1]; !second.Start.Equal(base.Add(5*time.Hour)) || second.Tokens != 300 {
+		t.Errorf("second block = %+v, want start 14:00 with 300 tokens", second)
+	}
+	if third := blocks[2]; !third.Start.Equal(base.Add(12*time.Hour)) || !third.Active {
+		t.Errorf("third block = %+v, want active block starting 21:00", third)
+	}
+	if first.Active || blocks[1].Active {
+		t.Error("only the block containing now should be active")
+	}
+}
+
+func TestStatistics_GetLimitHits(t *testing.T) {
+	base := time.Date(2025, 6, 13, 9, 0, 0, 0, time.UTC)
+	minute := func(offset time.Duration) int64 { return base.Add(offset).Unix() / 60 }
+
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {},
+			"2025-06-14": {},
+		},
+		Minutes: map[int64]*models.MinuteActivity{
+			minute(10 * time.Minute): {MessageCount: 5},
+			minute(6 * time.Hour):    {MessageCount: 5},
+			minute(25 * time.Hour):   {MessageCount: 5},
+		},
+		LimitEvents: []models.LimitEvent{
+			{Time: base.Add(2 * time.Hour), Kind: models.RateLimit},
+			{Time: base.Add(4 * time.Hour), Kind: models.UsageLimit},
+			{Time: base.Add(4*time.Hour + time.Minute), Kind: models.UsageLimit},
+			{Time: base.Add(26 * time.Hour), Kind: models.UsageLimit},
+		},
+	}
+	s := New(analysis)
+
+	hits := s.GetLimitHits(5*time.Hour, base.Add(48*time.Hour))
+	if hits.UsageLimits != 3 || hits.RateLimits != 1 {
+		t.Errorf("UsageLimits = %d, RateLimits = %d, want 3 and 1", hits.UsageLimits, hits.RateLimits)
+	}
+	if hits.Blocks != 3 || hits.BlocksHit != 2 || hits.ActiveDays != 2 {
+		t.Errorf("Blocks = %d, BlocksHit = %d, ActiveDays = %d, want 3, 2, 2", hits.Blocks, hits.BlocksHit, hits.ActiveDays)
+	}
+	if len(hits.Days) != 2 || hits.Days[0].Date != "2025-06-13" || hits.Days[0].UsageLimits != 2 || hits.Days[0].RateLimits != 1 {
+		t.Errorf("Days = %+v, want 2 usage and 1 rate limit on 2025-06-13 first", hits.Days)
+	}
+
+	blocks := s.GetBlocks(5*time.Hour, base.Add(48*time.Hour))
+	if blocks[0].UsageLimits != 2 || blocks[0].RateLimits != 1 || blocks[1].UsageLimits != 0 {
+		t.Errorf("blocks = %+v, want the first block to hold the first day's events", blocks)
+	}
+}
+
+func TestStatistics_GetOpusDowngrades(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-14": {
+				OpusMinutes:       map[int64]bool{1: true, 2: true, 3: true},
+				DowngradedMinutes: map[int64]bool{4: true},
+				DowngradedCost:    0.5,
+				ModelSwitches:     1,
+			},
+			"2025-06-13": {OpusMinutes: map[int64]bool{1: true}},
+			"2025-06-12": {MessageCount: 3},
+		},
+	}
+
+	days := New(analysis).GetOpusDowngrades()
+	if len(days) != 2 || days[0].Date != "2025-06-13" {
+		t.Fatalf("days = %+v, want the two days with Opus use, oldest first", days)
+	}
+	if day := days[1]; day.OpusMinutes != 3 || day.DowngradedMinutes != 1 || day.Switches != 1 || day.Share() != 25 {
+		t.Errorf("2025-06-14 = %+v (share %.1f), want 3 Opus and 1 downgraded minute, 25%%", day, day.Share())
+	}
+	if days[0].Share() != 0 {
+		t.Errorf("2025-06-13 share = %.1f, want 0", days[0].Share())
+	}
+}
+
+func TestSessionSummary_Matches(t *testing.T) {
+	session := SessionSummary{Title: "Fix flaky uploader tests", Project: "photostructure/server"}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"uploader", true},
+		{"FLAKY", true},
+		{"photostructure", true},
+		{"uploader server", true}, // Words may match either field
+		{"uploader migration", false},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if got := session.Matches(tt.query); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStatistics_GetSessions(t *testing.T) {
+	now := time.Now()
+	analysis := &models.CostAnalysis{
+		Sessions: map[string]*models.SessionStats{
+			"old":    {StartTime: now.Add(-2 * time.Hour), Cost: 5.0},
+			"recent": {StartTime: now.Add(-time.Hour), Cost: 1.0},
+			"newest": {StartTime: now, Cost: 2.0},
+		},
+	}
+	s := New(analysis)
+
+	byTime := s.GetSessions("time", 0)
+	if byTime[0].SessionID != "newest" || byTime[2].SessionID != "old" {
+		t.Errorf("by time = %s, %s, %s; want newest first", byTime[0].SessionID, byTime[1].SessionID, byTime[2].SessionID)
+	}
+
+	byCost := s.GetSessions("cost", 2)
+	if len(byCost) != 2 || byCost[0].SessionID != "old" || byCost[1].SessionID != "newest" {
+		t.Errorf("by cost = %+v, want old then newest", byCost)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &models.CostAnalysis{
+		TotalCost: 10,
+		Projects: map[string]*models.ProjectStats{
+			"api":    {Cost: 6},
+			"legacy": {Cost: 4},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4":   {Cost: 8},
+			"claude-sonnet-4": {Cost: 2},
+		},
+		Sessions: map[string]*models.SessionStats{"s1": {Cost: 10}},
+	}
+	new := &models.CostAnalysis{
+		TotalCost: 20,
+		Projects: map[string]*models.ProjectStats{
+			"api": {Cost: 5},
+			"web": {Cost: 15},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4":   {Cost: 10},
+			"claude-sonnet-4": {Cost: 10},
+		},
+		Sessions: map[string]*models.SessionStats{"s2": {Cost: 12}, "s3": {Cost: 8}},
+	}
+
+	diff := Diff(old, new)
+	if diff.Cost.Delta() != 10 || diff.Cost.Percent() != 100 {
+		t.Errorf("cost = %+v, want +10, +100%%", diff.Cost)
+	}
+	if diff.Sessions != (Change{1, 2}) || diff.CostPerSession != (Change{10, 10}) {
+		t.Errorf("sessions = %+v at %+v each, want 1 then 2 at $10", diff.Sessions, diff.CostPerSession)
+	}
+
+	want := []NamedChange{
+		{Name: "web", Cost: Change{0, 15}, Added: true},
+		{Name: "legacy", Cost: Change{4, 0}, Removed: true},
+		{Name: "api", Cost: Change{6, 5}},
+	}
+	if !reflect.DeepEqual(diff.Projects, want) {
+		t.Errorf("projects = %+v, want %+v", diff.Projects, want)
+	}
+	if !math.IsInf(diff.Projects[0].Cost.Percent(), 1) {
+		t.Errorf("new project's change = %v%%, want +Inf", diff.Projects[0].Cost.Percent())
+	}
+
+	// Sonnet went from 20% to 50% of the cost, and Opus the other way
+	if len(diff.Models) != 2 || diff.Models[0].Name != "claude-opus-4" || diff.Models[0].Share != (Change{80, 50}) ||
+		diff.Models[1].Share != (Change{20, 50}) {
+		t.Errorf("models = %+v, want opus 80%% to 50%% then sonnet 20%% to 50%%", diff.Models)
+	}
+}
+
+func TestStatistics_GetCommitCosts(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	analysis := &models.CostAnalysis{
+		Projects: map[string]*models.ProjectStats{
+			"api": {Cost: 10},
+			"web": {Cost: 30},
+			"ops": {Cost: 5},
+		},
+		Sessions: map[string]*models.SessionStats{
+			"s1": {Project: "api", ProjectPath: "/srv/api"},
+			"s2": {Project: "api", ProjectPath: "/srv/api"},
+			"s3": {Project: "api", ProjectPath: "/srv/api-v2"},
+			"s4": {Project: "web", ProjectPath: "src/web"},
+		},
+	}
+	s := New(analysis)
+
+	dirs := s.GetProjectDirs()
+	if want := []string{"/srv/api", "/srv/api-v2"}; !reflect.DeepEqual(dirs["api"], want) {
+		t.Errorf("api dirs = %v, want %v", dirs["api"], want)
+	}
+	if want := []string{filepath.Join(home, "src/web")}; !reflect.DeepEqual(dirs["web"], want) {
+		t.Errorf("web dirs = %v, want %v under the home directory", dirs["web"], want)
+	}
+
+	costs := s.GetCommitCosts(map[string]int{"api": 4, "web": 0, "gone": 3})
+	if len(costs) != 2 || costs[0].Project != "web" || costs[1].Project != "api" {
+		t.Fatalf("costs = %+v, want web then api", costs)
+	}
+	if costs[1].CostPerCommit() != 2.5 || costs[1].CommitsPerDollar() != 0.4 {
+		t.Errorf("api = %v per commit, %v per dollar; want 2.5 and 0.4", costs[1].CostPerCommit(), costs[1].CommitsPerDollar())
+	}
+	if costs[0].CostPerCommit() != 0 {
+		t.Errorf("web without commits = %v per commit, want 0", costs[0].CostPerCommit())
+	}
+}
+
+func TestStatistics_GetLineCosts(t *testing.T) {
+	s := New(&models.CostAnalysis{Projects: map[string]*models.ProjectStats{
+		"api":  {Cost: 6, Lines: models.LineChanges{Added: 250, Removed: 50}},
+		"web":  {Cost: 9, Lines: models.LineChanges{Added: 10}},
+		"docs": {Cost: 20},
+	}})
+
+	costs := s.GetLineCosts()
+	if len(costs) != 2 || costs[0].Project != "web" || costs[1].Project != "api" {
+		t.Fatalf("costs = %+v, want web then api without docs", costs)
+	}
+	if got := costs[1].CostPer100Lines(); got != 2 {
+		t.Errorf("api CostPer100Lines = %v, want 2", got)
+	}
+}
+
+func TestStatistics_GetTimeSaved(t *testing.T) {
+	s := New(&models.CostAnalysis{
+		TotalCost: 12,
+		ToolUse:   &models.ToolUseStats{Accepted: 30, Rejected: 5},
+		Sessions: map[string]*models.SessionStats{
+			"s1": {MessageCount: 40},
+			"s2": {MessageCount: 20},
+		},
+		Lines: models.LineChanges{Added: 500, Removed: 100},
+	})
+
+	// 30×2 + 60×0.5 + 6×5 = 120 minutes
+	saved := s.GetTimeSaved(TimeSavedRates{PerToolAction: 2, PerMessage: 0.5, Per100Lines: 5})
+	if saved.Hours != 2 || saved.CostPerHour() != 6 {
+		t.Errorf("saved %v hours at %v per hour, want 2 at 6", saved.Hours, saved.CostPerHour())
+	}
+	if saved.ToolActions != 30 || saved.Messages != 60 || saved.Lines != 600 {
+		t.Errorf("counted %+v, want 30 tool calls, 60 messages, 600 lines", saved)
+	}
+	if saved := s.GetTimeSaved(TimeSavedRates{}); saved.Hours != 0 || saved.CostPerHour() != 0 {
+		t.Errorf("without rates = %+v, want no time saved", saved)
+	}
+}
+
+func TestStatistics_GetConcurrency(t *testing.T) {
+	start := time.Date(2025, 6, 9, 9, 0, 0, 0, time.Local)
+	base := start.Unix() / 60
+	active := func(offsets ...int64) map[int64]bool {
+		minutes := make(map[int64]bool)
+		for _, offset := range offsets {
+			minutes[base+offset] = true
+		}
+		return minutes
+	}
+	s := New(&models.CostAnalysis{Sessions: map[string]*models.SessionStats{
+		// a runs from 0 through 3; b at 2, then again at 20 after a long break
+		"a": {ActiveMinutes: active(0, 3)},
+		"b": {ActiveMinutes: active(2, 20)},
+		"c": {ActiveMinutes: active(24 * 60)},
+	}})
+
+	c := s.GetConcurrency()
+	if c.BusyMinutes != 6 || c.ParallelMinutes != 1 {
+		t.Errorf("busy %d, parallel %d minutes; want 6 and 1", c.BusyMinutes, c.ParallelMinutes)
+	}
+	if c.AvgSessions != 7.0/6 || c.MaxSessions != 2 || !c.MaxAt.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("avg %v, max %d at %v; want 7/6 and 2 at 09:02", c.AvgSessions, c.MaxSessions, c.MaxAt)
+	}
+	if !reflect.DeepEqual(c.SessionsPerDay, []int{1, 2}) || c.MedianPerDay() != 1.5 || c.MaxDay != "2025-06-09" {
+		t.Errorf("per day %v (median %v, max on %s), want [1 2], 1.5, 2025-06-09", c.SessionsPerDay, c.MedianPerDay(), c.MaxDay)
+	}
+}
+
+func TestStatistics_GetObservedHours(t *testing.T) {
+	at := func(day, hour, minute int) int64 {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, time.Local).Unix() / 60
+	}
+	analysis := &models.CostAnalysis{
+		ActiveMinutes: map[int64]bool{
+			at(9, 9, 0): true, at(9, 12, 0): true, at(9, 17, 30): true,
+			at(10, 8, 0): true, at(10, 18, 0): true,
+			// Past midnight, so it counts toward the 10th with days starting
+			// at 04:00
+			at(11, 1, 0):  true,
+			at(11, 10, 0): true, at(11, 16, 0): true,
+		},
+		DayBoundary: models.DayBoundary{StartHour: 4},
+	}
+
+	hours := New(analysis).GetObservedHours()
+	if hours.Days != 3 {
+		t.Fatalf("Days = %d, want 3", hours.Days)
+	}
+	if hours.First.Median != 9*time.Hour || hours.First.P10 != 8*time.Hour+12*time.Minute {
+		t.Errorf("First = %+v, want median 9:00 and p10 8:12", hours.First)
+	}
+	// The last activities are 16:00, 17:30, and 25:00
+	if hours.Last.Median != 17*time.Hour+30*time.Minute || hours.Last.P90 != 23*time.Hour+30*time.Minute {
+		t.Errorf("Last = %+v, want median 17:30 and p90 23:30", hours.Last)
+	}
+	// The spans are 6h, 8h30m, and 17h
+	if hours.Span.Median != 8*time.Hour+30*time.Minute || hours.Span.P90 != 15*time.Hour+18*time.Minute {
+		t.Errorf("Span = %+v, want median 8h30m and p90 15h18m", hours.Span)
+	}
+}
+
+func TestStatistics_GetStreaks(t *testing.T) {
+	daily := make(map[string]*models.DailyActivity)
+	for _, date := range []string{"2025-08-30", "2025-08-31", "2025-09-01", "2025-09-03", "2025-10-30", "2025-10-31"} {
+		daily[date] = &models.DailyActivity{MessageCount: 1}
+	}
+	// Days without messages don't count
+	daily["2025-09-02"] = &models.DailyActivity{}
+	s := New(&models.CostAnalysis{DailyActivity: daily})
+
+	streaks := s.GetStreaks("2025-11-01")
+	if streaks.Current != 2 || streaks.Longest != 3 || streaks.LongestStart != "2025-08-30" || streaks.LongestEnd != "2025-09-01" {
+		t.Errorf("streaks = %+v, want current 2, longest 3 from 2025-08-30", streaks)
+	}
+	if streaks.Days != 64 || streaks.DaysPerWeek() != 6.0/64*7 {
+		t.Errorf("%d days, %v per week; want 64 days, 6 active", streaks.Days, streaks.DaysPerWeek())
+	}
+	if want := []string{"2025-08", "2025-09", "2025-10"}; !reflect.DeepEqual(streaks.Months, want) || streaks.MonthsSpanned != 4 {
+		t.Errorf("months %v of %d, want %v of 4", streaks.Months, streaks.MonthsSpanned, want)
+	}
+
+	if streaks := s.GetStreaks("2025-11-02"); streaks.Current != 0 {
+		t.Errorf("Current = %d two days after the last activity, want 0", streaks.Current)
+	}
+}
@@ -0,0 +1,81 @@
+package calculator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CommitCost is a project's spend beside the git commits made in its
+// directories over the same window
+type CommitCost struct {
+	Project string
+	Cost    float64
+	Commits int
+}
+
+// CostPerCommit is the spend per commit, or zero without commits
+func (c CommitCost) CostPerCommit() float64 {
+	if c.Commits == 0 {
+		return 0
+	}
+	return c.Cost / float64(c.Commits)
+}
+
+// CommitsPerDollar is the commits per dollar spent, or zero without spend
+func (c CommitCost) CommitsPerDollar() float64 {
+	if c.Cost == 0 {
+		return 0
+	}
+	return float64(c.Commits) / c.Cost
+}
+
+// GetProjectDirs returns the directories each project's sessions ran in,
+// sorted. Paths the parser left relative to the home directory are made
+// absolute.
+func (s *Statistics) GetProjectDirs() map[string][]string {
+	home, _ := os.UserHomeDir()
+	seen := make(map[string]map[string]bool)
+	for _, session := range s.analysis.Sessions {
+		dir := session.ProjectPath
+		if dir == "" {
+			continue
+		}
+		if !filepath.IsAbs(dir) && home != "" {
+			dir = filepath.Join(home, dir)
+		}
+		if seen[session.Project] == nil {
+			seen[session.Project] = make(map[string]bool)
+		}
+		seen[session.Project][dir] = true
+	}
+
+	dirs := make(map[string][]string, len(seen))
+	for project, paths := range seen {
+		for path := range paths {
+			dirs[project] = append(dirs[project], path)
+		}
+		sort.Strings(dirs[project])
+	}
+	return dirs
+}
+
+// GetCommitCosts pairs the projects in commits, counted by the caller, with
+// their cost, most expensive first
+func (s *Statistics) GetCommitCosts(commits map[string]int) []CommitCost {
+	costs := make([]CommitCost, 0, len(commits))
+	for name, count := range commits {
+		project, ok := s.analysis.Projects[name]
+		if !ok {
+			continue
+		}
+		costs = append(costs, CommitCost{Project: name, Cost: project.Cost, Commits: count})
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Cost != costs[j].Cost {
+			return costs[i].Cost > costs[j].Cost
+		}
+		return costs[i].Project < costs[j].Project
+	})
+	return costs
+}
@@ -0,0 +1,105 @@
+package calculator
+
+import (
+	"sort"
+	"time"
+)
+
+// sessionIdleMinutes is the longest pause between responses during which a
+// session still counts as running, as while a tool runs or its user reads
+const sessionIdleMinutes = 5
+
+// Concurrency describes how many sessions run at once and how many are
+// used each day
+type Concurrency struct {
+	// BusyMinutes counts the minutes with any session running, and
+	// ParallelMinutes those with two or more
+	BusyMinutes     int
+	ParallelMinutes int
+	// AvgSessions is the sessions running at once, averaged over the busy
+	// minutes
+	AvgSessions float64
+	MaxSessions int
+	MaxAt       time.Time // The first minute MaxSessions were running
+	// SessionsPerDay lists the sessions with activity on each active day,
+	// fewest first
+	SessionsPerDay []int
+	MaxDay         string // The first date with the most sessions
+}
+
+// ParallelShare is the percentage of busy time with two or more sessions
+// running
+func (c Concurrency) ParallelShare() float64 {
+	if c.BusyMinutes == 0 {
+		return 0
+	}
+	return float64(c.ParallelMinutes) / float64(c.BusyMinutes) * 100
+}
+
+// MedianPerDay is the median sessions per active day
+func (c Concurrency) MedianPerDay() float64 {
+	n := len(c.SessionsPerDay)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return float64(c.SessionsPerDay[n/2])
+	}
+	return float64(c.SessionsPerDay[n/2-1]+c.SessionsPerDay[n/2]) / 2
+}
+
+// GetConcurrency counts the sessions running in each minute, bridging pauses
+// of up to a few minutes between a session's responses, and the sessions
+// active on each day
+func (s *Statistics) GetConcurrency() Concurrency {
+	running := make(map[int64]int)
+	perDay := make(map[string]int)
+	for _, session := range s.analysis.Sessions {
+		minutes := make([]int64, 0, len(session.ActiveMinutes))
+		for minute := range session.ActiveMinutes {
+			minutes = append(minutes, minute)
+		}
+		sort.Slice(minutes, func(i, j int) bool { return minutes[i] < minutes[j] })
+
+		days := make(map[string]bool)
+		for i, minute := range minutes {
+			running[minute]++
+			if i > 0 && minute-minutes[i-1] <= sessionIdleMinutes {
+				for m := minutes[i-1] + 1; m < minute; m++ {
+					running[m]++
+				}
+			}
+			days[s.analysis.DayBoundary.Date(time.Unix(minute*60, 0))] = true
+		}
+		for day := range days {
+			perDay[day]++
+		}
+	}
+
+	var c Concurrency
+	total := 0
+	for minute, count := range running {
+		c.BusyMinutes++
+		total += count
+		if count >= 2 {
+			c.ParallelMinutes++
+		}
+		at := time.Unix(minute*60, 0)
+		if count > c.MaxSessions || count == c.MaxSessions && at.Before(c.MaxAt) {
+			c.MaxSessions, c.MaxAt = count, at
+		}
+	}
+	if c.BusyMinutes > 0 {
+		c.AvgSessions = float64(total) / float64(c.BusyMinutes)
+	}
+
+	maxPerDay := 0
+	for day, count := range perDay {
+		c.SessionsPerDay = append(c.SessionsPerDay, count)
+		if count > maxPerDay || count == maxPerDay && day < c.MaxDay {
+			maxPerDay, c.MaxDay = count, day
+		}
+	}
+	sort.Ints(c.SessionsPerDay)
+	return c
+}
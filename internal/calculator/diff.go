@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// AnalysisDiff is what changed between two analyses, as of the old one and
+// the new one
+type AnalysisDiff struct {
+	Cost     Change
+	Sessions Change
+	Tokens   Change // Input, output, and cache tokens
+	// CostPerSession and CacheHitRate, a percentage, are averages over each
+	// analysis
+	CostPerSession Change
+	CacheHitRate   Change
+	// Projects are those in either analysis, by cost change, largest either
+	// way first
+	Projects []NamedChange
+	// Models are those in either analysis, by change in their share of the
+	// cost, largest either way first
+	Models []ModelChange
+}
+
+// Change is a value in the old and new analyses
+type Change struct {
+	Old, New float64
+}
+
+// Delta is New less Old
+func (c Change) Delta() float64 {
+	return c.New - c.Old
+}
+
+// Percent is the delta as a percentage of Old; it's infinite when Old is
+// zero and New isn't
+func (c Change) Percent() float64 {
+	if c.Old == 0 {
+		if c.New == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return c.Delta() / c.Old * 100
+}
+
+// NamedChange is the cost of a project in each analysis. Added and Removed
+// mark projects only in the new or only in the old.
+type NamedChange struct {
+	Name    string
+	Cost    Change
+	Added   bool
+	Removed bool
+}
+
+// ModelChange is a model's cost, and its percentage share of the total, in
+// each analysis
+type ModelChange struct {
+	NamedChange
+	Share Change
+}
+
+// Diff compares two analyses, such as last week's saved one and this week's
+func Diff(old, new *models.CostAnalysis) AnalysisDiff {
+	oldStats, newStats := New(old), New(new)
+	diff := AnalysisDiff{
+		Cost:           Change{old.TotalCost, new.TotalCost},
+		Sessions:       Change{float64(len(old.Sessions)), float64(len(new.Sessions))},
+		Tokens:         Change{float64(totalTokens(old)), float64(totalTokens(new))},
+		CostPerSession: Change{oldStats.GetAverageCostPerSession(), newStats.GetAverageCostPerSession()},
+		CacheHitRate:   Change{oldStats.GetCacheHitRate(), newStats.GetCacheHitRate()},
+	}
+
+	oldProjects, newProjects := make(map[string]float64), make(map[string]float64)
+	for name, project := range old.Projects {
+		oldProjects[name] = project.Cost
+	}
+	for name, project := range new.Projects {
+		newProjects[name] = project.Cost
+	}
+	diff.Projects = namedChanges(oldProjects, newProjects)
+	sortChanges(diff.Projects, func(c NamedChange) float64 { return c.Cost.Delta() })
+
+	oldModels, newModels := make(map[string]float64), make(map[string]float64)
+	for name, model := range old.Models {
+		oldModels[name] = model.Cost
+	}
+	for name, model := range new.Models {
+		newModels[name] = model.Cost
+	}
+	for _, change := range namedChanges(oldModels, newModels) {
+		diff.Models = append(diff.Models, ModelChange{
+			NamedChange: change,
+			Share:       Change{share(change.Cost.Old, old.TotalCost), share(change.Cost.New, new.TotalCost)},
+		})
+	}
+	sort.SliceStable(diff.Models, func(i, j int) bool {
+		a, b := math.Abs(diff.Models[i].Share.Delta()), math.Abs(diff.Models[j].Share.Delta())
+		if a != b {
+			return a > b
+		}
+		return diff.Models[i].Name < diff.Models[j].Name
+	})
+	return diff
+}
+
+// namedChanges pairs the costs of names in either map
+func namedChanges(old, new map[string]float64) []NamedChange {
+	var changes []NamedChange
+	for name, cost := range old {
+		_, kept := new[name]
+		changes = append(changes, NamedChange{Name: name, Cost: Change{cost, new[name]}, Removed: !kept})
+	}
+	for name, cost := range new {
+		if _, ok := old[name]; !ok {
+			changes = append(changes, NamedChange{Name: name, Cost: Change{0, cost}, Added: true})
+		}
+	}
+	return changes
+}
+
+// sortChanges orders changes by the size of by, largest first, then name
+func sortChanges(changes []NamedChange, by func(NamedChange) float64) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := math.Abs(by(changes[i])), math.Abs(by(changes[j]))
+		if a != b {
+			return a > b
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
+
+// share is part as a percentage of total
+func share(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
+
+// totalTokens sums an analysis's input, output, and cache tokens
+func totalTokens(a *models.CostAnalysis) int {
+	return a.TotalInputTokens + a.TotalOutputTokens + a.TotalCacheRead + a.TotalCacheWrite
+}
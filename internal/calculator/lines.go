@@ -0,0 +1,43 @@
+package calculator
+
+import (
+	"sort"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// LineCost is a project's spend beside the lines its accepted file edits
+// changed
+type LineCost struct {
+	Project string
+	Cost    float64
+	Lines   models.LineChanges
+}
+
+// CostPer100Lines is the spend per hundred changed lines, or zero without
+// changes
+func (c LineCost) CostPer100Lines() float64 {
+	if c.Lines.Total() == 0 {
+		return 0
+	}
+	return c.Cost / float64(c.Lines.Total()) * 100
+}
+
+// GetLineCosts returns the projects whose file edits changed any lines,
+// most expensive first
+func (s *Statistics) GetLineCosts() []LineCost {
+	var costs []LineCost
+	for name, project := range s.analysis.Projects {
+		if project.Lines.Total() == 0 {
+			continue
+		}
+		costs = append(costs, LineCost{Project: name, Cost: project.Cost, Lines: project.Lines})
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Cost != costs[j].Cost {
+			return costs[i].Cost > costs[j].Cost
+		}
+		return costs[i].Project < costs[j].Project
+	})
+	return costs
+}
@@ -0,0 +1,88 @@
+package calculator
+
+import (
+	"sort"
+	"time"
+)
+
+// Streaks are runs of consecutive active days and how regularly Claude is
+// used
+type Streaks struct {
+	// Current counts the consecutive active days through today, or through
+	// yesterday when today has no activity yet
+	Current int
+	// Longest is the longest run, from LongestStart to LongestEnd
+	Longest                  int
+	LongestStart, LongestEnd string
+	ActiveDays               int
+	// Days counts the days from the first active day through today
+	Days int
+	// Months lists the months with activity as YYYY-MM, and MonthsSpanned
+	// counts the months from the first through today's
+	Months        []string
+	MonthsSpanned int
+}
+
+// DaysPerWeek is the average active days per week since the first active
+// day
+func (s Streaks) DaysPerWeek() float64 {
+	if s.Days == 0 {
+		return 0
+	}
+	return float64(s.ActiveDays) / float64(max(s.Days, 7)) * 7
+}
+
+// GetStreaks finds runs of active days in the daily totals. today is the
+// current day as YYYY-MM-DD.
+func (s *Statistics) GetStreaks(today string) Streaks {
+	dates := make([]string, 0, len(s.analysis.DailyActivity))
+	for date, activity := range s.analysis.DailyActivity {
+		if activity.MessageCount > 0 {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	var streaks Streaks
+	if len(dates) == 0 {
+		return streaks
+	}
+	streaks.ActiveDays = len(dates)
+
+	// Dates are parsed as UTC so that adding days isn't thrown off by
+	// clock changes
+	parse := func(date string) time.Time {
+		t, _ := time.Parse("2006-01-02", date)
+		return t
+	}
+	run, start := 0, ""
+	for i, date := range dates {
+		if i > 0 && parse(dates[i-1]).AddDate(0, 0, 1).Format("2006-01-02") == date {
+			run++
+		} else {
+			run, start = 1, date
+		}
+		if run > streaks.Longest {
+			streaks.Longest, streaks.LongestStart, streaks.LongestEnd = run, start, date
+		}
+	}
+
+	last := dates[len(dates)-1]
+	yesterday := parse(today).AddDate(0, 0, -1).Format("2006-01-02")
+	if last == today || last == yesterday {
+		streaks.Current = run
+	}
+
+	first, end := parse(dates[0]), parse(today)
+	if end.Before(parse(last)) {
+		end = parse(last)
+	}
+	streaks.Days = int(end.Sub(first).Hours()/24) + 1
+	streaks.MonthsSpanned = (end.Year()-first.Year())*12 + int(end.Month()-first.Month()) + 1
+	for _, date := range dates {
+		if month := date[:7]; len(streaks.Months) == 0 || streaks.Months[len(streaks.Months)-1] != month {
+			streaks.Months = append(streaks.Months, month)
+		}
+	}
+	return streaks
+}
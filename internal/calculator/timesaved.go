@@ -0,0 +1,48 @@
+package calculator
+
+// TimeSavedRates are the minutes of manual work assumed for each accepted
+// tool call, assistant message, and hundred lines changed by accepted edits
+type TimeSavedRates struct {
+	PerToolAction float64
+	PerMessage    float64
+	Per100Lines   float64
+}
+
+// IsZero reports whether no rate is set
+func (r TimeSavedRates) IsZero() bool {
+	return r == TimeSavedRates{}
+}
+
+// TimeSaved is the estimate of the hours saved over an analysis, and what
+// it was counted from
+type TimeSaved struct {
+	Hours       float64
+	Cost        float64
+	ToolActions int
+	Messages    int
+	Lines       int
+}
+
+// CostPerHour is the spend per hour saved, or zero without any time saved
+func (t TimeSaved) CostPerHour() float64 {
+	if t.Hours == 0 {
+		return 0
+	}
+	return t.Cost / t.Hours
+}
+
+// GetTimeSaved estimates the hours saved at rates
+func (s *Statistics) GetTimeSaved(rates TimeSavedRates) TimeSaved {
+	saved := TimeSaved{Cost: s.analysis.TotalCost, Lines: s.analysis.Lines.Total()}
+	if s.analysis.ToolUse != nil {
+		saved.ToolActions = s.analysis.ToolUse.Accepted
+	}
+	for _, session := range s.analysis.Sessions {
+		saved.Messages += session.MessageCount
+	}
+	minutes := float64(saved.ToolActions)*rates.PerToolAction +
+		float64(saved.Messages)*rates.PerMessage +
+		float64(saved.Lines)/100*rates.Per100Lines
+	saved.Hours = minutes / 60
+	return saved
+}
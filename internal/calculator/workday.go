@@ -0,0 +1,71 @@
+package calculator
+
+import (
+	"sort"
+	"time"
+)
+
+// ClockPercentiles are the 10th, 50th, and 90th percentiles of a time of
+// day, as the time since midnight of the day it counts toward. Times past
+// the next midnight, in days that start later than midnight, exceed 24
+// hours.
+type ClockPercentiles struct {
+	P10, Median, P90 time.Duration
+}
+
+// ObservedHours is when Claude was first and last used each active day
+type ObservedHours struct {
+	Days  int
+	First ClockPercentiles
+	Last  ClockPercentiles
+	// Span is the time from the first activity of a day to the last
+	Span ClockPercentiles
+}
+
+// GetObservedHours returns the spread of the first and last activity of each
+// day, divided into days as daily totals are
+func (s *Statistics) GetObservedHours() ObservedHours {
+	type bounds struct{ first, last time.Duration }
+	days := make(map[string]*bounds)
+	for minute := range s.analysis.ActiveMinutes {
+		start := s.analysis.DayBoundary.Start(time.Unix(minute*60, 0))
+		t := time.Unix(minute*60, 0).In(start.Location())
+		// Wall-clock time, so days with a clock change still read true
+		clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if t.Day() != start.Day() {
+			clock += 24 * time.Hour
+		}
+
+		day := start.Format("2006-01-02")
+		if b, ok := days[day]; !ok {
+			days[day] = &bounds{clock, clock}
+		} else {
+			b.first, b.last = min(b.first, clock), max(b.last, clock)
+		}
+	}
+
+	firsts := make([]float64, 0, len(days))
+	lasts := make([]float64, 0, len(days))
+	spans := make([]float64, 0, len(days))
+	for _, b := range days {
+		firsts = append(firsts, float64(b.first))
+		lasts = append(lasts, float64(b.last))
+		spans = append(spans, float64(b.last-b.first))
+	}
+	return ObservedHours{
+		Days:  len(days),
+		First: clockPercentiles(firsts),
+		Last:  clockPercentiles(lasts),
+		Span:  clockPercentiles(spans),
+	}
+}
+
+// clockPercentiles sorts durations and returns their percentiles, rounded
+// to the minute
+func clockPercentiles(durations []float64) ClockPercentiles {
+	sort.Float64s(durations)
+	at := func(p float64) time.Duration {
+		return time.Duration(Percentile(durations, p)).Round(time.Minute)
+	}
+	return ClockPercentiles{P10: at(10), Median: at(50), P90: at(90)}
+}
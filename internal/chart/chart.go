@@ -0,0 +1,273 @@
+// Package chart renders the analysis as PNG or SVG images for wikis and
+// slide decks.
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	gochart "github.com/wcharczuk/go-chart/v2"
+)
+
+// Types lists the available charts
+var Types = []string{"daily-cost", "model-mix", "projects", "hourly"}
+
+// Formats lists the available image formats
+var Formats = []string{"png", "svg"}
+
+// MaxProjects is how many projects the projects chart shows
+const MaxProjects = 8
+
+// ErrNotEnoughData is returned when the analysis has too little activity to
+// draw the chart
+var ErrNotEnoughData = errors.New("not enough activity to chart")
+
+// Options sizes a chart in pixels
+type Options struct {
+	Width  int
+	Height int
+}
+
+// FormatFor returns the image format for a file name's extension
+func FormatFor(path string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, f := range Formats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("can't tell the image format of %q: use a .png or .svg file", path)
+}
+
+// Render draws the chart type of analysis to w as format
+func Render(w io.Writer, analysis *models.CostAnalysis, chartType, format string, opts Options) error {
+	var renderer gochart.RendererProvider
+	switch format {
+	case "png":
+		renderer = gochart.PNG
+	case "svg":
+		renderer = gochart.SVG
+	default:
+		return fmt.Errorf("unknown image format %q: use one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	stats := calculator.New(analysis)
+	var c renderable
+	var err error
+	switch chartType {
+	case "daily-cost":
+		c, err = dailyCost(stats, opts)
+	case "model-mix":
+		c, err = modelMix(analysis, opts)
+	case "projects":
+		c, err = projects(stats, opts)
+	case "hourly":
+		c, err = hourly(stats, opts)
+	default:
+		return fmt.Errorf("unknown chart type %q: use one of %s", chartType, strings.Join(Types, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	return c.Render(renderer, w)
+}
+
+// renderable is the part of go-chart's chart types Render uses
+type renderable interface {
+	Render(rp gochart.RendererProvider, w io.Writer) error
+}
+
+// dailyCost is cost over time as a filled line, overlaid with its 7- and
+// 30-day rolling averages
+func dailyCost(stats *calculator.Statistics, opts Options) (renderable, error) {
+	var xs []time.Time
+	var ys, avg7, avg30 []float64
+	for _, day := range stats.GetDailyTrend() {
+		date, err := time.ParseInLocation("2006-01-02", day.Date, time.Local)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, date)
+		ys = append(ys, day.Cost)
+		avg7 = append(avg7, day.Avg7)
+		avg30 = append(avg30, day.Avg30)
+	}
+	// go-chart can't scale an axis over a single point
+	if len(xs) < 2 {
+		return nil, fmt.Errorf("%w: daily-cost needs at least two days", ErrNotEnoughData)
+	}
+	// The legend takes the title's place above the plot, so the cost
+	// series' name doubles as the title
+	c := &gochart.Chart{
+		Width:  opts.Width,
+		Height: opts.Height,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: gochart.XAxis{ValueFormatter: gochart.TimeDateValueFormatter, Ticks: dayTicks(xs)},
+		YAxis: gochart.YAxis{ValueFormatter: dollars, Range: fromZero(ys)},
+		Series: []gochart.Series{
+			gochart.TimeSeries{
+				Name:    "Daily cost (API value)",
+				XValues: xs,
+				YValues: ys,
+				Style: gochart.Style{
+					StrokeColor: gochart.ColorBlue,
+					FillColor:   gochart.ColorBlue.WithAlpha(64),
+				},
+			},
+			gochart.TimeSeries{
+				Name:    "7-day average",
+				XValues: xs,
+				YValues: avg7,
+				Style:   gochart.Style{StrokeColor: gochart.ColorOrange, StrokeWidth: 2},
+			},
+			gochart.TimeSeries{
+				Name:    "30-day average",
+				XValues: xs,
+				YValues: avg30,
+				Style:   gochart.Style{StrokeColor: gochart.ColorRed, StrokeWidth: 2, StrokeDashArray: []float64{5, 5}},
+			},
+		},
+	}
+	c.Elements = []gochart.Renderable{gochart.LegendThin(c)}
+	return c, nil
+}
+
+// modelMix is each model's share of cost
+func modelMix(analysis *models.CostAnalysis, opts Options) (renderable, error) {
+	names := make([]string, 0, len(analysis.Models))
+	var total float64
+	for name, stats := range analysis.Models {
+		if stats.Cost > 0 {
+			names = append(names, name)
+			total += stats.Cost
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: model-mix needs priced messages", ErrNotEnoughData)
+	}
+	sort.Slice(names, func(i, j int) bool { return analysis.Models[names[i]].Cost > analysis.Models[names[j]].Cost })
+
+	values := make([]gochart.Value, 0, len(names))
+	for _, name := range names {
+		cost := analysis.Models[name].Cost
+		values = append(values, gochart.Value{
+			Label: fmt.Sprintf("%s %.0f%%", name, cost/total*100),
+			Value: cost,
+		})
+	}
+	// go-chart draws a pie's title over the pie itself, and the labels
+	// already say what it is
+	return &gochart.PieChart{
+		Width:  opts.Width,
+		Height: opts.Height,
+		Values: values,
+	}, nil
+}
+
+// projects is a bar per project, most expensive first
+func projects(stats *calculator.Statistics, opts Options) (renderable, error) {
+	top := stats.GetTopProjects(MaxProjects)
+	if len(top) == 0 {
+		return nil, fmt.Errorf("%w: projects needs at least one project", ErrNotEnoughData)
+	}
+
+	bars := make([]gochart.Value, 0, len(top))
+	costs := make([]float64, 0, len(top))
+	for _, project := range top {
+		bars = append(bars, gochart.Value{Label: projectLabel(project.Name), Value: project.Cost})
+		costs = append(costs, project.Cost)
+	}
+	return &gochart.BarChart{
+		Title:  fmt.Sprintf("Top %d projects by cost", len(bars)),
+		Width:  opts.Width,
+		Height: opts.Height,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 50},
+		},
+		BarWidth:     max(20, opts.Width/(2*len(bars))),
+		UseBaseValue: true,
+		YAxis:        gochart.YAxis{ValueFormatter: dollars, Range: fromZero(costs)},
+		Bars:         bars,
+	}, nil
+}
+
+// hourly is a bar of the average cost per active day for each hour of the
+// day
+func hourly(stats *calculator.Statistics, opts Options) (renderable, error) {
+	bars := make([]gochart.Value, 0, 24)
+	costs := make([]float64, 0, 24)
+	for _, h := range stats.GetHourlyDistribution() {
+		bars = append(bars, gochart.Value{
+			Label: fmt.Sprintf("%02d", h.Hour),
+			Value: h.AvgCost(),
+			Style: gochart.Style{FillColor: gochart.ColorBlue, StrokeColor: gochart.ColorBlue},
+		})
+		costs = append(costs, h.AvgCost())
+	}
+	if slices.Max(costs) == 0 {
+		return nil, fmt.Errorf("%w: hourly needs at least one priced message", ErrNotEnoughData)
+	}
+	return &gochart.BarChart{
+		Title:  "Average cost per active day by hour of day",
+		Width:  opts.Width,
+		Height: opts.Height,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 50},
+		},
+		BarWidth:     max(4, opts.Width/(2*len(bars))),
+		BarSpacing:   max(2, opts.Width/(4*len(bars))),
+		UseBaseValue: true,
+		YAxis:        gochart.YAxis{ValueFormatter: dollars, Range: fromZero(costs)},
+		Bars:         bars,
+	}, nil
+}
+
+// fromZero is a y axis range from zero to a little above the largest value,
+// so bars and areas aren't exaggerated
+func fromZero(values []float64) *gochart.ContinuousRange {
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	if top == 0 {
+		top = 1
+	}
+	return &gochart.ContinuousRange{Min: 0, Max: top * 1.1}
+}
+
+// dayTicks labels the x axis with about ten of the days
+func dayTicks(days []time.Time) []gochart.Tick {
+	step := max(1, (len(days)+9)/10)
+	var ticks []gochart.Tick
+	for i := 0; i < len(days); i += step {
+		value := gochart.TimeToFloat64(days[i])
+		ticks = append(ticks, gochart.Tick{Value: value, Label: days[i].Format("Jan 2")})
+	}
+	return ticks
+}
+
+// projectLabel shortens a project path to its last segment, which fits
+// under a bar
+func projectLabel(name string) string {
+	if base := filepath.Base(name); base != "." && base != "/" {
+		return base
+	}
+	return name
+}
+
+func dollars(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return fmt.Sprintf("$%.2f", f)
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func testAnalysis() *models.CostAnalysis {
+	return &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 4, Cost: 2},
+			"2025-06-13": {MessageCount: 3, Cost: 1.25},
+		},
+		HourlyActivity: map[int]*models.HourlyActivity{
+			9:  {MessageCount: 5, Cost: 2},
+			14: {MessageCount: 2, Cost: 1.25},
+		},
+		Minutes: map[int64]*models.MinuteActivity{
+			time.Date(2025, 6, 12, 9, 0, 0, 0, time.Local).Unix() / 60:  {MessageCount: 5, Cost: 2},
+			time.Date(2025, 6, 13, 14, 0, 0, 0, time.Local).Unix() / 60: {MessageCount: 2, Cost: 1.25},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4-20250514":   {Cost: 2.5},
+			"claude-sonnet-4-20250514": {Cost: 0.75},
+		},
+		Projects: map[string]*models.ProjectStats{
+			"/home/me/src/app": {Cost: 2},
+			"/home/me/src/cli": {Cost: 1.25},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	opts := Options{Width: 800, Height: 400}
+	for _, chartType := range Types {
+		for _, format := range Formats {
+			var buf bytes.Buffer
+			if err := Render(&buf, testAnalysis(), chartType, format, opts); err != nil {
+				t.Errorf("%s as %s: %v", chartType, format, err)
+				continue
+			}
+			png := bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG"))
+			svg := strings.Contains(buf.String(), "<svg")
+			if format == "png" && !png || format == "svg" && !svg {
+				t.Errorf("%s as %s: output isn't %s", chartType, format, format)
+			}
+		}
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, testAnalysis(), "heatmap", "png", Options{Width: 800, Height: 400}); err == nil {
+		t.Error("unknown chart type: expected error")
+	}
+	if err := Render(&buf, testAnalysis(), "daily-cost", "gif", Options{Width: 800, Height: 400}); err == nil {
+		t.Error("unknown format: expected error")
+	}
+
+	empty := &models.CostAnalysis{}
+	for _, chartType := range Types {
+		if err := Render(&buf, empty, chartType, "svg", Options{Width: 800, Height: 400}); !errors.Is(err, ErrNotEnoughData) {
+			t.Errorf("%s without data: err = %v, want ErrNotEnoughData", chartType, err)
+		}
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for path, want := range map[string]string{"cost.png": "png", "out/Cost.SVG": "svg", "cost.jpg": "", "cost": ""} {
+		got, err := FormatFor(path)
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("FormatFor(%q) = %q, %v", path, got, err)
+		}
+	}
+}
@@ -1,25 +1,281 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/chart"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/prompt"
+	"github.com/photostructure/go-claude-costs/internal/rules"
+	"github.com/photostructure/go-claude-costs/internal/state"
+	"github.com/photostructure/go-claude-costs/internal/statsd"
+	"github.com/photostructure/go-claude-costs/internal/store"
+	"github.com/photostructure/go-claude-costs/internal/termimg"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
 )
 
 // Config holds the application configuration
 type Config struct {
-	ClaudeDir string
-	Days      int
-	Verbose   bool
-	ShowCache bool
+	ClaudeDir  string
+	ConfigFile string
+	CPUProfile string
+	MemProfile string
+	TraceFile  string
+	LogLevel   string
+	LogFormat  string
+	// ResponseMin and ResponseMax bound the deltas accepted as response times
+	ResponseMin time.Duration
+	ResponseMax time.Duration
+	// UTCDays and DayStartHour divide daily totals into days; see
+	// DayBoundary
+	UTCDays      bool
+	DayStartHour int
+	// ExcludeSkewed discards response and turn times involving entries
+	// whose timestamps run backwards
+	ExcludeSkewed bool
+	// MaxMemory, such as "2GiB", caps the memory used parsing; see
+	// MemoryLimit. Empty means no limit.
+	MaxMemory string
+	// NoSymlinks skips symlinked project directories and files rather than
+	// following them
+	NoSymlinks bool
+	// RejectionPatterns are extra tool_result substrings that mark a tool use
+	// as rejected by the user
+	RejectionPatterns []string
+	// Now, in RFC 3339, replaces the current time so output is reproducible;
+	// empty means the clock. See Clock.
+	Now string
+	// FromAnalysis is an analysis saved by export --format analysis to
+	// report on instead of parsing the logs
+	FromAnalysis string
+	// DiffFiles are the two saved analyses the diff command compares, which
+	// it reads instead of the logs
+	DiffFiles []string
+	// Profile selects a [profiles.NAME] section of the config file
+	Profile string
+	// Projects limits the analysis to projects matching these patterns
+	Projects []string
+	// Account limits the analysis to one account, by name or ID;
+	// AccountNames maps account IDs to names
+	Account      string
+	AccountNames map[string]string
+	// Aliases roll raw project paths up into named groups
+	Aliases rules.Aliases
+	// TagRules label sessions for --group-by tag
+	TagRules rules.TagRules
+	// PricingRules price matching projects at negotiated rates
+	PricingRules rules.PricingRules
+	// GroupBy selects the cost breakdown: "project" or "tag"
+	GroupBy string
+	// InvoiceFile is an Anthropic cost CSV to reconcile against
+	InvoiceFile string
+	// InvoiceTolerance is the daily divergence percentage that gets flagged
+	InvoiceTolerance float64
+	// Commits has the summary count git commits in each project directory
+	// and show cost per commit
+	Commits bool
+	// DB is the SQLite index kept by the daemon command. Commands read
+	// from it when it exists.
+	DB string
+	// NoCache parses the JSONL files even when the index exists
+	NoCache bool
+	// StateFile persists the high-water mark for report --since-last-run
+	StateFile string
+	// SinceLastRun limits the report to activity after the previous run
+	SinceLastRun bool
+	// SlackWebhook and DiscordWebhook receive the daily summary from report
+	SlackWebhook   string
+	DiscordWebhook string
+	// NotifyDate is the day the webhook summary covers: "today",
+	// "yesterday", or YYYY-MM-DD
+	NotifyDate string
+	// Format selects the output: "text" or "gha" for GitHub Actions
+	Format string
+	// ShowHours adds the table of cost and tokens by hour of day, limited
+	// to HoursFrom through HoursTo when they're set (see HoursRange)
+	ShowHours bool
+	HoursFrom string
+	HoursTo   string
+	// WorkHours, such as "09:00-18:00", on WorkDays, such as "mon-fri",
+	// split the summary's cost between work and off hours
+	WorkHours string
+	WorkDays  string
+	// Graphics is the terminal graphics protocol for the summary's charts:
+	// "auto" to detect it, or one of termimg.Protocols
+	Graphics string
+	// FailOver is the spend cap in dollars; exceeding it is an error
+	FailOver float64
+	// Check suppresses all output; the result is only the exit code
+	Check bool
+	// Strict turns malformed lines and unreadable files into an error
+	Strict bool
+	// BlockLength is the billing block length for blocks and watch
+	BlockLength time.Duration
+	// Plan names the subscription plan watch estimates rate limits against,
+	// and the summary prices with BillingDay; Plans holds plans defined in
+	// the config file
+	Plan  string
+	Plans map[string]limits.Plan
+	// BillingDay is the day of the month the subscription renews. When it's
+	// set, the summary compares the API value with what Plan cost over the
+	// same days.
+	BillingDay int
+	// MinutesPerToolAction, MinutesPerMessage, and MinutesPer100Lines are
+	// the assumptions behind the summary's estimate of time saved: how long
+	// each accepted tool call, assistant message, and hundred changed lines
+	// would have taken by hand. The estimate is shown when any is set.
+	MinutesPerToolAction float64
+	MinutesPerMessage    float64
+	MinutesPer100Lines   float64
+	// Interval is how often watch and serve re-read the Claude directory
+	Interval time.Duration
+	// TopInterval is how often top redraws; TopWindow is how recently a
+	// session must have had activity to be listed
+	TopInterval time.Duration
+	TopWindow   time.Duration
+	// PromptFormat is one of prompt.Formats; the prompt segment turns yellow
+	// at PromptWarn and red at PromptAlert dollars, zero disabling either
+	PromptFormat string
+	PromptWarn   float64
+	PromptAlert  float64
+	// EstimateModel and EstimateTokens are what the estimate command prices
+	EstimateModel  string
+	EstimateTokens models.TokenCounts
+	// Fixture is what gen-fixture writes; its Days comes from Days and its
+	// Size from FixtureSize
+	Fixture     fixture.Options
+	FixtureSize string
+	// Addr is the listen address for serve; GRPCAddr, when set, is where it
+	// also serves gRPC
+	Addr     string
+	GRPCAddr string
+	// Pprof exposes /debug/pprof/ from serve
+	Pprof bool
+	// WSOrigins are other origins allowed to open serve's /ws
+	WSOrigins []string
+	// Token is the bearer token serve requires; BasicAuth is the
+	// "user:password" it accepts instead
+	Token     string
+	BasicAuth string
+	// TLSCert and TLSKey switch serve to HTTPS; TLSClientCA additionally
+	// requires client certificates signed by that CA
+	TLSCert     string
+	TLSKey      string
+	TLSClientCA string
+	// ReadOnly disables serve's endpoints that change state
+	ReadOnly bool
+	// Influx is the InfluxDB URL push writes to, with InfluxOrg,
+	// InfluxBucket, and InfluxToken
+	Influx       string
+	InfluxOrg    string
+	InfluxBucket string
+	InfluxToken  string
+	// Graphite is the host:port of a Graphite plaintext listener for push;
+	// GraphitePrefix starts every metric path
+	Graphite       string
+	GraphitePrefix string
+	// StatsD is the host:port watch sends per-message counters to, named
+	// with StatsDPrefix in StatsDFormat: "dogstatsd" or "statsd"
+	StatsD       string
+	StatsDPrefix string
+	StatsDFormat string
+	// Events is where watch and daemon send each new message: a file or
+	// named pipe, an http:// or https:// URL, or a kafka:// or nats:// target
+	Events string
+	// SessionSort orders the sessions command: "time" or "cost"
+	SessionSort string
+	// SessionSearch keeps the sessions whose title or project contains
+	// all of its words
+	SessionSearch string
+	// Top caps the rows of ranked lists, such as projects, sessions, and
+	// models; zero shows all
+	Top int
+	// ExportFormat is "json", "jsonl", "csv", "pdf", "ics", or "analysis";
+	// ExportTable selects one table
+	ExportFormat string
+	ExportTable  string
+	// ExportTemplate is a text/template file export renders the report
+	// through instead of ExportFormat
+	ExportTemplate string
+	// ExportQuery is a jq expression applied to JSON exports
+	ExportQuery string
+	// ExportSchema prints the JSON Schema instead of exporting
+	ExportSchema bool
+	// Output is the export destination; empty means stdout. chart requires
+	// it and takes the image format from its extension.
+	Output string
+	// ChartType is one of chart.Types; ChartWidth and ChartHeight are in
+	// pixels
+	ChartType   string
+	ChartWidth  int
+	ChartHeight int
+	// CacheAlert is the daily cache hit rate percentage that triggers a warning
+	CacheAlert float64
+	// AnomalyStdDev and AnomalyMedian flag days and sessions costing this
+	// many standard deviations above, or times, the trailing mean and
+	// median; zero disables either
+	AnomalyStdDev float64
+	AnomalyMedian float64
+	Days          int
+	Quiet         bool // Print only the total; can't be combined with Verbose
+	OneLine       bool // Print the shell prompt status line instead of the summary
+	Bench         bool // Report parsing throughput instead of the summary
+	Verbose       bool
+	ShowCache     bool
+	ShowTools     bool
 }
 
 // NewDefault creates a new Config with default values
 func NewDefault() *Config {
 	return &Config{
-		Days:      30,
-		Verbose:   false,
-		ShowCache: false,
-		ClaudeDir: getDefaultClaudeDir(),
+		Days:             30,
+		Verbose:          false,
+		ShowCache:        false,
+		ClaudeDir:        getDefaultClaudeDir(),
+		ConfigFile:       DefaultPath(),
+		StateFile:        state.DefaultPath(),
+		DB:               store.DefaultPath(),
+		LogLevel:         "info",
+		LogFormat:        "text",
+		GroupBy:          "project",
+		InvoiceTolerance: 5,
+		NotifyDate:       "yesterday",
+		Format:           "text",
+		Graphics:         "auto",
+		WorkDays:         "mon-fri",
+		AnomalyStdDev:    3,
+		AnomalyMedian:    5,
+		BlockLength:      5 * time.Hour,
+		Plan:             "pro",
+		Interval:         30 * time.Second,
+		TopInterval:      2 * time.Second,
+		TopWindow:        15 * time.Minute,
+		PromptFormat:     string(prompt.ANSI),
+		PromptWarn:       10,
+		PromptAlert:      25,
+		EstimateModel:    "claude-sonnet-4",
+		Fixture:          fixture.DefaultOptions(),
+		Addr:             "127.0.0.1:8080",
+		SessionSort:      "time",
+		Top:              10,
+		ExportFormat:     "json",
+		ChartType:        "daily-cost",
+		ChartWidth:       1024,
+		ChartHeight:      512,
+		GraphitePrefix:   "claude",
+		StatsDPrefix:     "claude",
+		StatsDFormat:     statsd.DogStatsD,
+		// Matches parser.DefaultResponseMax
+		ResponseMax: 5 * time.Minute,
 	}
 }
 
@@ -29,14 +285,275 @@ func (c *Config) Validate() error {
 		c.Days = 30
 	}
 
-	// Ensure ClaudeDir exists
-	if _, err := os.Stat(c.ClaudeDir); os.IsNotExist(err) {
+	if c.GroupBy != "project" && c.GroupBy != "tag" {
+		return fmt.Errorf("invalid --group-by %q: use project or tag", c.GroupBy)
+	}
+	if err := c.TagRules.Validate(); err != nil {
+		return err
+	}
+	if err := c.PricingRules.Validate(); err != nil {
+		return err
+	}
+
+	if c.Format != "text" && c.Format != "gha" {
+		return fmt.Errorf("invalid --format %q: use text or gha", c.Format)
+	}
+	if c.Graphics != "auto" && !slices.Contains(termimg.Protocols, termimg.Protocol(c.Graphics)) {
+		return fmt.Errorf("invalid --graphics %q: use auto, kitty, iterm, sixel, or none", c.Graphics)
+	}
+	if c.WorkHours != "" {
+		if _, err := rules.ParseWorkHours(c.WorkHours, c.WorkDays); err != nil {
+			return err
+		}
+	}
+	if c.Now != "" {
+		if _, err := time.Parse(time.RFC3339, c.Now); err != nil {
+			return fmt.Errorf("invalid --now %q: use RFC 3339, such as 2025-06-30T12:00:00Z", c.Now)
+		}
+	}
+	if _, _, err := c.HoursRange(c.Clock()); err != nil {
+		return err
+	}
+	if c.FailOver < 0 {
+		return errors.New("--fail-over must not be negative")
+	}
+
+	if c.SessionSort != "time" && c.SessionSort != "cost" {
+		return fmt.Errorf("invalid --sort %q: use time or cost", c.SessionSort)
+	}
+	if !slices.Contains([]string{"json", "jsonl", "csv", "pdf", "ics", "analysis"}, c.ExportFormat) {
+		return fmt.Errorf("invalid export --format %q: use json, jsonl, csv, pdf, ics, or analysis", c.ExportFormat)
+	}
+	if c.ExportQuery != "" && (c.ExportFormat != "json" || c.ExportTemplate != "") {
+		return errors.New("--query only applies to --format json")
+	}
+	if c.ExportTemplate != "" && c.ExportTable != "" {
+		return errors.New("--table can't be used with --template, which is given the whole report")
+	}
+	if c.ExportFormat == "pdf" && c.ExportTable != "" {
+		return errors.New("--table can't be used with --format pdf, which includes the summary and projects")
+	}
+	if c.ExportFormat == "ics" && c.ExportTable != "" {
+		return errors.New("--table can't be used with --format ics, which exports sessions")
+	}
+	if c.ExportFormat == "analysis" && (c.ExportTable != "" || c.ExportTemplate != "") {
+		return errors.New("--table and --template can't be used with --format analysis, which saves the whole analysis")
+	}
+	if !slices.Contains(chart.Types, c.ChartType) {
+		return fmt.Errorf("invalid chart --type %q: use one of %s", c.ChartType, strings.Join(chart.Types, ", "))
+	}
+	if c.ChartWidth <= 0 || c.ChartHeight <= 0 {
+		return errors.New("chart --width and --height must be positive")
+	}
+	if c.BlockLength <= 0 {
+		return errors.New("--block-length must be positive")
+	}
+	if _, err := limits.Lookup(c.Plan, c.Plans); err != nil {
+		return err
+	}
+	if c.BillingDay < 0 || c.BillingDay > 31 {
+		return fmt.Errorf("invalid billing_day %d: use a day of the month, 1 to 31", c.BillingDay)
+	}
+	if c.MinutesPerToolAction < 0 || c.MinutesPerMessage < 0 || c.MinutesPer100Lines < 0 {
+		return errors.New("minutes saved per tool action, message, and 100 lines must not be negative")
+	}
+	if c.Interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	if c.TopInterval <= 0 || c.TopWindow <= 0 {
+		return errors.New("top's --interval and --active must be positive")
+	}
+	if !slices.Contains(prompt.Formats, prompt.Format(c.PromptFormat)) {
+		return fmt.Errorf("invalid prompt-segment --format %q: use ansi, zsh, bash, tmux, or plain", c.PromptFormat)
+	}
+	if c.PromptWarn < 0 || c.PromptAlert < 0 {
+		return errors.New("prompt-segment --warn and --alert must not be negative")
+	}
+	if _, ok := models.ResolveModel(c.EstimateModel); !ok {
+		return fmt.Errorf("unknown estimate --model %q: use a model ID such as claude-opus-4 or claude-sonnet-4-20250514", c.EstimateModel)
+	}
+	if t := c.EstimateTokens; t.Input < 0 || t.Output < 0 || t.CacheWrite < 0 || t.CacheRead < 0 {
+		return errors.New("estimate token counts must not be negative")
+	}
+	if c.Influx != "" && c.InfluxBucket == "" {
+		return errors.New("--influx requires --bucket")
+	}
+	if c.StatsDFormat != statsd.DogStatsD && c.StatsDFormat != statsd.StatsD {
+		return fmt.Errorf("invalid --statsd-format %q: use %s or %s", c.StatsDFormat, statsd.DogStatsD, statsd.StatsD)
+	}
+	if err := c.validateServe(); err != nil {
+		return err
+	}
+	if c.Quiet && c.Verbose {
+		return errors.New("--quiet and --verbose can't be combined")
+	}
+	if c.Top < 0 {
+		return errors.New("--top must not be negative")
+	}
+
+	if c.InvoiceTolerance < 0 {
+		return errors.New("--invoice-tolerance must not be negative")
+	}
+
+	if c.SlackWebhook != "" || c.DiscordWebhook != "" {
+		if c.SinceLastRun {
+			return errors.New("webhook summaries cover whole days and can't be combined with --since-last-run")
+		}
+		if _, err := c.NotifyDay(c.Clock()); err != nil {
+			return err
+		}
+	}
+
+	if c.CacheAlert < 0 || c.CacheAlert > 100 {
+		return errors.New("--cache-alert must be a percentage between 0 and 100")
+	}
+
+	if c.AnomalyStdDev < 0 || c.AnomalyMedian < 0 {
+		return errors.New("anomaly thresholds must not be negative")
+	}
+
+	if c.DayStartHour < 0 || c.DayStartHour > 23 {
+		return errors.New("--day-start-hour must be between 0 and 23")
+	}
+
+	if _, err := c.MemoryLimit(); err != nil {
+		return err
+	}
+	if c.FixtureSize != "" {
+		if _, err := ParseSize(c.FixtureSize); err != nil {
+			return fmt.Errorf("gen-fixture --size: %w", err)
+		}
+	}
+
+	if c.ResponseMin < 0 || c.ResponseMax < 0 {
+		return errors.New("response time bounds must not be negative")
+	}
+	if c.ResponseMax > 0 && c.ResponseMin >= c.ResponseMax {
+		return errors.New("--response-min must be less than --response-max")
+	}
+
+	// Ensure ClaudeDir exists, unless reporting on saved analyses
+	if _, err := os.Stat(c.ClaudeDir); os.IsNotExist(err) && c.FromAnalysis == "" && len(c.DiffFiles) == 0 {
 		return err
 	}
 
 	return nil
 }
 
+// Clock returns the time reports are as of: Now when it's set, otherwise
+// the current time
+func (c *Config) Clock() time.Time {
+	if now, err := time.Parse(time.RFC3339, c.Now); err == nil {
+		return now
+	}
+	return time.Now()
+}
+
+// DayBoundary is how daily totals divide time into days
+func (c *Config) DayBoundary() models.DayBoundary {
+	return models.DayBoundary{UTC: c.UTCDays, StartHour: c.DayStartHour}
+}
+
+// sizeUnits are the suffixes ParseSize accepts, longest first so that "MiB"
+// isn't read as "B"
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// ParseSize reads a positive number of bytes with an optional unit, such as
+// 512MiB or 2GB
+func ParseSize(size string) (int64, error) {
+	number := strings.TrimSpace(size)
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if n, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(n), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 || n*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: use a size such as 512MiB or 2GB", size)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// MemoryLimit returns MaxMemory in bytes, or zero when it's unset
+func (c *Config) MemoryLimit() (int64, error) {
+	if strings.TrimSpace(c.MaxMemory) == "" {
+		return 0, nil
+	}
+	limit, err := ParseSize(c.MaxMemory)
+	if err != nil {
+		return 0, fmt.Errorf("--max-memory: %w", err)
+	}
+	return limit, nil
+}
+
+// NotifyDay resolves NotifyDate relative to now as YYYY-MM-DD
+func (c *Config) NotifyDay(now time.Time) (string, error) {
+	switch c.NotifyDate {
+	case "today":
+		return c.DayBoundary().Date(now), nil
+	case "", "yesterday":
+		return c.DayBoundary().Start(now).AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", c.NotifyDate); err != nil {
+		return "", fmt.Errorf("invalid --notify-date %q: use today, yesterday, or YYYY-MM-DD", c.NotifyDate)
+	}
+	return c.NotifyDate, nil
+}
+
+// HoursRange resolves HoursFrom and HoursTo relative to now as [from, to).
+// Each is YYYY-MM-DD, "today", "yesterday", or a weekday such as "monday"
+// for its most recent occurrence, so --hours-from monday is this week. Both
+// days are included; an unset end is the zero time.
+func (c *Config) HoursRange(now time.Time) (from, to time.Time, err error) {
+	if c.HoursFrom != "" {
+		if from, err = parseDay(c.HoursFrom, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --hours-from: %w", err)
+		}
+	}
+	if c.HoursTo != "" {
+		if to, err = parseDay(c.HoursTo, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --hours-to: %w", err)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--hours-from %s is after --hours-to %s", c.HoursFrom, c.HoursTo)
+	}
+	return from, to, nil
+}
+
+// parseDay returns local midnight of a day given as YYYY-MM-DD, "today",
+// "yesterday", or a weekday name
+func parseDay(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(value, day.String()) {
+			back := (int(today.Weekday()) - int(day) + 7) % 7
+			return today.AddDate(0, 0, -back), nil
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: use YYYY-MM-DD, today, yesterday, or a weekday", value)
+	}
+	return t, nil
+}
+
 // getDefaultClaudeDir returns the default Claude directory path
 func getDefaultClaudeDir() string {
 	home, err := os.UserHomeDir()
@@ -45,3 +562,19 @@ func getDefaultClaudeDir() string {
 	}
 	return filepath.Join(home, ".claude")
 }
+
+// validateServe checks serve's authentication and TLS settings
+func (c *Config) validateServe() error {
+	if c.BasicAuth != "" {
+		if user, _, ok := strings.Cut(c.BasicAuth, ":"); !ok || user == "" {
+			return errors.New("--basic-auth must be user:password")
+		}
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("--tls-cert and --tls-key must be given together")
+	}
+	if c.TLSClientCA != "" && c.TLSCert == "" {
+		return errors.New("--tls-client-ca requires --tls-cert and --tls-key")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_HoursRange(t *testing.T) {
+	// A Thursday
+	now := time.Date(2025, 6, 12, 15, 4, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		from, to         string
+		wantFrom, wantTo time.Time
+	}{
+		{"", "", time.Time{}, time.Time{}},
+		{"monday", "", day(9), time.Time{}},
+		{"Thursday", "", day(12), time.Time{}},
+		{"friday", "", day(6), time.Time{}},
+		{"yesterday", "today", day(11), day(13)},
+		{"2025-06-01", "2025-06-07", day(1), day(8)},
+	}
+	for _, tt := range tests {
+		c := &Config{HoursFrom: tt.from, HoursTo: tt.to}
+		from, to, err := c.HoursRange(now)
+		if err != nil {
+			t.Errorf("%q..%q: %v", tt.from, tt.to, err)
+			continue
+		}
+		if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+			t.Errorf("%q..%q = %v..%v, want %v..%v", tt.from, tt.to, from, to, tt.wantFrom, tt.wantTo)
+		}
+	}
+
+	for _, bad := range []*Config{
+		{HoursFrom: "last week"},
+		{HoursTo: "2025-13-01"},
+		{HoursFrom: "2025-06-10", HoursTo: "2025-06-09"},
+	} {
+		if _, _, err := bad.HoursRange(now); err == nil {
+			t.Errorf("%q..%q: expected error", bad.HoursFrom, bad.HoursTo)
+		}
+	}
+}
+
+func TestConfig_MemoryLimit(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"", 0},
+		{"1048576", 1 << 20},
+		{"512MiB", 512 << 20},
+		{"2 GiB", 2 << 30},
+		{"1.5GB", 1_500_000_000},
+		{"64KB", 64_000},
+	}
+	for _, tt := range tests {
+		got, err := (&Config{MaxMemory: tt.size}).MemoryLimit()
+		if err != nil || got != tt.want {
+			t.Errorf("MemoryLimit(%q) = %d, %v, want %d", tt.size, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"lots", "2XB", "-1GiB", "0", "GiB"} {
+		if _, err := (&Config{MaxMemory: bad}).MemoryLimit(); err == nil {
+			t.Errorf("MemoryLimit(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/rules"
+)
+
+// Settings are the keys allowed at the top level of the configuration file
+// and in each [profiles.NAME] section. Zero values leave the corresponding
+// setting unchanged.
+type Settings struct {
+	ClaudeDir         string             `toml:"claude_dir"`
+	RejectionPatterns []string           `toml:"reject_patterns"`
+	Projects          []string           `toml:"projects"`
+	Account           string             `toml:"account"`
+	Aliases           rules.Aliases      `toml:"aliases"`
+	Tags              rules.TagRules     `toml:"tags"`
+	Pricing           rules.PricingRules `toml:"pricing"`
+	SlackWebhook      string             `toml:"slack_webhook"`
+	DiscordWebhook    string             `toml:"discord_webhook"`
+	FailOver          float64            `toml:"fail_over"`
+	Plan              string             `toml:"plan"`
+	BillingDay        int                `toml:"billing_day"`
+	// The assumptions of the time saved estimate
+	MinutesPerToolAction float64 `toml:"minutes_per_tool_action"`
+	MinutesPerMessage    float64 `toml:"minutes_per_message"`
+	MinutesPer100Lines   float64 `toml:"minutes_per_100_lines"`
+	Days                 int     `toml:"days"`
+	Top                  int     `toml:"top"`
+	WorkHours            string  `toml:"work_hours"`
+	WorkDays             string  `toml:"work_days"`
+	AnomalyStdDev        float64 `toml:"anomaly_stddev"`
+	AnomalyMedian        float64 `toml:"anomaly_median"`
+	PromptWarn           float64 `toml:"prompt_warn"`
+	PromptAlert          float64 `toml:"prompt_alert"`
+	UTCDays              bool    `toml:"utc_days"`
+	DayStartHour         int     `toml:"day_start_hour"`
+	MaxMemory            string  `toml:"max_memory"`
+	NoSymlinks           bool    `toml:"no_symlinks"`
+}
+
+// File is the TOML configuration file
+type File struct {
+	Settings
+	// Profiles are named settings selected with --profile, e.g. one per client
+	Profiles map[string]Settings `toml:"profiles"`
+	// Accounts name accounts by user or account ID
+	Accounts map[string]string `toml:"accounts"`
+	// Plans define or override the rate limits of subscription plans
+	Plans map[string]limits.Plan `toml:"plans"`
+	// Serve configures the serve command's authentication and TLS
+	Serve ServeSettings `toml:"serve"`
+	// Push configures the push command's destinations
+	Push PushSettings `toml:"push"`
+}
+
+// ServeSettings are the keys of the [serve] section
+type ServeSettings struct {
+	Addr        string `toml:"addr"`
+	GRPCAddr    string `toml:"grpc_addr"`
+	Token       string `toml:"token"`
+	BasicAuth   string `toml:"basic_auth"`
+	TLSCert     string `toml:"tls_cert"`
+	TLSKey      string `toml:"tls_key"`
+	TLSClientCA string `toml:"tls_client_ca"`
+	ReadOnly    bool   `toml:"read_only"`
+}
+
+// PushSettings are the keys of the [push] section
+type PushSettings struct {
+	Influx         string `toml:"influx"`
+	Org            string `toml:"org"`
+	Bucket         string `toml:"bucket"`
+	InfluxToken    string `toml:"influx_token"`
+	Graphite       string `toml:"graphite"`
+	GraphitePrefix string `toml:"graphite_prefix"`
+}
+
+// DefaultPath returns the default configuration file location, usually
+// ~/.config/claude-costs/config.toml
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "claude-costs", "config.toml")
+}
+
+// LoadFile reads a TOML configuration file. When optional is true a missing
+// file is not an error and yields an empty File.
+func LoadFile(path string, optional bool) (*File, error) {
+	f := &File{}
+	if path == "" {
+		return f, nil
+	}
+
+	meta, err := toml.DecodeFile(path, f)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %q in config %s", undecoded[0].String(), path)
+	}
+
+	for i, alias := range f.Aliases {
+		if alias.Pattern == "" || alias.Name == "" {
+			return nil, fmt.Errorf("alias %d in config %s needs both pattern and name", i+1, path)
+		}
+	}
+	for name, profile := range f.Profiles {
+		for i, alias := range profile.Aliases {
+			if alias.Pattern == "" || alias.Name == "" {
+				return nil, fmt.Errorf("alias %d of profile %s in config %s needs both pattern and name", i+1, name, path)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// Profile returns f with the named profile applied on top of the top-level
+// settings. Profile values replace top-level values, except that rule lists
+// combine with the profile's rules first. An empty name returns f unchanged.
+func (f *File) Profile(name string) (*File, error) {
+	if name == "" {
+		return f, nil
+	}
+	profile, ok := f.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(f.Profiles))
+		for n := range f.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: the config file defines no profiles", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q: use one of %s", name, strings.Join(names, ", "))
+	}
+
+	merged := f.Settings
+	if profile.ClaudeDir != "" {
+		merged.ClaudeDir = profile.ClaudeDir
+	}
+	if len(profile.Projects) > 0 {
+		merged.Projects = profile.Projects
+	}
+	if profile.SlackWebhook != "" {
+		merged.SlackWebhook = profile.SlackWebhook
+	}
+	if profile.DiscordWebhook != "" {
+		merged.DiscordWebhook = profile.DiscordWebhook
+	}
+	if profile.FailOver > 0 {
+		merged.FailOver = profile.FailOver
+	}
+	if profile.Account != "" {
+		merged.Account = profile.Account
+	}
+	if profile.Plan != "" {
+		merged.Plan = profile.Plan
+	}
+	if profile.BillingDay > 0 {
+		merged.BillingDay = profile.BillingDay
+	}
+	if profile.MinutesPerToolAction > 0 {
+		merged.MinutesPerToolAction = profile.MinutesPerToolAction
+	}
+	if profile.MinutesPerMessage > 0 {
+		merged.MinutesPerMessage = profile.MinutesPerMessage
+	}
+	if profile.MinutesPer100Lines > 0 {
+		merged.MinutesPer100Lines = profile.MinutesPer100Lines
+	}
+	if profile.Days > 0 {
+		merged.Days = profile.Days
+	}
+	if profile.Top > 0 {
+		merged.Top = profile.Top
+	}
+	if profile.WorkHours != "" {
+		merged.WorkHours = profile.WorkHours
+	}
+	if profile.WorkDays != "" {
+		merged.WorkDays = profile.WorkDays
+	}
+	if profile.AnomalyStdDev > 0 {
+		merged.AnomalyStdDev = profile.AnomalyStdDev
+	}
+	if profile.AnomalyMedian > 0 {
+		merged.AnomalyMedian = profile.AnomalyMedian
+	}
+	if profile.PromptWarn > 0 {
+		merged.PromptWarn = profile.PromptWarn
+	}
+	if profile.PromptAlert > 0 {
+		merged.PromptAlert = profile.PromptAlert
+	}
+	if profile.UTCDays {
+		merged.UTCDays = true
+	}
+	if profile.DayStartHour > 0 {
+		merged.DayStartHour = profile.DayStartHour
+	}
+	if profile.MaxMemory != "" {
+		merged.MaxMemory = profile.MaxMemory
+	}
+	if profile.NoSymlinks {
+		merged.NoSymlinks = true
+	}
+	// Aliases and pricing rules are first-match-wins, so the profile's take
+	// precedence
+	merged.RejectionPatterns = append(append([]string(nil), profile.RejectionPatterns...), f.RejectionPatterns...)
+	merged.Aliases = append(append(rules.Aliases(nil), profile.Aliases...), f.Aliases...)
+	merged.Tags = append(append(rules.TagRules(nil), profile.Tags...), f.Tags...)
+	merged.Pricing = append(append(rules.PricingRules(nil), profile.Pricing...), f.Pricing...)
+
+	return &File{Settings: merged, Accounts: f.Accounts, Plans: f.Plans, Serve: f.Serve, Push: f.Push}, nil
+}
+
+// ApplyFile copies settings from f into c. Settings for which changed returns
+// true were given on the command line and take precedence.
+func (c *Config) ApplyFile(f *File, changed func(flag string) bool) {
+	if f.ClaudeDir != "" && !changed("claude-dir") {
+		c.ClaudeDir = expandHome(f.ClaudeDir)
+	}
+	if f.Days > 0 && !changed("days") {
+		c.Days = f.Days
+	}
+	if f.Top > 0 && !changed("top") && !changed("limit") {
+		c.Top = f.Top
+	}
+	if f.SlackWebhook != "" && !changed("slack-webhook") {
+		c.SlackWebhook = f.SlackWebhook
+	}
+	if f.DiscordWebhook != "" && !changed("discord-webhook") {
+		c.DiscordWebhook = f.DiscordWebhook
+	}
+	if f.FailOver > 0 && !changed("fail-over") {
+		c.FailOver = f.FailOver
+	}
+	if f.Account != "" && !changed("account") {
+		c.Account = f.Account
+	}
+	if len(f.Accounts) > 0 {
+		c.AccountNames = f.Accounts
+	}
+	if f.Plan != "" && !changed("plan") {
+		c.Plan = f.Plan
+	}
+	if len(f.Plans) > 0 {
+		c.Plans = f.Plans
+	}
+	if f.BillingDay > 0 {
+		c.BillingDay = f.BillingDay
+	}
+	if f.MinutesPerToolAction > 0 {
+		c.MinutesPerToolAction = f.MinutesPerToolAction
+	}
+	if f.MinutesPerMessage > 0 {
+		c.MinutesPerMessage = f.MinutesPerMessage
+	}
+	if f.MinutesPer100Lines > 0 {
+		c.MinutesPer100Lines = f.MinutesPer100Lines
+	}
+	if f.WorkHours != "" && !changed("work-hours") {
+		c.WorkHours = f.WorkHours
+	}
+	if f.WorkDays != "" && !changed("work-days") {
+		c.WorkDays = f.WorkDays
+	}
+	if f.AnomalyStdDev > 0 && !changed("anomaly-stddev") {
+		c.AnomalyStdDev = f.AnomalyStdDev
+	}
+	if f.AnomalyMedian > 0 && !changed("anomaly-median") {
+		c.AnomalyMedian = f.AnomalyMedian
+	}
+	if f.PromptWarn > 0 && !changed("warn") {
+		c.PromptWarn = f.PromptWarn
+	}
+	if f.PromptAlert > 0 && !changed("alert") {
+		c.PromptAlert = f.PromptAlert
+	}
+	if f.UTCDays && !changed("utc-days") {
+		c.UTCDays = true
+	}
+	if f.DayStartHour > 0 && !changed("day-start-hour") {
+		c.DayStartHour = f.DayStartHour
+	}
+	if f.MaxMemory != "" && !changed("max-memory") {
+		c.MaxMemory = f.MaxMemory
+	}
+	if f.NoSymlinks && !changed("no-symlinks") {
+		c.NoSymlinks = true
+	}
+	c.applyServe(f.Serve, changed)
+	c.applyPush(f.Push, changed)
+	// A filter given on the command line replaces the file's
+	if len(f.Projects) > 0 && !changed("project") {
+		c.Projects = f.Projects
+	}
+
+	// Lists from the file and the command line combine
+	c.RejectionPatterns = append(c.RejectionPatterns, f.RejectionPatterns...)
+	c.Aliases = append(c.Aliases, f.Aliases...)
+	c.TagRules = append(c.TagRules, f.Tags...)
+	c.PricingRules = append(c.PricingRules, f.Pricing...)
+}
+
+// applyServe copies the [serve] section into c
+func (c *Config) applyServe(s ServeSettings, changed func(flag string) bool) {
+	if s.Addr != "" && !changed("addr") {
+		c.Addr = s.Addr
+	}
+	if s.GRPCAddr != "" && !changed("grpc-addr") {
+		c.GRPCAddr = s.GRPCAddr
+	}
+	if s.Token != "" && !changed("token") {
+		c.Token = s.Token
+	}
+	if s.BasicAuth != "" && !changed("basic-auth") {
+		c.BasicAuth = s.BasicAuth
+	}
+	if s.TLSCert != "" && !changed("tls-cert") {
+		c.TLSCert = expandHome(s.TLSCert)
+	}
+	if s.TLSKey != "" && !changed("tls-key") {
+		c.TLSKey = expandHome(s.TLSKey)
+	}
+	if s.TLSClientCA != "" && !changed("tls-client-ca") {
+		c.TLSClientCA = expandHome(s.TLSClientCA)
+	}
+	if s.ReadOnly && !changed("read-only") {
+		c.ReadOnly = true
+	}
+}
+
+// applyPush copies the [push] section into c
+func (c *Config) applyPush(p PushSettings, changed func(flag string) bool) {
+	if p.Influx != "" && !changed("influx") {
+		c.Influx = p.Influx
+	}
+	if p.Org != "" && !changed("org") {
+		c.InfluxOrg = p.Org
+	}
+	if p.Bucket != "" && !changed("bucket") {
+		c.InfluxBucket = p.Bucket
+	}
+	if p.InfluxToken != "" && !changed("influx-token") {
+		c.InfluxToken = p.InfluxToken
+	}
+	if p.Graphite != "" && !changed("graphite") {
+		c.Graphite = p.Graphite
+	}
+	if p.GraphitePrefix != "" && !changed("graphite-prefix") {
+		c.GraphitePrefix = p.GraphitePrefix
+	}
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+days = 14
+top = 20
+reject_patterns = ["blocked by hook"]
+work_hours = "08:00-16:00"
+
+[[aliases]]
+pattern = "photostructure/*"
+name = "PhotoStructure"
+
+[[pricing]]
+project = "clients/*"
+discount = 15
+[pricing.models."claude-opus-4*"]
+input = 12.0
+output = 60.0
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewDefault()
+	cfg.Days = 7
+	cfg.ApplyFile(f, func(flag string) bool { return flag == "days" })
+
+	if cfg.Days != 7 {
+		t.Errorf("Days = %d, want 7 from the command line", cfg.Days)
+	}
+	if cfg.Top != 20 {
+		t.Errorf("Top = %d, want 20 from the file", cfg.Top)
+	}
+	if len(cfg.Aliases) != 1 || cfg.Aliases.Resolve("src/photostructure/app") != "PhotoStructure" {
+		t.Errorf("Aliases = %+v, want PhotoStructure rule", cfg.Aliases)
+	}
+	if len(cfg.RejectionPatterns) != 1 {
+		t.Errorf("RejectionPatterns = %v, want one pattern", cfg.RejectionPatterns)
+	}
+	if len(cfg.PricingRules) != 1 || cfg.PricingRules[0].Models["claude-opus-4*"].Output != 60 {
+		t.Errorf("PricingRules = %+v, want the clients rule with Opus rates", cfg.PricingRules)
+	}
+	if cfg.WorkHours != "08:00-16:00" || cfg.WorkDays != "mon-fri" {
+		t.Errorf("WorkHours = %q on %q, want 08:00-16:00 on the default mon-fri", cfg.WorkHours, cfg.WorkDays)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.toml"), true); err != nil {
+		t.Errorf("optional missing file: unexpected error %v", err)
+	}
+	if _, err := LoadFile(filepath.Join(dir, "missing.toml"), false); err == nil {
+		t.Error("required missing file: expected error")
+	}
+
+	unknown := filepath.Join(dir, "unknown.toml")
+	if err := os.WriteFile(unknown, []byte("dayz = 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(unknown, false); err == nil {
+		t.Error("unknown key: expected error")
+	}
+}
+
+func TestFile_Profile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+days = 14
+fail_over = 100
+reject_patterns = ["blocked by hook"]
+
+[profiles.work]
+claude_dir = "/work/.claude"
+projects = ["acme/*"]
+fail_over = 50
+reject_patterns = ["denied by policy"]
+
+[[profiles.work.aliases]]
+pattern = "acme/*"
+name = "Acme"
+
+[profiles.personal]
+days = 30
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work, err := f.Profile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.ClaudeDir != "/work/.claude" || work.FailOver != 50 || work.Days != 14 {
+		t.Errorf("work = %+v, want profile dir and budget over top-level days", work.Settings)
+	}
+	if len(work.RejectionPatterns) != 2 || work.RejectionPatterns[0] != "denied by policy" {
+		t.Errorf("work RejectionPatterns = %v, want profile pattern first, then top-level", work.RejectionPatterns)
+	}
+	if len(work.Projects) != 1 || len(work.Aliases) != 1 {
+		t.Errorf("work Projects = %v, Aliases = %v, want one each", work.Projects, work.Aliases)
+	}
+
+	personal, err := f.Profile("personal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if personal.Days != 30 || personal.FailOver != 100 || personal.ClaudeDir != "" {
+		t.Errorf("personal = %+v, want 30 days and the top-level budget", personal.Settings)
+	}
+
+	if same, err := f.Profile(""); err != nil || same != f {
+		t.Errorf("empty profile = %v, %v, want the file unchanged", same, err)
+	}
+	if _, err := f.Profile("home"); err == nil {
+		t.Error("unknown profile: expected error")
+	}
+
+	cfg := NewDefault()
+	cfg.ApplyFile(work, func(flag string) bool { return false })
+	if cfg.FailOver != 50 || len(cfg.Projects) != 1 {
+		t.Errorf("ApplyFile: FailOver = %v, Projects = %v, want the work profile's", cfg.FailOver, cfg.Projects)
+	}
+}
+
+func TestLoadFile_Serve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[serve]
+addr = "0.0.0.0:8443"
+token = "from-file"
+tls_cert = "cert.pem"
+read_only = true
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewDefault()
+	cfg.Token = "from-flag"
+	cfg.ApplyFile(f, func(flag string) bool { return flag == "token" })
+
+	if cfg.Addr != "0.0.0.0:8443" || !cfg.ReadOnly {
+		t.Errorf("Addr = %q, ReadOnly = %v, want the file's", cfg.Addr, cfg.ReadOnly)
+	}
+	if cfg.Token != "from-flag" {
+		t.Errorf("Token = %q, want the command line's", cfg.Token)
+	}
+	if err := cfg.validateServe(); err == nil {
+		t.Error("tls_cert without tls_key: expected error")
+	}
+
+	cfg.TLSCert = ""
+	cfg.BasicAuth = "nocolon"
+	if err := cfg.validateServe(); err == nil {
+		t.Error("basic auth without a password separator: expected error")
+	}
+}
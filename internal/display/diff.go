@@ -0,0 +1,109 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// ShowDiff writes what changed between two saved analyses to w: the
+// totals, then up to limit projects and models (zero shows all), largest
+// changes first
+func ShowDiff(w io.Writer, oldName string, old *models.CostAnalysis, newName string, new *models.CostAnalysis, limit int) {
+	diff := calculator.Diff(old, new)
+
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint("🔀 Changes"))
+	fmt.Fprintf(w, "Old: %s (%s)\n", oldName, formatPeriod(old))
+	fmt.Fprintf(w, "New: %s (%s)\n\n", newName, formatPeriod(new))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"", "Old", "New", "Change", "Change %"})
+	t.AppendRows([]table.Row{
+		{"Cost", formatCurrency(diff.Cost.Old), formatCurrency(diff.Cost.New), formatSignedCurrency(diff.Cost.Delta()), formatPercentDiff(diff.Cost.Percent())},
+		{"Sessions", formatNumber(int(diff.Sessions.Old)), formatNumber(int(diff.Sessions.New)), fmt.Sprintf("%+d", int(diff.Sessions.Delta())), formatPercentDiff(diff.Sessions.Percent())},
+		{"Tokens", formatTokensWithSuffix(int(diff.Tokens.Old)), formatTokensWithSuffix(int(diff.Tokens.New)), formatSignedTokens(int(diff.Tokens.Delta())), formatPercentDiff(diff.Tokens.Percent())},
+		{"Cost/session", formatCurrency(diff.CostPerSession.Old), formatCurrency(diff.CostPerSession.New), formatSignedCurrency(diff.CostPerSession.Delta()), formatPercentDiff(diff.CostPerSession.Percent())},
+		{"Cache hit rate", fmt.Sprintf("%.1f%%", diff.CacheHitRate.Old), fmt.Sprintf("%.1f%%", diff.CacheHitRate.New), formatPoints(diff.CacheHitRate.Delta()), ""},
+	})
+	fmt.Fprintln(w, t.Render())
+
+	if len(diff.Projects) > 0 {
+		fmt.Fprintf(w, "\n%s\n", text.Bold.Sprint("📁 Projects"))
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"Project", "Old", "New", "Change", "Change %"})
+		for i, project := range diff.Projects {
+			if limit > 0 && i == limit {
+				t.AppendRow(table.Row{fmt.Sprintf("... %d more", len(diff.Projects)-limit)})
+				break
+			}
+			t.AppendRow(table.Row{
+				truncateString(project.Name, 40),
+				formatCurrency(project.Cost.Old),
+				formatCurrency(project.Cost.New),
+				formatSignedCurrency(project.Cost.Delta()),
+				changeLabel(project),
+			})
+		}
+		fmt.Fprintln(w, t.Render())
+	}
+
+	if len(diff.Models) > 0 {
+		fmt.Fprintf(w, "\n%s\n", text.Bold.Sprint("🤖 Models"))
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"Model", "Old", "New", "Old Share", "New Share", "Shift"})
+		for i, model := range diff.Models {
+			if limit > 0 && i == limit {
+				t.AppendRow(table.Row{fmt.Sprintf("... %d more", len(diff.Models)-limit)})
+				break
+			}
+			t.AppendRow(table.Row{
+				model.Name,
+				formatCurrency(model.Cost.Old),
+				formatCurrency(model.Cost.New),
+				fmt.Sprintf("%.1f%%", model.Share.Old),
+				fmt.Sprintf("%.1f%%", model.Share.New),
+				formatPoints(model.Share.Delta()),
+			})
+		}
+		fmt.Fprintln(w, t.Render())
+	}
+}
+
+// changeLabel is a project's percentage change, or whether it's new or
+// gone
+func changeLabel(change calculator.NamedChange) string {
+	switch {
+	case change.Added:
+		return text.FgGreen.Sprint("new")
+	case change.Removed:
+		return text.FgYellow.Sprint("gone")
+	}
+	return formatPercentDiff(change.Cost.Percent())
+}
+
+// formatPeriod is the span of an analysis's activity
+func formatPeriod(a *models.CostAnalysis) string {
+	if a.EndDate.IsZero() {
+		return "no activity"
+	}
+	return a.StartDate.Local().Format("2006-01-02") + " to " + a.EndDate.Local().Format("2006-01-02")
+}
+
+// formatPoints formats a change in a percentage, as in "+3.2 pts"
+func formatPoints(points float64) string {
+	return fmt.Sprintf("%+.1f pts", points)
+}
+
+func formatSignedTokens(n int) string {
+	if n < 0 {
+		return "-" + formatTokensWithSuffix(-n)
+	}
+	return "+" + formatTokensWithSuffix(n)
+}
@@ -0,0 +1,48 @@
+package display_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/testutil"
+)
+
+func TestShowDiff_Golden(t *testing.T) {
+	deterministic(t)
+
+	old := &models.CostAnalysis{
+		StartDate: time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 10, 11, 17, 0, 0, 0, time.UTC),
+		TotalCost: 10,
+		Projects: map[string]*models.ProjectStats{
+			"api":    {Cost: 6},
+			"legacy": {Cost: 4},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4":   {Cost: 8},
+			"claude-sonnet-4": {Cost: 2},
+		},
+		Sessions: map[string]*models.SessionStats{"s1": {Cost: 10}},
+	}
+	new := &models.CostAnalysis{
+		StartDate: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC),
+		TotalCost: 20,
+		Projects: map[string]*models.ProjectStats{
+			"api": {Cost: 5},
+			"web": {Cost: 15},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4":   {Cost: 10},
+			"claude-sonnet-4": {Cost: 10},
+		},
+		Sessions: map[string]*models.SessionStats{"s2": {Cost: 12}, "s3": {Cost: 8}},
+	}
+
+	var buf bytes.Buffer
+	display.ShowDiff(&buf, "2026-w41.analysis", old, "2026-w42.analysis", new, 2)
+	testutil.Golden(t, "testdata/diff.golden", buf.String())
+}
@@ -1,46 +1,263 @@
 package display
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/chart"
+	"github.com/photostructure/go-claude-costs/internal/limits"
 	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/rules"
+	"github.com/photostructure/go-claude-costs/internal/termimg"
+)
+
+// Verbosity selects how much the summary shows
+type Verbosity int
+
+const (
+	// Quiet prints the total on one line, for shell prompts and scripts
+	Quiet Verbosity = iota - 1
+	// Normal prints the summary
+	Normal
+	// Verbose lists every row of ranked lists and adds the session table
+	// and the detailed cache and tool sections
+	Verbose
 )
 
 // Display handles formatting and displaying the analysis results
 type Display struct {
-	analysis  *models.CostAnalysis
-	stats     *calculator.Statistics
-	verbose   bool
-	showCache bool
+	analysis   *models.CostAnalysis
+	stats      *calculator.Statistics
+	verbosity  Verbosity
+	top        int
+	showCache  bool
+	showTools  bool
+	groupBy    string
+	cacheAlert float64
+	since      time.Time
+	planName   string
+	plan       limits.Plan
+	billingDay int // Zero when the summary doesn't show the subscription
+	commits    map[string]int
+	// timeSavedRates are the assumptions of the time saved estimate
+	timeSavedRates calculator.TimeSavedRates
+	blockLength    time.Duration
+	graphics       termimg.Protocol
+	showHours      bool
+	hoursFrom      time.Time
+	hoursTo        time.Time
+	workHours      *rules.WorkHours
+	anomalies      calculator.AnomalyThresholds
+	out            io.Writer
+	now            func() time.Time
+	claudeDir      string
 }
 
 // New creates a new Display instance
-func New(analysis *models.CostAnalysis, verbose, showCache bool) *Display {
+func New(analysis *models.CostAnalysis, verbosity Verbosity, showCache bool) *Display {
 	return &Display{
-		analysis:  analysis,
-		stats:     calculator.New(analysis),
-		verbose:   verbose,
-		showCache: showCache,
+		analysis:    analysis,
+		stats:       calculator.New(analysis),
+		verbosity:   verbosity,
+		top:         10,
+		showCache:   showCache || verbosity >= Verbose,
+		blockLength: 5 * time.Hour,
+		out:         os.Stdout,
+		now:         time.Now,
+	}
+}
+
+// SetOutput sets where the display writes, stdout by default
+func (d *Display) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// SetClock sets the clock that places the active billing block, for
+// output that doesn't change from run to run
+func (d *Display) SetClock(now func() time.Time) {
+	d.now = now
+}
+
+// SetClaudeDir names the analyzed directory, or saved analysis, in the
+// report's heading; the default is ~/.claude
+func (d *Display) SetClaudeDir(dir string) {
+	d.claudeDir = dir
+}
+
+// SetTop sets how many rows ranked lists such as projects, sessions, and
+// models show; zero shows all. Verbose output shows all regardless.
+func (d *Display) SetTop(n int) {
+	d.top = n
+}
+
+// rows returns how many rows ranked lists show, zero for all
+func (d *Display) rows() int {
+	if d.verbosity >= Verbose {
+		return 0
 	}
+	return d.top
+}
+
+// SetCacheAlert sets the cache hit rate percentage below which the most
+// recent day is flagged. Zero disables the alert.
+func (d *Display) SetCacheAlert(threshold float64) {
+	d.cacheAlert = threshold
+}
+
+// SetAnomalyThresholds sets when a day's or session's cost is listed as an
+// anomaly. The zero value lists none.
+func (d *Display) SetAnomalyThresholds(t calculator.AnomalyThresholds) {
+	d.anomalies = t
+}
+
+// SetShowTools enables the detailed tool usage sections, which verbose
+// output always shows
+func (d *Display) SetShowTools(show bool) {
+	d.showTools = show || d.verbosity >= Verbose
+}
+
+// SetGroupBy selects how costs are broken down: "project" (the default) or
+// "tag"
+func (d *Display) SetGroupBy(groupBy string) {
+	d.groupBy = groupBy
+}
+
+// SetSince marks the analysis as covering only activity after since, which
+// the cost summary reports instead of the day count. The zero time is the
+// default day window.
+func (d *Display) SetSince(since time.Time) {
+	d.since = since
+}
+
+// SetPlan sets the subscription plan whose rate limits the watch view
+// estimates against, and that SetBillingDay prices
+func (d *Display) SetPlan(name string, plan limits.Plan) {
+	d.planName = name
+	d.plan = plan
+}
+
+// SetBillingDay has the summary compare the API value with the plan set by
+// SetPlan, renewing on day of each month
+func (d *Display) SetBillingDay(day int) {
+	d.billingDay = day
+}
+
+// SetTimeSavedRates has the summary estimate the hours saved at rates
+func (d *Display) SetTimeSavedRates(rates calculator.TimeSavedRates) {
+	d.timeSavedRates = rates
+}
+
+// SetCommits shows cost per git commit for the projects in commits, which
+// counts the commits made in each project's directories during the window.
+// Projects that aren't git repositories are left out of it.
+func (d *Display) SetCommits(commits map[string]int) {
+	d.commits = commits
+}
+
+// SetBlockLength sets the billing block length used to count blocks that
+// reached the usage limit
+func (d *Display) SetBlockLength(length time.Duration) {
+	d.blockLength = length
 }
 
-// ShowAll displays all analysis results
+// SetGraphics draws the activity charts as inline images with protocol p
+// instead of text bars and sparklines. The zero value and termimg.None keep
+// the text.
+func (d *Display) SetGraphics(p termimg.Protocol) {
+	d.graphics = p
+}
+
+// SetShowHours adds the table of cost and tokens by hour of day, covering
+// activity in [from, to). Zero times leave that end open.
+func (d *Display) SetShowHours(from, to time.Time) {
+	d.showHours = true
+	d.hoursFrom = from
+	d.hoursTo = to
+}
+
+// SetWorkHours adds the split of cost between working hours and off hours
+func (d *Display) SetWorkHours(w rules.WorkHours) {
+	d.workHours = &w
+}
+
+// ShowAll displays all analysis results, or only the total when quiet
 func (d *Display) ShowAll() {
-	home, _ := os.UserHomeDir()
-	fmt.Printf("Analyzing: %s/.claude\n\n", home)
+	if d.verbosity <= Quiet {
+		d.showTotal()
+		return
+	}
+
+	dir := d.claudeDir
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = home + "/.claude"
+	}
+	fmt.Fprintf(d.out, "Analyzing: %s\n\n", dir)
 	d.showCostSummary()
 	d.showTokenSummary()
-	d.showProjectCosts()
+	d.showAnomalies()
+	if d.groupBy == "tag" {
+		d.showTagCosts()
+	} else {
+		d.showProjectCosts()
+	}
+	d.showCommitCosts()
+	d.showLineCosts()
+	if d.verbosity >= Verbose {
+		d.ShowSessions("cost", "")
+	}
+	d.showAccountCosts()
+	d.showImageCosts()
+	d.showContextOverhead()
+	d.showLowYieldSessions()
 	d.showActivityPatterns()
+	d.showConcurrency()
+	d.showObservedHours()
+	d.showStreaks()
+	if d.showHours {
+		d.showHourlyCosts()
+	}
+	if d.workHours != nil {
+		d.showWorkSplit()
+	}
+	d.showLimitHits()
 	d.showModelUsage()
+	d.showCategories()
+	d.showOpusDowngrades()
+	d.showRightSizing()
 	d.showToolUse()
+	d.showInterruptions()
+	if d.showTools {
+		d.showToolRejections()
+		d.showBashCommands()
+	}
+	d.showFileExtensions()
 	d.showResponseTimeStats()
+	d.showTokenVelocity()
+}
+
+// showTotal prints the cost, tokens, and sessions on one plain line, such as
+// "$12.34 API value, 1.2M tokens, 8 sessions in the last 30 days"
+func (d *Display) showTotal() {
+	tokens := d.analysis.TotalInputTokens + d.analysis.TotalOutputTokens +
+		d.analysis.TotalCacheRead + d.analysis.TotalCacheWrite
+	span := fmt.Sprintf("in the last %d days", int(d.analysis.EndDate.Sub(d.analysis.StartDate).Hours()/24)+1)
+	if !d.since.IsZero() {
+		span = "since " + d.since.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Fprintf(d.out, "%s API value, %s tokens, %d sessions %s\n",
+		formatCurrency(d.analysis.TotalCost), formatTokensWithSuffix(tokens), len(d.analysis.Sessions), span)
 }
 
 // showCostSummary displays the cost summary
@@ -58,17 +275,125 @@ func (d *Display) showCostSummary() {
 		costPerDay = d.analysis.TotalCost / float64(len(activeDays))
 	}
 
-	fmt.Printf("💰 %s API value (last %d days, %d with activity)\n",
-		text.Bold.Sprint(formatCurrency(d.analysis.TotalCost)),
-		int(d.analysis.EndDate.Sub(d.analysis.StartDate).Hours()/24)+1,
-		len(activeDays))
+	if d.since.IsZero() {
+		fmt.Fprintf(d.out, "💰 %s API value (last %d days, %d with activity)\n",
+			text.Bold.Sprint(formatCurrency(d.analysis.TotalCost)),
+			int(d.analysis.EndDate.Sub(d.analysis.StartDate).Hours()/24)+1,
+			len(activeDays))
+	} else {
+		fmt.Fprintf(d.out, "💰 %s API value since %s (%d days with activity)\n",
+			text.Bold.Sprint(formatCurrency(d.analysis.TotalCost)),
+			d.since.Local().Format("2006-01-02 15:04"),
+			len(activeDays))
+	}
+	d.showCacheSplit()
+	d.showSubscription()
+	d.showTimeSaved()
 
-	fmt.Printf("📊 %d sessions • %s/session • %s/day\n",
+	fmt.Fprintf(d.out, "📊 %d sessions • %s/session • %s/day\n",
 		len(d.analysis.Sessions),
 		formatCurrency(d.stats.GetAverageCostPerSession()),
 		formatCurrency(costPerDay))
+	d.showCostTrend()
 
-	fmt.Println("Note: This shows API value, not your actual subscription cost")
+	fmt.Fprintln(d.out, "Note: This shows API value, not your actual subscription cost")
+	if n := len(d.analysis.DuplicateFiles); n > 0 {
+		fmt.Fprintf(d.out, "Note: Skipped %d copies of session files, listed by doctor\n", n)
+	}
+}
+
+// showCacheSplit breaks the cache writes and reads out of the headline
+// cost, and compares them with paying the plain input price for the same
+// tokens. Each response is priced at its own model's rates.
+func (d *Display) showCacheSplit() {
+	if d.analysis.TotalCacheWrite == 0 && d.analysis.TotalCacheRead == 0 {
+		return
+	}
+	cache := d.analysis.Cache
+	compared := "saved " + formatCurrency(cache.Net())
+	if cache.Net() < 0 {
+		compared = "cost " + formatCurrency(-cache.Net()) + " more"
+	}
+	fmt.Fprintf(d.out, "   of which %s cache writes, %s cache reads (%s vs uncached)\n",
+		formatCurrency(cache.WriteCost), formatCurrency(cache.ReadCost), compared)
+}
+
+// showSubscription compares the API value with what the plan cost over the
+// days the headline covers
+func (d *Display) showSubscription() {
+	if d.billingDay == 0 || d.analysis.EndDate.IsZero() {
+		return
+	}
+	end := d.analysis.EndDate.Local()
+	end = time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, time.Local)
+	start := d.since
+	if start.IsZero() {
+		start = d.analysis.StartDate.Local()
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	}
+	cost := limits.SubscriptionCost(d.plan, d.billingDay, start, end)
+	if cost <= 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "💳 %s subscription (%s, renews on day %d) • %.1fx value multiple\n",
+		formatCurrency(cost), d.planName, d.billingDay, d.analysis.TotalCost/cost)
+}
+
+// showTimeSaved prints the estimate of the hours saved and the assumptions
+// it rests on. It's omitted unless SetTimeSavedRates was called.
+func (d *Display) showTimeSaved() {
+	rates := d.timeSavedRates
+	if rates.IsZero() {
+		return
+	}
+	saved := d.stats.GetTimeSaved(rates)
+	if saved.Hours == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "⏳ ~%.1f hours saved (estimated) • %s per hour saved\n", saved.Hours, formatCurrency(saved.CostPerHour()))
+
+	var assumptions []string
+	if rates.PerToolAction > 0 {
+		assumptions = append(assumptions, fmt.Sprintf("%g min × %s accepted tool calls", rates.PerToolAction, formatNumber(saved.ToolActions)))
+	}
+	if rates.PerMessage > 0 {
+		assumptions = append(assumptions, fmt.Sprintf("%g min × %s messages", rates.PerMessage, formatNumber(saved.Messages)))
+	}
+	if rates.Per100Lines > 0 {
+		assumptions = append(assumptions, fmt.Sprintf("%g min per 100 of %s changed lines", rates.Per100Lines, formatNumber(saved.Lines)))
+	}
+	fmt.Fprintf(d.out, "   assuming %s\n", strings.Join(assumptions, ", "))
+}
+
+// weekChangeRatio is the week over week change in spend, either way, that
+// the summary calls out
+const weekChangeRatio = 2
+
+// showCostTrend describes the line fit to daily cost, and notes when spend
+// doubled or halved week over week or the trend changed direction
+func (d *Display) showCostTrend() {
+	trend, ok := d.stats.GetCostTrend()
+	if !ok {
+		return
+	}
+	icon, sign := "📈", "+"
+	if trend.Slope < 0 {
+		icon, sign = "📉", "-"
+	}
+	fmt.Fprintf(d.out, "%s Daily cost trend: %s%s per day (R² %.2f over %d days)\n",
+		icon, sign, formatCurrency(math.Abs(trend.Slope)), trend.R2, trend.Days)
+
+	weeks := fmt.Sprintf("%s this week, %s the week before", formatCurrency(trend.ThisWeek), formatCurrency(trend.LastWeek))
+	switch change := trend.WeekChange(); {
+	case change >= weekChangeRatio:
+		fmt.Fprintf(d.out, "%s Spend up %.1fx week over week: %s\n", text.FgYellow.Sprint("⚠"), change, weeks)
+	case change > 0 && change <= 1.0/weekChangeRatio:
+		fmt.Fprintf(d.out, "↘ Spend down to %.1fx week over week: %s\n", change, weeks)
+	case trend.Reversed() && trend.RecentSlope > 0:
+		fmt.Fprintf(d.out, "↗ Trend turned upward this week: %s\n", weeks)
+	case trend.Reversed():
+		fmt.Fprintf(d.out, "↘ Trend turned downward this week: %s\n", weeks)
+	}
 }
 
 // showTokenSummary displays token usage summary
@@ -80,7 +405,11 @@ func (d *Display) showTokenSummary() {
 	// Format total with suffix (M for millions)
 	totalStr := formatTokensWithSuffix(totalAllTokens)
 
-	fmt.Printf("%s\n", text.Bold.Sprint("🔤 "+totalStr+" tokens total"))
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔤 "+totalStr+" tokens total"))
+	if expiry := d.analysis.CacheExpiry; expiry.Rewrites > 0 && !d.showCache {
+		fmt.Fprintf(d.out, "🧊 %s spent writing the cache again after %d idle gaps just past %.0f minutes (--cache for details)\n",
+			formatCurrency(expiry.ExtraCost), expiry.Rewrites, models.CacheTTL.Minutes())
+	}
 
 	if d.showCache {
 		t := table.NewWriter()
@@ -93,25 +422,168 @@ func (d *Display) showTokenSummary() {
 		t.AppendRow(table.Row{"Cache Hit Rate", fmt.Sprintf("%.1f%%", d.stats.GetCacheHitRate())})
 		t.AppendRow(table.Row{"Total Tokens", formatNumber(totalAllTokens)})
 
-		fmt.Println(t.Render())
+		fmt.Fprintln(d.out, t.Render())
+
+		d.showCacheROI()
+		d.showCacheExpiry()
 	}
-	fmt.Println()
+	fmt.Fprintln(d.out)
 }
 
-// showProjectCosts displays project cost breakdown
-func (d *Display) showProjectCosts() {
-	fmt.Printf("%s\n", text.Bold.Sprint("📁 Project Costs"))
+// showContextOverhead displays how much input re-sent earlier context, and
+// the sessions where that cost the most
+func (d *Display) showContextOverhead() {
+	total, sessions := d.stats.GetContextOverhead(d.rows())
+	if total.RepeatedTokens == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔁 Context Overhead"))
+	fmt.Fprintf(d.out, "%.0f%% of input tokens re-sent earlier context, costing %s\n",
+		total.ContextOverhead(), formatCurrency(total.RepeatedCost))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Session", "Project", "Messages", "Re-sent Tokens", "Overhead", "Re-sent Cost"})
+	for _, session := range sessions {
+		t.AppendRow(table.Row{
+			sessionLabel(session.SessionID, session.Title),
+			truncateString(session.Project, 30),
+			session.Messages,
+			formatTokensWithSuffix(session.RepeatedTokens),
+			fmt.Sprintf("%.0f%%", session.ContextOverhead()),
+			formatCurrency(session.RepeatedCost),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Every turn re-sends the conversation so far; /compact or a fresh session shrinks it.")
+	fmt.Fprintln(d.out)
+}
+
+// showLowYieldSessions displays the sessions that read the most input per
+// output token, if any crossed calculator.LowYieldRatio
+func (d *Display) showLowYieldSessions() {
+	sessions := d.stats.GetLowYieldSessions(d.rows())
+	if len(sessions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("⚖️  Low-Yield Sessions"))
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Session", "Project", "Messages", "Input", "Output", "In:Out", "Cost"})
+	for _, session := range sessions {
+		t.AppendRow(table.Row{
+			sessionLabel(session.SessionID, session.Title),
+			truncateString(session.Project, 30),
+			session.Messages,
+			formatTokensWithSuffix(session.InputTokens),
+			formatTokensWithSuffix(session.OutputTokens),
+			formatRatio(session.InputOutputRatio()),
+			formatCurrency(session.Cost),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintf(d.out, "Sessions reading %d or more input tokens per output token, cache included.\n", calculator.LowYieldRatio)
+	fmt.Fprintln(d.out)
+}
+
+// showImageCosts displays the input spend of turns with images by project,
+// if any had images
+func (d *Display) showImageCosts() {
+	costs := d.stats.GetImageCosts()
+	if len(costs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🖼️  Image Turns"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Project", "Images", "Turns", "Input Cost", "Of Project"})
+	for _, c := range costs {
+		share := "-"
+		if c.ProjectCost > 0 {
+			share = fmt.Sprintf("%.1f%%", c.InputCost/c.ProjectCost*100)
+		}
+		t.AppendRow(table.Row{truncateString(c.Project, 40), c.Images, c.Turns, formatCurrency(c.InputCost), share})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Input cost covers input, cache write, and cache read tokens of every response in a turn with images.")
+	fmt.Fprintln(d.out)
+}
+
+// showCacheROI displays per-project cache write costs against read savings
+func (d *Display) showCacheROI() {
+	rois := d.stats.GetCacheROI()
+	if len(rois) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "\n%s\n", text.Bold.Sprint("💾 Cache ROI by Project"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Project", "Write Cost", "Write Premium", "Read Savings", "Net", "ROI"})
+
+	for _, roi := range rois {
+		ratio := "N/A"
+		if roi.WritePremium > 0 {
+			ratio = fmt.Sprintf("%.1fx", roi.ROI)
+		}
+		t.AppendRow(table.Row{
+			truncateString(roi.Project, 40),
+			formatCurrency(roi.WriteCost),
+			formatCurrency(roi.WritePremium),
+			formatCurrency(roi.ReadSavings),
+			formatCurrency(roi.Net),
+			ratio,
+		})
+	}
+
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Write premium is what cache writes cost above plain input; "+
+		"ROI below 1.0x means caching cost more than it saved.")
+}
+
+// showCacheExpiry displays the cache writes forced by idle gaps just past
+// the cache's time to live, by how long the session sat idle
+func (d *Display) showCacheExpiry() {
+	expiry := d.analysis.CacheExpiry
+	if expiry.Rewrites == 0 {
+		return
+	}
 
-	limit := 10
-	if d.verbose {
-		limit = 0
+	fmt.Fprintf(d.out, "\n%s\n", text.Bold.Sprint("🧊 Cache Expiry"))
+
+	minutes := make([]int, 0, len(expiry.Gaps))
+	for minute := range expiry.Gaps {
+		minutes = append(minutes, minute)
+	}
+	sort.Ints(minutes)
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Idle", "Rewrites"})
+	for _, minute := range minutes {
+		t.AppendRow(table.Row{fmt.Sprintf("%d-%d min", minute, minute+1), expiry.Gaps[minute]})
 	}
+	t.AppendFooter(table.Row{"Total", expiry.Rewrites})
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintf(d.out, "These gaps wrote %s tokens to the cache again, %s more than reading them from a warm cache.\n",
+		formatNumber(expiry.Tokens), formatCurrency(expiry.ExtraCost))
+	fmt.Fprintf(d.out, "The cache expires %.0f minutes after its last use; replying sooner keeps it warm.\n", models.CacheTTL.Minutes())
+}
+
+// showProjectCosts displays project cost breakdown
+func (d *Display) showProjectCosts() {
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("📁 Project Costs"))
 
-	projects := d.stats.GetTopProjects(limit)
+	projects := d.stats.GetTopProjects(d.rows())
 
 	t := table.NewWriter()
 	t.SetStyle(table.StyleLight)
-	t.AppendHeader(table.Row{"Project", "Cost", "Sessions", "Tokens", "Days", "Avg Response"})
+	t.AppendHeader(table.Row{"Project", "Cost", "Sessions", "Tokens", "In:Out", "Days", "Avg Response", "P50", "P90", "Tok/Min"})
 
 	for _, proj := range projects {
 		// Calculate total tokens including cache
@@ -122,71 +594,635 @@ func (d *Display) showProjectCosts() {
 			formatCurrency(proj.Cost),
 			proj.Sessions,
 			formatTokensWithSuffix(totalTokens),
+			formatRatio(proj.InputOutputRatio()),
 			proj.ActiveDays,
 			formatDuration(proj.AvgResponseTime),
+			formatDuration(proj.P50ResponseTime),
+			formatDuration(proj.P90ResponseTime),
+			formatVelocity(proj.TokensPerMinute),
 		})
 	}
 
-	fmt.Println(t.Render())
+	fmt.Fprintln(d.out, t.Render())
 
-	if !d.verbose && len(d.analysis.Projects) > 10 {
-		fmt.Printf("\nShowing top 10 of %d projects. Use -v to see all.\n", len(d.analysis.Projects))
+	if len(projects) < len(d.analysis.Projects) {
+		fmt.Fprintf(d.out, "\nShowing top %d of %d projects. Use -v to see all.\n", len(projects), len(d.analysis.Projects))
 	}
-	fmt.Println()
+	fmt.Fprintln(d.out)
+}
+
+// showCommitCosts compares each git project's spend with its commits over
+// the same window. It's omitted unless SetCommits was called.
+func (d *Display) showCommitCosts() {
+	if d.commits == nil {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔨 Cost per Commit"))
+	costs := d.stats.GetCommitCosts(d.commits)
+	if len(costs) == 0 {
+		fmt.Fprintln(d.out, "No projects are git repositories.")
+		fmt.Fprintln(d.out)
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Project", "Cost", "Commits", "Cost/Commit", "Commits/$"})
+	if rows := d.rows(); rows > 0 && len(costs) > rows {
+		costs = costs[:rows]
+	}
+	for _, cost := range costs {
+		perCommit := "-"
+		if cost.Commits > 0 {
+			perCommit = formatCurrency(cost.CostPerCommit())
+		}
+		t.AppendRow(table.Row{
+			truncateString(cost.Project, 40),
+			formatCurrency(cost.Cost),
+			cost.Commits,
+			perCommit,
+			fmt.Sprintf("%.2f", cost.CommitsPerDollar()),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Commits on each repository's current branch, by its user.email when that's set.")
+	fmt.Fprintln(d.out)
+}
+
+// showLineCosts compares each project's spend with the lines its accepted
+// Edit, MultiEdit, Write, and NotebookEdit calls changed
+func (d *Display) showLineCosts() {
+	costs := d.stats.GetLineCosts()
+	if len(costs) == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("✏️ Lines Changed"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Project", "Cost", "Added", "Removed", "Cost/100 Lines"})
+	if rows := d.rows(); rows > 0 && len(costs) > rows {
+		costs = costs[:rows]
+	}
+	for _, cost := range costs {
+		t.AppendRow(table.Row{
+			truncateString(cost.Project, 40),
+			formatCurrency(cost.Cost),
+			formatNumber(cost.Lines.Added),
+			formatNumber(cost.Lines.Removed),
+			formatCurrency(cost.CostPer100Lines()),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Estimated from the edits' diffs, or their inputs when there's no diff. Writes count the whole file as added.")
+	fmt.Fprintln(d.out)
+}
+
+// showAccountCosts displays cost per login. It's omitted unless entries
+// name more than one account.
+func (d *Display) showAccountCosts() {
+	accounts := d.stats.GetAccounts()
+	if len(accounts) < 2 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("👤 Account Costs"))
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Account", "Cost", "Share", "Sessions", "Messages", "Tokens"})
+	for _, account := range accounts {
+		t.AppendRow(table.Row{
+			truncateString(account.Name, 40),
+			formatCurrency(account.Cost),
+			fmt.Sprintf("%.1f%%", account.Percentage),
+			account.Sessions,
+			account.Messages,
+			formatTokensWithSuffix(account.Tokens),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Use --account to report on one of them.")
+	fmt.Fprintln(d.out)
+}
+
+// showTagCosts displays costs grouped by session tag
+func (d *Display) showTagCosts() {
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🏷️  Tag Costs"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Tag", "Cost", "Sessions", "Tokens"})
+
+	tags := d.stats.GetTagSummaries()
+	for _, tag := range tags {
+		t.AppendRow(table.Row{
+			tag.Tag,
+			formatCurrency(tag.Cost),
+			tag.Sessions,
+			formatTokensWithSuffix(tag.Tokens),
+		})
+	}
+
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Sessions matching several tags count toward each of them.")
+	fmt.Fprintln(d.out)
 }
 
 // showActivityPatterns displays activity patterns
 func (d *Display) showActivityPatterns() {
-	fmt.Printf("%s\n", text.Bold.Sprint("⏰ Activity Patterns"))
-
-	// Hourly distribution
-	fmt.Println("\nHourly Distribution:")
-	hourly := d.stats.GetHourlyDistribution()
-	maxHourly := 0
-	for _, h := range hourly {
-		if h.Messages > maxHourly {
-			maxHourly = h.Messages
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("⏰ Activity Patterns"))
+
+	// Hourly and weekday distributions, averaged over the days each was
+	// active so that a few busy hours aren't outweighed by many quiet ones
+	fmt.Fprintln(d.out, "\nHourly Distribution, average cost per active day:")
+	if !d.showImage("hourly") {
+		hourly := d.stats.GetHourlyDistribution()
+		maxHourly := 0.0
+		for _, h := range hourly {
+			maxHourly = max(maxHourly, h.AvgCost())
+		}
+		for _, h := range hourly {
+			fmt.Fprintf(d.out, "%02d:00 %s %s\n", h.Hour, createBar(int(h.AvgCost()*100), int(maxHourly*100), 20), formatAverage(h.AvgCost(), h.Days))
 		}
 	}
 
-	for _, h := range hourly {
-		bar := createBar(h.Messages, maxHourly, 20)
-		fmt.Printf("%02d:00 %s %d\n", h.Hour, bar, h.Messages)
+	fmt.Fprintln(d.out, "\nWeekday Distribution, average cost per active day:")
+	weekdays := d.stats.GetWeekdayDistribution()
+	maxWeekday := 0.0
+	for _, w := range weekdays {
+		maxWeekday = max(maxWeekday, w.AvgCost())
+	}
+	for _, w := range weekdays {
+		fmt.Fprintf(d.out, "%s   %s %s\n", w.Weekday.String()[:3], createBar(int(w.AvgCost()*100), int(maxWeekday*100), 20), formatAverage(w.AvgCost(), w.Days))
 	}
 
 	// Daily trend sparkline
-	fmt.Println("\nDaily Activity:")
+	fmt.Fprintln(d.out, "\nDaily Activity:")
 	daily := d.stats.GetDailyTrend()
 	if len(daily) > 0 {
-		values := make([]int, len(daily))
-		for i, d := range daily {
-			values[i] = d.Messages
+		if !d.showImage("daily-cost") {
+			values := make([]int, len(daily))
+			for i, d := range daily {
+				values[i] = d.Messages
+			}
+			fmt.Fprintln(d.out, createSparkline(values))
+		}
+		d.showRollingAverages(daily)
+		d.showCacheHitRateTrend(daily)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// sessionsPerDayBuckets group days by their session count for the
+// histogram of sessions per day: 1, 2, 3, 4-5, 6-9, and 10 or more
+var sessionsPerDayBuckets = []int{1, 2, 3, 4, 6, 10}
+
+// showConcurrency reports how many sessions run at once and how many are
+// used each day
+func (d *Display) showConcurrency() {
+	c := d.stats.GetConcurrency()
+	if c.BusyMinutes == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🧵 Parallel Sessions"))
+	fmt.Fprintf(d.out, "%.1f sessions running on average while any was • 2 or more %.0f%% of the time\n",
+		c.AvgSessions, c.ParallelShare())
+	fmt.Fprintf(d.out, "Most at once: %d at %s\n", c.MaxSessions, c.MaxAt.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(d.out, "Sessions per active day: median %g, most %d on %s\n",
+		c.MedianPerDay(), c.SessionsPerDay[len(c.SessionsPerDay)-1], c.MaxDay)
+
+	counts := make([]int, len(sessionsPerDayBuckets))
+	for _, n := range c.SessionsPerDay {
+		i := sort.SearchInts(sessionsPerDayBuckets, n+1) - 1
+		counts[i]++
+	}
+	maxCount := slices.Max(counts)
+	fmt.Fprintln(d.out, "\nDays by sessions used:")
+	for i, low := range sessionsPerDayBuckets {
+		label := strconv.Itoa(low)
+		switch {
+		case i == len(sessionsPerDayBuckets)-1:
+			label += "+"
+		case sessionsPerDayBuckets[i+1]-1 > low:
+			label += fmt.Sprintf("-%d", sessionsPerDayBuckets[i+1]-1)
+		}
+		fmt.Fprintf(d.out, "%-5s %s %d\n", label, createBar(counts[i], maxCount, 20), counts[i])
+	}
+	fmt.Fprintln(d.out)
+}
+
+// showObservedHours reports when Claude was typically first and last used
+// each active day, as a record of working hours
+func (d *Display) showObservedHours() {
+	hours := d.stats.GetObservedHours()
+	if hours.Days == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🕰️ Observed Working Hours"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"", "P10", "Median", "P90"})
+	for _, row := range []struct {
+		name   string
+		p      calculator.ClockPercentiles
+		format func(time.Duration) string
+	}{
+		{"First activity", hours.First, formatClock},
+		{"Last activity", hours.Last, formatClock},
+		{"Span", hours.Span, formatSpan},
+	} {
+		t.AppendRow(table.Row{row.name, row.format(row.p.P10), row.format(row.p.Median), row.format(row.p.P90)})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	note := fmt.Sprintf("Over %d active days.", hours.Days)
+	if hours.Last.P90 >= 24*time.Hour {
+		note += " Times marked +1 are past midnight, counted toward the day before."
+	}
+	fmt.Fprintln(d.out, note)
+	fmt.Fprintln(d.out)
+}
+
+// showStreaks reports the current and longest runs of active days, and how
+// many days and months had activity
+func (d *Display) showStreaks() {
+	streaks := d.stats.GetStreaks(d.analysis.DayBoundary.Date(d.now()))
+	if streaks.ActiveDays == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔥 Streaks"))
+	longest := fmt.Sprintf("%s to %s", streaks.LongestStart, streaks.LongestEnd)
+	if streaks.Longest == 1 {
+		longest = streaks.LongestStart
+	}
+	fmt.Fprintf(d.out, "Current streak: %s • Longest: %s (%s)\n", formatDays(streaks.Current), formatDays(streaks.Longest), longest)
+	fmt.Fprintf(d.out, "%.1f active days per week • Active in %d of %d months: %s\n",
+		streaks.DaysPerWeek(), len(streaks.Months), streaks.MonthsSpanned, strings.Join(streaks.Months, ", "))
+	fmt.Fprintln(d.out, "Only the days analyzed count; use --days for a longer history.")
+	fmt.Fprintln(d.out)
+}
+
+// formatDays formats a number of days, such as "1 day" or "12 days"
+func formatDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// formatClock formats a time since midnight as "HH:MM", adding "+1" when
+// it falls on the next day
+func formatClock(d time.Duration) string {
+	s := fmt.Sprintf("%02d:%02d", int(d.Hours())%24, int(d.Minutes())%60)
+	if d >= 24*time.Hour {
+		s += " +1"
+	}
+	return s
+}
+
+// formatAverage describes an average cost per active day, such as "$1.25
+// over 4 days", or "-" without any
+func formatAverage(avg float64, days int) string {
+	switch days {
+	case 0:
+		return "-"
+	case 1:
+		return formatCurrency(avg) + " on 1 day"
+	}
+	return fmt.Sprintf("%s over %d days", formatCurrency(avg), days)
+}
+
+// showImage draws one of chart.Types inline when the terminal supports
+// graphics. It reports false, having printed nothing, when the caller should
+// fall back to text.
+func (d *Display) showImage(chartType string) bool {
+	if d.graphics == "" || d.graphics == termimg.None {
+		return false
+	}
+	var buf bytes.Buffer
+	if err := chart.Render(&buf, d.analysis, chartType, "png", chart.Options{Width: 800, Height: 320}); err != nil {
+		return false
+	}
+	return termimg.Write(d.out, d.graphics, buf.Bytes()) == nil
+}
+
+// showHourlyCosts displays cost and tokens for each hour of the day that had
+// activity in the hours range
+func (d *Display) showHourlyCosts() {
+	title := "🕘 Hourly Costs"
+	switch {
+	case !d.hoursFrom.IsZero() && !d.hoursTo.IsZero():
+		title += fmt.Sprintf(" (%s to %s)", d.hoursFrom.Format("2006-01-02"), d.hoursTo.AddDate(0, 0, -1).Format("2006-01-02"))
+	case !d.hoursFrom.IsZero():
+		title += fmt.Sprintf(" (since %s)", d.hoursFrom.Format("2006-01-02"))
+	case !d.hoursTo.IsZero():
+		title += fmt.Sprintf(" (through %s)", d.hoursTo.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint(title))
+
+	hours := d.stats.GetHourlyCosts(d.hoursFrom, d.hoursTo)
+	var total calculator.HourlyData
+	maxCost := 0.0
+	for _, h := range hours {
+		total.Messages += h.Messages
+		total.Cost += h.Cost
+		total.Tokens += h.Tokens
+		maxCost = max(maxCost, h.Cost)
+	}
+	if total.Messages == 0 {
+		fmt.Fprintln(d.out, "No activity in this range")
+		fmt.Fprintln(d.out)
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Hour", "Messages", "Cost", "Days", "Avg/Day", "Tokens", "Share", ""})
+	for _, h := range hours {
+		if h.Messages == 0 {
+			continue
+		}
+		share := 0.0
+		if total.Cost > 0 {
+			share = h.Cost / total.Cost * 100
+		}
+		t.AppendRow(table.Row{
+			fmt.Sprintf("%02d:00", h.Hour),
+			h.Messages,
+			formatCurrency(h.Cost),
+			h.Days,
+			formatCurrency(h.AvgCost()),
+			formatNumber(h.Tokens),
+			fmt.Sprintf("%.1f%%", share),
+			createBar(int(h.Cost*100), int(maxCost*100), 20),
+		})
+	}
+	t.AppendFooter(table.Row{"Total", total.Messages, formatCurrency(total.Cost), "", "", formatNumber(total.Tokens), "", ""})
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// showAnomalies lists the days and sessions whose cost spiked, if any
+func (d *Display) showAnomalies() {
+	days, sessions := d.stats.GetAnomalies(d.anomalies, d.rows())
+	if len(days)+len(sessions) == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🚨 Anomalies"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Day or Session", "Cost", "Trailing Median", "Spike"})
+	for _, a := range days {
+		t.AppendRow(table.Row{a.Date, "Day", formatCurrency(a.Cost), formatCurrency(a.Median), formatSpike(a)})
+	}
+	for _, a := range sessions {
+		t.AppendRow(table.Row{a.Date, fmt.Sprintf("Session %s (%s)", sessionLabel(a.SessionID, a.Title), a.Project),
+			formatCurrency(a.Cost), formatCurrency(a.Median), formatSpike(a)})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// formatSpike describes how far an anomaly is above normal, such as
+// "8.2x, 4.1σ"
+func formatSpike(a calculator.Anomaly) string {
+	var parts []string
+	if a.Multiple > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fx", a.Multiple))
+	}
+	if a.StdDevs > 0 {
+		parts = append(parts, fmt.Sprintf("%.1fσ", a.StdDevs))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showWorkSplit displays cost inside and outside working hours
+func (d *Display) showWorkSplit() {
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("💼 Work Hours ("+d.workHours.String()+")"))
+
+	split := d.stats.GetWorkSplit(d.workHours.Contains)
+	total := split.WorkCost + split.OffCost
+	share := func(cost float64) string {
+		if total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", cost/total*100)
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"", "Messages", "Cost", "Share"})
+	t.AppendRow(table.Row{"Work hours", split.WorkMessages, formatCurrency(split.WorkCost), share(split.WorkCost)})
+	t.AppendRow(table.Row{"Off hours", split.OffMessages, formatCurrency(split.OffCost), share(split.OffCost)})
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// showLimitHits displays how often usage limits and rate limits were hit.
+// It's omitted when there were none.
+func (d *Display) showLimitHits() {
+	hits := d.stats.GetLimitHits(d.blockLength, d.now())
+	if hits.UsageLimits == 0 && hits.RateLimits == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🚦 Limits"))
+	usageDays := 0
+	for _, day := range hits.Days {
+		if day.UsageLimits > 0 {
+			usageDays++
+		}
+	}
+	fmt.Fprintf(d.out, "Usage limit reached: %d times, on %d of %d active days and in %d of %d billing blocks\n",
+		hits.UsageLimits, usageDays, hits.ActiveDays, hits.BlocksHit, hits.Blocks)
+	fmt.Fprintf(d.out, "Rate limit errors: %d\n", hits.RateLimits)
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Usage Limits", "Rate Limits"})
+	for _, day := range hits.Days {
+		t.AppendRow(table.Row{day.Date, day.UsageLimits, day.RateLimits})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// showRollingAverages displays the 7-day average cost as a sparkline, which
+// is steadier than the day-to-day activity
+func (d *Display) showRollingAverages(daily []calculator.DailyData) {
+	// Scale to cents so the sparkline keeps precision for small days
+	values := make([]int, len(daily))
+	for i, day := range daily {
+		values[i] = int(day.Avg7 * 100)
+	}
+	latest := daily[len(daily)-1]
+	fmt.Fprintln(d.out, "\nDaily Cost, 7-day Average:")
+	fmt.Fprintf(d.out, "%s latest %s/day, 30-day %s/day\n", createSparkline(values),
+		formatCurrency(latest.Avg7), formatCurrency(latest.Avg30))
+}
+
+// showCacheHitRateTrend displays the daily cache hit rate sparkline and
+// flags a drop below the alert threshold
+func (d *Display) showCacheHitRateTrend(daily []calculator.DailyData) {
+	rates := make([]int, 0, len(daily))
+	minRate, latest := 100.0, 0.0
+	for _, day := range daily {
+		if !day.HasCacheData {
+			continue
+		}
+		// Scale to tenths of a percent so the sparkline keeps precision
+		rates = append(rates, int(day.CacheHitRate*10))
+		if day.CacheHitRate < minRate {
+			minRate = day.CacheHitRate
+		}
+		latest = day.CacheHitRate
+	}
+	if len(rates) == 0 {
+		return
+	}
+
+	fmt.Fprintln(d.out, "\nDaily Cache Hit Rate:")
+	fmt.Fprintf(d.out, "%s latest %.1f%%, min %.1f%%\n", createSparkline(rates), latest, minRate)
+
+	if d.cacheAlert > 0 {
+		if alert, day := d.stats.GetCacheHitRateAlert(d.cacheAlert); alert {
+			fmt.Fprintf(d.out, "%s cache hit rate on %s was %.1f%%, below the %.1f%% alert threshold\n",
+				text.FgYellow.Sprint("⚠"), day.Date, day.CacheHitRate, d.cacheAlert)
 		}
-		fmt.Println(createSparkline(values))
 	}
-	fmt.Println()
 }
 
 // showModelUsage displays model usage distribution
 func (d *Display) showModelUsage() {
-	fmt.Printf("%s\n", text.Bold.Sprint("🤖 Model Usage"))
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🤖 Model Usage"))
 
-	models := d.stats.GetModelDistribution()
+	models := d.stats.GetForegroundModelDistribution(d.rows())
 
 	t := table.NewWriter()
 	t.SetStyle(table.StyleLight)
-	t.AppendHeader(table.Row{"Model", "Count", "Percentage"})
+	t.AppendHeader(table.Row{"Model", "Count", "Percentage", "P50", "P90", "Tok/Min"})
 
 	for _, model := range models {
 		t.AppendRow(table.Row{
 			model.Model,
 			model.Count,
 			fmt.Sprintf("%.1f%%", model.Percentage),
+			formatDuration(model.P50ResponseTime),
+			formatDuration(model.P90ResponseTime),
+			formatVelocity(model.TokensPerMinute),
 		})
 	}
 
-	fmt.Println(t.Render())
-	fmt.Println()
+	fmt.Fprintln(d.out, t.Render())
+
+	background, subagents := d.stats.GetBackgroundTasks()
+	if background.Messages > 0 {
+		fmt.Fprintf(d.out, "Background Haiku tasks: %s calls, %s (%.1f%% of spend), not counted above\n",
+			formatNumber(background.Messages), formatCurrency(background.Cost), background.Share)
+	}
+	if subagents.Messages > 0 {
+		fmt.Fprintf(d.out, "Haiku subagents: %s responses, %s (%.1f%% of spend)\n",
+			formatNumber(subagents.Messages), formatCurrency(subagents.Cost), subagents.Share)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// showCategories displays cost by what the responses did: replies, tool
+// calls, thinking, subagent work, and housekeeping
+func (d *Display) showCategories() {
+	categories := d.stats.GetCategories()
+	if len(categories) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🧩 Cost by Response Type"))
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Type", "Messages", "Tokens", "Cost", "Share", ""})
+	for _, c := range categories {
+		t.AppendRow(table.Row{
+			c.Category,
+			c.Messages,
+			formatTokensWithSuffix(c.Tokens),
+			formatCurrency(c.Cost),
+			fmt.Sprintf("%.1f%%", c.Share),
+			createBar(int(c.Share*10), 1000, 20),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// showOpusDowngrades displays how much intended-Opus time ran on Sonnet
+// after Claude Code switched models mid-session. It's omitted when no
+// session switched.
+func (d *Display) showOpusDowngrades() {
+	days := d.stats.GetOpusDowngrades()
+	var total calculator.DowngradeDay
+	for _, day := range days {
+		total.OpusMinutes += day.OpusMinutes
+		total.DowngradedMinutes += day.DowngradedMinutes
+		total.DowngradedCost += day.DowngradedCost
+		total.Switches += day.Switches
+	}
+	if total.DowngradedMinutes == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔀 Opus Downgrades"))
+	fmt.Fprintf(d.out, "%s of %s meant for Opus (%.1f%%) ran on Sonnet after %d mid-session switches (%s)\n",
+		formatSpan(time.Duration(total.DowngradedMinutes)*time.Minute),
+		formatSpan(time.Duration(total.OpusMinutes+total.DowngradedMinutes)*time.Minute),
+		total.Share(), total.Switches, formatCurrency(total.DowngradedCost))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Opus", "On Sonnet", "Share", "Switches"})
+	for _, day := range days {
+		t.AppendRow(table.Row{
+			day.Date,
+			formatSpan(time.Duration(day.OpusMinutes) * time.Minute),
+			formatSpan(time.Duration(day.DowngradedMinutes) * time.Minute),
+			fmt.Sprintf("%.1f%%", day.Share()),
+			day.Switches,
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Sonnet responses after /model are treated as deliberate and not counted.")
+	fmt.Fprintln(d.out)
+}
+
+// showRightSizing displays short Opus sessions priced at cheaper models
+func (d *Display) showRightSizing() {
+	total, sessions := d.stats.GetRightSizing(d.rows())
+	if len(sessions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🎯 Model Right-Sizing"))
+	fmt.Fprintf(d.out, "Short Opus sessions cost %s; on Sonnet they'd have cost %s (save %s), on Haiku %s\n",
+		formatCurrency(total.OpusCost), formatCurrency(total.SonnetCost),
+		text.Bold.Sprint(formatCurrency(total.SonnetSavings())), formatCurrency(total.HaikuCost))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Session", "Project", "Prompts", "Output", "Opus", "As Sonnet", "As Haiku"})
+	for _, session := range sessions {
+		t.AppendRow(table.Row{
+			sessionLabel(session.SessionID, session.Title),
+			truncateString(session.Project, 30),
+			session.Prompts,
+			formatTokensWithSuffix(session.OutputTokens),
+			formatCurrency(session.OpusCost),
+			formatCurrency(session.SonnetCost),
+			formatCurrency(session.HaikuCost),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintf(d.out, "Sessions of up to %d prompts with at most %s output tokens each. Try /model sonnet for quick questions.\n",
+		calculator.SimplePrompts, formatTokensWithSuffix(calculator.SimpleOutputPerPrompt))
+	fmt.Fprintln(d.out)
 }
 
 // showToolUse displays tool usage statistics
@@ -195,38 +1231,253 @@ func (d *Display) showToolUse() {
 		return
 	}
 
-	fmt.Printf("%s\n", text.Bold.Sprint("🔧 Tool Use"))
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🔧 Tool Use"))
 
 	total := d.analysis.ToolUse.Accepted + d.analysis.ToolUse.Rejected
 	acceptRate := float64(d.analysis.ToolUse.Accepted) / float64(total) * 100
 
-	fmt.Printf("Accepted: %d (%.1f%%)\n", d.analysis.ToolUse.Accepted, acceptRate)
-	fmt.Printf("Rejected: %d (%.1f%%)\n", d.analysis.ToolUse.Rejected, 100-acceptRate)
-	fmt.Println()
+	fmt.Fprintf(d.out, "Accepted: %d (%.1f%%)\n", d.analysis.ToolUse.Accepted, acceptRate)
+	fmt.Fprintf(d.out, "Rejected: %d (%.1f%%)\n", d.analysis.ToolUse.Rejected, 100-acceptRate)
+	if cost := d.stats.GetRejectedCost(); cost > 0 {
+		fmt.Fprintf(d.out, "Spend on rejected work: %s (%.1f%% of spend)\n", formatCurrency(cost), cost/d.analysis.TotalCost*100)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// showInterruptions displays how often turns were interrupted or abandoned
+// and what they cost, by project. It's omitted when none were.
+func (d *Display) showInterruptions() {
+	total, projects := d.stats.GetInterruptions()
+	if len(projects) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("✋ Interrupted Turns"))
+	fmt.Fprintf(d.out, "%d of %d turns interrupted and %d abandoned (%.1f%%), %s spent on them (%.1f%% of spend)\n",
+		total.Interrupted, total.Turns, total.Abandoned, total.Rate(), formatCurrency(total.Cost), total.Share())
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Project", "Turns", "Interrupted", "Abandoned", "Rate", "Cost", "Share"})
+	for _, proj := range projects {
+		t.AppendRow(table.Row{
+			truncateString(proj.Project, 40),
+			proj.Turns,
+			proj.Interrupted,
+			proj.Abandoned,
+			fmt.Sprintf("%.1f%%", proj.Rate()),
+			formatCurrency(proj.Cost),
+			fmt.Sprintf("%.1f%%", proj.Share()),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Abandoned turns ended their session waiting on a tool call.")
+	fmt.Fprintln(d.out)
+}
+
+// showToolRejections displays rejections by tool, pattern, and project
+func (d *Display) showToolRejections() {
+	tools := d.stats.GetToolStats("")
+	if len(tools) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🚫 Tool Rejections"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Tool", "Uses", "Rejected", "Errors", "Rejection Rate", "Rejected Cost"})
+	for _, tool := range tools {
+		t.AppendRow(table.Row{
+			truncateString(tool.Tool, 30),
+			tool.Uses,
+			tool.Rejected,
+			tool.Errors,
+			fmt.Sprintf("%.1f%%", tool.RejectionRate),
+			formatCurrency(tool.RejectedCost),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+
+	if patterns := d.stats.GetRejectedPatterns(d.rows()); len(patterns) > 0 {
+		fmt.Fprintln(d.out, "\nMost rejected:")
+		for _, pattern := range patterns {
+			fmt.Fprintf(d.out, "  %-30s %d\n", truncateString(pattern.Pattern, 30), pattern.Count)
+		}
+	}
+
+	if projects := d.stats.GetProjectRejections(); len(projects) > 0 {
+		fmt.Fprintln(d.out, "\nBy project:")
+		pt := table.NewWriter()
+		pt.SetStyle(table.StyleLight)
+		pt.AppendHeader(table.Row{"Project", "Uses", "Rejected", "Rejection Rate", "Rejected Cost"})
+		for _, proj := range projects {
+			pt.AppendRow(table.Row{
+				truncateString(proj.Tool, 40),
+				proj.Uses,
+				proj.Rejected,
+				fmt.Sprintf("%.1f%%", proj.RejectionRate),
+				formatCurrency(proj.RejectedCost),
+			})
+		}
+		fmt.Fprintln(d.out, pt.Render())
+	}
+	fmt.Fprintln(d.out)
+}
+
+// showBashCommands displays Bash tool usage grouped by command
+func (d *Display) showBashCommands() {
+	commands := d.stats.GetBashCommands(d.rows())
+	if len(commands) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("🐚 Bash Commands"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Command", "Count", "Failed", "Rejected", "Failure Rate"})
+	for _, cmd := range commands {
+		t.AppendRow(table.Row{
+			truncateString(cmd.Command, 30),
+			cmd.Count,
+			cmd.Failures,
+			cmd.Rejected,
+			fmt.Sprintf("%.1f%%", cmd.FailureRate),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// showFileExtensions displays file tool activity by extension
+func (d *Display) showFileExtensions() {
+	extensions := d.stats.GetFileExtensions("", d.rows())
+	if len(extensions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("📝 Files by Extension"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Extension", "Edits", "Writes", "Reads", "% of Changes"})
+	for _, ext := range extensions {
+		t.AppendRow(table.Row{
+			ext.Extension,
+			ext.Edits,
+			ext.Writes,
+			ext.Reads,
+			fmt.Sprintf("%.1f%%", ext.ChangeShare),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+
+	if d.verbosity >= Verbose {
+		for _, proj := range d.stats.GetTopProjects(0) {
+			if mix := d.changeMix(proj.Name); mix != "" {
+				fmt.Fprintf(d.out, "%s: %s\n", truncateString(proj.Name, 40), mix)
+			}
+		}
+	}
+	fmt.Fprintln(d.out)
+}
+
+// changeMix summarizes a project's edits and writes by extension, e.g.
+// ".go 60%, .ts 20%, .md 20%"
+func (d *Display) changeMix(project string) string {
+	parts := []string{}
+	for _, ext := range d.stats.GetFileExtensions(project, 0) {
+		if ext.ChangeShare > 0 && len(parts) < 5 {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", ext.Extension, ext.ChangeShare))
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 // showResponseTimeStats displays response time statistics
 func (d *Display) showResponseTimeStats() {
 	stats := d.stats.GetResponseTimeStats()
-	if stats.Count == 0 {
+	skew := d.analysis.ClockSkew
+	if stats.Count == 0 && d.analysis.ResponseTimeOutliers == 0 && skew.Excluded == 0 && !d.analysis.MemoryLimited {
 		return
 	}
 
-	fmt.Printf("%s\n", text.Bold.Sprint("⏱️  Response Times"))
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("⏱️  Response Times"))
+	if d.analysis.MemoryLimited {
+		fmt.Fprintf(d.out, "Skipped to stay within --max-memory\n\n")
+		return
+	}
+
+	turnWall, turnModel := d.stats.GetTurnTimeStats()
 
 	t := table.NewWriter()
 	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"", "Response", "Turn (wall)", "Turn (model)"})
 
-	t.AppendRow(table.Row{"Min", formatSeconds(stats.Min)})
-	t.AppendRow(table.Row{"Average", formatSeconds(stats.Average)})
-	t.AppendRow(table.Row{"P50", formatSeconds(stats.P50)})
-	t.AppendRow(table.Row{"P90", formatSeconds(stats.P90)})
-	t.AppendRow(table.Row{"P95", formatSeconds(stats.P95)})
-	t.AppendRow(table.Row{"P99", formatSeconds(stats.P99)})
-	t.AppendRow(table.Row{"Max", formatSeconds(stats.Max)})
+	row := func(label string, value func(calculator.ResponseTimeStats) float64) {
+		t.AppendRow(table.Row{
+			label,
+			formatStat(stats, value),
+			formatStat(turnWall, value),
+			formatStat(turnModel, value),
+		})
+	}
+	row("Min", func(s calculator.ResponseTimeStats) float64 { return s.Min })
+	row("Average", func(s calculator.ResponseTimeStats) float64 { return s.Average })
+	row("P50", func(s calculator.ResponseTimeStats) float64 { return s.P50 })
+	row("P90", func(s calculator.ResponseTimeStats) float64 { return s.P90 })
+	row("P95", func(s calculator.ResponseTimeStats) float64 { return s.P95 })
+	row("P99", func(s calculator.ResponseTimeStats) float64 { return s.P99 })
+	row("Max", func(s calculator.ResponseTimeStats) float64 { return s.Max })
 
-	fmt.Println(t.Render())
-	fmt.Println()
+	if stats.Count > 0 {
+		fmt.Fprintln(d.out, t.Render())
+		fmt.Fprintln(d.out, "Turn (model) excludes local tool execution time "+
+			"("+formatSeconds(d.analysis.ToolExecutionTime.Seconds())+" total)")
+	}
+	if outliers := d.analysis.ResponseTimeOutliers; outliers > 0 {
+		fmt.Fprintf(d.out, "%d outliers excluded (outside --response-min/--response-max)\n", outliers)
+	}
+	switch {
+	case skew.Excluded > 0:
+		fmt.Fprintf(d.out, "%d times excluded (timestamps run backwards in %d sessions)\n", skew.Excluded, skew.Sessions)
+	case skew.Sessions > 0:
+		fmt.Fprintf(d.out, "Timestamps run backwards in %d sessions (clock changes or sleep); --exclude-skewed drops the times affected\n", skew.Sessions)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// showTokenVelocity displays output token throughput
+func (d *Display) showTokenVelocity() {
+	if len(d.analysis.ActiveMinutes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("⚡ Token Velocity"))
+	fmt.Fprintf(d.out, "%s output tokens/active minute over %d active minutes\n",
+		formatVelocity(d.stats.GetTokenVelocity()), len(d.analysis.ActiveMinutes))
+
+	// Only sessions with sustained activity are meaningful here
+	slowest := d.stats.GetSessionVelocities(10, 5)
+	if len(slowest) > 0 {
+		fmt.Fprintln(d.out, "\nSlowest sessions:")
+
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"Session", "Project", "Cost", "Minutes", "Tok/Min"})
+		for _, session := range slowest {
+			t.AppendRow(table.Row{
+				shortID(session.SessionID),
+				truncateString(session.Project, 30),
+				formatCurrency(session.Cost),
+				session.ActiveMinutes,
+				formatVelocity(session.TokensPerMinute),
+			})
+		}
+		fmt.Fprintln(d.out, t.Render())
+	}
+	fmt.Fprintln(d.out)
 }
 
 // Helper functions
@@ -248,6 +1499,14 @@ func formatNumber(n int) string {
 	return result
 }
 
+// formatRatio formats input per output token, as in "142:1"
+func formatRatio(ratio float64) string {
+	if ratio == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f:1", ratio)
+}
+
 func formatTokensWithSuffix(n int) string {
 	if n >= 1_000_000 {
 		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
@@ -257,6 +1516,15 @@ func formatTokensWithSuffix(n int) string {
 	return fmt.Sprintf("%d", n)
 }
 
+func formatBytes(n int) string {
+	if n >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	} else if n >= 1<<10 {
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
 func formatDuration(d time.Duration) string {
 	if d == 0 {
 		return "N/A"
@@ -267,6 +1535,13 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.1fs", d.Seconds())
 }
 
+func formatVelocity(tokensPerMinute float64) string {
+	if tokensPerMinute == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.0f", tokensPerMinute)
+}
+
 func formatSeconds(s float64) string {
 	if s < 1 {
 		return fmt.Sprintf("%.0fms", s*1000)
@@ -274,6 +1549,28 @@ func formatSeconds(s float64) string {
 	return fmt.Sprintf("%.1fs", s)
 }
 
+func formatStat(stats calculator.ResponseTimeStats, value func(calculator.ResponseTimeStats) float64) string {
+	if stats.Count == 0 {
+		return "N/A"
+	}
+	return formatSeconds(value(stats))
+}
+
+func shortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// sessionLabel names a session by its title, or its short ID without one
+func sessionLabel(id, title string) string {
+	if title == "" {
+		return shortID(id)
+	}
+	return truncateString(title, 40)
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,113 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// budgetWarnRatio is the share of the --fail-over cap at which a warning is
+// emitted before the build actually fails
+const budgetWarnRatio = 0.8
+
+// ShowGitHubActions prints GitHub Actions workflow commands for the analysis
+// and appends a Markdown job summary to summaryPath, normally
+// $GITHUB_STEP_SUMMARY. An empty summaryPath skips the job summary.
+// failOver is the spend cap; zero disables the budget annotations.
+func (d *Display) ShowGitHubActions(failOver float64, summaryPath string) error {
+	cost := d.analysis.TotalCost
+	fmt.Fprintln(d.out, workflowCommand("notice", "Claude Code spend",
+		fmt.Sprintf("%s API value across %d sessions", formatCurrency(cost), len(d.analysis.Sessions))))
+
+	if failOver > 0 {
+		switch {
+		case cost > failOver:
+			fmt.Fprintln(d.out, workflowCommand("error", "Claude Code budget exceeded",
+				fmt.Sprintf("%s API value exceeds the %s cap", formatCurrency(cost), formatCurrency(failOver))))
+		case cost > failOver*budgetWarnRatio:
+			fmt.Fprintln(d.out, workflowCommand("warning", "Claude Code budget",
+				fmt.Sprintf("%s API value is %.0f%% of the %s cap", formatCurrency(cost), cost/failOver*100, formatCurrency(failOver))))
+		}
+	}
+
+	if d.cacheAlert > 0 {
+		if alert, day := d.stats.GetCacheHitRateAlert(d.cacheAlert); alert {
+			fmt.Fprintln(d.out, workflowCommand("warning", "Claude Code cache hit rate",
+				fmt.Sprintf("%s cache hit rate was %.1f%%, below %.1f%%", day.Date, day.CacheHitRate, d.cacheAlert)))
+		}
+	}
+
+	if summaryPath == "" {
+		return nil
+	}
+	f, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+	defer f.Close()
+
+	d.writeJobSummary(f, failOver)
+	return f.Close()
+}
+
+// writeJobSummary writes the Markdown job summary
+func (d *Display) writeJobSummary(w io.Writer, failOver float64) {
+	cost := d.analysis.TotalCost
+
+	fmt.Fprintf(w, "## Claude Code spend\n\n")
+	fmt.Fprintf(w, "| | |\n|---|---:|\n")
+	fmt.Fprintf(w, "| API value | %s |\n", formatCurrency(cost))
+	fmt.Fprintf(w, "| Sessions | %d |\n", len(d.analysis.Sessions))
+	fmt.Fprintf(w, "| Tokens | %s |\n", formatTokensWithSuffix(d.analysis.TotalInputTokens+d.analysis.TotalOutputTokens+
+		d.analysis.TotalCacheRead+d.analysis.TotalCacheWrite))
+	if failOver > 0 {
+		status := "✅"
+		if cost > failOver {
+			status = "❌"
+		} else if cost > failOver*budgetWarnRatio {
+			status = "⚠️"
+		}
+		fmt.Fprintf(w, "| Budget | %s %s (%.0f%%) |\n", status, formatCurrency(failOver), cost/failOver*100)
+	}
+
+	projects := d.stats.GetTopProjects(d.top)
+	if len(projects) > 0 {
+		fmt.Fprintf(w, "\n### Top projects\n\n")
+		fmt.Fprintf(w, "| Project | Cost | Sessions |\n|---|---:|---:|\n")
+		for _, proj := range projects {
+			fmt.Fprintf(w, "| %s | %s | %d |\n", markdownEscape(proj.Name), formatCurrency(proj.Cost), proj.Sessions)
+		}
+	}
+
+	models := d.stats.GetForegroundModelDistribution(d.top)
+	if len(models) > 0 {
+		fmt.Fprintf(w, "\n### Models\n\n")
+		fmt.Fprintf(w, "| Model | Messages | Share |\n|---|---:|---:|\n")
+		for _, model := range models {
+			fmt.Fprintf(w, "| %s | %d | %.1f%% |\n", markdownEscape(model.Model), model.Count, model.Percentage)
+		}
+	}
+
+	fmt.Fprintf(w, "\n_API value, not actual subscription cost._\n\n")
+}
+
+// workflowCommand formats a GitHub Actions annotation such as ::error
+func workflowCommand(command, title, message string) string {
+	return fmt.Sprintf("::%s title=%s::%s", command, escapeProperty(title), escapeData(message))
+}
+
+// escapeData escapes a workflow command message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// markdownEscape keeps table cells intact
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
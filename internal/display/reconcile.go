@@ -0,0 +1,74 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/photostructure/go-claude-costs/internal/invoice"
+)
+
+// ShowReconciliation writes local API value against invoice line items to
+// w
+func ShowReconciliation(w io.Writer, report *invoice.Report) {
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint("🧾 Invoice Reconciliation"))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Model", "Local", "Invoice", "Diff", "Diff %", ""})
+
+	for _, day := range report.Days {
+		flag := ""
+		if day.Diverges {
+			flag = text.FgYellow.Sprint("⚠")
+		}
+		t.AppendRow(table.Row{
+			day.Date,
+			text.Bold.Sprint("total"),
+			formatCurrency(day.Local),
+			formatCurrency(day.Invoice),
+			formatSignedCurrency(day.Diff()),
+			formatPercentDiff(day.DiffPercent()),
+			flag,
+		})
+		for _, model := range day.Models {
+			t.AppendRow(table.Row{
+				"",
+				model.Model,
+				formatCurrency(model.Local),
+				formatCurrency(model.Invoice),
+				formatSignedCurrency(model.Diff()),
+				"",
+				"",
+			})
+		}
+	}
+
+	t.AppendFooter(table.Row{
+		"Total", "",
+		formatCurrency(report.LocalTotal),
+		formatCurrency(report.InvoiceTotal),
+		formatSignedCurrency(report.LocalTotal - report.InvoiceTotal),
+		"", "",
+	})
+
+	fmt.Fprintln(w, t.Render())
+	fmt.Fprintf(w, "%d of %d days diverge by more than %.1f%%\n", report.Divergent, len(report.Days), report.Tolerance)
+	fmt.Fprintln(w, "Note: local days use your time zone; run with TZ=UTC to match invoice days exactly")
+}
+
+func formatSignedCurrency(amount float64) string {
+	if amount < 0 {
+		return "-" + formatCurrency(-amount)
+	}
+	return "+" + formatCurrency(amount)
+}
+
+func formatPercentDiff(percent float64) string {
+	if math.IsInf(percent, 0) {
+		return "N/A"
+	}
+	return fmt.Sprintf("%+.1f%%", percent)
+}
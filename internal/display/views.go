@@ -0,0 +1,531 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/invoice"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/live"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/tokens"
+)
+
+// ShowDaily displays cost and activity per day
+func (d *Display) ShowDaily() {
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint("📅 Daily Costs"))
+
+	daily := d.stats.GetDailyTrend()
+	maxCost := 0.0
+	for _, day := range daily {
+		if day.Cost > maxCost {
+			maxCost = day.Cost
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Messages", "Cost", "7d Avg", "30d Avg", "Cache Hit", "Models", ""})
+
+	totalMessages := 0
+	for _, day := range daily {
+		hitRate := "-"
+		if day.HasCacheData {
+			hitRate = fmt.Sprintf("%.1f%%", day.CacheHitRate)
+		}
+		// Scale to cents so the bar keeps precision for small days
+		t.AppendRow(table.Row{
+			day.Date,
+			day.Messages,
+			formatCurrency(day.Cost),
+			formatCurrency(day.Avg7),
+			formatCurrency(day.Avg30),
+			hitRate,
+			formatDayModels(day.Models),
+			createBar(int(day.Cost*100), int(maxCost*100), 20),
+		})
+		totalMessages += day.Messages
+	}
+	t.AppendFooter(table.Row{"Total", totalMessages, formatCurrency(d.analysis.TotalCost), "", "", "", "", ""})
+
+	fmt.Fprintln(d.out, t.Render())
+	d.showCacheHitRateTrend(daily)
+	fmt.Fprintln(d.out)
+}
+
+// formatDayModels summarizes a day's model mix by share of cost, such as
+// "opus-4 75%, sonnet-4 25%", naming at most two models
+func formatDayModels(dayModels []calculator.DailyModel) string {
+	const shown = 2
+	parts := make([]string, 0, shown+1)
+	for i, m := range dayModels {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("+%d", len(dayModels)-shown))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", invoice.NormalizeModel(m.Model), m.Percentage))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShowProjects displays the project cost table, or tag costs when grouping
+// by tag
+func (d *Display) ShowProjects() {
+	if d.groupBy == "tag" {
+		d.showTagCosts()
+		return
+	}
+	d.showProjectCosts()
+}
+
+// ShowSessions displays the top sessions sorted by sortBy ("time" or
+// "cost"), keeping only those matching search when it's set
+func (d *Display) ShowSessions(sortBy string, search string) {
+	title := "💬 Sessions"
+	if search != "" {
+		title += fmt.Sprintf(" matching %q", search)
+	}
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint(title))
+
+	var sessions []calculator.SessionSummary
+	var matchCost float64
+	for _, session := range d.stats.GetSessions(sortBy, 0) {
+		if session.Matches(search) {
+			sessions = append(sessions, session)
+			matchCost += session.Cost
+		}
+	}
+	matches := len(sessions)
+	if matches == 0 && search != "" {
+		fmt.Fprintf(d.out, "No sessions match\n\n")
+		return
+	}
+	if limit := d.rows(); limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Session", "Project", "Started", "Duration", "Active", "Messages", "Tokens", "Cost", "Overhead"})
+
+	for _, session := range sessions {
+		t.AppendRow(table.Row{
+			sessionLabel(session.SessionID, session.Title),
+			truncateString(session.Project, 30),
+			session.Start.Local().Format("2006-01-02 15:04"),
+			formatSpan(session.End.Sub(session.Start)),
+			fmt.Sprintf("%dm", session.ActiveMinutes),
+			session.Messages,
+			formatTokensWithSuffix(session.Tokens),
+			formatCurrency(session.Cost),
+			fmt.Sprintf("%.0f%%", session.ContextOverhead()),
+		})
+	}
+
+	if search != "" {
+		t.AppendFooter(table.Row{fmt.Sprintf("%d matching", matches), "", "", "", "", "", "", formatCurrency(matchCost), ""})
+	}
+
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out, "Overhead is the share of input tokens that re-sent earlier context.")
+	if len(sessions) < matches {
+		fmt.Fprintf(d.out, "\nShowing %d of %d sessions. Use -v to see all.\n", len(sessions), matches)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// ShowBlocks displays billing blocks of the given length
+func (d *Display) ShowBlocks(length time.Duration, now time.Time) {
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint(fmt.Sprintf("🧱 %s Billing Blocks", formatSpan(length))))
+
+	blocks := d.stats.GetBlocks(length, now)
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Start", "End", "Messages", "Tokens", "Cost", "Limits", ""})
+
+	for _, block := range blocks {
+		status := ""
+		if block.Active {
+			status = text.FgGreen.Sprintf("active, %s left", formatSpan(block.End.Sub(now)))
+		}
+		t.AppendRow(table.Row{
+			block.Start.Local().Format("2006-01-02 15:04"),
+			block.End.Local().Format("15:04"),
+			block.Messages,
+			formatTokensWithSuffix(block.Tokens),
+			formatCurrency(block.Cost),
+			formatLimitHits(block),
+			status,
+		})
+	}
+
+	fmt.Fprintln(d.out, t.Render())
+	fmt.Fprintln(d.out)
+}
+
+// ShowBlockHistory writes the most expensive finished blocks on record and
+// how the typical block measures against each plan to w
+func ShowBlockHistory(w io.Writer, length time.Duration, summary calculator.BlockSummary, fits []limits.Fit) {
+	if summary.Blocks == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint(fmt.Sprintf("🏔️  Peak %s Blocks (%d finished since %s)",
+		formatSpan(length), summary.Blocks, summary.Since.Local().Format("2006-01-02"))))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Start", "Messages", "Tokens", "Cost"})
+	for _, block := range summary.Peaks {
+		t.AppendRow(table.Row{
+			block.Start.Local().Format("2006-01-02 15:04"),
+			block.Messages,
+			formatTokensWithSuffix(block.Tokens),
+			formatCurrency(block.Cost),
+		})
+	}
+	fmt.Fprintln(w, t.Render())
+	fmt.Fprintf(w, "Typical block: %s median, %s at the 90th percentile; %s tokens excluding cache median, %s at the 90th\n\n",
+		formatCurrency(summary.MedianCost), formatCurrency(summary.P90Cost),
+		formatTokensWithSuffix(summary.MedianTokens), formatTokensWithSuffix(summary.P90Tokens))
+
+	t = table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Plan", "Median", "90th %ile", "Peak", "Blocks Over"})
+	for _, fit := range fits {
+		over := fmt.Sprintf("%d (%.0f%%)", fit.Over, float64(fit.Over)/float64(summary.Blocks)*100)
+		if fit.Over > 0 {
+			over = text.FgYellow.Sprint(over)
+		}
+		t.AppendRow(table.Row{
+			fit.Name,
+			fmt.Sprintf("%.0f%%", fit.Median),
+			fmt.Sprintf("%.0f%%", fit.P90),
+			fmt.Sprintf("%.0f%%", fit.Peak),
+			over,
+		})
+	}
+	fmt.Fprintln(w, t.Render())
+	fmt.Fprintln(w, "Usage is each block's share of the plan's tightest limit. Built-in limits are estimates.")
+	fmt.Fprintln(w)
+}
+
+// ShowPlans replays the finished blocks of the given length against each
+// plan and shows how often each would have throttled
+func (d *Display) ShowPlans(length time.Duration, now time.Time, custom map[string]limits.Plan) {
+	blocks := d.stats.GetBlocks(length, now)
+	sims := limits.Simulate(blocks, d.analysis.Minutes, custom)
+	if len(sims) == 0 || sims[0].Blocks == 0 {
+		fmt.Fprintln(d.out, "No finished billing blocks to simulate")
+		return
+	}
+
+	fmt.Fprintf(d.out, "%s\n", text.Bold.Sprint(fmt.Sprintf("📋 Plan Simulation (%d finished %s blocks)", sims[0].Blocks, formatSpan(length))))
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Plan", "Price", "Limits per Block", "Throttled", "Refused", "Refused Value", "Locked Out"})
+	for _, sim := range sims {
+		price := "-"
+		if sim.Plan.Price > 0 {
+			price = formatCurrency(sim.Plan.Price) + "/mo"
+		}
+		throttled := fmt.Sprintf("%d (%.0f%%)", sim.Throttled, float64(sim.Throttled)/float64(sim.Blocks)*100)
+		if sim.Throttled > 0 {
+			throttled = text.FgYellow.Sprint(throttled)
+		}
+		t.AppendRow(table.Row{
+			sim.Name,
+			price,
+			formatPlanLimits(sim.Plan),
+			throttled,
+			fmt.Sprintf("%s msgs", formatNumber(sim.BlockedMessages)),
+			formatCurrency(sim.BlockedCost),
+			formatSpan(sim.LockedOut),
+		})
+	}
+	fmt.Fprintln(d.out, t.Render())
+
+	if best, ok := limits.Recommend(sims); ok {
+		if best.Throttled == 0 {
+			fmt.Fprintf(d.out, "%s %s (%s/month) is the cheapest plan that would never have throttled you\n",
+				text.FgGreen.Sprint("✓"), text.Bold.Sprint(best.Name), formatCurrency(best.Plan.Price))
+		} else {
+			fmt.Fprintf(d.out, "%s Every plan would have throttled you; %s (%s/month) the least, in %d of %d blocks\n",
+				text.FgYellow.Sprint("⚠"), text.Bold.Sprint(best.Name), formatCurrency(best.Plan.Price), best.Throttled, best.Blocks)
+		}
+	}
+	fmt.Fprintln(d.out, "Limits and prices are estimates; set your own under [plans.NAME] in the config file.")
+	fmt.Fprintln(d.out)
+}
+
+// formatPlanLimits lists a plan's limits, as in "250 msgs, 19.0K tok, $18.00"
+func formatPlanLimits(plan limits.Plan) string {
+	var parts []string
+	if plan.Messages > 0 {
+		parts = append(parts, formatNumber(plan.Messages)+" msgs")
+	}
+	if plan.Tokens > 0 {
+		parts = append(parts, formatTokensWithSuffix(plan.Tokens)+" tok")
+	}
+	if plan.Cost > 0 {
+		parts = append(parts, formatCurrency(plan.Cost))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShowWatch displays the compact live view used by the watch command
+func (d *Display) ShowWatch(now time.Time, blockLength time.Duration) {
+	today := d.stats.GetDailySummary(d.analysis.DayBoundary.Date(now))
+
+	fmt.Fprintf(d.out, "%s  %s\n\n", text.Bold.Sprint("claude-costs watch"), now.Format("15:04:05"))
+	fmt.Fprintf(d.out, "💰 Today: %s API value, %d messages\n", text.Bold.Sprint(formatCurrency(today.Cost)), today.Messages)
+	if today.TopProject != "" {
+		fmt.Fprintf(d.out, "📁 Top project: %s (%s)\n", today.TopProject, formatCurrency(today.TopProjectCost))
+	}
+
+	if current := activeBlock(d.stats.GetBlocks(blockLength, now)); current != nil {
+		estimate := limits.New(*current, d.plan, now)
+		fmt.Fprintf(d.out, "🧱 Block: started %s, %s elapsed, resets in %s at %s\n",
+			current.Start.Local().Format("15:04"),
+			formatSpan(now.Sub(current.Start)),
+			text.Bold.Sprint(formatSpan(estimate.Remaining)),
+			estimate.Reset.Local().Format("15:04"))
+		fmt.Fprintf(d.out, "   %d messages, %s tokens (%s excluding cache), %s\n",
+			current.Messages,
+			formatTokensWithSuffix(current.Tokens),
+			formatTokensWithSuffix(current.InputOutputTokens),
+			formatCurrency(current.Cost))
+		if hits := formatLimitHits(*current); hits != "" {
+			fmt.Fprintf(d.out, "   Limits hit this block: %s\n", hits)
+		}
+		d.showLimitEstimate(estimate)
+	} else {
+		fmt.Fprintln(d.out, "🧱 Block: no active block")
+	}
+
+	if d.cacheAlert > 0 {
+		if alert, day := d.stats.GetCacheHitRateAlert(d.cacheAlert); alert {
+			fmt.Fprintf(d.out, "%s cache hit rate on %s was %.1f%%, below the %.1f%% alert threshold\n",
+				text.FgYellow.Sprint("⚠"), day.Date, day.CacheHitRate, d.cacheAlert)
+		}
+	}
+}
+
+// ShowOneLine prints today's cost, the cost over the analyzed days, and the
+// active block's cost on one plain line for shell prompts, as in
+// "today $3.82 | 30d $141.20 | block $0.95". The block is left out when
+// none is active.
+func (d *Display) ShowOneLine(now time.Time, days int, blockLength time.Duration) {
+	today := d.stats.GetDailySummary(d.analysis.DayBoundary.Date(now))
+	line := fmt.Sprintf("today %s | %dd %s", formatCurrency(today.Cost), days, formatCurrency(d.analysis.TotalCost))
+	if current := activeBlock(d.stats.GetBlocks(blockLength, now)); current != nil {
+		line += " | block " + formatCurrency(current.Cost)
+	}
+	fmt.Fprintln(d.out, line)
+}
+
+// ShowTop writes the sessions active within window to w, as the top
+// command redraws it
+func ShowTop(w io.Writer, now time.Time, window time.Duration, sessions []live.Session) {
+	fmt.Fprintf(w, "%s  %s\n\n", text.Bold.Sprint("claude-costs top"), now.Format("15:04:05"))
+	if len(sessions) == 0 {
+		fmt.Fprintf(w, "No sessions active in the last %s\n", formatSpan(window))
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Session", "Project", "Model", "Last Active", "Messages", "Tokens", "Cost", "Tok/Min"})
+
+	var totalCost, totalRate float64
+	for _, session := range sessions {
+		t.AppendRow(table.Row{
+			sessionLabel(session.ID, session.Title),
+			truncateString(session.Project, 30),
+			session.Model,
+			formatAgo(now.Sub(session.LastActive)),
+			session.Messages,
+			formatTokensWithSuffix(session.Tokens),
+			formatCurrency(session.Cost),
+			formatVelocity(session.TokensPerMinute),
+		})
+		totalCost += session.Cost
+		totalRate += session.TokensPerMinute
+	}
+	t.AppendFooter(table.Row{
+		fmt.Sprintf("%d active", len(sessions)), "", "", "", "", "",
+		formatCurrency(totalCost),
+		formatVelocity(totalRate),
+	})
+
+	fmt.Fprintln(w, t.Render())
+	fmt.Fprintf(w, "Sessions with activity in the last %s; Tok/Min is output tokens over the last %s\n",
+		formatSpan(window), formatSpan(live.RateWindow))
+}
+
+// ShowEstimate writes the cost of tokens at tier to w, line by line
+func ShowEstimate(w io.Writer, model string, tier models.PricingTier, tokens models.TokenCounts) {
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint("🧮 Estimate for "+model))
+
+	// Single requests cost fractions of a cent, so show more places than
+	// formatCurrency
+	dollars := func(amount float64) string { return fmt.Sprintf("$%.4f", amount) }
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Tokens", "Count", "Per Million", "Cost"})
+	for _, line := range []struct {
+		name  string
+		count int
+		rate  float64
+	}{
+		{"Input", tokens.Input, tier.Input},
+		{"Output", tokens.Output, tier.Output},
+		{"Cache write", tokens.CacheWrite, tier.CacheWrite},
+		{"Cache read", tokens.CacheRead, tier.CacheRead},
+	} {
+		t.AppendRow(table.Row{line.name, formatNumber(line.count), formatCurrency(line.rate), dollars(float64(line.count) * line.rate / 1_000_000)})
+	}
+	t.AppendFooter(table.Row{"Total", formatNumber(tokens.Input + tokens.Output + tokens.CacheWrite + tokens.CacheRead), "", dollars(tokens.CostAt(tier))})
+	fmt.Fprintln(w, t.Render())
+}
+
+// ShowTokens writes the estimated tokens of files, up to limit of them
+// (zero shows all), and what sending them all as context costs at each
+// model's rates, to w
+func ShowTokens(w io.Writer, files []tokens.File, limit int) {
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint("🔢 Estimated Tokens"))
+	total, totalBytes := 0, 0
+	for _, f := range files {
+		total += f.Tokens
+		totalBytes += f.Bytes
+	}
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"File", "Size", "Tokens"})
+	shown := files
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, f := range shown {
+		t.AppendRow(table.Row{f.Path, formatBytes(f.Bytes), formatNumber(f.Tokens)})
+	}
+	if len(shown) < len(files) {
+		t.AppendRow(table.Row{fmt.Sprintf("... %d more", len(files)-len(shown)), "", ""})
+	}
+	t.AppendFooter(table.Row{fmt.Sprintf("%d files", len(files)), formatBytes(totalBytes), formatNumber(total)})
+	fmt.Fprintln(w, t.Render())
+	if total > tokens.ContextWindow {
+		fmt.Fprintf(w, "%s\n", text.FgYellow.Sprintf("⚠️  More than the %s-token context window", formatNumber(tokens.ContextWindow)))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%s\n", text.Bold.Sprint("💵 Cost as Context"))
+	ids := make([]string, 0, len(models.ModelPricing))
+	for id := range models.ModelPricing {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := models.ModelPricing[ids[i]], models.ModelPricing[ids[j]]
+		if a.Input != b.Input {
+			return a.Input > b.Input
+		}
+		return ids[i] > ids[j]
+	})
+
+	t = table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Model", "Uncached", "Cache Write", "Cache Read"})
+	for _, id := range ids {
+		tier := models.ModelPricing[id]
+		t.AppendRow(table.Row{id, formatCurrency(tier.Cost(total, 0, 0, 0)), formatCurrency(tier.Cost(0, 0, total, 0)),
+			formatCurrency(tier.Cost(0, 0, 0, total))})
+	}
+	fmt.Fprintln(w, t.Render())
+	fmt.Fprintln(w, "Uncached is each request without caching; with caching, the first request pays Cache Write and later ones Cache Read")
+}
+
+// formatAgo formats how long ago something happened, as in "45s ago" or
+// "3m ago"
+func formatAgo(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds ago", int(max(d, 0).Seconds()))
+	}
+	return formatSpan(d) + " ago"
+}
+
+// formatLimitHits summarizes a block's usage limit and rate limit events
+func formatLimitHits(block calculator.Block) string {
+	var parts []string
+	if block.UsageLimits > 0 {
+		parts = append(parts, text.FgRed.Sprintf("%d usage", block.UsageLimits))
+	}
+	if block.RateLimits > 0 {
+		parts = append(parts, text.FgYellow.Sprintf("%d rate", block.RateLimits))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showLimitEstimate shows the active block's usage against each plan limit
+func (d *Display) showLimitEstimate(estimate limits.Estimate) {
+	if len(estimate.Usage) == 0 {
+		return
+	}
+	fmt.Fprintf(d.out, "\n📊 %s plan limits (estimated):\n", d.planName)
+	for _, usage := range estimate.Usage {
+		bar := createBar(min(int(usage.Percent()), 100), 100, 20)
+		percent := fmt.Sprintf("%3.0f%%", usage.Percent())
+		status := "on pace to stay under"
+		switch {
+		case usage.Used >= usage.Limit:
+			percent = text.FgRed.Sprint(percent)
+			status = text.FgRed.Sprint("limit reached")
+		case !usage.LimitAt.IsZero():
+			percent = text.FgYellow.Sprint(percent)
+			status = text.FgYellow.Sprintf("at this rate reached at %s", usage.LimitAt.Local().Format("15:04"))
+		}
+
+		fmt.Fprintf(d.out, "   %-9s %s %s  %s / %s  %s\n",
+			usage.Name, bar, percent, formatLimitValue(usage.Name, usage.Used), formatLimitValue(usage.Name, usage.Limit), status)
+	}
+}
+
+// formatLimitValue formats an amount of the named limit
+func formatLimitValue(name string, value float64) string {
+	switch name {
+	case "cost":
+		return formatCurrency(value)
+	case "tokens":
+		return formatTokensWithSuffix(int(value))
+	}
+	return formatNumber(int(value))
+}
+
+// activeBlock returns the block containing the current time, if any
+func activeBlock(blocks []calculator.Block) *calculator.Block {
+	if n := len(blocks); n > 0 && blocks[n-1].Active {
+		return &blocks[n-1]
+	}
+	return nil
+}
+
+// formatSpan formats a wall-clock span such as "2h05m" or "45m"
+func formatSpan(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	s := fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	return strings.TrimSuffix(s, "00m")
+}
@@ -0,0 +1,114 @@
+package display_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/invoice"
+	"github.com/photostructure/go-claude-costs/internal/limits"
+	"github.com/photostructure/go-claude-costs/internal/live"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/tokens"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/testutil"
+)
+
+// deterministic pins the local time zone to UTC and turns colors off until
+// the test ends
+func deterministic(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+	text.DisableColors()
+	t.Cleanup(text.EnableColors)
+}
+
+func TestShowBlockHistory_Golden(t *testing.T) {
+	deterministic(t)
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	summary := calculator.BlockSummary{
+		Blocks: 4,
+		Since:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Peaks: []calculator.Block{
+			{Start: start, Messages: 120, Tokens: 2_400_000, Cost: 18.5},
+			{Start: start.Add(-48 * time.Hour), Messages: 80, Tokens: 1_100_000, Cost: 9.25},
+		},
+		MedianCost:   6,
+		P90Cost:      15,
+		MedianTokens: 40_000,
+		P90Tokens:    90_000,
+	}
+	fits := []limits.Fit{
+		{Name: "pro", Median: 60, P90: 140, Peak: 210, Over: 2},
+		{Name: "max20", Median: 4, P90: 9, Peak: 14},
+	}
+
+	var buf bytes.Buffer
+	display.ShowBlockHistory(&buf, 5*time.Hour, summary, fits)
+	testutil.Golden(t, "testdata/blocks.golden", buf.String())
+}
+
+func TestShowTop_Golden(t *testing.T) {
+	deterministic(t)
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+	sessions := []live.Session{
+		{ID: "0f8e2c1a-session", Project: "src/api", Title: "Fix the flaky test", Model: "claude-sonnet-4-20250514",
+			LastActive: now.Add(-45 * time.Second), Messages: 12, Cost: 1.75, Tokens: 350_000, TokensPerMinute: 420},
+		{ID: "9b7d3e4f-session", Project: "src/web", Model: "claude-opus-4-20250514",
+			LastActive: now.Add(-3 * time.Minute), Messages: 4, Cost: 2.5, Tokens: 90_000, TokensPerMinute: 80},
+	}
+
+	var buf bytes.Buffer
+	display.ShowTop(&buf, now, 10*time.Minute, sessions)
+	display.ShowTop(&buf, now, 10*time.Minute, nil)
+	testutil.Golden(t, "testdata/top.golden", buf.String())
+}
+
+func TestShowEstimate_Golden(t *testing.T) {
+	deterministic(t)
+	model := "claude-sonnet-4-20250514"
+	counts := models.TokenCounts{Input: 1200, Output: 800, CacheWrite: 5000, CacheRead: 20_000}
+
+	var buf bytes.Buffer
+	display.ShowEstimate(&buf, model, models.ModelPricing[model], counts)
+	testutil.Golden(t, "testdata/estimate.golden", buf.String())
+}
+
+func TestShowTokens_Golden(t *testing.T) {
+	deterministic(t)
+	files := []tokens.File{
+		{Path: "README.md", Bytes: 48_000, Tokens: 12_000},
+		{Path: "main.go", Bytes: 8_000, Tokens: 2_400},
+		{Path: "go.mod", Bytes: 400, Tokens: 150},
+	}
+
+	var buf bytes.Buffer
+	display.ShowTokens(&buf, files, 2)
+	testutil.Golden(t, "testdata/tokens.golden", buf.String())
+}
+
+func TestShowReconciliation_Golden(t *testing.T) {
+	deterministic(t)
+	report := &invoice.Report{
+		Days: []invoice.DayReconciliation{
+			{Date: "2026-10-14", Local: 12, Invoice: 12.1, Models: []invoice.ModelReconciliation{
+				{Model: "claude-sonnet-4-20250514", Local: 12, Invoice: 12.1},
+			}},
+			{Date: "2026-10-15", Local: 20, Invoice: 15, Diverges: true, Models: []invoice.ModelReconciliation{
+				{Model: "claude-opus-4-20250514", Local: 14, Invoice: 10},
+				{Model: "claude-sonnet-4-20250514", Local: 6, Invoice: 5},
+			}},
+		},
+		Tolerance:    5,
+		LocalTotal:   32,
+		InvoiceTotal: 27.1,
+		Divergent:    1,
+	}
+
+	var buf bytes.Buffer
+	display.ShowReconciliation(&buf, report)
+	testutil.Golden(t, "testdata/reconciliation.golden", buf.String())
+}
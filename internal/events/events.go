@@ -0,0 +1,138 @@
+// Package events forwards usage events to a file, a named pipe, an HTTP
+// endpoint, a Kafka topic, or a NATS JetStream subject.
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Event is one priced assistant response
+type Event struct {
+	Time             time.Time `json:"time"`
+	ID               string    `json:"id"`
+	SessionID        string    `json:"session_id"`
+	Project          string    `json:"project"`
+	Model            string    `json:"model"`
+	Cost             float64   `json:"cost"` // USD
+	InputTokens      int       `json:"input_tokens"`
+	OutputTokens     int       `json:"output_tokens"`
+	CacheReadTokens  int       `json:"cache_read_tokens"`
+	CacheWriteTokens int       `json:"cache_write_tokens"`
+}
+
+// Sink receives batches of events. Write returns nil only once the whole
+// batch is delivered; after an error it may be sent again whole, so events
+// are delivered at least once and consumers can drop repeats by ID.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Open creates a sink for target:
+//
+//   - kafka://BROKER[,BROKER...]/TOPIC produces each event to a Kafka topic
+//   - nats://HOST[:PORT]/SUBJECT publishes each event to a NATS JetStream
+//     subject
+//   - an http:// or https:// URL is POSTed each batch as application/x-ndjson
+//   - anything else is a file appended to, one event per line. A named pipe
+//     must exist, and opening it waits for a reader.
+func Open(target string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(target, "kafka://"):
+		return openKafka(target)
+	case strings.HasPrefix(target, "nats://"):
+		return openNATS(target)
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return &httpSink{url: target, client: &http.Client{Timeout: sendTimeout}}, nil
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event sink: %w", err)
+	}
+	return &fileSink{f: f}, nil
+}
+
+// encodeLines encodes events as JSON Lines
+func encodeLines(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// fileSink appends to a file or named pipe
+type fileSink struct {
+	f *os.File
+}
+
+// Write implements Sink
+func (s *fileSink) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	data, err := encodeLines(events)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(data); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// sendTimeout bounds each delivery to a remote sink
+const sendTimeout = 30 * time.Second
+
+// httpSink POSTs each batch to a URL
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+// Write implements Sink
+func (s *httpSink) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	data, err := encodeLines(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post events: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to post events: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *httpSink) Close() error {
+	return nil
+}
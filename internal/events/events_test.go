@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var batch = []Event{
+	{Time: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), ID: "a", Model: "claude-opus-4", Cost: 0.5, OutputTokens: 10},
+	{Time: time.Date(2026, 10, 1, 9, 1, 0, 0, time.UTC), ID: "b", Model: "claude-sonnet-4", Cost: 0.25},
+}
+
+func TestSink_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	for i := 0; i < 2; i++ {
+		sink, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Write(context.Background(), batch[i:i+1]); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"id":"a"`) || !strings.Contains(lines[1], `"id":"b"`) {
+		t.Errorf("file = %q, want events a then b appended", data)
+	}
+	if want := `"time":"2026-10-01T09:00:00Z","id":"a","session_id":"","project":"","model":"claude-opus-4","cost":0.5`; !strings.Contains(lines[0], want) {
+		t.Errorf("line = %s, want it to contain %s", lines[0], want)
+	}
+}
+
+func TestSink_HTTP(t *testing.T) {
+	var got, contentType string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, contentType = string(body), r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := Open(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/x-ndjson" || strings.Count(got, "\n") != 2 {
+		t.Errorf("posted %s %q, want two JSON lines", contentType, got)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := sink.Write(context.Background(), batch); err == nil {
+		t.Error("Write succeeded when the endpoint failed")
+	}
+}
+
+func TestOpen_InvalidTargets(t *testing.T) {
+	for _, target := range []string{"kafka://localhost:9092", "kafka:///usage", "nats://localhost:4222", "nats:///usage"} {
+		if _, err := Open(target); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("Open(%q) = %v, want an invalid target error", target, err)
+		}
+	}
+
+	sink, err := Open("kafka://broker1:9092,broker2:9092/claude.usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if w := sink.(*kafkaSink).writer; w.Topic != "claude.usage" || w.Addr.String() != "broker1:9092,broker2:9092" {
+		t.Errorf("writer = %s %s, want both brokers and claude.usage", w.Addr, w.Topic)
+	}
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSink produces each event as a message keyed by session, so a
+// session's events stay in order on one partition
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+// openKafka parses kafka://BROKER[,BROKER...]/TOPIC
+func openKafka(target string) (*kafkaSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka target %q: %w", target, err)
+	}
+	topic := strings.Trim(u.Path, "/")
+	if u.Host == "" || topic == "" {
+		return nil, fmt.Errorf("invalid Kafka target %q: use kafka://BROKER[,BROKER...]/TOPIC", target)
+	}
+	return &kafkaSink{writer: &kafka.Writer{
+		Addr:     kafka.TCP(strings.Split(u.Host, ",")...),
+		Topic:    topic,
+		Balancer: &kafka.Hash{},
+		// Every in-sync replica has the batch before Write returns
+		RequiredAcks: kafka.RequireAll,
+		// Write hands over whole batches, so there's nothing to wait for
+		BatchTimeout: 10 * time.Millisecond,
+	}}, nil
+}
+
+// Write implements Sink
+func (s *kafkaSink) Write(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	messages := make([]kafka.Message, len(events))
+	for i, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages[i] = kafka.Message{Key: []byte(event.SessionID), Value: value}
+	}
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to produce events: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
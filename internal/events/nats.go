@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsBatch is the most events published before waiting for their
+// acknowledgements, within JetStream's default limit on pending publishes
+const natsBatch = 1000
+
+// natsSink publishes each event to JetStream, which acknowledges each once
+// a stream has stored it. Events carry their ID as the message ID, so the
+// stream drops repeats within its duplicate window.
+type natsSink struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+// openNATS parses nats://HOST[:PORT]/SUBJECT and connects. A stream must
+// already capture the subject.
+func openNATS(target string) (*natsSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS target %q: %w", target, err)
+	}
+	subject := strings.Trim(u.Path, "/")
+	if u.Host == "" || subject == "" {
+		return nil, fmt.Errorf("invalid NATS target %q: use nats://HOST[:PORT]/SUBJECT", target)
+	}
+	u.Path = ""
+
+	// Reconnecting forever lets the daemon ride out server restarts; until
+	// then publishes fail and their events are sent again
+	conn, err := nats.Connect(u.String(), nats.Name("claude-costs"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn, jetstream.WithPublishAsyncTimeout(sendTimeout))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return &natsSink{conn: conn, js: js, subject: subject}, nil
+}
+
+// Write implements Sink
+func (s *natsSink) Write(ctx context.Context, events []Event) error {
+	for len(events) > 0 {
+		n := min(len(events), natsBatch)
+		if err := s.publish(ctx, events[:n]); err != nil {
+			return err
+		}
+		events = events[n:]
+	}
+	return nil
+}
+
+// publish sends events without waiting, then waits for every
+// acknowledgement
+func (s *natsSink) publish(ctx context.Context, events []Event) error {
+	acks := make([]jetstream.PubAckFuture, 0, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		ack, err := s.js.PublishAsync(s.subject, data, jetstream.WithMsgID(event.ID))
+		if err != nil {
+			return fmt.Errorf("failed to publish events: %w", err)
+		}
+		acks = append(acks, ack)
+	}
+	for _, ack := range acks {
+		select {
+		case <-ack.Ok():
+		case err := <-ack.Err():
+			return fmt.Errorf("failed to publish events: %w", err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *natsSink) Close() error {
+	return s.conn.Drain()
+}
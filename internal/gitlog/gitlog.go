@@ -0,0 +1,65 @@
+// Package gitlog lists the commits made in a project directory, for
+// comparing spend with output.
+package gitlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrNotRepo is returned for directories that aren't in a git work tree
+var ErrNotRepo = errors.New("not a git repository")
+
+// Commits returns the hashes of the non-merge commits on the current branch
+// of dir's repository that touched dir and were committed from since until
+// until. When the repository sets user.email, only that author's commits
+// are listed.
+func Commits(ctx context.Context, dir string, since, until time.Time) ([]string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, ErrNotRepo
+	}
+	if _, err := git(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, err
+		}
+		return nil, ErrNotRepo
+	}
+
+	args := []string{"log", "--no-merges", "--format=%H",
+		"--since=" + since.Format(time.RFC3339), "--until=" + until.Format(time.RFC3339)}
+	// An unset user.email makes git config exit 1
+	if email, err := git(ctx, dir, "config", "user.email"); err == nil && email != "" {
+		args = append(args, "--author=<"+email+">")
+	}
+	out, err := git(ctx, dir, append(args, "--", ".")...)
+	if err != nil {
+		// A repository without commits has no current branch to log
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// git runs git in dir and returns its trimmed output
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
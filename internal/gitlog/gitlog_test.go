@@ -0,0 +1,65 @@
+package gitlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	repo := t.TempDir()
+	run := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(date, email, file string) {
+		if err := os.WriteFile(filepath.Join(repo, file), []byte(date+email), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run(nil, "add", ".")
+		run([]string{"GIT_COMMITTER_DATE=" + date, "GIT_AUTHOR_DATE=" + date, "GIT_AUTHOR_EMAIL=" + email, "GIT_AUTHOR_NAME=a", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=" + email},
+			"commit", "-q", "-m", date)
+	}
+
+	run(nil, "init", "-q")
+	ctx := context.Background()
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 14)
+	if hashes, err := Commits(ctx, repo, since, until); err != nil || len(hashes) != 0 {
+		t.Errorf("empty repository = %v, %v; want no commits", hashes, err)
+	}
+
+	run(nil, "config", "user.email", "me@example.com")
+	if err := os.Mkdir(filepath.Join(repo, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	commit("2026-09-20T10:00:00Z", "me@example.com", "a")     // Before the window
+	commit("2026-10-02T10:00:00Z", "me@example.com", "a")     // Counted
+	commit("2026-10-03T10:00:00Z", "me@example.com", "sub/b") // Counted, in sub
+	commit("2026-10-04T10:00:00Z", "you@example.com", "a")    // Someone else's
+
+	if hashes, err := Commits(ctx, repo, since, until); err != nil || len(hashes) != 2 {
+		t.Errorf("repository = %v, %v; want 2 commits", hashes, err)
+	}
+	if hashes, err := Commits(ctx, filepath.Join(repo, "sub"), since, until); err != nil || len(hashes) != 1 {
+		t.Errorf("subdirectory = %v, %v; want the 1 commit touching it", hashes, err)
+	}
+
+	if _, err := Commits(ctx, t.TempDir(), since, until); !errors.Is(err, ErrNotRepo) {
+		t.Errorf("plain directory error = %v, want ErrNotRepo", err)
+	}
+	if _, err := Commits(ctx, filepath.Join(repo, "missing"), since, until); !errors.Is(err, ErrNotRepo) {
+		t.Errorf("missing directory error = %v, want ErrNotRepo", err)
+	}
+}
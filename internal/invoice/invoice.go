@@ -0,0 +1,278 @@
+package invoice
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// UnknownModel groups costs that can't be attributed to a model, such as
+// legacy costUSD entries or invoice rows without a model column
+const UnknownModel = "(unknown)"
+
+// LineItem is one invoice row, summed per day and model
+type LineItem struct {
+	Date  string // YYYY-MM-DD
+	Model string // Normalized model family, see NormalizeModel
+	Cost  float64
+}
+
+// Column names recognized in invoice CSV headers, checked in order
+var (
+	dateColumns  = []string{"usage_date_utc", "usage_date", "date", "day"}
+	modelColumns = []string{"model", "model_name", "product"}
+	costColumns  = []string{"cost_usd", "cost", "amount_usd", "amount", "total"}
+)
+
+// Parse reads an Anthropic usage/cost CSV export. Columns are located by
+// header name, so extra columns (workspace, API key, token type) are summed
+// together per day and model.
+func Parse(r io.Reader) ([]LineItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invoice header: %w", err)
+	}
+
+	dateCol := findColumn(header, dateColumns)
+	modelCol := findColumn(header, modelColumns)
+	costCol := findColumn(header, costColumns)
+	if dateCol < 0 || costCol < 0 {
+		return nil, errors.New("invoice CSV needs a date column and a cost column")
+	}
+
+	totals := make(map[LineItem]float64)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invoice line %d: %w", line, err)
+		}
+		if len(record) <= dateCol || len(record) <= costCol {
+			continue
+		}
+
+		date, err := parseDate(record[dateCol])
+		if err != nil {
+			return nil, fmt.Errorf("invoice line %d: %w", line, err)
+		}
+		cost, err := parseAmount(record[costCol])
+		if err != nil {
+			return nil, fmt.Errorf("invoice line %d: %w", line, err)
+		}
+
+		model := UnknownModel
+		if modelCol >= 0 && modelCol < len(record) && strings.TrimSpace(record[modelCol]) != "" {
+			model = NormalizeModel(record[modelCol])
+		}
+
+		totals[LineItem{Date: date, Model: model}] += cost
+	}
+
+	items := make([]LineItem, 0, len(totals))
+	for key, cost := range totals {
+		key.Cost = cost
+		items = append(items, key)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Date != items[j].Date {
+			return items[i].Date < items[j].Date
+		}
+		return items[i].Model < items[j].Model
+	})
+
+	return items, nil
+}
+
+// findColumn returns the index of the first header matching one of names
+func findColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, col := range header {
+			normalized := strings.ToLower(strings.TrimSpace(col))
+			normalized = strings.NewReplacer(" ", "_", "(", "", ")", "").Replace(normalized)
+			if normalized == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseDate accepts dates and timestamps and returns YYYY-MM-DD
+func parseDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "01/02/2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", s)
+}
+
+// parseAmount parses a currency amount such as "$1,234.56"
+func parseAmount(s string) (float64, error) {
+	cleaned := strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	if cleaned == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
+
+var (
+	familyPattern  = regexp.MustCompile(`opus|sonnet|haiku`)
+	versionPattern = regexp.MustCompile(`\d+(?:[.-]\d)?`)
+	datePattern    = regexp.MustCompile(`\d{8}`)
+)
+
+// NormalizeModel maps model identifiers and display names to a comparable
+// family key, so "claude-sonnet-4-20250514" and "Claude Sonnet 4" both become
+// "sonnet-4", and "claude-3-5-haiku-20241022" becomes "haiku-3.5".
+func NormalizeModel(name string) string {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	family := familyPattern.FindString(lower)
+	if family == "" {
+		return lower
+	}
+
+	// Drop date stamps so they aren't mistaken for versions
+	withoutDates := datePattern.ReplaceAllString(lower, "")
+	version := versionPattern.FindString(withoutDates)
+	version = strings.ReplaceAll(version, "-", ".")
+	if version == "" {
+		return family
+	}
+	return family + "-" + version
+}
+
+// ModelReconciliation compares local and invoiced cost for one model on one day
+type ModelReconciliation struct {
+	Model   string
+	Local   float64
+	Invoice float64
+}
+
+// Diff returns local minus invoice
+func (m ModelReconciliation) Diff() float64 {
+	return m.Local - m.Invoice
+}
+
+// DayReconciliation compares local and invoiced cost for one day
+type DayReconciliation struct {
+	Date     string
+	Models   []ModelReconciliation
+	Local    float64
+	Invoice  float64
+	Diverges bool // Difference exceeds the tolerance
+}
+
+// Diff returns local minus invoice
+func (d DayReconciliation) Diff() float64 {
+	return d.Local - d.Invoice
+}
+
+// DiffPercent returns the difference as a percentage of the invoiced amount
+func (d DayReconciliation) DiffPercent() float64 {
+	if d.Invoice == 0 {
+		if d.Local == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return d.Diff() / d.Invoice * 100
+}
+
+// Report is the result of reconciling an analysis against an invoice
+type Report struct {
+	Days         []DayReconciliation
+	Tolerance    float64 // Percentage
+	LocalTotal   float64
+	InvoiceTotal float64
+	Divergent    int
+}
+
+// minDivergence is the absolute difference below which a day never counts as
+// divergent, so rounding on tiny days doesn't raise flags
+const minDivergence = 0.01
+
+// Reconcile compares local daily costs in analysis with invoice line items.
+// Days diverge when local and invoiced cost differ by more than tolerance
+// percent of the invoiced amount.
+func Reconcile(analysis *models.CostAnalysis, items []LineItem, tolerance float64) *Report {
+	type key struct{ date, model string }
+	local := make(map[key]float64)
+	invoiced := make(map[key]float64)
+	dates := make(map[string]bool)
+
+	for date, day := range analysis.DailyActivity {
+		attributed := 0.0
+		for model, cost := range day.ModelCosts {
+			local[key{date, NormalizeModel(model)}] += cost
+			attributed += cost
+		}
+		if rest := day.Cost - attributed; rest > 1e-9 {
+			local[key{date, UnknownModel}] += rest
+		}
+		dates[date] = true
+	}
+	for _, item := range items {
+		invoiced[key{item.Date, item.Model}] += item.Cost
+		dates[item.Date] = true
+	}
+
+	report := &Report{Tolerance: tolerance}
+	byDate := make(map[string]*DayReconciliation)
+	for date := range dates {
+		byDate[date] = &DayReconciliation{Date: date}
+	}
+
+	modelKeys := make(map[key]bool)
+	for k := range local {
+		modelKeys[k] = true
+	}
+	for k := range invoiced {
+		modelKeys[k] = true
+	}
+	for k := range modelKeys {
+		day := byDate[k.date]
+		day.Models = append(day.Models, ModelReconciliation{
+			Model:   k.model,
+			Local:   local[k],
+			Invoice: invoiced[k],
+		})
+		day.Local += local[k]
+		day.Invoice += invoiced[k]
+	}
+
+	for _, day := range byDate {
+		sort.Slice(day.Models, func(i, j int) bool { return day.Models[i].Model < day.Models[j].Model })
+		diff := math.Abs(day.Diff())
+		day.Diverges = diff >= minDivergence && diff > day.Invoice*tolerance/100
+		if day.Diverges {
+			report.Divergent++
+		}
+		report.LocalTotal += day.Local
+		report.InvoiceTotal += day.Invoice
+		report.Days = append(report.Days, *day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Date < report.Days[j].Date })
+
+	return report
+}
@@ -0,0 +1,92 @@
+package invoice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func TestNormalizeModel(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4-20250514":   "sonnet-4",
+		"Claude Sonnet 4":            "sonnet-4",
+		"claude-opus-4-20250514":     "opus-4",
+		"claude-3-5-haiku-20241022":  "haiku-3.5",
+		"Claude Haiku 3.5":           "haiku-3.5",
+		"claude-3-opus-20240229":     "opus-3",
+		"text-embedding-thing":       "text-embedding-thing",
+		"claude-3-5-sonnet-20240620": "sonnet-3.5",
+	}
+	for name, want := range tests {
+		if got := NormalizeModel(name); got != want {
+			t.Errorf("NormalizeModel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	csv := `usage_date_utc,model,workspace,token_type,cost_usd
+2025-06-13,Claude Sonnet 4,Default,input,"$1.50"
+2025-06-13,Claude Sonnet 4,Default,output,2.50
+2025-06-13,Claude Opus 4,Default,output,10.00
+2025-06-14T00:00:00Z,Claude Sonnet 4,Default,input,"1,000.00"
+`
+	items, err := Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []LineItem{
+		{Date: "2025-06-13", Model: "opus-4", Cost: 10},
+		{Date: "2025-06-13", Model: "sonnet-4", Cost: 4},
+		{Date: "2025-06-14", Model: "sonnet-4", Cost: 1000},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+
+	if _, err := Parse(strings.NewReader("foo,bar\n1,2\n")); err == nil {
+		t.Error("expected error for CSV without date and cost columns")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	analysis := &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {Cost: 4.0, ModelCosts: map[string]float64{"claude-sonnet-4-20250514": 4.0}},
+			"2025-06-14": {Cost: 9.0, ModelCosts: map[string]float64{"claude-sonnet-4-20250514": 8.0}},
+		},
+	}
+	items := []LineItem{
+		{Date: "2025-06-13", Model: "sonnet-4", Cost: 4.1},
+		{Date: "2025-06-14", Model: "sonnet-4", Cost: 6.0},
+		{Date: "2025-06-15", Model: "opus-4", Cost: 2.0},
+	}
+
+	report := Reconcile(analysis, items, 5)
+
+	if len(report.Days) != 3 {
+		t.Fatalf("got %d days, want 3", len(report.Days))
+	}
+	if report.Days[0].Diverges {
+		t.Error("2025-06-13 is within 5% and shouldn't diverge")
+	}
+	if !report.Days[1].Diverges || !report.Days[2].Diverges {
+		t.Error("2025-06-14 and 2025-06-15 should diverge")
+	}
+	if report.Divergent != 2 {
+		t.Errorf("Divergent = %d, want 2", report.Divergent)
+	}
+
+	// The legacy costUSD remainder is reported separately
+	day := report.Days[1]
+	if len(day.Models) != 2 || day.Models[0].Model != UnknownModel || day.Models[0].Local != 1.0 {
+		t.Errorf("2025-06-14 models = %+v, want unknown remainder of 1.0", day.Models)
+	}
+}
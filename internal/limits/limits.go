@@ -0,0 +1,197 @@
+// Package limits estimates how close a billing block is to the rate limits
+// of a Claude subscription plan.
+package limits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+)
+
+// Plan is the usage allowed per billing block. A zero limit is not checked.
+type Plan struct {
+	Tokens   int     `toml:"tokens"`   // Input and output tokens
+	Messages int     `toml:"messages"` // Assistant messages
+	Cost     float64 `toml:"cost"`     // API-equivalent dollars
+	Price    float64 `toml:"price"`    // Subscription price in USD per month
+}
+
+// Plans are the built-in plans. Anthropic doesn't publish exact limits, so
+// these are community estimates; override them in the config file.
+var Plans = map[string]Plan{
+	"pro":   {Tokens: 19_000, Messages: 250, Cost: 18, Price: 20},
+	"max5":  {Tokens: 88_000, Messages: 1_000, Cost: 35, Price: 100},
+	"max20": {Tokens: 220_000, Messages: 2_000, Cost: 140, Price: 200},
+}
+
+// Lookup returns the named plan. Plans in custom take precedence over the
+// built-in ones.
+func Lookup(name string, custom map[string]Plan) (Plan, error) {
+	if plan, ok := custom[name]; ok {
+		return plan, nil
+	}
+	if plan, ok := Plans[name]; ok {
+		return plan, nil
+	}
+
+	names := make([]string, 0, len(Plans)+len(custom))
+	for n := range Plans {
+		names = append(names, n)
+	}
+	for n := range custom {
+		if _, ok := Plans[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return Plan{}, fmt.Errorf("unknown plan %q: use one of %s", name, strings.Join(names, ", "))
+}
+
+// Usage is one limit of a plan measured against a block
+type Usage struct {
+	Name      string // "tokens", "messages", or "cost"
+	Used      float64
+	Limit     float64
+	Projected float64   // Usage at the end of the block at the current rate
+	LimitAt   time.Time // When the current rate reaches the limit; zero if not within the block
+}
+
+// Percent returns Used as a percentage of Limit
+func (u Usage) Percent() float64 {
+	return u.Used / u.Limit * 100
+}
+
+// Estimate is a block's usage against each limit of a plan
+type Estimate struct {
+	Reset     time.Time     // When the block ends and the limits reset
+	Remaining time.Duration // Time until Reset
+	Usage     []Usage
+}
+
+// New estimates block's usage against plan at now. Rates are averaged over
+// the time since the block started.
+func New(block calculator.Block, plan Plan, now time.Time) Estimate {
+	elapsed := now.Sub(block.Start)
+	if elapsed < time.Minute {
+		elapsed = time.Minute
+	}
+	remaining := block.End.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	estimate := Estimate{Reset: block.End, Remaining: remaining}
+	limits := []struct {
+		name  string
+		used  float64
+		limit float64
+	}{
+		{"tokens", float64(block.InputOutputTokens), float64(plan.Tokens)},
+		{"messages", float64(block.Messages), float64(plan.Messages)},
+		{"cost", block.Cost, plan.Cost},
+	}
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+		rate := l.used / elapsed.Seconds()
+		usage := Usage{
+			Name:      l.name,
+			Used:      l.used,
+			Limit:     l.limit,
+			Projected: l.used + rate*remaining.Seconds(),
+		}
+		switch {
+		case l.used >= l.limit:
+			usage.LimitAt = block.LastActivity
+		case rate > 0 && usage.Projected >= l.limit:
+			usage.LimitAt = now.Add(time.Duration((l.limit - l.used) / rate * float64(time.Second)))
+		}
+		estimate.Usage = append(estimate.Usage, usage)
+	}
+	return estimate
+}
+
+// Fit is how a history of blocks measures against a plan. Each block's
+// usage is its highest percentage of any of the plan's limits.
+type Fit struct {
+	Name   string
+	Plan   Plan
+	Median float64 // Percent of the plan used by the typical block
+	P90    float64
+	Peak   float64
+	Over   int // Blocks that reached a limit
+}
+
+// Fits measures blocks against the built-in plans and those in custom,
+// smallest plan first
+func Fits(blocks []calculator.Block, custom map[string]Plan) []Fit {
+	var fits []Fit
+	for _, named := range allPlans(custom) {
+		fit := Fit{Name: named.name, Plan: named.plan}
+		usage := make([]float64, 0, len(blocks))
+		for _, block := range blocks {
+			used := named.plan.used(block.InputOutputTokens, block.Messages, block.Cost)
+			if used >= 100 {
+				fit.Over++
+			}
+			usage = append(usage, used)
+		}
+		sort.Float64s(usage)
+		fit.Median = calculator.Percentile(usage, 50)
+		fit.P90 = calculator.Percentile(usage, 90)
+		if len(usage) > 0 {
+			fit.Peak = usage[len(usage)-1]
+		}
+		fits = append(fits, fit)
+	}
+	return fits
+}
+
+// used returns the highest percentage of any of the plan's limits that the
+// usage reaches
+func (p Plan) used(tokens, messages int, cost float64) float64 {
+	used := 0.0
+	for _, l := range []struct{ used, limit float64 }{
+		{float64(tokens), float64(p.Tokens)},
+		{float64(messages), float64(p.Messages)},
+		{cost, p.Cost},
+	} {
+		if l.limit > 0 {
+			used = max(used, l.used/l.limit*100)
+		}
+	}
+	return used
+}
+
+type namedPlan struct {
+	name string
+	plan Plan
+}
+
+// allPlans returns the built-in plans with those in custom added or
+// overriding them, smallest cost limit first
+func allPlans(custom map[string]Plan) []namedPlan {
+	merged := make(map[string]Plan, len(Plans)+len(custom))
+	for name, plan := range Plans {
+		merged[name] = plan
+	}
+	for name, plan := range custom {
+		merged[name] = plan
+	}
+
+	plans := make([]namedPlan, 0, len(merged))
+	for name, plan := range merged {
+		plans = append(plans, namedPlan{name, plan})
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if plans[i].plan.Cost != plans[j].plan.Cost {
+			return plans[i].plan.Cost < plans[j].plan.Cost
+		}
+		return plans[i].name < plans[j].name
+	})
+	return plans
+}
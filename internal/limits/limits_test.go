@@ -0,0 +1,159 @@
+package limits
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func TestLookup(t *testing.T) {
+	if plan, err := Lookup("max5", nil); err != nil || plan != Plans["max5"] {
+		t.Errorf("Lookup(max5) = %+v, %v, want built-in plan", plan, err)
+	}
+
+	custom := map[string]Plan{"pro": {Messages: 100}, "team": {Cost: 50}}
+	if plan, err := Lookup("pro", custom); err != nil || plan.Messages != 100 {
+		t.Errorf("Lookup(pro) = %+v, %v, want config override", plan, err)
+	}
+	if plan, err := Lookup("team", custom); err != nil || plan.Cost != 50 {
+		t.Errorf("Lookup(team) = %+v, %v, want custom plan", plan, err)
+	}
+	if _, err := Lookup("enterprise", custom); err == nil {
+		t.Error("unknown plan: expected error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	block := calculator.Block{
+		Start:             start,
+		End:               start.Add(5 * time.Hour),
+		LastActivity:      start.Add(59 * time.Minute),
+		Cost:              10,
+		Messages:          300,
+		InputOutputTokens: 4_000,
+	}
+	now := start.Add(time.Hour)
+
+	estimate := New(block, Plan{Tokens: 10_000, Messages: 250, Cost: 30}, now)
+	if !estimate.Reset.Equal(block.End) || estimate.Remaining != 4*time.Hour {
+		t.Errorf("Reset = %v, Remaining = %v, want %v and 4h", estimate.Reset, estimate.Remaining, block.End)
+	}
+	if len(estimate.Usage) != 3 {
+		t.Fatalf("Usage = %d limits, want 3", len(estimate.Usage))
+	}
+
+	tokens := estimate.Usage[0]
+	if tokens.Percent() != 40 || tokens.Projected != 20_000 {
+		t.Errorf("tokens = %.0f%%, projected %.0f, want 40%% and 20000", tokens.Percent(), tokens.Projected)
+	}
+	// 4k tokens an hour reaches 10k 1.5 hours from now
+	if want := now.Add(90 * time.Minute); !tokens.LimitAt.Equal(want) {
+		t.Errorf("tokens LimitAt = %v, want %v", tokens.LimitAt, want)
+	}
+
+	messages := estimate.Usage[1]
+	if !messages.LimitAt.Equal(block.LastActivity) {
+		t.Errorf("messages LimitAt = %v, want last activity once over the limit", messages.LimitAt)
+	}
+
+	// $10 an hour reaches $30 at 12:00, within the block
+	if cost := estimate.Usage[2]; cost.LimitAt.IsZero() {
+		t.Errorf("cost LimitAt is zero, want a projected time")
+	}
+
+	slow := New(block, Plan{Tokens: 1_000_000}, now)
+	if len(slow.Usage) != 1 || !slow.Usage[0].LimitAt.IsZero() {
+		t.Errorf("Usage = %+v, want one limit that isn't reached", slow.Usage)
+	}
+}
+
+func TestFits(t *testing.T) {
+	blocks := []calculator.Block{
+		{Cost: 9, Messages: 10, InputOutputTokens: 1_900},  // 50% of team's cost
+		{Cost: 18, Messages: 10, InputOutputTokens: 1_900}, // 100% of team's cost
+		{Cost: 1, Messages: 150, InputOutputTokens: 1_900}, // 150% of team's messages
+	}
+	fits := Fits(blocks, map[string]Plan{"team": {Messages: 100, Cost: 18}})
+	if len(fits) != 4 || fits[0].Name != "pro" || fits[1].Name != "team" || fits[3].Name != "max20" {
+		t.Fatalf("Fits = %+v, want pro, team, max5, max20 by cost limit", fits)
+	}
+
+	team := fits[1]
+	if team.Median != 100 || team.Peak != 150 || team.Over != 2 {
+		t.Errorf("team = median %.0f%%, peak %.0f%%, %d over; want 100%%, 150%%, 2", team.Median, team.Peak, team.Over)
+	}
+	if max20 := fits[3]; max20.Over != 0 {
+		t.Errorf("max20 = %d over, want 0", max20.Over)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	minute := func(offset time.Duration) int64 { return start.Add(offset).Unix() / 60 }
+	minutes := map[int64]*models.MinuteActivity{
+		minute(0):              {MessageCount: 60, Cost: 1},
+		minute(time.Hour):      {MessageCount: 60, Cost: 1}, // Reaches the limit
+		minute(2 * time.Hour):  {MessageCount: 30, Cost: 1}, // Refused
+		minute(6 * time.Hour):  {MessageCount: 10, Cost: 1},
+		minute(12 * time.Hour): {MessageCount: 500, Cost: 1}, // Active
+	}
+	blocks := []calculator.Block{
+		{Start: start, End: start.Add(5 * time.Hour)},
+		{Start: start.Add(6 * time.Hour), End: start.Add(11 * time.Hour)},
+		{Start: start.Add(12 * time.Hour), End: start.Add(17 * time.Hour), Active: true},
+	}
+
+	custom := map[string]Plan{
+		"small": {Messages: 120, Price: 10},
+		"big":   {Messages: 1_000, Price: 15},
+		"free":  {Messages: 1},
+	}
+	sims := Simulate(blocks, minutes, custom)
+	byName := make(map[string]Simulation)
+	for _, sim := range sims {
+		byName[sim.Name] = sim
+	}
+
+	small := byName["small"]
+	if small.Blocks != 2 || small.Throttled != 1 || small.BlockedMessages != 30 || small.BlockedCost != 1 {
+		t.Errorf("small = %+v, want 2 blocks, 1 throttled, 30 messages and $1 refused", small)
+	}
+	if want := 5*time.Hour - 61*time.Minute; small.LockedOut != want {
+		t.Errorf("LockedOut = %v, want %v from the end of the limit's minute to the reset", small.LockedOut, want)
+	}
+	if big := byName["big"]; big.Throttled != 0 {
+		t.Errorf("big throttled %d blocks, want 0", big.Throttled)
+	}
+
+	best, ok := Recommend(sims)
+	if !ok || best.Name != "big" {
+		t.Errorf("Recommend = %s, %v; want big, the cheapest that never throttled", best.Name, ok)
+	}
+}
+
+func TestSubscriptionCost(t *testing.T) {
+	plan := Plan{Price: 100}
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		billingDay int
+		start, end time.Time
+		want       float64
+	}{
+		{"whole period", 15, day(9, 15), day(10, 15), 100},
+		{"across a renewal", 15, day(10, 10), day(10, 17), 100*5.0/30 + 100*2.0/31},
+		{"short month renews on its last day", 31, day(2, 28), day(3, 31), 100},
+		{"from the last day of a short month", 31, day(3, 1), day(3, 2), 100.0 / 31},
+		{"no billing day", 0, day(9, 15), day(10, 15), 0},
+	}
+	for _, tt := range tests {
+		got := SubscriptionCost(plan, tt.billingDay, tt.start, tt.end)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: SubscriptionCost = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package limits
+
+import (
+	"sort"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// Simulation is the result of replaying past activity against a plan's
+// limits, minute by minute. Once a block reaches a limit, the rest of its
+// messages count as refused until it resets; the replay doesn't move them
+// into later blocks.
+type Simulation struct {
+	Name      string
+	Plan      Plan
+	Blocks    int // Finished blocks replayed
+	Throttled int // Blocks that reached a limit
+	// BlockedMessages and BlockedCost are the activity after a limit was
+	// reached, which the plan would have refused
+	BlockedMessages int
+	BlockedCost     float64
+	// LockedOut sums the time from reaching a limit to the block's reset
+	LockedOut time.Duration
+}
+
+// Simulate replays the activity in minutes, grouped into blocks, against
+// the built-in plans and those in custom, smallest plan first. The active
+// block is left out, since it hasn't finished.
+func Simulate(blocks []calculator.Block, minutes map[int64]*models.MinuteActivity, custom map[string]Plan) []Simulation {
+	keys := make([]int64, 0, len(minutes))
+	for minute := range minutes {
+		keys = append(keys, minute)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var sims []Simulation
+	for _, named := range allPlans(custom) {
+		sim := Simulation{Name: named.name, Plan: named.plan}
+		next := 0
+		for _, block := range blocks {
+			if block.Active {
+				continue
+			}
+			sim.Blocks++
+
+			var tokens, messages int
+			var cost float64
+			var limitAt time.Time
+			for ; next < len(keys); next++ {
+				t := time.Unix(keys[next]*60, 0)
+				if t.Before(block.Start) {
+					continue
+				}
+				if !t.Before(block.End) {
+					break
+				}
+				activity := minutes[keys[next]]
+				if !limitAt.IsZero() {
+					sim.BlockedMessages += activity.MessageCount
+					sim.BlockedCost += activity.Cost
+					continue
+				}
+				tokens += activity.InputOutputTokens
+				messages += activity.MessageCount
+				cost += activity.Cost
+				if named.plan.used(tokens, messages, cost) >= 100 {
+					limitAt = t.Add(time.Minute)
+				}
+			}
+
+			if !limitAt.IsZero() {
+				sim.Throttled++
+				sim.LockedOut += max(block.End.Sub(limitAt), 0)
+			}
+		}
+		sims = append(sims, sim)
+	}
+	return sims
+}
+
+// Recommend returns the cheapest priced plan that throttled no block, or
+// the one that throttled the fewest when every plan throttled some
+func Recommend(sims []Simulation) (Simulation, bool) {
+	var best Simulation
+	found := false
+	for _, sim := range sims {
+		if sim.Plan.Price <= 0 {
+			continue
+		}
+		if !found || sim.Throttled < best.Throttled ||
+			(sim.Throttled == best.Throttled && sim.Plan.Price < best.Plan.Price) {
+			best, found = sim, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,40 @@
+package limits
+
+import "time"
+
+// SubscriptionCost is what plan cost from start to end, when it renews on
+// billingDay of each month. Each billing period's price is spread evenly over
+// its days, so a partial period costs its share. Days past the end of a
+// short month renew on its last day.
+func SubscriptionCost(plan Plan, billingDay int, start, end time.Time) float64 {
+	if plan.Price <= 0 || billingDay <= 0 || !end.After(start) {
+		return 0
+	}
+
+	var cost float64
+	period := renewal(start, billingDay, 0)
+	if period.After(start) {
+		period = renewal(start, billingDay, -1)
+	}
+	for period.Before(end) {
+		next := renewal(period, billingDay, 1)
+		from, to := period, next
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		cost += plan.Price * to.Sub(from).Hours() / next.Sub(period).Hours()
+		period = next
+	}
+	return cost
+}
+
+// renewal is midnight on billingDay of the month months after t's, in t's
+// location
+func renewal(t time.Time, billingDay, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(billingDay, last)-1)
+}
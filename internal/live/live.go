@@ -0,0 +1,106 @@
+package live
+
+import (
+	"sort"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+// RateWindow is the span over which a session's token rate is measured
+const RateWindow = 5 * time.Minute
+
+// Session is a session's activity as seen by the tracker
+type Session struct {
+	ID         string
+	Project    string
+	Title      string
+	Model      string // Model of the latest response
+	LastActive time.Time
+	Messages   int
+	Cost       float64
+	Tokens     int // Input, output, and cache tokens
+	// TokensPerMinute is output tokens per minute over the RateWindow
+	// before now
+	TokensPerMinute float64
+}
+
+// Tracker sums the priced responses of each session as they are parsed, for
+// the top command
+type Tracker struct {
+	sessions map[string]*session
+}
+
+type session struct {
+	Session
+	recent []parser.Message // Responses within RateWindow of LastActive
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{sessions: make(map[string]*session)}
+}
+
+// Add counts a response
+func (t *Tracker) Add(m parser.Message) {
+	s, ok := t.sessions[m.SessionID]
+	if !ok {
+		s = &session{Session: Session{ID: m.SessionID, Project: m.Project}}
+		t.sessions[m.SessionID] = s
+	}
+
+	s.Messages++
+	s.Cost += m.Cost
+	s.Tokens += m.InputTokens + m.OutputTokens + m.CacheReadTokens + m.CacheWriteTokens
+	if !m.Time.Before(s.LastActive) {
+		s.LastActive = m.Time
+		s.Model = m.Model
+	}
+
+	// Drop responses too old to count toward the rate
+	recent := s.recent[:0]
+	for _, r := range s.recent {
+		if s.LastActive.Sub(r.Time) <= RateWindow {
+			recent = append(recent, r)
+		}
+	}
+	s.recent = append(recent, m)
+}
+
+// SetTitle names a session, once Claude Code has summarized it
+func (t *Tracker) SetTitle(id, title string) {
+	if s, ok := t.sessions[id]; ok && title != "" {
+		s.Title = title
+	}
+}
+
+// Active returns the sessions with a response within window before now,
+// busiest first
+func (t *Tracker) Active(now time.Time, window time.Duration) []Session {
+	var active []Session
+	for _, s := range t.sessions {
+		if now.Sub(s.LastActive) > window {
+			continue
+		}
+		session := s.Session
+		output := 0
+		for _, r := range s.recent {
+			if now.Sub(r.Time) <= RateWindow {
+				output += r.OutputTokens
+			}
+		}
+		session.TokensPerMinute = float64(output) / RateWindow.Minutes()
+		active = append(active, session)
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].TokensPerMinute != active[j].TokensPerMinute {
+			return active[i].TokensPerMinute > active[j].TokensPerMinute
+		}
+		if !active[i].LastActive.Equal(active[j].LastActive) {
+			return active[i].LastActive.After(active[j].LastActive)
+		}
+		return active[i].ID < active[j].ID
+	})
+	return active
+}
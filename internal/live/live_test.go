@@ -0,0 +1,52 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+func TestTracker_Active(t *testing.T) {
+	now := time.Date(2025, 6, 12, 15, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	add := func(session, model string, ago time.Duration, output int) {
+		tracker.Add(parser.Message{
+			SessionID:    session,
+			Project:      "api",
+			Model:        model,
+			Time:         now.Add(-ago),
+			Cost:         1,
+			InputTokens:  10,
+			OutputTokens: output,
+		})
+	}
+	add("busy", "claude-opus-4", 20*time.Minute, 9000)
+	add("busy", "claude-sonnet-4", 2*time.Minute, 500)
+	add("busy", "claude-opus-4", 4*time.Minute, 1000) // Logged out of order
+	add("quiet", "claude-sonnet-4", time.Minute, 100)
+	add("idle", "claude-sonnet-4", time.Hour, 100)
+	tracker.SetTitle("busy", "Fix the flaky test")
+
+	active := tracker.Active(now, 15*time.Minute)
+	if len(active) != 2 {
+		t.Fatalf("Active = %d sessions, want 2 (idle is outside the window)", len(active))
+	}
+
+	busy := active[0]
+	if busy.ID != "busy" || busy.Title != "Fix the flaky test" {
+		t.Errorf("first = %s %q, want busy, titled", busy.ID, busy.Title)
+	}
+	if busy.Model != "claude-sonnet-4" {
+		t.Errorf("Model = %s, want the latest response's", busy.Model)
+	}
+	if busy.Messages != 3 || busy.Cost != 3 || busy.Tokens != 10530 {
+		t.Errorf("busy = %d messages, $%.2f, %d tokens; want 3, $3.00, 10530", busy.Messages, busy.Cost, busy.Tokens)
+	}
+	if busy.TokensPerMinute != 300 {
+		t.Errorf("TokensPerMinute = %.1f, want 300 (1500 output tokens in 5 minutes)", busy.TokensPerMinute)
+	}
+	if active[1].ID != "quiet" || active[1].TokensPerMinute != 20 {
+		t.Errorf("second = %s at %.1f tok/min, want quiet at 20", active[1].ID, active[1].TokensPerMinute)
+	}
+}
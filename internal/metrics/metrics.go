@@ -0,0 +1,251 @@
+// Package metrics writes daily and per-project measurements to time series
+// databases: InfluxDB over its HTTP write API and Graphite over its plaintext
+// protocol.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// Timeout bounds a single write
+const Timeout = 10 * time.Second
+
+// Field is one value of a Point. Counts are integers in InfluxDB.
+type Field struct {
+	Name  string
+	Value float64
+	Count bool
+}
+
+// Point is one measurement, timestamped at the start of its day. Writing the
+// same point again overwrites it, so a whole window can be pushed on every
+// run.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      []Field
+	Time        time.Time
+}
+
+// Points returns a "claude_daily" point for each day of analysis and a
+// "claude_project" point, tagged with the project, for each project active
+// that day
+func Points(analysis *models.CostAnalysis) []Point {
+	dates := make([]string, 0, len(analysis.DailyActivity))
+	for date := range analysis.DailyActivity {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	var points []Point
+	for _, date := range dates {
+		day := analysis.DailyActivity[date]
+		t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			continue
+		}
+
+		points = append(points, Point{
+			Measurement: "claude_daily",
+			Fields: []Field{
+				{Name: "cost", Value: day.Cost},
+				{Name: "messages", Value: float64(day.MessageCount), Count: true},
+				{Name: "input_tokens", Value: float64(day.InputTokens), Count: true},
+				{Name: "cache_read_tokens", Value: float64(day.CacheReadTokens), Count: true},
+				{Name: "cache_write_tokens", Value: float64(day.CacheWriteTokens), Count: true},
+				{Name: "cache_hit_rate", Value: day.CacheHitRate()},
+			},
+			Time: t,
+		})
+
+		projects := make([]string, 0, len(day.ProjectCosts))
+		for project := range day.ProjectCosts {
+			projects = append(projects, project)
+		}
+		sort.Strings(projects)
+		for _, project := range projects {
+			points = append(points, Point{
+				Measurement: "claude_project",
+				Tags:        map[string]string{"project": project},
+				Fields:      []Field{{Name: "cost", Value: day.ProjectCosts[project]}},
+				Time:        t,
+			})
+		}
+	}
+	return points
+}
+
+// Influx is an InfluxDB write destination. InfluxDB 1.8 and later accept
+// the same API with Bucket "database/retention-policy" and Token
+// "user:password".
+type Influx struct {
+	URL    string
+	Org    string
+	Bucket string
+	Token  string
+}
+
+// WriteInflux posts points to InfluxDB's /api/v2/write endpoint
+func WriteInflux(ctx context.Context, client *http.Client, dest Influx, points []Point) error {
+	endpoint, err := url.Parse(strings.TrimSuffix(dest.URL, "/") + "/api/v2/write")
+	if err != nil {
+		return fmt.Errorf("invalid InfluxDB URL: %w", err)
+	}
+	query := url.Values{"bucket": {dest.Bucket}, "precision": {"s"}}
+	if dest.Org != "" {
+		query.Set("org", dest.Org)
+	}
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(LineProtocol(points)))
+	if err != nil {
+		return fmt.Errorf("invalid InfluxDB URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if dest.Token != "" {
+		req.Header.Set("Authorization", "Token "+dest.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// LineProtocol encodes points in InfluxDB line protocol with second
+// precision
+func LineProtocol(points []Point) []byte {
+	var b bytes.Buffer
+	for _, point := range points {
+		b.WriteString(escape(point.Measurement, ", "))
+
+		keys := make([]string, 0, len(point.Tags))
+		for key := range point.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// Empty tag values aren't allowed
+			if value := point.Tags[key]; value != "" {
+				fmt.Fprintf(&b, ",%s=%s", escape(key, ",= "), escape(value, ",= "))
+			}
+		}
+
+		for i, field := range point.Fields {
+			sep := ","
+			if i == 0 {
+				sep = " "
+			}
+			value := strconv.FormatFloat(field.Value, 'f', -1, 64)
+			if field.Count {
+				value = strconv.FormatInt(int64(field.Value), 10) + "i"
+			}
+			fmt.Fprintf(&b, "%s%s=%s", sep, escape(field.Name, ",= "), value)
+		}
+		fmt.Fprintf(&b, " %d\n", point.Time.Unix())
+	}
+	return b.Bytes()
+}
+
+// escape backslash-escapes the characters in special
+func escape(s, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// WriteGraphite sends points to a Graphite (carbon) plaintext listener at
+// addr, such as "localhost:2003"
+func WriteGraphite(ctx context.Context, addr, prefix string, points []Point) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Graphite: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(GraphiteLines(prefix, points)); err != nil {
+		return fmt.Errorf("failed to write to Graphite: %w", err)
+	}
+	return conn.Close()
+}
+
+// GraphiteLines encodes points in Graphite's plaintext protocol. A point's
+// path is prefix, the measurement without its "claude_" prefix, then its
+// tag values, then the field: "claude.project.my_app.cost".
+func GraphiteLines(prefix string, points []Point) []byte {
+	var b bytes.Buffer
+	for _, point := range points {
+		var path []string
+		if prefix != "" {
+			path = append(path, prefix)
+		}
+		path = append(path, strings.TrimPrefix(point.Measurement, "claude_"))
+
+		keys := make([]string, 0, len(point.Tags))
+		for key := range point.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path = append(path, graphiteName(point.Tags[key]))
+		}
+
+		for _, field := range point.Fields {
+			fmt.Fprintf(&b, "%s.%s %s %d\n", strings.Join(path, "."), field.Name,
+				strconv.FormatFloat(field.Value, 'f', -1, 64), point.Time.Unix())
+		}
+	}
+	return b.Bytes()
+}
+
+// graphiteName replaces characters that would split or break a metric path,
+// so "/home/me/src/app" becomes "home_me_src_app"
+func graphiteName(s string) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s), "_")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
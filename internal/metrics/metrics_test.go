@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func testAnalysis() *models.CostAnalysis {
+	return &models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-14": {MessageCount: 2, Cost: 0.5, ProjectCosts: map[string]float64{"web app": 0.5}},
+			"2025-06-13": {
+				MessageCount:    3,
+				Cost:            1.25,
+				InputTokens:     100,
+				CacheReadTokens: 300,
+				ProjectCosts:    map[string]float64{"api": 1, "web app": 0.25},
+			},
+		},
+	}
+}
+
+func TestPoints(t *testing.T) {
+	points := Points(testAnalysis())
+
+	var got []string
+	for _, p := range points {
+		got = append(got, p.Measurement+"/"+p.Tags["project"]+"@"+p.Time.Format("2006-01-02"))
+	}
+	want := []string{
+		"claude_daily/@2025-06-13", "claude_project/api@2025-06-13", "claude_project/web app@2025-06-13",
+		"claude_daily/@2025-06-14", "claude_project/web app@2025-06-14",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("points = %v, want %v", got, want)
+	}
+	if h, m, _ := points[0].Time.Clock(); h != 0 || m != 0 || points[0].Time.Location() != time.Local {
+		t.Errorf("point time = %v, want local midnight", points[0].Time)
+	}
+}
+
+func TestLineProtocol(t *testing.T) {
+	day := time.Unix(1749772800, 0)
+	got := string(LineProtocol([]Point{
+		{
+			Measurement: "claude_daily",
+			Fields:      []Field{{Name: "cost", Value: 1.25}, {Name: "messages", Value: 3, Count: true}},
+			Time:        day,
+		},
+		{
+			Measurement: "claude_project",
+			Tags:        map[string]string{"project": "web app,v2", "empty": ""},
+			Fields:      []Field{{Name: "cost", Value: 0.25}},
+			Time:        day,
+		},
+	}))
+	want := "claude_daily cost=1.25,messages=3i 1749772800\n" +
+		`claude_project,project=web\ app\,v2 cost=0.25 1749772800` + "\n"
+	if got != want {
+		t.Errorf("LineProtocol =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteInflux(t *testing.T) {
+	var req *http.Request
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		req, body = r, string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	dest := Influx{URL: ts.URL + "/", Org: "home", Bucket: "claude", Token: "secret"}
+	if err := WriteInflux(context.Background(), ts.Client(), dest, Points(testAnalysis())); err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/api/v2/write" || req.URL.Query().Get("bucket") != "claude" ||
+		req.URL.Query().Get("org") != "home" || req.URL.Query().Get("precision") != "s" {
+		t.Errorf("request URL = %s", req.URL)
+	}
+	if req.Header.Get("Authorization") != "Token secret" {
+		t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+	}
+	if n := strings.Count(body, "\n"); n != 5 {
+		t.Errorf("wrote %d lines, want 5:\n%s", n, body)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	err := WriteInflux(context.Background(), failing.Client(), Influx{URL: failing.URL, Bucket: "x"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "bucket not found") {
+		t.Errorf("err = %v, want the server's message", err)
+	}
+}
+
+func TestWriteGraphite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	lines := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var got []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		lines <- got
+	}()
+
+	if err := WriteGraphite(context.Background(), listener.Addr().String(), "claude", Points(testAnalysis())); err != nil {
+		t.Fatal(err)
+	}
+	got := <-lines
+
+	// 6 daily fields on each of 2 days, plus 3 project costs
+	if len(got) != 15 {
+		t.Fatalf("wrote %d lines, want 15: %v", len(got), got)
+	}
+	var found bool
+	for _, line := range got {
+		if strings.HasPrefix(line, "claude.project.web_app.cost 0.25 ") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no claude.project.web_app.cost line in %v", got)
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// DayBoundary decides which day a time counts toward in daily totals. The
+// zero value is local calendar days.
+type DayBoundary struct {
+	// UTC counts UTC days instead of local ones, so days don't shift when
+	// traveling across time zones
+	UTC bool
+	// StartHour is the hour of the day, 0-23, at which days begin, so that
+	// with 4 a session running past midnight counts toward the day it began
+	StartHour int
+}
+
+// Start returns the time the day containing t began. Days begin at the
+// same wall-clock hour whatever the daylight saving time offset, so days
+// with a clock change are an hour shorter or longer.
+func (b DayBoundary) Start(t time.Time) time.Time {
+	if b.UTC {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), b.StartHour, 0, 0, 0, t.Location())
+	if start.After(t) {
+		start = time.Date(t.Year(), t.Month(), t.Day()-1, b.StartHour, 0, 0, 0, t.Location())
+	}
+	return start
+}
+
+// Date returns the day t counts toward as YYYY-MM-DD
+func (b DayBoundary) Date(t time.Time) string {
+	return b.Start(t).Format("2006-01-02")
+}
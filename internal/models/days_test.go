@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDayBoundary(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	local := time.Local
+	time.Local = newYork
+	t.Cleanup(func() { time.Local = local })
+
+	at := func(value string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", value, newYork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		boundary DayBoundary
+		time     string
+		want     string
+	}{
+		{DayBoundary{}, "2025-06-12 23:00", "2025-06-12"},
+		{DayBoundary{UTC: true}, "2025-06-12 23:00", "2025-06-13"},
+		{DayBoundary{StartHour: 4}, "2025-06-13 02:30", "2025-06-12"},
+		{DayBoundary{StartHour: 4}, "2025-06-13 04:00", "2025-06-13"},
+		{DayBoundary{UTC: true, StartHour: 4}, "2025-06-12 23:30", "2025-06-12"},
+		// Clocks went forward at 02:00 on March 9, so 03:30 is 2.5 hours
+		// after midnight but still before the day starts
+		{DayBoundary{StartHour: 4}, "2025-03-09 03:30", "2025-03-08"},
+		{DayBoundary{StartHour: 4}, "2025-03-09 04:30", "2025-03-09"},
+		// And back at 02:00 on November 2
+		{DayBoundary{StartHour: 4}, "2025-11-02 03:30", "2025-11-01"},
+	}
+	for _, tt := range tests {
+		if got := tt.boundary.Date(at(tt.time)); got != tt.want {
+			t.Errorf("%+v.Date(%s) = %s, want %s", tt.boundary, tt.time, got, tt.want)
+		}
+	}
+
+	start := DayBoundary{StartHour: 4}.Start(at("2025-03-09 12:00"))
+	if want := at("2025-03-09 04:00"); !start.Equal(want) {
+		t.Errorf("Start on the day clocks go forward = %v, want %v", start, want)
+	}
+}
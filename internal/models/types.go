@@ -1,15 +1,17 @@
 package models
 
 import (
+	"sort"
+	"strings"
 	"time"
 )
 
 // PricingTier represents the cost per million tokens for a specific model
 type PricingTier struct {
-	Input      float64
-	Output     float64
-	CacheWrite float64
-	CacheRead  float64
+	Input      float64 `toml:"input"`
+	Output     float64 `toml:"output"`
+	CacheWrite float64 `toml:"cache_write"`
+	CacheRead  float64 `toml:"cache_read"`
 }
 
 // ModelPricing maps model names to their pricing tiers
@@ -75,6 +77,103 @@ var DefaultPricing = PricingTier{
 	CacheRead:  0.30,
 }
 
+// ResolveModel returns the model ID in ModelPricing that name refers to:
+// the ID itself, or for a name without its snapshot date, such as
+// "claude-opus-4", the newest snapshot
+func ResolveModel(name string) (string, bool) {
+	if _, ok := ModelPricing[name]; ok {
+		return name, true
+	}
+	resolved := ""
+	for id := range ModelPricing {
+		date, ok := strings.CutPrefix(id, name+"-")
+		if ok && len(date) == len("20060102") && strings.Trim(date, "0123456789") == "" && id > resolved {
+			resolved = id
+		}
+	}
+	return resolved, resolved != ""
+}
+
+// Cost returns the price of the given token counts at this tier
+func (t PricingTier) Cost(input, output, cacheWrite, cacheRead int) float64 {
+	return (float64(input)*t.Input +
+		float64(output)*t.Output +
+		float64(cacheWrite)*t.CacheWrite +
+		float64(cacheRead)*t.CacheRead) / 1_000_000
+}
+
+// CacheCosts breaks down what prompt caching cost and saved
+type CacheCosts struct {
+	WriteCost    float64 // Cost of cache write tokens
+	WritePremium float64 // Cost of cache writes above the plain input price
+	ReadCost     float64 // Cost of cache read tokens
+	ReadSavings  float64 // Cache reads priced at plain input minus ReadCost
+}
+
+// ImageStats are the images pasted into or read during a project's sessions
+type ImageStats struct {
+	// Images counts image blocks, and Turns the turns that had any
+	Images int
+	Turns  int
+	// InputCost is the input, cache write, and cache read cost of the
+	// responses in those turns
+	InputCost float64
+}
+
+// InterruptStats are the turns of a project's sessions that the user
+// interrupted mid-task or that ended abruptly
+type InterruptStats struct {
+	// Turns counts human prompts, of which Interrupted were stopped by the
+	// user and Abandoned ended their session waiting on a tool call
+	Turns       int
+	Interrupted int
+	Abandoned   int
+	// Cost is what the responses of those turns cost before they ended
+	Cost float64
+}
+
+// CacheTTL is how long the API keeps a prompt cache entry after its last use
+const CacheTTL = 5 * time.Minute
+
+// CacheExpiryStats are responses that wrote the prompt cache again after the
+// session sat idle a few minutes past the cache's time to live
+type CacheExpiryStats struct {
+	// Gaps counts the idle gaps by whole minutes
+	Gaps     map[int]int
+	Rewrites int
+	// Tokens are the cache write tokens of those responses
+	Tokens int
+	// ExtraCost is what writing those tokens cost over reading them from a
+	// warm cache
+	ExtraCost float64
+}
+
+// Add accumulates other into c
+func (c *CacheCosts) Add(other CacheCosts) {
+	c.WriteCost += other.WriteCost
+	c.WritePremium += other.WritePremium
+	c.ReadCost += other.ReadCost
+	c.ReadSavings += other.ReadSavings
+}
+
+// Net returns read savings minus the write premium; negative means caching
+// cost more than it saved
+func (c CacheCosts) Net() float64 {
+	return c.ReadSavings - c.WritePremium
+}
+
+// NewCacheCosts computes cache costs for the given token counts at tier
+func NewCacheCosts(tier PricingTier, cacheWrite, cacheRead int) CacheCosts {
+	writes := float64(cacheWrite) / 1_000_000
+	reads := float64(cacheRead) / 1_000_000
+	return CacheCosts{
+		WriteCost:    writes * tier.CacheWrite,
+		WritePremium: writes * (tier.CacheWrite - tier.Input),
+		ReadCost:     reads * tier.CacheRead,
+		ReadSavings:  reads * (tier.Input - tier.CacheRead),
+	}
+}
+
 // Entry represents a single entry in the JSONL file
 type Entry struct {
 	ParsedTimestamp time.Time       `json:"-"` // Computed field, not from JSON
@@ -85,7 +184,24 @@ type Entry struct {
 	Type            string          `json:"type"`
 	Timestamp       string          `json:"timestamp"`
 	SessionID       string          `json:"sessionId"`
+	GitBranch       string          `json:"gitBranch,omitempty"`
 	CostUSD         float64         `json:"costUSD,omitempty"`
+	// Content is the text of system entries
+	Content interface{} `json:"content,omitempty"`
+	// IsAPIErrorMessage marks assistant entries Claude Code wrote for a
+	// failed API request rather than a model response
+	IsAPIErrorMessage bool `json:"isApiErrorMessage,omitempty"`
+	// IsSidechain marks entries from subagents
+	IsSidechain bool `json:"isSidechain,omitempty"`
+	// UserID and AccountUUID identify the login, when Claude Code records it
+	UserID      string `json:"userID,omitempty"`
+	AccountUUID string `json:"accountUuid,omitempty"`
+	// Account is the resolved account name, carried forward within a file
+	Account string `json:"-"`
+	// Summary is the title of summary entries, naming the conversation
+	// that ends at LeafUUID
+	Summary  string `json:"summary,omitempty"`
+	LeafUUID string `json:"leafUuid,omitempty"`
 }
 
 // MessageContent represents the message field in an entry
@@ -107,6 +223,32 @@ type Usage struct {
 // ToolUseResult tracks tool use acceptance/rejection
 type ToolUseResult struct {
 	Interrupted bool `json:"interrupted"`
+	// StructuredPatch is the diff an Edit or Write made, when Claude Code
+	// records one
+	StructuredPatch []PatchHunk `json:"structuredPatch"`
+}
+
+// PatchHunk is one hunk of a file tool's diff. Lines start with "+" when
+// added, "-" when removed, and " " for context.
+type PatchHunk struct {
+	Lines []string `json:"lines"`
+}
+
+// LineChanges counts lines of code added and removed by accepted file edits
+type LineChanges struct {
+	Added   int
+	Removed int
+}
+
+// Add adds other's counts to l
+func (l *LineChanges) Add(other LineChanges) {
+	l.Added += other.Added
+	l.Removed += other.Removed
+}
+
+// Total is the lines added plus the lines removed
+func (l LineChanges) Total() int {
+	return l.Added + l.Removed
 }
 
 // ToolContent represents content items that might be tool results
@@ -116,10 +258,44 @@ type ToolContent struct {
 	IsError bool   `json:"is_error"`
 }
 
+// ExtensionStats counts file tool calls for one file extension
+type ExtensionStats struct {
+	Reads  int
+	Edits  int
+	Writes int
+}
+
+// BashCommandStats counts Bash tool calls for one command, such as git or npm
+type BashCommandStats struct {
+	Count    int
+	Failures int // Results flagged is_error, excluding user rejections
+	Rejected int
+}
+
+// ToolStats counts tool calls and their outcomes for one tool
+type ToolStats struct {
+	Uses     int
+	Rejected int // Declined by the user or interrupted
+	Errors   int // Results flagged is_error that weren't rejections
+	// RejectedCost is the cost of the responses that proposed the rejected
+	// calls, split evenly among the calls of each response
+	RejectedCost float64
+}
+
 // SessionStats holds aggregated statistics for a session
 type SessionStats struct {
-	StartTime        time.Time
-	EndTime          time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	ActiveMinutes map[int64]bool // Unix minutes with assistant activity
+	Project       string         // Display name, after aliases
+	ProjectPath   string         // Project path before aliases
+	ProjectDir    string         // Encoded directory under projects/
+	// Files counts the log files of the session. Resuming a session starts
+	// a new file, and subagents write their own.
+	Files            int
+	GitBranch        string
+	Title            string // From Claude Code's summary entries, if any
+	Tags             []string
 	ResponseTimes    []time.Duration
 	Cost             float64
 	InputTokens      int
@@ -128,11 +304,43 @@ type SessionStats struct {
 	CacheWriteTokens int
 	TotalTokens      int
 	MessageCount     int
+	// ContextTokens are the input tokens of the session's responses, of
+	// which RepeatedTokens re-sent the previous response's context at a
+	// cost of RepeatedCost
+	ContextTokens  int
+	RepeatedTokens int
+	RepeatedCost   float64
+	// Prompts counts the human prompts, not tool results or subagent turns
+	Prompts int
+	// OpusCost is the cost of the session's Opus responses, and OpusTokens
+	// their tokens, so they can be priced at other models
+	OpusCost   float64
+	OpusTokens TokenCounts
+}
+
+// TokenCounts are the tokens of one or more responses
+type TokenCounts struct {
+	Input      int
+	Output     int
+	CacheWrite int
+	CacheRead  int
+}
+
+// CostAt returns the price of the tokens at tier
+func (c TokenCounts) CostAt(tier PricingTier) float64 {
+	return tier.Cost(c.Input, c.Output, c.CacheWrite, c.CacheRead)
 }
 
 // ProjectStats holds aggregated statistics for a project
 type ProjectStats struct {
+	Cache            CacheCosts
+	Images           ImageStats
+	Interrupts       InterruptStats
 	ActiveDays       map[string]bool
+	ActiveMinutes    map[int64]bool // Unix minutes with assistant activity
+	FileExtensions   map[string]*ExtensionStats
+	Tools            map[string]*ToolStats
+	Lines            LineChanges // Changed by accepted file edits
 	SessionIDs       map[string]bool
 	ResponseTimes    []time.Duration
 	Cost             float64
@@ -144,16 +352,93 @@ type ProjectStats struct {
 	TotalTokens      int
 }
 
+// AccountStats holds aggregated statistics for one login
+type AccountStats struct {
+	SessionIDs   map[string]bool
+	Cost         float64
+	MessageCount int
+	TotalTokens  int // Input, output, and cache tokens
+}
+
+// ModelStats holds aggregated statistics for a model
+type ModelStats struct {
+	Cache            CacheCosts
+	ActiveMinutes    map[int64]bool // Unix minutes with assistant activity
+	Cost             float64
+	InputTokens      int
+	OutputTokens     int
+	CacheReadTokens  int
+	CacheWriteTokens int
+}
+
+// LimitKind distinguishes plan usage limits from API rate limiting
+type LimitKind string
+
+const (
+	// UsageLimit is the plan's usage limit; requests fail until it resets
+	UsageLimit LimitKind = "usage"
+	// RateLimit is an API rate limit error (HTTP 429)
+	RateLimit LimitKind = "rate"
+)
+
+// LimitEvent is a usage limit or rate limit message logged by Claude Code
+type LimitEvent struct {
+	Time      time.Time
+	Kind      LimitKind
+	SessionID string
+	Project   string
+}
+
 // HourlyActivity tracks activity by hour of day
 type HourlyActivity struct {
 	MessageCount int
 	Cost         float64
 }
 
-// DailyActivity tracks activity by date
-type DailyActivity struct {
+// MinuteActivity tracks assistant activity within one Unix minute
+type MinuteActivity struct {
 	MessageCount int
 	Cost         float64
+	Tokens       int // Input, output, and cache tokens
+	// InputOutputTokens excludes cache tokens, which don't count toward plan
+	// rate limits
+	InputOutputTokens int
+}
+
+// DailyActivity tracks activity by date
+type DailyActivity struct {
+	ModelCosts       map[string]float64 // Cost by model name
+	ProjectCosts     map[string]float64 // Cost by project name
+	SessionCosts     map[string]float64 // Cost by session ID
+	MessageCount     int
+	Cost             float64
+	InputTokens      int
+	CacheReadTokens  int
+	CacheWriteTokens int
+	// OpusMinutes holds Unix minutes with Opus responses
+	OpusMinutes map[int64]bool
+	// DowngradedMinutes holds Unix minutes with Sonnet responses in sessions
+	// that had been using Opus
+	DowngradedMinutes map[int64]bool
+	// DowngradedCost is the cost of those Sonnet responses
+	DowngradedCost float64
+	// ModelSwitches counts Opus-to-Sonnet switches within a session
+	ModelSwitches int
+}
+
+// CacheHitRate returns the share of prompt tokens served from cache that day
+func (d *DailyActivity) CacheHitRate() float64 {
+	return CacheHitRate(d.InputTokens, d.CacheReadTokens, d.CacheWriteTokens)
+}
+
+// CacheHitRate returns cache reads as a percentage of all prompt tokens
+// (uncached input, cache reads, and cache writes)
+func CacheHitRate(input, cacheRead, cacheWrite int) float64 {
+	total := input + cacheRead + cacheWrite
+	if total == 0 {
+		return 0
+	}
+	return float64(cacheRead) / float64(total) * 100
 }
 
 // ToolUseStats tracks tool acceptance/rejection statistics
@@ -164,19 +449,154 @@ type ToolUseStats struct {
 
 // CostAnalysis holds the complete analysis results
 type CostAnalysis struct {
-	StartDate         time.Time
-	EndDate           time.Time
-	ResponseTimes     []time.Duration
-	Sessions          map[string]*SessionStats
-	Projects          map[string]*ProjectStats
-	HourlyActivity    map[int]*HourlyActivity
-	DailyActivity     map[string]*DailyActivity
-	ModelUsage        map[string]int
-	ToolUse           *ToolUseStats
-	TotalCost         float64
-	CacheSavings      float64
-	TotalInputTokens  int
-	TotalOutputTokens int
-	TotalCacheRead    int
-	TotalCacheWrite   int
+	Cache          CacheCosts
+	StartDate      time.Time
+	EndDate        time.Time
+	ResponseTimes  []time.Duration
+	Sessions       map[string]*SessionStats
+	Projects       map[string]*ProjectStats
+	HourlyActivity map[int]*HourlyActivity
+	DailyActivity  map[string]*DailyActivity
+	ModelUsage     map[string]int
+	Models         map[string]*ModelStats
+	// FileExtensions counts Read/Edit/Write tool calls by file extension
+	FileExtensions map[string]*ExtensionStats
+	// Lines counts lines changed by accepted file edits
+	Lines LineChanges
+	// Tools counts tool calls and outcomes by tool name
+	Tools map[string]*ToolStats
+	// RejectedPatterns counts rejections by tool and target, e.g. "Bash git"
+	// or "Edit .go"
+	RejectedPatterns map[string]int
+	// BashCommands counts Bash tool calls by leading command
+	BashCommands map[string]*BashCommandStats
+	// ActiveMinutes holds Unix minutes with any assistant activity
+	ActiveMinutes map[int64]bool
+	// Minutes holds cost and tokens by Unix minute, for billing blocks
+	Minutes map[int64]*MinuteActivity
+	// TurnTimes holds wall-clock latency from a user prompt to the final
+	// assistant reply of that turn, including local tool execution
+	TurnTimes []time.Duration
+	// TurnModelTimes holds the same turns with tool execution time removed
+	TurnModelTimes []time.Duration
+	// ModelResponseTimes holds response times keyed by the responding model
+	ModelResponseTimes map[string][]time.Duration
+	ToolUse            *ToolUseStats
+	TotalCost          float64
+	CacheSavings       float64
+	TotalInputTokens   int
+	TotalOutputTokens  int
+	TotalCacheRead     int
+	TotalCacheWrite    int
+	// ResponseTimeOutliers counts response times discarded for falling
+	// outside the configured bounds
+	ResponseTimeOutliers int
+	// ClockSkew counts timestamps that run backwards
+	ClockSkew ClockSkewStats
+	// MemoryLimited reports that the parser reached its memory limit and
+	// skipped response and turn times rather than run out of memory
+	MemoryLimited bool
+	// Accounts holds statistics by account name, or by ID for accounts
+	// without a configured name
+	Accounts map[string]*AccountStats
+	// LimitEvents holds usage limit and rate limit messages in time order
+	LimitEvents []LimitEvent
+	// DayBoundary is how DailyActivity and each project's ActiveDays divide
+	// time into days
+	DayBoundary DayBoundary
+	// ParseErrors counts malformed lines, entries with invalid timestamps,
+	// and files that couldn't be read
+	ParseErrors int
+	// UnknownEntryTypes counts the entries skipped because the parser
+	// doesn't read their type and no handler was registered for it, by type
+	UnknownEntryTypes map[string]int
+	// DuplicateFiles lists the session files left out as copies of others
+	DuplicateFiles []DuplicateFile
+	// ToolExecutionTime is the total time between tool_use requests and
+	// their matching tool_result entries
+	ToolExecutionTime time.Duration
+	// CacheExpiry counts cache writes forced by idle gaps just past the
+	// cache's time to live
+	CacheExpiry CacheExpiryStats
+	// Background holds the Haiku responses Claude Code makes on the main
+	// thread for its own housekeeping, such as titling sessions, and
+	// HaikuSubagents those of subagents running on Haiku
+	Background     TaskStats
+	HaikuSubagents TaskStats
+	// ByCategory breaks responses down by what they did, keyed by the
+	// Category constants
+	ByCategory map[string]*CategoryStats
+}
+
+// DuplicateFile is a session file left out of an analysis because it copies
+// another, as a synced dotfile or backup does
+type DuplicateFile struct {
+	Path     string
+	Original string // The file counted instead
+}
+
+// ClockSkewStats count timestamps that run backwards within a session, as
+// when the clock is changed or a machine wakes from sleep and resyncs. They
+// turn response and turn times negative or implausibly long.
+type ClockSkewStats struct {
+	// OutOfOrder counts entries timestamped before the entry preceding them
+	// in the session's log
+	OutOfOrder int
+	// ParentAfterChild counts entries timestamped before their parent entry
+	ParentAfterChild int
+	// Sessions counts the sessions with either
+	Sessions int
+	// Excluded counts response and turn times discarded because an entry
+	// of theirs was out of order
+	Excluded int
+}
+
+// UnknownTypes returns the keys of UnknownEntryTypes, most frequent first
+func (a *CostAnalysis) UnknownTypes() []string {
+	types := make([]string, 0, len(a.UnknownEntryTypes))
+	for typ := range a.UnknownEntryTypes {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if a.UnknownEntryTypes[types[i]] != a.UnknownEntryTypes[types[j]] {
+			return a.UnknownEntryTypes[types[i]] > a.UnknownEntryTypes[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// Kinds of response for the cost breakdown. Each priced response falls in
+// the first that applies, in the order of Categories.
+const (
+	CategoryHousekeeping = "housekeeping" // Claude Code's own Haiku calls
+	CategorySubagent     = "subagent"     // Responses in a subagent (sidechain)
+	CategoryToolCall     = "tool call"    // Responses that call a tool
+	CategoryThinking     = "thinking"     // Extended thinking blocks
+	CategoryReply        = "reply"        // Replies to the user
+)
+
+// Categories lists the response categories in order of precedence
+var Categories = []string{CategoryHousekeeping, CategorySubagent, CategoryToolCall, CategoryThinking, CategoryReply}
+
+// CategoryStats are the responses of one category
+type CategoryStats struct {
+	Messages int
+	Tokens   int // Input, output, and cache tokens
+	Cost     float64
+}
+
+// TaskStats are the responses of one kind of task
+type TaskStats struct {
+	Messages map[string]int // By model
+	Cost     float64
+}
+
+// Count returns the number of responses
+func (t TaskStats) Count() int {
+	count := 0
+	for _, n := range t.Messages {
+		count += n
+	}
+	return count
 }
@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestResolveModel(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"claude-opus-4-20250514", "claude-opus-4-20250514", true},
+		{"claude-opus-4", "claude-opus-4-20250514", true},
+		{"claude-3-5-sonnet", "claude-3-5-sonnet-20241022", true},
+		{"claude-3", "", false}, // Not a whole model name
+		{"claude-opus", "", false},
+		{"gpt-4", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ResolveModel(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolveModel(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
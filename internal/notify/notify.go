@@ -0,0 +1,136 @@
+// Package notify posts daily cost summaries to chat webhooks such as Slack
+// and Discord.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+)
+
+// Timeout bounds a single webhook request
+const Timeout = 10 * time.Second
+
+// Formatter renders a daily summary as a webhook request body
+type Formatter func(summary calculator.DailySummary) ([]byte, error)
+
+// Slack formats summary for a Slack incoming webhook
+func Slack(summary calculator.DailySummary) ([]byte, error) {
+	text := fmt.Sprintf("*Claude Code API value for %s: %s* (%s)", summary.Date, currency(summary.Cost), delta(summary))
+	if summary.TopProject != "" {
+		text += fmt.Sprintf("\nTop project: %s (%s)", summary.TopProject, currency(summary.TopProjectCost))
+	}
+	if summary.TopSession != "" {
+		text += fmt.Sprintf("\nTop session: `%s` %s(%s)", shortID(summary.TopSession), inProject(summary), currency(summary.TopSessionCost))
+	}
+
+	return json.Marshal(map[string]string{"text": text})
+}
+
+// Discord formats summary as an embed for a Discord webhook
+func Discord(summary calculator.DailySummary) ([]byte, error) {
+	type field struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+	type embed struct {
+		Title       string  `json:"title"`
+		Description string  `json:"description"`
+		Color       int     `json:"color"`
+		Fields      []field `json:"fields,omitempty"`
+	}
+
+	e := embed{
+		Title:       "Claude Code API value for " + summary.Date,
+		Description: fmt.Sprintf("**%s** (%s)", currency(summary.Cost), delta(summary)),
+		Color:       0x2ecc71,
+	}
+	if summary.Delta() > 0 {
+		e.Color = 0xe67e22
+	}
+	if summary.TopProject != "" {
+		e.Fields = append(e.Fields, field{
+			Name:   "Top project",
+			Value:  fmt.Sprintf("%s (%s)", summary.TopProject, currency(summary.TopProjectCost)),
+			Inline: true,
+		})
+	}
+	if summary.TopSession != "" {
+		e.Fields = append(e.Fields, field{
+			Name:   "Top session",
+			Value:  fmt.Sprintf("`%s` %s(%s)", shortID(summary.TopSession), inProject(summary), currency(summary.TopSessionCost)),
+			Inline: true,
+		})
+	}
+
+	return json.Marshal(map[string][]embed{"embeds": {e}})
+}
+
+// Post sends a formatted summary to a webhook URL
+func Post(ctx context.Context, client *http.Client, url string, format Formatter, summary calculator.DailySummary) error {
+	body, err := format(summary)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// delta describes the change from the previous day
+func delta(summary calculator.DailySummary) string {
+	if summary.PreviousCost == 0 {
+		return "no activity the day before"
+	}
+	d := summary.Delta()
+	arrow := "▲"
+	if d < 0 {
+		arrow = "▼"
+		d = -d
+	}
+	return fmt.Sprintf("%s %s, %.0f%% vs previous day", arrow, currency(d), d/summary.PreviousCost*100)
+}
+
+func inProject(summary calculator.DailySummary) string {
+	if summary.TopSessionProject == "" {
+		return ""
+	}
+	return "in " + summary.TopSessionProject + " "
+}
+
+func currency(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// shortID abbreviates a session UUID
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
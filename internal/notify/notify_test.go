@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+)
+
+var testSummary = calculator.DailySummary{
+	Date:              "2025-06-13",
+	Cost:              3.5,
+	PreviousCost:      2.0,
+	TopProject:        "api",
+	TopProjectCost:    2.5,
+	TopSession:        "0123456789abcdef",
+	TopSessionProject: "api",
+	TopSessionCost:    1.5,
+}
+
+func TestSlack(t *testing.T) {
+	body, err := Slack(testSummary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct{ Text string }
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"2025-06-13: $3.50", "▲ $1.50, 75% vs previous day", "Top project: api ($2.50)", "`01234567` in api ($1.50)"} {
+		if !strings.Contains(payload.Text, want) {
+			t.Errorf("Slack text %q missing %q", payload.Text, want)
+		}
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	body, err := Discord(testSummary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Embeds []struct {
+			Title  string
+			Fields []struct{ Name, Value string }
+		}
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Embeds) != 1 || len(payload.Embeds[0].Fields) != 2 {
+		t.Fatalf("payload = %s, want one embed with two fields", body)
+	}
+	if payload.Embeds[0].Fields[0].Value != "api ($2.50)" {
+		t.Errorf("top project field = %q", payload.Embeds[0].Fields[0].Value)
+	}
+}
+
+func TestPost(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		if strings.Contains(r.URL.Path, "fail") {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	if err := Post(context.Background(), server.Client(), server.URL+"/ok", Slack, testSummary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(received, "api") {
+		t.Errorf("server received %q", received)
+	}
+
+	err := Post(context.Background(), server.Client(), server.URL+"/fail", Slack, testSummary)
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("error = %v, want webhook failure with response body", err)
+	}
+}
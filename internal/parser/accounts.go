@@ -0,0 +1,45 @@
+package parser
+
+import "github.com/photostructure/go-claude-costs/internal/models"
+
+// UnknownAccount is the account of entries in files that never name one
+const UnknownAccount = "(unknown)"
+
+// entryAccountID returns the account UUID or user ID of an entry, if any
+func entryAccountID(entry *models.Entry) string {
+	if entry.AccountUUID != "" {
+		return entry.AccountUUID
+	}
+	return entry.UserID
+}
+
+// accountName returns the configured name for an account ID, or the ID
+func (p *Parser) accountName(id string) string {
+	if name, ok := p.accountNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// includesAccount reports whether the account filter admits an account,
+// matching either its name or its ID
+func (p *Parser) includesAccount(account string) bool {
+	if account == p.accountFilter {
+		return true
+	}
+	name, ok := p.accountNames[p.accountFilter]
+	return ok && name == account
+}
+
+// updateAccountStats updates per-account cost and token statistics
+func (p *Parser) updateAccountStats(analysis *models.CostAnalysis, account, sessionID string, cost float64, tokens tokenData) {
+	stats := analysis.Accounts[account]
+	if stats == nil {
+		stats = &models.AccountStats{SessionIDs: make(map[string]bool)}
+		analysis.Accounts[account] = stats
+	}
+	stats.SessionIDs[sessionID] = true
+	stats.Cost += cost
+	stats.MessageCount++
+	stats.TotalTokens += tokens.inputTokens + tokens.outputTokens + tokens.cacheReadTokens + tokens.cacheWriteTokens
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// isHaiku reports whether a model is a Haiku model. Interactive sessions run
+// on Sonnet or Opus, so Haiku on the main thread is Claude Code's own
+// housekeeping, such as titling the session; Haiku in a subagent
+// (sidechain) is delegated work.
+func isHaiku(model string) bool {
+	return strings.Contains(model, "haiku")
+}
+
+// trackBackground classifies Haiku responses as housekeeping or subagent
+// work
+func (p *Parser) trackBackground(entry *models.Entry, analysis *models.CostAnalysis, model string, cost float64) {
+	if !isHaiku(model) {
+		return
+	}
+	stats := &analysis.Background
+	if entry.IsSidechain {
+		stats = &analysis.HaikuSubagents
+	}
+	if stats.Messages == nil {
+		stats.Messages = make(map[string]int)
+	}
+	stats.Messages[model]++
+	stats.Cost += cost
+}
+
+// responseCategory returns the models.Category constant that describes a
+// priced response
+func responseCategory(entry *models.Entry, model string) string {
+	if isHaiku(model) && !entry.IsSidechain {
+		return models.CategoryHousekeeping
+	}
+	if entry.IsSidechain {
+		return models.CategorySubagent
+	}
+	thinking := false
+	for _, item := range contentItems(entry) {
+		switch item["type"] {
+		case "tool_use":
+			return models.CategoryToolCall
+		case "thinking", "redacted_thinking":
+			thinking = true
+		}
+	}
+	if thinking {
+		return models.CategoryThinking
+	}
+	return models.CategoryReply
+}
+
+// trackCategory adds a response to its category
+func (p *Parser) trackCategory(entry *models.Entry, analysis *models.CostAnalysis, model string, cost float64, tokens tokenData) {
+	category := responseCategory(entry, model)
+	if analysis.ByCategory == nil {
+		analysis.ByCategory = make(map[string]*models.CategoryStats)
+	}
+	stats := analysis.ByCategory[category]
+	if stats == nil {
+		stats = &models.CategoryStats{}
+		analysis.ByCategory[category] = stats
+	}
+	stats.Messages++
+	stats.Tokens += tokens.inputTokens + tokens.outputTokens + tokens.cacheReadTokens + tokens.cacheWriteTokens
+	stats.Cost += cost
+}
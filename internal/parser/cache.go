@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// cacheNearMiss is how far past models.CacheTTL an idle gap counts as a
+// near miss, one a slightly quicker reply would have avoided
+const cacheNearMiss = 5 * time.Minute
+
+// trackCacheExpiry records responses that wrote the prompt cache again after
+// the session sat idle just past models.CacheTTL. The gap runs from the previous
+// response, when the cache was last used, so it includes this response's own
+// latency. Subagent (sidechain) entries are skipped because they cache their
+// own prompts.
+func (p *Parser) trackCacheExpiry(entry *models.Entry, analysis *models.CostAnalysis, model string, tokens tokenData, timestamp time.Time) {
+	if entry.IsSidechain {
+		return
+	}
+	last := p.lastResponse
+	p.lastResponse = timestamp
+	if last.IsZero() || model == "" {
+		return
+	}
+
+	gap := timestamp.Sub(last)
+	if gap <= models.CacheTTL || gap > models.CacheTTL+cacheNearMiss {
+		return
+	}
+	// A warm cache is mostly read; an expired one is mostly written again
+	if tokens.cacheWriteTokens <= tokens.cacheReadTokens {
+		return
+	}
+
+	pricing := p.pricingFor(model)
+	expiry := &analysis.CacheExpiry
+	if expiry.Gaps == nil {
+		expiry.Gaps = make(map[int]int)
+	}
+	expiry.Gaps[int(gap/time.Minute)]++
+	expiry.Rewrites++
+	expiry.Tokens += tokens.cacheWriteTokens
+	expiry.ExtraCost += float64(tokens.cacheWriteTokens) * (pricing.CacheWrite - pricing.CacheRead) / 1_000_000
+}
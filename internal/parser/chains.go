@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"sort"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// link is what's needed of an entry to follow a conversation into it from
+// another file
+type link struct {
+	parent string
+	time   time.Time
+	user   bool
+	prompt bool // A human prompt, which starts a turn
+	skewed bool // Timestamped before the entry preceding it
+	file   int  // Which of the files read the entry is in
+	turn   turnKey
+	inTurn bool
+}
+
+// turn accumulates a turn's span, which may continue across files
+type turn struct {
+	start  time.Time // When the prompt was sent
+	end    time.Time // The last reply
+	tools  []toolSpan
+	skewed bool
+}
+
+// toolSpan is the time between a tool_use and its tool_result
+type toolSpan struct {
+	end      time.Time
+	duration time.Duration
+}
+
+// turnKey identifies the turn of an entry by its prompt's UUID or, when its
+// chain reaches an entry not yet read, by that entry's UUID
+type turnKey struct {
+	root string
+	exit string
+}
+
+// exitKey is an entry a chain reached before it was read, in the directory
+// it's looked up in
+type exitKey struct {
+	dir  string
+	uuid string
+}
+
+// pendingSpan is a tool_result whose tool_use is in another file
+type pendingSpan struct {
+	dir  string
+	id   string
+	end  time.Time
+	turn *turn
+}
+
+// pendingResponse is a reply whose parent is later in its file or in another
+type pendingResponse struct {
+	dir     string
+	parent  string
+	project string
+	model   string
+	time    time.Time
+	skewed  bool
+}
+
+// resetChains clears the conversation state kept across files. Resumed and
+// branched sessions continue conversations in new files, so parents are
+// looked up in every file of the project directory, not just the entry's
+// own.
+func (p *Parser) resetChains() {
+	p.chains = make(map[string]map[string]link)
+	p.toolUses = make(map[string]map[string]time.Time)
+	p.pendingSpans = nil
+	p.turns = make(map[string]*turn)
+	p.danglingTurns = make(map[exitKey]*turn)
+	p.pendingResponses = nil
+}
+
+// turnFor returns the accumulator of a turn, keyed by its prompt or, for
+// turns whose prompt hasn't been read, by where the chain left off
+func (p *Parser) turnFor(key turnKey) *turn {
+	if key.root == "" {
+		exit := exitKey{p.dir, key.exit}
+		t := p.danglingTurns[exit]
+		if t == nil {
+			t = &turn{}
+			p.danglingTurns[exit] = t
+		}
+		return t
+	}
+	t := p.turns[key.root]
+	if t == nil {
+		prompt := p.chains[p.dir][key.root]
+		t = &turn{start: prompt.time, skewed: prompt.skewed}
+		p.turns[key.root] = t
+	}
+	return t
+}
+
+// chainRoot follows parents from uuid through the directory's index to the
+// prompt that started its turn
+func (p *Parser) chainRoot(dir, uuid string) (string, link, bool) {
+	links := p.chains[dir]
+	for depth := 0; depth < maxTurnDepth; depth++ {
+		l, ok := links[uuid]
+		if !ok {
+			return "", link{}, false
+		}
+		if l.prompt {
+			return uuid, l, true
+		}
+		if l.parent == "" {
+			return "", link{}, false
+		}
+		uuid = l.parent
+	}
+	return "", link{}, false
+}
+
+// finishChains resolves the parents found in other files, then records the
+// response times and turn times of every file
+func (p *Parser) finishChains(analysis *models.CostAnalysis) {
+	for _, r := range p.pendingResponses {
+		parent, ok := p.chains[r.dir][r.parent]
+		if !ok || !parent.user {
+			continue
+		}
+		if p.excludeSkewed && (r.skewed || parent.skewed) {
+			analysis.ClockSkew.Excluded++
+			continue
+		}
+		p.recordResponseTime(analysis, r.project, r.model, r.time.Sub(parent.time))
+	}
+
+	for _, span := range p.pendingSpans {
+		if usedAt, ok := p.toolUses[span.dir][span.id]; ok {
+			if d := span.end.Sub(usedAt); d > 0 {
+				span.turn.tools = append(span.turn.tools, toolSpan{span.end, d})
+			}
+		}
+	}
+
+	for exit, partial := range p.danglingTurns {
+		root, prompt, ok := p.chainRoot(exit.dir, exit.uuid)
+		if !ok {
+			continue
+		}
+		t := p.turns[root]
+		if t == nil {
+			t = &turn{start: prompt.time, skewed: prompt.skewed}
+			p.turns[root] = t
+		}
+		if partial.end.After(t.end) {
+			t.end = partial.end
+		}
+		t.tools = append(t.tools, partial.tools...)
+		t.skewed = t.skewed || partial.skewed
+	}
+
+	roots := make([]string, 0, len(p.turns))
+	for root := range p.turns {
+		roots = append(roots, root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		ti, tj := p.turns[roots[i]], p.turns[roots[j]]
+		if !ti.start.Equal(tj.start) {
+			return ti.start.Before(tj.start)
+		}
+		return roots[i] < roots[j]
+	})
+	for _, root := range roots {
+		p.recordTurn(analysis, p.turns[root])
+	}
+	p.resetChains()
+}
+
+// recordTurn records a turn's wall-clock and model-only time
+func (p *Parser) recordTurn(analysis *models.CostAnalysis, t *turn) {
+	if t.end.IsZero() {
+		return
+	}
+	if p.excludeSkewed && t.skewed {
+		analysis.ClockSkew.Excluded++
+		return
+	}
+	wall := t.end.Sub(t.start)
+	if wall <= 0 {
+		return
+	}
+
+	// Tool results after the final reply (e.g. an abandoned turn) aren't
+	// part of the measured span
+	var tools time.Duration
+	for _, span := range t.tools {
+		if !span.end.After(t.end) {
+			tools += span.duration
+		}
+	}
+
+	model := wall - tools
+	if model < 0 {
+		model = 0
+	}
+
+	analysis.TurnTimes = append(analysis.TurnTimes, wall)
+	analysis.TurnModelTimes = append(analysis.TurnModelTimes, model)
+	analysis.ToolExecutionTime += tools
+}
@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// trackContext splits a response's input between context repeated from the
+// previous response, which re-sends that response's input and output, and
+// novel content such as the new prompt and tool results. After /compact the
+// shorter summary counts as repeated context. Subagent (sidechain) entries
+// are skipped because they carry a context of their own.
+func (p *Parser) trackContext(entry *models.Entry, analysis *models.CostAnalysis, sessionID, model string, tokens tokenData) {
+	if entry.IsSidechain || model == "" {
+		return
+	}
+	context := tokens.inputTokens + tokens.cacheReadTokens + tokens.cacheWriteTokens
+	repeated := min(context, p.lastContext)
+	p.lastContext = context + tokens.outputTokens
+	if context == 0 {
+		return
+	}
+
+	session := p.getOrCreateSession(analysis, sessionID)
+	session.ContextTokens += context
+	session.RepeatedTokens += repeated
+	session.RepeatedCost += tokens.inputCost() * float64(repeated) / float64(context)
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// DedupeFiles leaves out copies of session files, as synced dotfiles and
+// backups put alongside the originals under other project directories.
+// Claude Code names each file for its session, so only files sharing a name
+// are compared: one whose content is the same as, or the start of, another's
+// is a copy. Of identical files the first listed is kept. The files kept are
+// returned in their original order.
+func DedupeFiles(files []string) ([]string, []models.DuplicateFile) {
+	return dedupeFiles(osFiles{}, files)
+}
+
+// dedupeFiles is DedupeFiles for files read from fsys
+func dedupeFiles(fsys fileSystem, files []string) ([]string, []models.DuplicateFile) {
+	bySession := make(map[string][]int)
+	for i, file := range files {
+		name := path.Base(filepath.ToSlash(file))
+		bySession[name] = append(bySession[name], i)
+	}
+
+	originals := make(map[int]string)
+	for _, group := range bySession {
+		if len(group) < 2 {
+			continue
+		}
+
+		// Files that can't be statted are kept, so that reading them
+		// reports the error
+		sizes := make(map[int]int64, len(group))
+		candidates := group[:0:0]
+		for _, i := range group {
+			if info, err := fsys.Stat(files[i]); err == nil {
+				sizes[i] = info.Size()
+				candidates = append(candidates, i)
+			}
+		}
+
+		// Largest first, so each file is compared with those it may be the
+		// start of
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return sizes[candidates[a]] > sizes[candidates[b]]
+		})
+		var kept []int
+		for _, i := range candidates {
+			for _, k := range kept {
+				if samePrefix(fsys, files[i], files[k], sizes[i]) {
+					originals[i] = files[k]
+					break
+				}
+			}
+			if _, ok := originals[i]; !ok {
+				kept = append(kept, i)
+			}
+		}
+	}
+
+	if len(originals) == 0 {
+		return files, nil
+	}
+	unique := make([]string, 0, len(files)-len(originals))
+	var duplicates []models.DuplicateFile
+	for i, file := range files {
+		if original, ok := originals[i]; ok {
+			duplicates = append(duplicates, models.DuplicateFile{Path: file, Original: original})
+		} else {
+			unique = append(unique, file)
+		}
+	}
+	return unique, duplicates
+}
+
+// samePrefix reports whether the first n bytes of two files are the same.
+// Files that can't be read aren't.
+func samePrefix(fsys fileSystem, a, b string, n int64) bool {
+	fa, err := fsys.Open(a)
+	if err != nil {
+		return false
+	}
+	defer fa.Close()
+	fb, err := fsys.Open(b)
+	if err != nil {
+		return false
+	}
+	defer fb.Close()
+
+	ra, rb := io.LimitReader(fa, n), io.LimitReader(fb, n)
+	bufA, bufB := make([]byte, 64*1024), make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(ra, bufA)
+		nb, _ := io.ReadFull(rb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false
+		}
+		if errA != nil {
+			// Both ended together
+			return true
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// ParseEntryLine decodes one JSONL line as Claude Code writes it, setting
+// ParsedTimestamp when the entry has a timestamp. Entries without one, such
+// as summaries, are returned with a zero ParsedTimestamp. Lines that aren't
+// an entry or carry an invalid timestamp return an error; no line, however
+// malformed, panics.
+func ParseEntryLine(line []byte) (models.Entry, error) {
+	var entry models.Entry
+	err := decodeEntry(line, &entry)
+	return entry, err
+}
+
+// decodeEntry is ParseEntryLine decoding into a zero entry, such as one from
+// entryPool
+func decodeEntry(line []byte, entry *models.Entry) error {
+	if err := json.Unmarshal(line, entry); err != nil {
+		return err
+	}
+	if entry.Timestamp == "" {
+		return nil
+	}
+
+	timestamp, err := parseTimestamp(entry.Timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+	entry.ParsedTimestamp = timestamp
+	return nil
+}
@@ -0,0 +1,50 @@
+package parser
+
+import "github.com/photostructure/go-claude-costs/internal/models"
+
+// builtinEntryTypes are the entry types the parser reads itself. Claude Code
+// also writes others, such as file history snapshots, and adds more over
+// time.
+var builtinEntryTypes = []string{"user", "assistant", "summary", "system"}
+
+// EntryHandler is called with an entry of the type it was registered for,
+// along with its session ID and project name
+type EntryHandler func(entry models.Entry, sessionID, project string)
+
+// WithEntryHandler calls fn with each entry of type typ in the analysis, in
+// file order as each file is parsed, those of built-in types after the
+// parser's own processing, and every entry of large files is read rather
+// than seeking to the cutoff. Registering a type also stops it being counted
+// in UnknownEntryTypes.
+func WithEntryHandler(typ string, fn EntryHandler) Option {
+	return func(p *Parser) {
+		if fn != nil {
+			p.entryTypes[typ] = append(p.entryTypes[typ], fn)
+		}
+	}
+}
+
+// countEntryType tallies entries whose type is neither built in nor has a
+// handler. Entries without a type aren't counted.
+func (p *Parser) countEntryType(entry *models.Entry, analysis *models.CostAnalysis) {
+	if entry.Type == "" {
+		return
+	}
+	if _, ok := p.entryTypes[entry.Type]; !ok {
+		analysis.UnknownEntryTypes[entry.Type]++
+	}
+}
+
+// handleEntry passes an entry to the handlers of its type, if any
+func (p *Parser) handleEntry(entry *models.Entry, sessionID, projectName string) {
+	for _, fn := range p.entryTypes[entry.Type] {
+		fn(*entry, sessionID, projectName)
+	}
+}
+
+// logUnknownEntryTypes notes the skipped types, most frequent first
+func (p *Parser) logUnknownEntryTypes(analysis *models.CostAnalysis) {
+	for _, typ := range analysis.UnknownTypes() {
+		p.logger.Debug("skipped entries of unknown type", "type", typ, "count", analysis.UnknownEntryTypes[typ])
+	}
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindOption configures FindFiles
+type FindOption func(*finder)
+
+// SkipSymlinks leaves out symlinked project directories and files rather
+// than following them
+func SkipSymlinks() FindOption {
+	return func(f *finder) {
+		f.skipSymlinks = true
+	}
+}
+
+// finder lists the JSONL files under a projects directory, visiting each
+// directory and file once however many paths lead to it
+type finder struct {
+	skipSymlinks bool
+	seen         map[int64][]os.FileInfo // Directories and files visited, by modification time
+}
+
+// found is a directory entry to visit
+type found struct {
+	path string
+	info os.FileInfo // Of the target, for symlinks
+	link bool
+}
+
+// FindFiles returns the JSONL files under the projects directory of
+// claudeDir: those in each project directory, then those one level deeper.
+// Symlinked directories and files are followed unless SkipSymlinks is
+// given. A directory or file reached by more than one path, whether through
+// symlinks, bind mounts, or a link back to an ancestor, is listed once,
+// preferring a path without symlinks.
+func FindFiles(claudeDir string, opts ...FindOption) ([]string, error) {
+	f := &finder{seen: make(map[int64][]os.FileInfo)}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	projectsDir := filepath.Join(claudeDir, "projects")
+	if info, err := os.Stat(projectsDir); err == nil {
+		f.visit(info)
+	}
+
+	var files []string
+	dirs := []string{projectsDir}
+	for depth := 0; depth <= 2; depth++ {
+		var subdirs, jsonl []found
+		for _, dir := range dirs {
+			entries := f.list(dir)
+			for _, entry := range entries {
+				switch {
+				case entry.info.IsDir() && depth < 2:
+					subdirs = append(subdirs, entry)
+				case entry.info.Mode().IsRegular() && strings.HasSuffix(entry.path, ".jsonl"):
+					jsonl = append(jsonl, entry)
+				}
+			}
+		}
+
+		// Files directly under projects aren't any project's
+		if depth > 0 {
+			for _, entry := range f.unvisited(jsonl) {
+				files = append(files, entry.path)
+			}
+		}
+		dirs = nil
+		for _, entry := range f.unvisited(subdirs) {
+			dirs = append(dirs, entry.path)
+		}
+	}
+	return files, nil
+}
+
+// list returns the entries of dir in lexical order, with the targets of
+// symlinks. Dangling symlinks and unreadable directories are skipped.
+func (f *finder) list(dir string) []found {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	list := make([]found, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		link := entry.Type()&os.ModeSymlink != 0
+		if link && f.skipSymlinks {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		list = append(list, found{path: path, info: info, link: link})
+	}
+	return list
+}
+
+// unvisited returns the entries not yet visited, in order, marking them
+// visited. Entries reached without a symlink claim their targets first.
+func (f *finder) unvisited(entries []found) []found {
+	keep := make([]bool, len(entries))
+	for _, pass := range []bool{false, true} {
+		for i, entry := range entries {
+			if entry.link == pass && f.visit(entry.info) {
+				keep[i] = true
+			}
+		}
+	}
+	var unvisited []found
+	for i, entry := range entries {
+		if keep[i] {
+			unvisited = append(unvisited, entry)
+		}
+	}
+	return unvisited
+}
+
+// visit marks a directory or file visited, reporting whether it wasn't
+// already. Entries are bucketed by modification time, which every path to
+// the same file shares, so few are compared.
+func (f *finder) visit(info os.FileInfo) bool {
+	key := info.ModTime().UnixNano()
+	for _, seen := range f.seen[key] {
+		if os.SameFile(seen, info) {
+			return false
+		}
+	}
+	f.seen[key] = append(f.seen[key], info)
+	return true
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// WithFS reads the Claude directory from fsys instead of the disk, as from
+// embedded fixtures, a zip archive, or an fstest.MapFS. The parser's Claude
+// directory is then a path within fsys, "." for its root. Files are listed
+// as FindFiles lists them on disk, except that fsys decides whether
+// symlinks are followed. Nothing is read from the host: project directory
+// names are decoded without looking for the paths they encode, so every
+// hyphen in a name under home separates directories.
+func WithFS(fsys fs.FS) Option {
+	return func(p *Parser) {
+		p.source = fsSource{fsys}
+		p.fsys = fsFiles{fsys}
+	}
+}
+
+// fsSource is a LineSource reading the JSONL files of an fs.FS
+type fsSource struct {
+	fsys fs.FS
+}
+
+// Files implements LineSource, listing the JSONL files in each project
+// directory under claudeDir, then those one level deeper
+func (s fsSource) Files(claudeDir string) ([]string, error) {
+	var files []string
+	dirs := []string{path.Join(claudeDir, "projects")}
+	for depth := 0; depth <= 2; depth++ {
+		var subdirs []string
+		for _, dir := range dirs {
+			entries, err := fs.ReadDir(s.fsys, dir)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				name := path.Join(dir, entry.Name())
+				info, err := fs.Stat(s.fsys, name)
+				if err != nil {
+					continue
+				}
+				switch {
+				case info.IsDir() && depth < 2:
+					subdirs = append(subdirs, name)
+				// Files directly under projects aren't any project's
+				case info.Mode().IsRegular() && strings.HasSuffix(name, ".jsonl") && depth > 0:
+					files = append(files, name)
+				}
+			}
+		}
+		dirs = subdirs
+	}
+	return files, nil
+}
+
+// EachLine implements LineSource
+func (s fsSource) EachLine(file string, cutoff time.Time, fn func(line []byte)) error {
+	f, err := s.fsys.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	scanner.Buffer((*buf)[:0], MaxLineSize)
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// fileSystem is where the files DedupeFiles compares are read from
+type fileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	Open(name string) (fs.File, error)
+}
+
+// osFiles reads files from the disk
+type osFiles struct{}
+
+func (osFiles) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+func (osFiles) Open(name string) (fs.File, error)     { return os.Open(name) }
+
+// fsFiles reads files from an fs.FS
+type fsFiles struct {
+	fsys fs.FS
+}
+
+func (f fsFiles) Stat(name string) (fs.FileInfo, error) { return fs.Stat(f.fsys, name) }
+func (f fsFiles) Open(name string) (fs.File, error)     { return f.fsys.Open(name) }
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fuzzSeeds are lines shaped like Claude Code's, and damaged versions of them
+var fuzzSeeds = []string{
+	`{"uuid":"1","type":"user","timestamp":"2025-06-13T09:00:00Z","sessionId":"s","message":{"role":"user","content":"Fix the build"}}`,
+	`{"uuid":"2","parentUuid":"1","type":"assistant","timestamp":"2025-06-13T09:00:05Z","sessionId":"s","message":{"role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go build ./..."}}],"usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":300,"cache_read_input_tokens":4000}}}`,
+	`{"uuid":"3","parentUuid":"2","type":"user","timestamp":"2025-06-13T09:00:09Z","toolUseResult":{"interrupted":true},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"The user doesn't want to proceed"}],"is_error":true}]}}`,
+	`{"uuid":"4","type":"assistant","timestamp":"2025-06-13T09:01:00Z","isApiErrorMessage":true,"message":{"model":"<synthetic>","content":[{"type":"text","text":"Claude AI usage limit reached|1751234400"}]}}`,
+	`{"uuid":"5","type":"system","timestamp":"2025-06-13T09:02:00Z","content":"API Error: Rate limited"}`,
+	`{"type":"summary","summary":"Fixing the build","leafUuid":"2"}`,
+	`{"uuid":"6","type":"assistant","timestamp":"yesterday"}`,
+	`{"uuid":"7","type":`,
+	`{"message":{"content":[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]}}`,
+	`{"message":{"content":{"type":"tool_use"},"usage":null},"timestamp":"2025-06-13T09:03:00Z","type":"assistant"}`,
+	`[1,2,3]`,
+	`null`,
+}
+
+func FuzzParseEntryLine(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Add([]byte(`{"message":{"content":` + strings.Repeat("[", 20000) + strings.Repeat("]", 20000) + `}}`))
+
+	f.Fuzz(func(t *testing.T, line []byte) {
+		entry, err := ParseEntryLine(line)
+		if err == nil && entry.Timestamp != "" && entry.ParsedTimestamp.IsZero() {
+			t.Errorf("timestamp %q accepted but not parsed", entry.Timestamp)
+		}
+	})
+}
+
+// fuzzSource serves one session file of the given lines
+type fuzzSource []byte
+
+func (s fuzzSource) Files(claudeDir string) ([]string, error) {
+	return []string{"/claude/projects/-home-user-src-app/session.jsonl"}, nil
+}
+
+func (s fuzzSource) EachLine(file string, cutoff time.Time, fn func(line []byte)) error {
+	for _, line := range bytes.Split(s, []byte("\n")) {
+		fn(line)
+	}
+	return nil
+}
+
+// FuzzParseAll runs whole files through every stage of the parser, where
+// entries that decode but hold unexpected shapes are handled
+func FuzzParseAll(f *testing.F) {
+	f.Add([]byte(strings.Join(fuzzSeeds, "\n")))
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(fuzzSeeds[0] + "\n" + seed))
+	}
+
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		analysis, err := New(365, "/claude", WithLineSource(fuzzSource(data)), WithNow(now)).ParseAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if analysis.TotalCost < 0 {
+			t.Errorf("TotalCost = %v", analysis.TotalCost)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// imageCount returns the number of image blocks in a user entry, including
+// those inside tool_results, such as screenshots a tool read
+func imageCount(entry *models.Entry) int {
+	count := 0
+	for _, item := range contentItems(entry) {
+		switch item["type"] {
+		case "image":
+			count++
+		case "tool_result":
+			blocks, _ := item["content"].([]interface{})
+			for _, block := range blocks {
+				if b, ok := block.(map[string]interface{}); ok && b["type"] == "image" {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// trackImages notes when the current turn carries images. A turn runs from
+// a human prompt to the next, and the images stay in it through any tool
+// calls. Subagent (sidechain) entries are skipped because they run turns of
+// their own.
+func (p *Parser) trackImages(entry *models.Entry, analysis *models.CostAnalysis, projectName string) {
+	if entry.IsSidechain {
+		return
+	}
+	if isPrompt(entry) {
+		p.turnImages = false
+	}
+	images := imageCount(entry)
+	if images == 0 {
+		return
+	}
+	project := p.getOrCreateProject(analysis, projectName)
+	project.Images.Images += images
+	if !p.turnImages {
+		project.Images.Turns++
+		p.turnImages = true
+	}
+}
+
+// trackImageCost attributes the input side of a response to images when its
+// turn carries any
+func (p *Parser) trackImageCost(entry *models.Entry, project *models.ProjectStats, model string, tokens tokenData) {
+	if entry.IsSidechain || !p.turnImages || model == "" {
+		return
+	}
+	project.Images.InputCost += tokens.inputCost()
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// interruptMarker starts the text Claude Code writes as a user message when
+// the user stops a response, as in "[Request interrupted by user for tool
+// use]"
+const interruptMarker = "[Request interrupted by user"
+
+// abandonedAfter is how long a session must have been idle, waiting on a
+// tool call, before its last turn counts as abandoned rather than running
+const abandonedAfter = 10 * time.Minute
+
+// isInterruption reports whether a user entry records the user stopping a
+// response or tool call
+func isInterruption(entry *models.Entry) bool {
+	if entry.ToolUseResult != nil && entry.ToolUseResult.Interrupted {
+		return true
+	}
+	if entry.Message == nil {
+		return false
+	}
+	if s, ok := entry.Message.Content.(string); ok {
+		return strings.HasPrefix(s, interruptMarker)
+	}
+	for _, item := range contentItems(entry) {
+		if text, _ := item["text"].(string); item["type"] == "text" && strings.HasPrefix(text, interruptMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackInterrupts follows the turns of the current file. A turn runs from a
+// human prompt to the next, and counts as interrupted at most once however
+// many of its tool calls were stopped. Subagent (sidechain) entries are
+// skipped because their interruptions stop the main thread's turn too.
+func (p *Parser) trackInterrupts(entry *models.Entry) {
+	if entry.IsSidechain {
+		return
+	}
+	switch {
+	case isInterruption(entry):
+		if !p.turnInterrupted {
+			p.interrupts.Interrupted++
+			p.interrupts.Cost += p.turnCost
+			p.turnInterrupted = true
+		}
+	case isPrompt(entry):
+		p.interrupts.Turns++
+		p.turnCost = 0
+		p.turnInterrupted = false
+	}
+}
+
+// finishInterrupts adds the file's turns to its project. The last turn
+// counts as abandoned when the session ended on a tool call that never got
+// a result, and has been idle for abandonedAfter since. last is when the
+// file's last entry was written and calls the tools it called.
+func (p *Parser) finishInterrupts(analysis *models.CostAnalysis, projectName string, last time.Time, calls []string) {
+	project, ok := analysis.Projects[projectName]
+	if !ok {
+		return
+	}
+	if !p.turnInterrupted && p.awaitingTool(calls) && time.Since(last) >= abandonedAfter {
+		p.interrupts.Abandoned++
+		p.interrupts.Cost += p.turnCost
+	}
+	project.Interrupts.Turns += p.interrupts.Turns
+	project.Interrupts.Interrupted += p.interrupts.Interrupted
+	project.Interrupts.Abandoned += p.interrupts.Abandoned
+	project.Interrupts.Cost += p.interrupts.Cost
+}
+
+// awaitingTool reports whether any of the tool calls hasn't had its
+// tool_result seen
+func (p *Parser) awaitingTool(calls []string) bool {
+	for _, id := range calls {
+		if _, pending := p.pendingTools[id]; pending {
+			return true
+		}
+	}
+	return false
+}
+
+// appendToolUseIDs appends the IDs of the tools an assistant entry called
+func appendToolUseIDs(ids []string, entry *models.Entry) []string {
+	if entry.Type != "assistant" {
+		return ids
+	}
+	for _, item := range contentItems(entry) {
+		if id, ok := item["id"].(string); ok && item["type"] == "tool_use" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
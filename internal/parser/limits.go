@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// usageLimitPatterns are lowercase substrings of the messages Claude Code
+// logs when the plan's usage limit is reached, such as "Claude AI usage
+// limit reached|1751234400" or "5-hour limit reached ∙ resets 3pm"
+var usageLimitPatterns = []string{
+	"usage limit reached",
+	"hour limit reached",
+	"weekly limit reached",
+	"limit will reset",
+}
+
+// rateLimitPatterns are lowercase substrings of API rate limit errors
+var rateLimitPatterns = []string{
+	"rate_limit_error",
+	"rate limit",
+	"rate-limit",
+	"api error: 429",
+	"status code 429",
+}
+
+// processLimitEvent records usage limit and rate limit messages. Only
+// entries Claude Code writes itself are checked, so a model response that
+// merely discusses rate limits isn't counted.
+func (p *Parser) processLimitEvent(entry *models.Entry, analysis *models.CostAnalysis, projectName, sessionID string) {
+	switch entry.Type {
+	case "system":
+	case "assistant":
+		if !entry.IsAPIErrorMessage && (entry.Message == nil || entry.Message.Model != "<synthetic>") {
+			return
+		}
+	default:
+		return
+	}
+
+	kind, ok := limitKind(entryText(entry))
+	if !ok {
+		return
+	}
+	analysis.LimitEvents = append(analysis.LimitEvents, models.LimitEvent{
+		Time:      entry.ParsedTimestamp,
+		Kind:      kind,
+		SessionID: sessionID,
+		Project:   projectName,
+	})
+}
+
+// limitKind classifies a message as a usage limit or rate limit
+func limitKind(text string) (models.LimitKind, bool) {
+	text = strings.ToLower(text)
+	for _, pattern := range usageLimitPatterns {
+		if strings.Contains(text, pattern) {
+			return models.UsageLimit, true
+		}
+	}
+	for _, pattern := range rateLimitPatterns {
+		if strings.Contains(text, pattern) {
+			return models.RateLimit, true
+		}
+	}
+	return "", false
+}
+
+// entryText returns the text of a system entry or the text blocks of a
+// message
+func entryText(entry *models.Entry) string {
+	if s, ok := entry.Content.(string); ok {
+		return s
+	}
+	if entry.Message == nil {
+		return ""
+	}
+	if s, ok := entry.Message.Content.(string); ok {
+		return s
+	}
+
+	var texts []string
+	for _, item := range contentItems(entry) {
+		if s, ok := item["text"].(string); ok && item["type"] == "text" {
+			texts = append(texts, s)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// estimateLines estimates the lines a file tool call changes from its
+// input. Edits count the lines between the parts old_string and new_string
+// share at either end. Writes count every line as added, since the file
+// they replace isn't in the input.
+func estimateLines(name string, input map[string]interface{}) models.LineChanges {
+	var lines models.LineChanges
+	switch name {
+	case "Edit":
+		old, _ := input["old_string"].(string)
+		new, _ := input["new_string"].(string)
+		lines = diffLines(old, new)
+	case "MultiEdit":
+		edits, _ := input["edits"].([]interface{})
+		for _, edit := range edits {
+			e, _ := edit.(map[string]interface{})
+			old, _ := e["old_string"].(string)
+			new, _ := e["new_string"].(string)
+			lines.Add(diffLines(old, new))
+		}
+	case "Write":
+		content, _ := input["content"].(string)
+		lines.Added = len(splitLines(content))
+	case "NotebookEdit":
+		if mode, _ := input["edit_mode"].(string); mode != "delete" {
+			source, _ := input["new_source"].(string)
+			lines.Added = len(splitLines(source))
+		}
+	}
+	return lines
+}
+
+// diffLines counts the lines of old removed and of new added, after
+// dropping the lines they share at the start and end
+func diffLines(old, new string) models.LineChanges {
+	a, b := splitLines(old), splitLines(new)
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	return models.LineChanges{Added: len(b), Removed: len(a)}
+}
+
+// splitLines splits s into lines, without an empty one after a final
+// newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// patchLines counts the added and removed lines of a structured patch
+func patchLines(hunks []models.PatchHunk) models.LineChanges {
+	var lines models.LineChanges
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				lines.Added++
+			case strings.HasPrefix(line, "-"):
+				lines.Removed++
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"runtime/metrics"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// memoryCheckInterval is how many lines are read between checks of the heap
+const memoryCheckInterval = 4096
+
+// liveHeap returns the heap in use as of the last garbage collection. It's a
+// variable so tests can stand in for the runtime.
+var liveHeap = func() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// WithMemoryLimit keeps the live heap under limit bytes by degrading rather
+// than running out of memory: once it passes three quarters of the limit,
+// the index of entries kept to follow conversations across files is
+// dropped, along with the response and turn times it's needed for. Costs
+// and everything else are unaffected. Zero means no limit.
+func WithMemoryLimit(limit int64) Option {
+	return func(p *Parser) {
+		p.memoryLimit = limit
+	}
+}
+
+// countLine counts a line read, checking the heap every
+// memoryCheckInterval lines
+func (p *Parser) countLine(analysis *models.CostAnalysis) {
+	p.lines++
+	if p.lines%memoryCheckInterval == 0 {
+		p.checkMemory(analysis)
+	}
+}
+
+// checkMemory drops response and turn times once the heap nears the limit
+func (p *Parser) checkMemory(analysis *models.CostAnalysis) {
+	if p.memoryLimit <= 0 || p.memoryLimited {
+		return
+	}
+	live := liveHeap()
+	if live < uint64(p.memoryLimit)/4*3 {
+		return
+	}
+
+	p.logger.Warn("memory limit reached, skipping response and turn times", "limit", p.memoryLimit, "live_heap", live)
+	p.memoryLimited = true
+	p.skewed = false
+	p.resetChains()
+
+	// Times already measured cover only the files read so far
+	analysis.MemoryLimited = true
+	analysis.ResponseTimes = []time.Duration{}
+	analysis.ModelResponseTimes = make(map[string][]time.Duration)
+	analysis.ResponseTimeOutliers = 0
+	analysis.ClockSkew.Excluded = 0
+	for _, project := range analysis.Projects {
+		project.ResponseTimes = nil
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// Message is one priced assistant response
+type Message struct {
+	// ID is the entry's UUID, or the session and time when it has none
+	ID               string
+	Time             time.Time
+	SessionID        string
+	Project          string
+	Model            string
+	Cost             float64
+	InputTokens      int
+	OutputTokens     int
+	CacheReadTokens  int
+	CacheWriteTokens int
+}
+
+// WithMessageHandler calls fn with each priced assistant response in the
+// analysis, in file order, as it is parsed
+func WithMessageHandler(fn func(Message)) Option {
+	return func(p *Parser) {
+		p.onMessage = fn
+	}
+}
+
+// handleMessage passes a priced response to the message handler, if any
+func (p *Parser) handleMessage(entry *models.Entry, projectName, sessionID, model string, cost float64, tokens tokenData) {
+	if p.onMessage == nil {
+		return
+	}
+	id := entry.UUID
+	if id == "" {
+		id = sessionID + "@" + entry.Timestamp
+	}
+	p.onMessage(Message{
+		ID:               id,
+		Time:             entry.ParsedTimestamp,
+		SessionID:        sessionID,
+		Project:          projectName,
+		Model:            model,
+		Cost:             cost,
+		InputTokens:      tokens.inputTokens,
+		OutputTokens:     tokens.outputTokens,
+		CacheReadTokens:  tokens.cacheReadTokens,
+		CacheWriteTokens: tokens.cacheWriteTokens,
+	})
+}
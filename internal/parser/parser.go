@@ -2,255 +2,702 @@ package parser
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/photostructure/go-claude-costs/internal/models"
-	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/photostructure/go-claude-costs/internal/rules"
 )
 
+// Default bounds for response times. Deltas outside these bounds are treated
+// as outliers (e.g. the user walked away) rather than model latency.
+const (
+	DefaultResponseMin = 0
+	DefaultResponseMax = 5 * time.Minute
+)
+
+// ErrNoJSONLFiles is returned when the Claude directory contains no JSONL files
+var ErrNoJSONLFiles = errors.New("no JSONL files found")
+
 // Parser handles parsing JSONL files and extracting cost data
 type Parser struct {
-	projectNameCache map[string]string // Cache for project name extraction
+	projectNameCache map[string]string      // Cache for project name extraction
+	pendingTools     map[string]pendingTool // tool_use blocks awaiting results, per file
+	sessionOpus      bool                   // The current file has used Opus since the last /model
+	lastFamily       string                 // Model family of the current file's previous response
+	lastResponse     time.Time              // When the current file's previous main-thread response arrived
+	turnImages       bool                   // The current file's turn includes images
+	lastContext      int                    // Input and output tokens of the current file's previous main-thread response
+	prompts          int                    // Human prompts in the current file
+	interrupts       models.InterruptStats  // The current file's turns
+	turnCost         float64                // Cost of the current file's turn so far
+	turnInterrupted  bool                   // The current file's turn was interrupted
+	pricing          *rules.PricingRule     // Negotiated rates of the current file's project, if any
+	logger           *slog.Logger
+	rejectionPats    []string
+	aliases          rules.Aliases
+	tagRules         rules.TagRules
+	pricingRules     rules.PricingRules
+	projectFilter    []string
+	accountNames     map[string]string // Account ID to display name
+	accountFilter    string
+	source           LineSource // Reads lines from an index instead of the files
+	fsys             fileSystem // Where files are compared for duplicates
+	findOpts         []FindOption
+	onMessage        func(Message)
+	entryTypes       map[string][]EntryHandler // Types read, with any handlers
 	claudeDir        string
 	daysToAnalyze    int
+	since            time.Time // Exclusive lower bound, overrides the day window
+	now              time.Time // End of the day window; zero is the current time
+	days             models.DayBoundary
+	responseMin      time.Duration
+	responseMax      time.Duration
+	excludeSkewed    bool
+	skewed           bool  // The entry being processed is timestamped out of order
+	memoryLimit      int64 // Bytes of live heap to stay under; zero is unlimited
+	memoryLimited    bool  // Response and turn times were dropped to stay under it
+	lines            int   // Lines read, for pacing memory checks
+	files            int   // Files read, numbering each file's links
+	readStats        *ReadStats
+	titledAt         map[string]time.Time            // Last entry of the file each session's title came from
+	dir              string                          // The current file's project directory
+	chains           map[string]map[string]link      // Entries of each project directory, by UUID
+	turns            map[string]*turn                // Turns by the UUID of their prompt
+	danglingTurns    map[exitKey]*turn               // Turns continued from a prompt in another file
+	pendingResponses []pendingResponse               // Replies to entries in other files
+	toolUses         map[string]map[string]time.Time // When each project directory's tools were called, by tool_use ID
+	pendingSpans     []pendingSpan                   // Tool results for tool uses in other files
+}
+
+// Option configures optional Parser behavior
+type Option func(*Parser)
+
+// WithLogger sets the logger used for parse warnings and diagnostics
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Parser) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+// WithResponseTimeBounds sets the range of parent-to-child deltas accepted as
+// response times. Deltas outside [min, max) are counted as outliers. A zero
+// max disables the ceiling.
+func WithResponseTimeBounds(min, max time.Duration) Option {
+	return func(p *Parser) {
+		p.responseMin = min
+		p.responseMax = max
+	}
+}
+
+// WithRejectionPatterns adds tool_result substrings that mean the user
+// declined a tool use, in addition to DefaultRejectionPatterns
+func WithRejectionPatterns(patterns ...string) Option {
+	return func(p *Parser) {
+		for _, pattern := range patterns {
+			if pattern != "" {
+				p.rejectionPats = append(p.rejectionPats, pattern)
+			}
+		}
+	}
+}
+
+// WithProjectAliases rolls projects matching the alias patterns up under the
+// alias names
+func WithProjectAliases(aliases rules.Aliases) Option {
+	return func(p *Parser) {
+		p.aliases = aliases
+	}
+}
+
+// WithTagRules labels sessions using the given tag rules
+func WithTagRules(tagRules rules.TagRules) Option {
+	return func(p *Parser) {
+		p.tagRules = tagRules
+	}
+}
+
+// WithPricingRules prices the projects matching a rule at its rates instead
+// of list prices
+func WithPricingRules(pricingRules rules.PricingRules) Option {
+	return func(p *Parser) {
+		p.pricingRules = pricingRules
+	}
+}
+
+// WithProjectFilter limits the analysis to projects matching any of the
+// patterns, tried against both the project path and its alias. No patterns
+// include every project.
+func WithProjectFilter(patterns ...string) Option {
+	return func(p *Parser) {
+		p.projectFilter = append(p.projectFilter, patterns...)
+	}
+}
+
+// LineSource supplies JSONL lines in place of reading the files, such as
+// the SQLite store kept up to date by the daemon command
+type LineSource interface {
+	// Files returns the JSONL file paths under claudeDir known to the source
+	Files(claudeDir string) ([]string, error)
+	// EachLine calls fn with the lines of file in order. Lines with a
+	// timestamp before cutoff may be omitted. fn doesn't keep a line once
+	// it returns, so sources may reuse the memory.
+	EachLine(file string, cutoff time.Time, fn func(line []byte)) error
+}
+
+// MaxLineSize is the longest JSONL line read
+const MaxLineSize = 50 * 1024 * 1024
+
+// WithLineSource reads JSONL lines from source instead of the Claude
+// directory
+func WithLineSource(source LineSource) Option {
+	return func(p *Parser) {
+		p.source = source
+	}
+}
+
+// WithFindOptions configures how files are found in the Claude directory.
+// Line sources find their own.
+func WithFindOptions(opts ...FindOption) Option {
+	return func(p *Parser) {
+		p.findOpts = append(p.findOpts, opts...)
+	}
+}
+
+// WithAccountNames names accounts by their user or account ID
+func WithAccountNames(names map[string]string) Option {
+	return func(p *Parser) {
+		p.accountNames = names
+	}
+}
+
+// WithAccount limits the analysis to one account, given by name or ID.
+// Entries without an account are excluded.
+func WithAccount(account string) Option {
+	return func(p *Parser) {
+		p.accountFilter = account
+	}
+}
+
+// WithSince limits the analysis to entries after since, instead of the last
+// days. Entries exactly at since were already reported and are skipped.
+func WithSince(since time.Time) Option {
+	return func(p *Parser) {
+		p.since = since
+	}
+}
+
+// WithNow counts the days to analyze back from now instead of the current
+// time, so a fixed directory analyzes the same way on any day
+func WithNow(now time.Time) Option {
+	return func(p *Parser) {
+		p.now = now
+	}
+}
+
+// WithDayBoundary divides daily totals into days as b does, instead of by
+// local calendar day
+func WithDayBoundary(b models.DayBoundary) Option {
+	return func(p *Parser) {
+		p.days = b
+	}
 }
 
 // New creates a new Parser instance
-func New(days int, claudeDir string) *Parser {
-	return &Parser{
+func New(days int, claudeDir string, opts ...Option) *Parser {
+	p := &Parser{
 		daysToAnalyze:    days,
 		claudeDir:        claudeDir,
 		projectNameCache: make(map[string]string),
+		logger:           slog.Default(),
+		responseMin:      DefaultResponseMin,
+		responseMax:      DefaultResponseMax,
+		rejectionPats:    append([]string(nil), DefaultRejectionPatterns...),
+		entryTypes:       make(map[string][]EntryHandler),
+		fsys:             osFiles{},
+	}
+	p.resetChains()
+	for _, typ := range builtinEntryTypes {
+		p.entryTypes[typ] = nil
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // ParseAll parses all JSONL files and returns the analysis
 func (p *Parser) ParseAll() (*models.CostAnalysis, error) {
+	now := p.now
+	if now.IsZero() {
+		now = time.Now()
+	}
 	analysis := &models.CostAnalysis{
-		Sessions:       make(map[string]*models.SessionStats),
-		Projects:       make(map[string]*models.ProjectStats),
-		HourlyActivity: make(map[int]*models.HourlyActivity),
-		DailyActivity:  make(map[string]*models.DailyActivity),
-		ModelUsage:     make(map[string]int),
-		ToolUse:        &models.ToolUseStats{},
-		ResponseTimes:  []time.Duration{},
-		StartDate:      time.Now(),
-		EndDate:        time.Time{},
-	}
-
-	cutoffTime := time.Now().AddDate(0, 0, -p.daysToAnalyze)
-
-	// Find all JSONL files
-	pattern := filepath.Join(p.claudeDir, "projects", "**", "*.jsonl")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find files: %w", err)
+		Sessions:           make(map[string]*models.SessionStats),
+		Projects:           make(map[string]*models.ProjectStats),
+		HourlyActivity:     make(map[int]*models.HourlyActivity),
+		DailyActivity:      make(map[string]*models.DailyActivity),
+		ModelUsage:         make(map[string]int),
+		Models:             make(map[string]*models.ModelStats),
+		ActiveMinutes:      make(map[int64]bool),
+		Minutes:            make(map[int64]*models.MinuteActivity),
+		Accounts:           make(map[string]*models.AccountStats),
+		FileExtensions:     make(map[string]*models.ExtensionStats),
+		ModelResponseTimes: make(map[string][]time.Duration),
+		UnknownEntryTypes:  make(map[string]int),
+		ToolUse:            &models.ToolUseStats{},
+		ResponseTimes:      []time.Duration{},
+		StartDate:          now,
+		EndDate:            time.Time{},
+		DayBoundary:        p.days,
 	}
 
-	// Also check one level deeper
-	pattern2 := filepath.Join(p.claudeDir, "projects", "**", "**", "*.jsonl")
-	files2, _ := filepath.Glob(pattern2)
-	files = append(files, files2...)
+	p.titledAt = make(map[string]time.Time)
+	p.resetChains()
 
-	// Remove duplicates
-	seen := make(map[string]bool)
-	uniqueFiles := []string{}
-	for _, f := range files {
-		if !seen[f] {
-			seen[f] = true
-			uniqueFiles = append(uniqueFiles, f)
-		}
+	cutoffTime := now.AddDate(0, 0, -p.daysToAnalyze)
+	if !p.since.IsZero() {
+		cutoffTime = p.since
 	}
 
+	var uniqueFiles []string
+	var err error
+	if p.source != nil {
+		uniqueFiles, err = p.source.Files(p.claudeDir)
+	} else {
+		uniqueFiles, err = FindFiles(p.claudeDir, p.findOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	uniqueFiles, analysis.DuplicateFiles = dedupeFiles(p.fsys, uniqueFiles)
+	for _, dup := range analysis.DuplicateFiles {
+		p.logger.Debug("skipped copy of a session file", "file", dup.Path, "original", dup.Original)
+	}
 	if len(uniqueFiles) == 0 {
-		return nil, claudecosts.ErrNoJSONLFiles
+		return nil, ErrNoJSONLFiles
 	}
 
-	// Parse each file
+	p.logger.Debug("discovered JSONL files", "dir", p.claudeDir, "count", len(uniqueFiles))
+
+	// Parse each file, skipping those not written since well before the
+	// cutoff: none of their entries can be in range. Line sources filter by
+	// timestamp themselves.
+	skipped := 0
 	for _, file := range uniqueFiles {
+		if p.source == nil && modifiedBefore(file, cutoffTime.Add(-clockMargin)) {
+			skipped++
+			continue
+		}
 		if err := p.parseFile(file, analysis, cutoffTime); err != nil {
 			// Continue on error, just log it
-			fmt.Fprintf(os.Stderr, "Warning: failed to parse %s: %v\n", file, err)
+			p.logger.Warn("failed to parse file", "file", file, "error", err)
+			analysis.ParseErrors++
 		}
 	}
 
+	if skipped > 0 {
+		p.logger.Debug("skipped files last modified before the cutoff", "count", skipped, "cutoff", cutoffTime)
+	}
+
+	p.finishChains(analysis)
+
+	// Files are parsed one at a time, so events interleave across sessions
+	sort.SliceStable(analysis.LimitEvents, func(i, j int) bool {
+		return analysis.LimitEvents[i].Time.Before(analysis.LimitEvents[j].Time)
+	})
+
+	p.logUnknownEntryTypes(analysis)
+
 	// Calculate totals and savings
 	p.calculateTotals(analysis)
 
 	return analysis, nil
 }
 
-// parseFile parses a single JSONL file
-func (p *Parser) parseFile(filename string, analysis *models.CostAnalysis, cutoffTime time.Time) error {
+// eachLine calls fn with each line of a JSONL file, read from the line
+// source when one is set. Lines of large files well before the cutoff may
+// be skipped.
+func (p *Parser) eachLine(filename string, cutoff time.Time, fn func(line []byte)) error {
+	if p.source != nil {
+		return p.source.EachLine(filename, cutoff, fn)
+	}
+
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	// Extract project name and session ID (with caching)
-	projectName, ok := p.projectNameCache[filename]
-	if !ok {
-		projectName = p.extractProjectName(filename)
-		p.projectNameCache[filename] = projectName
+	// Large files are mostly history; start near the cutoff
+	if p.seeks(cutoff) {
+		if err := seekCutoff(file, cutoff, fn); err != nil {
+			return err
+		}
 	}
-	sessionID := strings.TrimSuffix(filepath.Base(filename), ".jsonl")
-
-	// Single pass: collect entries and build UUID map
-	allEntries := make([]models.Entry, 0, 1000) // Pre-allocate for typical file size
-	entriesByUUID := make(map[string]*models.Entry, 1000)
 
 	scanner := bufio.NewScanner(file)
-	// Set a much larger buffer for very long lines (50MB)
-	const maxScanTokenSize = 50 * 1024 * 1024
-	buf := make([]byte, 0, 64*1024) // 64KB initial buffer
-	scanner.Buffer(buf, maxScanTokenSize)
+	// Start from a pooled 64KB buffer, growing for lines up to 50MB
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	scanner.Buffer((*buf)[:0], MaxLineSize)
 
 	for scanner.Scan() {
-		var entry models.Entry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue // Skip malformed lines
-		}
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
 
-		// Parse timestamp early to filter
-		timestamp, err := p.parseTimestamp(entry.Timestamp)
-		if err != nil {
-			continue
+// clockMargin allows for clocks that disagree with the file system's, as a
+// network home directory's server may, when skipping files by modification
+// time, and for entries written out of order when seeking to the cutoff
+const clockMargin = 24 * time.Hour
+
+// modifiedBefore reports whether a file was last written before t, so that
+// none of its entries can be later. Files that can't be statted aren't, so
+// that reading them reports the error.
+func modifiedBefore(file string, t time.Time) bool {
+	info, err := os.Stat(file)
+	return err == nil && info.ModTime().Before(t)
+}
+
+// maxPrefixEntries bounds the entries a file holds back until its session ID
+// and account are known. Files that name neither that early fall back to the
+// file name and UnknownAccount.
+const maxPrefixEntries = 1000
+
+// fileState is what parseFile keeps of the file it's reading. Entries are
+// processed as they're read rather than held, so huge histories parse in
+// memory proportional to the index of entries, not to the files.
+type fileState struct {
+	name        string
+	projectPath string
+	projectName string
+	sessionID   string
+	account     string         // The account last named
+	first       string         // The account of entries before the first mention
+	prefix      []models.Entry // Entries held back until streaming starts
+	streaming   bool
+	summaries   []summary
+	last        time.Time // When the last entry processed was written
+	lastCalls   []string  // Tools the last entry processed called, by ID
+	processed   int
+	previous    time.Time // When the previous entry processed was written
+	gitBranch   string
+
+	outOfOrder       int
+	parentAfterChild int
+}
+
+// parseFile parses a single JSONL file
+func (p *Parser) parseFile(filename string, analysis *models.CostAnalysis, cutoffTime time.Time) error {
+	// Extract project name and session ID (with caching)
+	projectPath, ok := p.projectNameCache[filename]
+	if !ok {
+		projectPath = p.extractProjectName(filename)
+		p.projectNameCache[filename] = projectPath
+	}
+	projectName := p.aliases.Resolve(projectPath)
+	if !p.includesProject(projectPath, projectName) {
+		return nil
+	}
+
+	f := &fileState{name: filename, projectPath: projectPath, projectName: projectName}
+	p.files++
+	p.pricing = p.pricingRules.Match(projectPath, projectName)
+	p.dir = projectDir(filename)
+	p.pendingTools = make(map[string]pendingTool)
+	p.sessionOpus, p.lastFamily = false, ""
+	p.lastResponse = time.Time{}
+	p.turnImages = false
+	p.lastContext = 0
+	p.prompts = 0
+	p.interrupts = models.InterruptStats{}
+	p.turnCost, p.turnInterrupted = 0, false
+	p.checkMemory(analysis)
+
+	if p.readStats != nil {
+		p.readStats.Files++
+	}
+	err := p.eachLine(filename, cutoffTime, func(line []byte) {
+		p.countRead(line)
+		if len(bytes.TrimSpace(line)) == 0 {
+			return
+		}
+		p.countLine(analysis)
+
+		entry := getEntry()
+		defer putEntry(entry)
+		if err := decodeEntry(line, entry); err != nil {
+			// Skip malformed lines
+			p.logger.Debug("skipping malformed line", "file", filename, "error", err)
+			analysis.ParseErrors++
+			return
 		}
+		p.countEntryType(entry, analysis)
 
-		// Skip entries before cutoff
-		if timestamp.Before(cutoffTime) {
-			continue
+		// Entries name their session, which resumed sessions carry into new
+		// files, and name the account only now and then, so the last one
+		// seen applies until the next
+		if f.sessionID == "" && !f.streaming {
+			f.sessionID = entry.SessionID
+		}
+		if id := entryAccountID(entry); id != "" {
+			f.account = p.accountName(id)
 		}
 
-		// Store entry with parsed timestamp
-		entry.ParsedTimestamp = timestamp
-		allEntries = append(allEntries, entry)
+		// Entries such as summaries carry no timestamp
+		if entry.Timestamp == "" {
+			if entry.Type == "summary" && entry.Summary != "" {
+				f.summaries = append(f.summaries, summary{entry.Summary, entry.LeafUUID})
+			}
+		} else {
+			timestamp := entry.ParsedTimestamp
 
-		if entry.UUID != "" {
-			// Store pointer to the entry in slice
-			entriesByUUID[entry.UUID] = &allEntries[len(allEntries)-1]
+			// Skip entries before cutoff
+			if timestamp.Before(cutoffTime) {
+				return
+			}
+			if !p.since.IsZero() && !timestamp.After(p.since) {
+				return
+			}
+			entry.Account = f.account
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
+		if !f.streaming {
+			f.prefix = append(f.prefix, *entry)
+			if (f.sessionID != "" && f.account != "") || len(f.prefix) >= maxPrefixEntries {
+				p.startStreaming(f, analysis)
+			}
+			return
+		}
+		p.processEntry(f, entry, analysis)
+	})
+	if err != nil {
 		return err
 	}
+	if !f.streaming {
+		p.startStreaming(f, analysis)
+	}
 
-	// Process all entries
-	for i := range allEntries {
-		entry := &allEntries[i]
-		timestamp := entry.ParsedTimestamp
+	p.finishClockSkew(f, analysis)
+	if f.processed > 0 {
+		p.finishInterrupts(analysis, projectName, f.last, f.lastCalls)
+	}
 
-		// Update date range
-		if analysis.StartDate.After(timestamp) || analysis.StartDate.IsZero() {
-			analysis.StartDate = timestamp
+	if session, ok := analysis.Sessions[f.sessionID]; ok {
+		session.ProjectPath = projectPath
+		session.ProjectDir = p.dir
+		// Of a resumed session's files, the latest has the current title
+		var end time.Time
+		if f.processed > 0 {
+			end = f.last
 		}
-		if analysis.EndDate.Before(timestamp) {
-			analysis.EndDate = timestamp
+		if title := p.sessionTitle(f.summaries); title != "" && !end.Before(p.titledAt[f.sessionID]) {
+			session.Title = title
+			p.titledAt[f.sessionID] = end
 		}
-
-		// Process based on entry type
-		switch entry.Type {
-		case "user":
-			p.processUserEntry(entry, analysis)
-		case "assistant":
-			p.processAssistantEntry(entry, analysis, projectName, sessionID, timestamp, entriesByUUID)
+		session.Prompts += p.prompts
+		session.Files++
+		if session.GitBranch == "" {
+			session.GitBranch = f.gitBranch
 		}
 	}
 
 	return nil
 }
 
-// processUserEntry processes user messages for tool use tracking
-func (p *Parser) processUserEntry(entry *models.Entry, analysis *models.CostAnalysis) {
-	if entry.Message == nil {
-		return
+// startStreaming settles the file's session ID and its first account, then
+// processes the entries held back until they were known
+func (p *Parser) startStreaming(f *fileState, analysis *models.CostAnalysis) {
+	f.streaming = true
+	if f.sessionID == "" {
+		f.sessionID = strings.TrimSuffix(filepath.Base(f.name), ".jsonl")
 	}
 
-	// Handle content as array of items
-	contentArray, ok := entry.Message.Content.([]interface{})
-	if !ok {
+	// A session rarely changes login, so entries before the first account
+	// mention are given that account
+	f.first = f.account
+	for i := range f.prefix {
+		if f.prefix[i].Account != "" {
+			f.first = f.prefix[i].Account
+			break
+		}
+	}
+	if f.first == "" {
+		f.first = UnknownAccount
+	}
+
+	prefix := f.prefix
+	f.prefix = nil
+	for i := range prefix {
+		p.processEntry(f, &prefix[i], analysis)
+	}
+}
+
+// processEntry adds an entry to the analysis
+func (p *Parser) processEntry(f *fileState, entry *models.Entry, analysis *models.CostAnalysis) {
+	if entry.Timestamp == "" {
+		p.handleEntry(entry, f.sessionID, f.projectName)
 		return
 	}
+	if entry.Account == "" {
+		entry.Account = f.first
+	}
+	if p.accountFilter != "" && !p.includesAccount(entry.Account) {
+		return
+	}
+	timestamp := entry.ParsedTimestamp
 
-	for _, item := range contentArray {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
+	// Update date range
+	if analysis.StartDate.After(timestamp) || analysis.StartDate.IsZero() {
+		analysis.StartDate = timestamp
+	}
+	if analysis.EndDate.Before(timestamp) {
+		analysis.EndDate = timestamp
+	}
+	p.skewed = p.checkClockSkew(f, entry)
+
+	// Process based on entry type
+	switch entry.Type {
+	case "user":
+		p.processUserEntry(entry, analysis, f.projectName)
+		p.processModelCommand(entry)
+		p.countPrompt(entry)
+		p.trackInterrupts(entry)
+	case "assistant":
+		p.processAssistantEntry(entry, analysis, f.projectName, f.sessionID, timestamp)
+	}
+	p.processLimitEvent(entry, analysis, f.projectName, f.sessionID)
+	p.handleEntry(entry, f.sessionID, f.projectName)
+	p.trackTurn(entry)
+
+	if f.gitBranch == "" {
+		f.gitBranch = entry.GitBranch
+	}
+	f.last = timestamp
+	f.lastCalls = appendToolUseIDs(f.lastCalls[:0], entry)
+	f.processed++
+}
+
+// processUserEntry processes user messages for tool use tracking
+func (p *Parser) processUserEntry(entry *models.Entry, analysis *models.CostAnalysis, projectName string) {
+	p.trackImages(entry, analysis, projectName)
+	items := contentItems(entry)
+	results := 0
+	for _, item := range items {
+		if item["type"] == "tool_result" {
+			results++
+		}
+	}
+	// The entry's toolUseResult describes its tool_result when there's one
+	result := entry.ToolUseResult
+	if results != 1 {
+		result = nil
+	}
+	for _, item := range items {
+		if item["type"] != "tool_result" {
 			continue
 		}
 
-		if itemMap["type"] == "tool_result" {
-			analysis.ToolUse.Accepted++
-
-			// Check various rejection indicators
-			rejected := false
-
-			// Check toolUseResult first
-			if entry.ToolUseResult != nil && entry.ToolUseResult.Interrupted {
-				rejected = true
-			} else {
-				// Check content for rejection messages
-				content, _ := itemMap["content"].(string)
-				isError, _ := itemMap["is_error"].(bool)
-
-				if strings.Contains(content, "user doesn't want to proceed") ||
-					strings.Contains(content, "tool use was rejected") ||
-					isError {
-					rejected = true
-				}
-			}
+		outcome := p.classifyToolResult(entry, item)
 
-			if rejected {
-				analysis.ToolUse.Rejected++
-				analysis.ToolUse.Accepted-- // Correct the count
-			}
+		// Errors have always counted as rejections in the headline numbers
+		if outcome == outcomeAccepted {
+			analysis.ToolUse.Accepted++
+		} else {
+			analysis.ToolUse.Rejected++
 		}
+
+		p.processToolResult(item, result, outcome, analysis, p.getOrCreateProject(analysis, projectName))
 	}
 }
 
 // processAssistantEntry processes an assistant message and updates stats
 func (p *Parser) processAssistantEntry(entry *models.Entry, analysis *models.CostAnalysis,
-	projectName, sessionID string, timestamp time.Time, entriesByUUID map[string]*models.Entry) {
+	projectName, sessionID string, timestamp time.Time) {
 
-	p.calculateResponseTime(entry, analysis, projectName, timestamp, entriesByUUID)
-	p.updateSessionStats(analysis, sessionID, timestamp)
+	p.calculateResponseTime(entry, analysis, projectName, timestamp)
+	p.updateSessionStats(analysis, sessionID, projectName, timestamp)
 	project := p.updateProjectStats(analysis, projectName, sessionID, timestamp)
+	p.processToolUses(entry, analysis, project)
 
 	cost, model, tokens := p.extractCostAndTokens(entry)
 	if cost == 0 && model == "" {
 		return
 	}
 
+	p.priceToolUses(entry, cost)
 	p.updateAnalysisStats(analysis, model, cost, tokens, timestamp)
+	p.trackModelSwitch(entry, analysis, model, cost, timestamp)
+	p.trackOpus(analysis, sessionID, model, cost, tokens)
+	p.trackBackground(entry, analysis, model, cost)
+	p.trackCategory(entry, analysis, model, cost, tokens)
+	p.trackCacheExpiry(entry, analysis, model, tokens, timestamp)
+	p.trackContext(entry, analysis, sessionID, model, tokens)
+	p.updateDailyAttribution(analysis, projectName, sessionID, cost, timestamp)
 	p.updateSessionCosts(analysis, sessionID, cost, tokens)
+	p.turnCost += cost
+	p.updateAccountStats(analysis, entry.Account, sessionID, cost, tokens)
 	p.updateProjectCosts(project, cost, tokens)
+	p.trackImageCost(entry, project, model, tokens)
+	p.handleMessage(entry, projectName, sessionID, model, cost, tokens)
 }
 
 // calculateResponseTime calculates and records response time
 func (p *Parser) calculateResponseTime(entry *models.Entry, analysis *models.CostAnalysis,
-	projectName string, timestamp time.Time, entriesByUUID map[string]*models.Entry) {
-	if entry.ParentUUID == "" {
+	projectName string, timestamp time.Time) {
+	if entry.ParentUUID == "" || p.memoryLimited {
 		return
 	}
 
-	parentEntry, ok := entriesByUUID[entry.ParentUUID]
-	if !ok || parentEntry.Type != "user" {
-		return
+	model := ""
+	if entry.Message != nil {
+		model = entry.Message.Model
 	}
 
-	parentTime, err := p.parseTimestamp(parentEntry.Timestamp)
-	if err != nil {
+	parent, ok := p.chains[p.dir][entry.ParentUUID]
+	if !ok {
+		// The parent may be later in the file, or in another of the
+		// project's files
+		p.pendingResponses = append(p.pendingResponses, pendingResponse{
+			dir:     p.dir,
+			parent:  entry.ParentUUID,
+			project: projectName,
+			model:   model,
+			time:    timestamp,
+			skewed:  p.skewed,
+		})
+		return
+	}
+	if !parent.user {
 		return
 	}
+	if p.excludeSkewed && (p.skewed || parent.skewed) {
+		analysis.ClockSkew.Excluded++
+		return
+	}
+
+	p.recordResponseTime(analysis, projectName, model, timestamp.Sub(parent.time))
+}
 
-	responseTime := timestamp.Sub(parentTime)
-	if responseTime <= 0 || responseTime >= 5*time.Minute {
+// recordResponseTime records the time a response took, unless it falls
+// outside the bounds
+func (p *Parser) recordResponseTime(analysis *models.CostAnalysis, projectName, model string, responseTime time.Duration) {
+	if responseTime <= 0 {
+		return
+	}
+	if responseTime < p.responseMin || (p.responseMax > 0 && responseTime >= p.responseMax) {
+		analysis.ResponseTimeOutliers++
 		return
 	}
 
@@ -258,12 +705,160 @@ func (p *Parser) calculateResponseTime(entry *models.Entry, analysis *models.Cos
 	if proj, ok := analysis.Projects[projectName]; ok {
 		proj.ResponseTimes = append(proj.ResponseTimes, responseTime)
 	}
+	if model != "" && model != "<synthetic>" {
+		analysis.ModelResponseTimes[model] = append(analysis.ModelResponseTimes[model], responseTime)
+	}
+}
+
+// maxTurnDepth bounds parent chain walks so malformed cycles can't hang parsing
+const maxTurnDepth = 10_000
+
+// trackTurn accumulates the wall-clock and model-only latency of the turn an
+// entry belongs to, then indexes the entry so later ones, in this file or
+// another of the project's, can follow their chain through it. A turn
+// starts at a user prompt and ends at the last assistant entry before the
+// next prompt; time between a tool_use and its matching tool_result is local
+// tool execution and is excluded from the model-only figure. Turns are
+// recorded by finishChains, once those continued in other files are
+// complete.
+func (p *Parser) trackTurn(entry *models.Entry) {
+	if p.memoryLimited {
+		return
+	}
+	links := p.chains[p.dir]
+	if links == nil {
+		links = make(map[string]link)
+		p.chains[p.dir] = links
+	}
+	toolUseTimes := p.toolUses[p.dir]
+	if toolUseTimes == nil {
+		toolUseTimes = make(map[string]time.Time)
+		p.toolUses[p.dir] = toolUseTimes
+	}
+
+	user := entry.Type == "user"
+	prompt := user && isPrompt(entry)
+	key, inTurn := turnRoot(entry, prompt, links)
+	if entry.UUID != "" {
+		links[entry.UUID] = link{
+			parent: entry.ParentUUID,
+			time:   entry.ParsedTimestamp,
+			user:   user,
+			prompt: prompt,
+			skewed: p.skewed,
+			file:   p.files,
+			turn:   key,
+			inTurn: inTurn,
+		}
+	}
+
+	switch entry.Type {
+	case "assistant":
+		var t *turn
+		if inTurn {
+			t = p.turnFor(key)
+			if entry.ParsedTimestamp.After(t.end) {
+				t.end = entry.ParsedTimestamp
+			}
+			t.skewed = t.skewed || p.skewed
+		}
+		for _, item := range contentItems(entry) {
+			if item["type"] == "tool_use" {
+				if id, ok := item["id"].(string); ok {
+					toolUseTimes[id] = entry.ParsedTimestamp
+				}
+			}
+		}
+	case "user":
+		if !inTurn || prompt {
+			return
+		}
+		t := p.turnFor(key)
+		t.skewed = t.skewed || p.skewed
+		for _, item := range contentItems(entry) {
+			if item["type"] != "tool_result" {
+				continue
+			}
+			id, _ := item["tool_use_id"].(string)
+			usedAt, ok := toolUseTimes[id]
+			if !ok {
+				// The tool may have been called in a file not yet read
+				p.pendingSpans = append(p.pendingSpans, pendingSpan{p.dir, id, entry.ParsedTimestamp, t})
+				continue
+			}
+			if d := entry.ParsedTimestamp.Sub(usedAt); d > 0 {
+				t.tools = append(t.tools, toolSpan{entry.ParsedTimestamp, d})
+			}
+		}
+	}
+}
+
+// turnRoot returns the turn containing entry: the prompt that started it
+// or, when the entry's parent hasn't been read yet, that parent, which
+// finishChains follows once every file has been. It reports false for
+// entries in no turn.
+func turnRoot(entry *models.Entry, prompt bool, links map[string]link) (turnKey, bool) {
+	if prompt {
+		// A prompt without a UUID can't be replied to
+		return turnKey{root: entry.UUID}, entry.UUID != ""
+	}
+	if entry.ParentUUID == "" {
+		return turnKey{}, false
+	}
+	if parent, ok := links[entry.ParentUUID]; ok {
+		return parent.turn, parent.inTurn
+	}
+	return turnKey{exit: entry.ParentUUID}, true
+}
+
+// isPrompt reports whether a user entry is a human prompt rather than a
+// tool_result returned to the model
+func isPrompt(entry *models.Entry) bool {
+	if entry.Message == nil {
+		return false
+	}
+	for _, item := range contentItems(entry) {
+		if item["type"] == "tool_result" {
+			return false
+		}
+	}
+	return true
+}
+
+// contentItems returns the message content blocks of an entry, or nil when
+// the content is a plain string
+func contentItems(entry *models.Entry) []map[string]interface{} {
+	if entry.Message == nil {
+		return nil
+	}
+	contentArray, ok := entry.Message.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	items := make([]map[string]interface{}, 0, len(contentArray))
+	for _, item := range contentArray {
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			items = append(items, itemMap)
+		}
+	}
+	return items
 }
 
 // updateSessionStats updates session-level statistics
-func (p *Parser) updateSessionStats(analysis *models.CostAnalysis, sessionID string, timestamp time.Time) {
+func (p *Parser) updateSessionStats(analysis *models.CostAnalysis, sessionID, projectName string, timestamp time.Time) {
 	session := p.getOrCreateSession(analysis, sessionID)
 	session.MessageCount++
+	session.Project = projectName
+
+	minute := activeMinute(timestamp)
+	if session.ActiveMinutes == nil {
+		session.ActiveMinutes = make(map[int64]bool)
+	}
+	session.ActiveMinutes[minute] = true
+	if analysis.ActiveMinutes != nil {
+		analysis.ActiveMinutes[minute] = true
+	}
 
 	if session.StartTime.IsZero() || timestamp.Before(session.StartTime) {
 		session.StartTime = timestamp
@@ -282,22 +877,35 @@ func (p *Parser) updateProjectStats(analysis *models.CostAnalysis, projectName,
 	}
 	project.SessionIDs[sessionID] = true
 
-	dayKey := timestamp.Format("2006-01-02")
+	dayKey := p.days.Date(timestamp)
 	if project.ActiveDays == nil {
 		project.ActiveDays = make(map[string]bool)
 	}
 	project.ActiveDays[dayKey] = true
 
+	if project.ActiveMinutes == nil {
+		project.ActiveMinutes = make(map[int64]bool)
+	}
+	project.ActiveMinutes[activeMinute(timestamp)] = true
+
 	return project
 }
 
 type tokenData struct {
+	tier             models.PricingTier
+	cache            models.CacheCosts
 	inputTokens      int
 	outputTokens     int
 	cacheReadTokens  int
 	cacheWriteTokens int
 }
 
+// inputCost returns the cost of the input side of a response: plain input,
+// cache writes, and cache reads
+func (t tokenData) inputCost() float64 {
+	return float64(t.inputTokens)*t.tier.Input/1_000_000 + t.cache.WriteCost + t.cache.ReadCost
+}
+
 // extractCostAndTokens extracts cost and token information from entry
 func (p *Parser) extractCostAndTokens(entry *models.Entry) (float64, string, tokenData) {
 	if entry.CostUSD > 0 {
@@ -314,11 +922,14 @@ func (p *Parser) extractCostAndTokens(entry *models.Entry) (float64, string, tok
 	}
 
 	usage := entry.Message.Usage
+	tier := p.pricingFor(model)
 	tokens := tokenData{
+		tier:             tier,
 		inputTokens:      usage.InputTokens,
 		outputTokens:     usage.OutputTokens,
 		cacheReadTokens:  usage.CacheReadInputTokens,
 		cacheWriteTokens: usage.CacheCreationInputTokens,
+		cache:            models.NewCacheCosts(tier, usage.CacheCreationInputTokens, usage.CacheReadInputTokens),
 	}
 
 	cost := p.calculateTokenCost(usage, model)
@@ -329,10 +940,50 @@ func (p *Parser) extractCostAndTokens(entry *models.Entry) (float64, string, tok
 func (p *Parser) updateAnalysisStats(analysis *models.CostAnalysis, model string, cost float64, tokens tokenData, timestamp time.Time) {
 	if model != "" {
 		analysis.ModelUsage[model]++
+		p.updateModelStats(analysis, model, cost, tokens, timestamp)
 	}
 
 	p.updateHourlyActivity(analysis, cost, timestamp)
-	p.updateDailyActivity(analysis, cost, timestamp)
+	p.updateDailyActivity(analysis, model, cost, tokens, timestamp)
+	p.updateMinuteActivity(analysis, cost, tokens, timestamp)
+}
+
+// updateMinuteActivity updates per-minute activity
+func (p *Parser) updateMinuteActivity(analysis *models.CostAnalysis, cost float64, tokens tokenData, timestamp time.Time) {
+	if analysis.Minutes == nil {
+		analysis.Minutes = make(map[int64]*models.MinuteActivity)
+	}
+	minute := activeMinute(timestamp)
+	activity := analysis.Minutes[minute]
+	if activity == nil {
+		activity = &models.MinuteActivity{}
+		analysis.Minutes[minute] = activity
+	}
+	activity.MessageCount++
+	activity.Cost += cost
+	activity.Tokens += tokens.inputTokens + tokens.outputTokens + tokens.cacheReadTokens + tokens.cacheWriteTokens
+	activity.InputOutputTokens += tokens.inputTokens + tokens.outputTokens
+}
+
+// updateModelStats updates per-model cost, token, and activity statistics
+func (p *Parser) updateModelStats(analysis *models.CostAnalysis, model string, cost float64, tokens tokenData, timestamp time.Time) {
+	stats := analysis.Models[model]
+	if stats == nil {
+		stats = &models.ModelStats{ActiveMinutes: make(map[int64]bool)}
+		analysis.Models[model] = stats
+	}
+	stats.Cost += cost
+	stats.InputTokens += tokens.inputTokens
+	stats.OutputTokens += tokens.outputTokens
+	stats.CacheReadTokens += tokens.cacheReadTokens
+	stats.CacheWriteTokens += tokens.cacheWriteTokens
+	stats.Cache.Add(tokens.cache)
+	stats.ActiveMinutes[activeMinute(timestamp)] = true
+}
+
+// activeMinute returns the Unix minute containing timestamp
+func activeMinute(timestamp time.Time) int64 {
+	return timestamp.Unix() / 60
 }
 
 // updateHourlyActivity updates hourly activity statistics
@@ -346,13 +997,38 @@ func (p *Parser) updateHourlyActivity(analysis *models.CostAnalysis, cost float6
 }
 
 // updateDailyActivity updates daily activity statistics
-func (p *Parser) updateDailyActivity(analysis *models.CostAnalysis, cost float64, timestamp time.Time) {
-	dayKey := timestamp.Format("2006-01-02")
+func (p *Parser) updateDailyActivity(analysis *models.CostAnalysis, model string, cost float64, tokens tokenData, timestamp time.Time) {
+	dayKey := p.days.Date(timestamp)
 	if analysis.DailyActivity[dayKey] == nil {
 		analysis.DailyActivity[dayKey] = &models.DailyActivity{}
 	}
-	analysis.DailyActivity[dayKey].MessageCount++
-	analysis.DailyActivity[dayKey].Cost += cost
+	day := analysis.DailyActivity[dayKey]
+	day.MessageCount++
+	day.Cost += cost
+	day.InputTokens += tokens.inputTokens
+	day.CacheReadTokens += tokens.cacheReadTokens
+	day.CacheWriteTokens += tokens.cacheWriteTokens
+	if model != "" {
+		if day.ModelCosts == nil {
+			day.ModelCosts = make(map[string]float64)
+		}
+		day.ModelCosts[model] += cost
+	}
+}
+
+// updateDailyAttribution attributes a message's cost to its project and
+// session for the day
+func (p *Parser) updateDailyAttribution(analysis *models.CostAnalysis, projectName, sessionID string, cost float64, timestamp time.Time) {
+	day := analysis.DailyActivity[p.days.Date(timestamp)]
+	if day == nil {
+		return
+	}
+	if day.ProjectCosts == nil {
+		day.ProjectCosts = make(map[string]float64)
+		day.SessionCosts = make(map[string]float64)
+	}
+	day.ProjectCosts[projectName] += cost
+	day.SessionCosts[sessionID] += cost
 }
 
 // updateSessionCosts updates session cost and token statistics
@@ -373,11 +1049,12 @@ func (p *Parser) updateProjectCosts(project *models.ProjectStats, cost float64,
 	project.OutputTokens += tokens.outputTokens
 	project.CacheReadTokens += tokens.cacheReadTokens
 	project.CacheWriteTokens += tokens.cacheWriteTokens
+	project.Cache.Add(tokens.cache)
 	project.TotalTokens += tokens.inputTokens + tokens.outputTokens
 }
 
 // parseTimestamp parses the timestamp string into time.Time
-func (p *Parser) parseTimestamp(timestamp string) (time.Time, error) {
+func parseTimestamp(timestamp string) (time.Time, error) {
 	if timestamp == "" {
 		return time.Time{}, fmt.Errorf("empty timestamp")
 	}
@@ -402,11 +1079,7 @@ func (p *Parser) parseTimestamp(timestamp string) (time.Time, error) {
 
 // calculateTokenCost calculates the cost based on token usage
 func (p *Parser) calculateTokenCost(usage *models.Usage, model string) float64 {
-	// Get pricing for model
-	pricing, ok := models.ModelPricing[model]
-	if !ok {
-		pricing = models.DefaultPricing
-	}
+	pricing := p.pricingFor(model)
 
 	cost := 0.0
 
@@ -433,6 +1106,21 @@ func (p *Parser) calculateTokenCost(usage *models.Usage, model string) float64 {
 	return cost
 }
 
+// pricingFor returns the pricing tier for model in the current file's
+// project
+func (p *Parser) pricingFor(model string) models.PricingTier {
+	return p.pricing.Tier(model, listPricing(model))
+}
+
+// listPricing returns the list pricing tier for model, falling back to the
+// default
+func listPricing(model string) models.PricingTier {
+	if pricing, ok := models.ModelPricing[model]; ok {
+		return pricing
+	}
+	return models.DefaultPricing
+}
+
 // getOrCreateSession gets or creates a session
 func (p *Parser) getOrCreateSession(analysis *models.CostAnalysis, sessionID string) *models.SessionStats {
 	if analysis.Sessions[sessionID] == nil {
@@ -454,9 +1142,46 @@ func (p *Parser) getOrCreateProject(analysis *models.CostAnalysis, projectName s
 	return analysis.Projects[projectName]
 }
 
-// extractProjectName extracts and decodes the project name from the file path
+// includesProject reports whether the project filter admits a project
+func (p *Parser) includesProject(projectPath, projectName string) bool {
+	if len(p.projectFilter) == 0 {
+		return true
+	}
+	for _, pattern := range p.projectFilter {
+		if rules.MatchProject(pattern, projectPath) || rules.MatchProject(pattern, projectName) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectDir returns the directory under projects/ that holds filename, such
+// as -home-mrm-src-node-sqlite. Unlike the decoded name it doesn't depend on
+// aliases or which directories still exist.
+func projectDir(filename string) string {
+	parts := strings.Split(filepath.ToSlash(filename), "/")
+	for i, part := range parts {
+		if part == "projects" && i+1 < len(parts)-1 {
+			return parts[i+1]
+		}
+	}
+	return filepath.Base(filepath.Dir(filename))
+}
+
+// statHost and userHomeDir look at the host when decoding project names;
+// tests replace them to check that WithFS parsers never do
+var (
+	statHost    = os.Stat
+	userHomeDir = os.UserHomeDir
+)
+
+// extractProjectName extracts and decodes the project name from the file
+// path. Hyphens that may be part of a directory name are resolved by
+// looking for the path on disk, except when reading from WithFS, whose
+// names decode the same on every machine.
 func (p *Parser) extractProjectName(filename string) string {
-	parts := strings.Split(filename, string(os.PathSeparator))
+	_, fromFS := p.source.(fsSource)
+	parts := strings.Split(filepath.ToSlash(filename), "/")
 
 	for i, part := range parts {
 		if part == "projects" && i+1 < len(parts) {
@@ -469,24 +1194,30 @@ func (p *Parser) extractProjectName(filename string) string {
 
 				// Try to reconstruct the path
 				if len(pathParts) > 2 && pathParts[0] == "home" {
+					// Without the host, every hyphen is a separator and the
+					// name is relative to the encoded home directory
+					if fromFS {
+						return strings.Join(pathParts[2:], "/")
+					}
+
 					// Build the full path
 					testPath := "/" + strings.Join(pathParts, "/")
 
 					// If path doesn't exist, try with hyphens in the last part
-					if _, err := os.Stat(testPath); err != nil && len(pathParts) > 3 {
+					if _, err := statHost(testPath); err != nil && len(pathParts) > 3 {
 						// Try combining the last parts with hyphens
 						for splitPoint := len(pathParts) - 1; splitPoint > 2; splitPoint-- {
 							basePath := "/" + strings.Join(pathParts[:splitPoint], "/")
 							namePart := strings.Join(pathParts[splitPoint:], "-")
 							testPath = basePath + "/" + namePart
-							if _, err := os.Stat(testPath); err == nil {
+							if _, err := statHost(testPath); err == nil {
 								break
 							}
 						}
 					}
 
 					// Remove home prefix for display
-					home, _ := os.UserHomeDir()
+					home, _ := userHomeDir()
 					if strings.HasPrefix(testPath, home) {
 						return strings.TrimPrefix(testPath, home+"/")
 					}
@@ -505,6 +1236,8 @@ func (p *Parser) extractProjectName(filename string) string {
 // calculateTotals calculates total costs and savings
 func (p *Parser) calculateTotals(analysis *models.CostAnalysis) {
 	for _, session := range analysis.Sessions {
+		// Project patterns may match either the raw path or its alias
+		session.Tags = p.tagRules.Tags(session.GitBranch, session.StartTime, session.ProjectPath, session.Project)
 		analysis.TotalCost += session.Cost
 		analysis.TotalInputTokens += session.InputTokens
 		analysis.TotalOutputTokens += session.OutputTokens
@@ -519,12 +1252,9 @@ func (p *Parser) calculateTotals(analysis *models.CostAnalysis) {
 		}
 	}
 
-	// Calculate cache savings (90% discount on cache reads)
-	// Assume average pricing for calculation
-	avgInputPrice := 3.0   // $ per million tokens
-	cacheReadPrice := 0.30 // $ per million tokens (90% discount)
-
-	fullCost := float64(analysis.TotalCacheRead) * avgInputPrice / 1_000_000
-	discountedCost := float64(analysis.TotalCacheRead) * cacheReadPrice / 1_000_000
-	analysis.CacheSavings = fullCost - discountedCost
+	// Cache savings use each model's own rates, accumulated per project
+	for _, project := range analysis.Projects {
+		analysis.Cache.Add(project.Cache)
+	}
+	analysis.CacheSavings = analysis.Cache.ReadSavings
 }
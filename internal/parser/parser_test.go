@@ -1,11 +1,22 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
 )
 
 func TestParser_New(t *testing.T) {
@@ -25,9 +36,7 @@ func TestParser_New(t *testing.T) {
 	}
 }
 
-func TestParser_parseTimestamp(t *testing.T) {
-	p := New(30, "/test")
-
+func TestParseTimestamp(t *testing.T) {
 	tests := []struct {
 		name      string
 		timestamp string
@@ -57,7 +66,7 @@ func TestParser_parseTimestamp(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := p.parseTimestamp(tt.timestamp)
+			result, err := parseTimestamp(tt.timestamp)
 
 			if tt.wantErr {
 				if err == nil {
@@ -168,6 +177,73 @@ func TestParser_extractProjectName(t *testing.T) {
 	}
 }
 
+func TestProjectDir(t *testing.T) {
+	tests := map[string]string{
+		"/claude/projects/-home-user-src-my-project/session.jsonl": "-home-user-src-my-project",
+		"/claude/projects/-home-user-src-api/nested/session.jsonl": "-home-user-src-api",
+		"/some/other/path/session.jsonl":                           "path",
+	}
+	for filename, want := range tests {
+		if got := projectDir(filename); got != want {
+			t.Errorf("projectDir(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestFindFiles_Symlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	projects := filepath.Join(tmpDir, "projects")
+	external := filepath.Join(tmpDir, "elsewhere", "-home-user-lib")
+	for _, dir := range []string{filepath.Join(projects, "-home-user-app", "s2"), external} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{
+		filepath.Join(projects, "-home-user-app", "s1.jsonl"),
+		filepath.Join(projects, "-home-user-app", "s2", "agent.jsonl"),
+		filepath.Join(external, "s3.jsonl"),
+	} {
+		if err := os.WriteFile(file, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A project kept elsewhere, the same project linked in twice, and a
+	// link back to the projects directory
+	links := map[string]string{
+		filepath.Join(projects, "-home-user-lib"):             external,
+		filepath.Join(projects, "-home-user-app-alias"):       filepath.Join(projects, "-home-user-app"),
+		filepath.Join(projects, "-home-user-app", "projects"): projects,
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+
+	followed, err := FindFiles(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(projects, "-home-user-app", "s1.jsonl"),
+		filepath.Join(projects, "-home-user-lib", "s3.jsonl"),
+		filepath.Join(projects, "-home-user-app", "s2", "agent.jsonl"),
+	}
+	if !reflect.DeepEqual(followed, want) {
+		t.Errorf("FindFiles() = %v, want %v", followed, want)
+	}
+
+	skipped, err := FindFiles(tmpDir, SkipSymlinks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{want[0], want[2]}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("FindFiles(SkipSymlinks()) = %v, want %v", skipped, want)
+	}
+}
+
 func TestParser_getOrCreateSession(t *testing.T) {
 	p := New(30, "/test")
 	analysis := &models.CostAnalysis{
@@ -194,81 +270,1656 @@ func TestParser_getOrCreateSession(t *testing.T) {
 	}
 }
 
-func BenchmarkParser_parseTimestamp(b *testing.B) {
+func TestParser_calculateResponseTimeBounds(t *testing.T) {
+	parentTime := time.Date(2025, 6, 13, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		delta        time.Duration
+		min          time.Duration
+		max          time.Duration
+		wantRecorded int
+		wantOutliers int
+	}{
+		{name: "within default bounds", delta: 10 * time.Second, max: DefaultResponseMax, wantRecorded: 1},
+		{name: "above default max", delta: 10 * time.Minute, max: DefaultResponseMax, wantOutliers: 1},
+		{name: "raised max keeps slow turn", delta: 10 * time.Minute, max: 30 * time.Minute, wantRecorded: 1},
+		{name: "below min", delta: 500 * time.Millisecond, min: time.Second, max: DefaultResponseMax, wantOutliers: 1},
+		{name: "no ceiling", delta: 2 * time.Hour, wantRecorded: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(30, "/test", WithResponseTimeBounds(tt.min, tt.max))
+			p.dir = "project"
+			p.chains[p.dir] = map[string]link{"parent": {time: parentTime, user: true}}
+			analysis := &models.CostAnalysis{Projects: make(map[string]*models.ProjectStats)}
+			entry := &models.Entry{ParentUUID: "parent", Type: "assistant"}
+
+			p.calculateResponseTime(entry, analysis, "project", parentTime.Add(tt.delta).Local())
+
+			if got := len(analysis.ResponseTimes); got != tt.wantRecorded {
+				t.Errorf("recorded %d response times, want %d", got, tt.wantRecorded)
+			}
+			if analysis.ResponseTimeOutliers != tt.wantOutliers {
+				t.Errorf("ResponseTimeOutliers = %d, want %d", analysis.ResponseTimeOutliers, tt.wantOutliers)
+			}
+		})
+	}
+}
+
+func TestParser_trackTurn(t *testing.T) {
 	p := New(30, "/test")
-	timestamp := "2025-06-13T14:30:45.123Z"
+	p.dir = "project"
+	start := time.Date(2025, 6, 13, 14, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, err := p.parseTimestamp(timestamp)
-		if err != nil {
-			b.Fatal(err)
+	entries := []models.Entry{
+		{UUID: "prompt", Type: "user", ParsedTimestamp: at(0),
+			Message: &models.MessageContent{Content: "run the tests"}},
+		{UUID: "a1", ParentUUID: "prompt", Type: "assistant", ParsedTimestamp: at(5),
+			Message: &models.MessageContent{Content: []interface{}{
+				map[string]interface{}{"type": "tool_use", "id": "tool-1"},
+			}}},
+		{UUID: "result", ParentUUID: "a1", Type: "user", ParsedTimestamp: at(65),
+			Message: &models.MessageContent{Content: []interface{}{
+				map[string]interface{}{"type": "tool_result", "tool_use_id": "tool-1"},
+			}}},
+		{UUID: "a2", ParentUUID: "result", Type: "assistant", ParsedTimestamp: at(75),
+			Message: &models.MessageContent{Content: "all tests pass"}},
+	}
+	analysis := &models.CostAnalysis{}
+	for i := range entries {
+		p.trackTurn(&entries[i])
+	}
+	p.finishChains(analysis)
+
+	if len(analysis.TurnTimes) != 1 {
+		t.Fatalf("got %d turns, want 1", len(analysis.TurnTimes))
+	}
+	if analysis.TurnTimes[0] != 75*time.Second {
+		t.Errorf("wall turn time = %v, want 75s", analysis.TurnTimes[0])
+	}
+	if analysis.TurnModelTimes[0] != 15*time.Second {
+		t.Errorf("model turn time = %v, want 15s", analysis.TurnModelTimes[0])
+	}
+	if analysis.ToolExecutionTime != 60*time.Second {
+		t.Errorf("ToolExecutionTime = %v, want 60s", analysis.ToolExecutionTime)
+	}
+}
+
+func TestParser_SessionIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := func(hours int) string {
+		return time.Now().Add(time.Duration(hours-3) * time.Hour).UTC().Format(time.RFC3339)
+	}
+	prompt := func(uuid, session string, hours int) string {
+		return `{"uuid":"` + uuid + `","type":"user","timestamp":"` + ts(hours) + `","sessionId":"` + session + `","message":{"role":"user","content":"go on"}}` + "\n"
+	}
+	reply := func(uuid, session string, hours int) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + ts(hours) + `","sessionId":"` + session + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
+	files := map[string]string{
+		// Files are named by random UUIDs, so the first may sort last
+		"b-first.jsonl":   prompt("u1", "abc", 0) + reply("a1", "abc", 0) + `{"type":"summary","summary":"Old title","leafUuid":"a1"}` + "\n",
+		"a-resumed.jsonl": `{"type":"summary","summary":"New title","leafUuid":"a2"}` + "\n" + prompt("u2", "abc", 1) + reply("a2", "abc", 2),
+		"legacy.jsonl":    strings.ReplaceAll(prompt("u3", "", 1)+reply("a3", "", 1), `"sessionId":"",`, ""),
+	}
+	for name, data := range files {
+		path := filepath.Join(tmpDir, "projects", "app", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
 		}
 	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.Sessions) != 2 || analysis.Sessions["legacy"] == nil {
+		t.Fatalf("sessions %v, want abc and the file-named legacy", analysis.Sessions)
+	}
+	session := analysis.Sessions["abc"]
+	if session == nil {
+		t.Fatal("no session abc")
+	}
+	if session.Files != 2 || session.MessageCount != 2 || session.Prompts != 2 || session.Title != "New title" {
+		t.Errorf("resumed session = %d files, %d messages, %d prompts, title %q; want 2, 2, 2, New title",
+			session.Files, session.MessageCount, session.Prompts, session.Title)
+	}
+	if got := session.EndTime.Sub(session.StartTime); got != 2*time.Hour {
+		t.Errorf("resumed session spans %v, want 2h across both files", got)
+	}
 }
 
-func BenchmarkParser_calculateTokenCost(b *testing.B) {
-	p := New(30, "/test")
-	usage := &models.Usage{
-		InputTokens:              1000,
-		OutputTokens:             500,
-		CacheReadInputTokens:     200,
-		CacheCreationInputTokens: 100,
+func TestParser_CrossFileChains(t *testing.T) {
+	tmpDir := t.TempDir()
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	at := func(seconds int) string { return start.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339) }
+	line := func(uuid, parent, typ string, seconds int, content string) string {
+		message := `{"role":"user","content":` + content + `}`
+		if typ == "assistant" {
+			message = `{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514","content":` + content + `}`
+		}
+		return `{"uuid":"` + uuid + `","parentUuid":"` + parent + `","type":"` + typ + `","timestamp":"` + at(seconds) +
+			`","sessionId":"s","message":` + message + "}\n"
 	}
-	model := "claude-opus-4-20250514"
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = p.calculateTokenCost(usage, model)
+	// The session was resumed while a tool ran, and its last prompt was
+	// answered in the new file. Files are named by random UUIDs, so the
+	// new one may sort first.
+	files := map[string]string{
+		"b-first.jsonl": line("u1", "", "user", 0, `"run the tests"`) +
+			line("a1", "u1", "assistant", 5, `[{"type":"tool_use","id":"t1"}]`) +
+			line("u3", "a1", "user", 200, `"and again"`),
+		"a-resumed.jsonl": line("r1", "a1", "user", 65, `[{"type":"tool_result","tool_use_id":"t1"}]`) +
+			line("a2", "r1", "assistant", 75, `"all tests pass"`) +
+			line("a3", "u3", "assistant", 210, `"still passing"`),
+	}
+	for name, data := range files {
+		path := filepath.Join(tmpDir, "projects", "app", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortDurations := func(d []time.Duration) []time.Duration {
+		sorted := append([]time.Duration(nil), d...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		return sorted
+	}
+	// a2 answers the tool result, and a3 the prompt in the other file
+	if got, want := sortDurations(analysis.ResponseTimes), []time.Duration{5 * time.Second, 10 * time.Second, 10 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ResponseTimes = %v, want %v", got, want)
+	}
+	// The first turn ends with the reply in the resumed file; the tool ran
+	// for 60s of its 75s
+	if got, want := sortDurations(analysis.TurnTimes), []time.Duration{10 * time.Second, 75 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TurnTimes = %v, want %v", got, want)
+	}
+	if got, want := sortDurations(analysis.TurnModelTimes), []time.Duration{10 * time.Second, 15 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TurnModelTimes = %v, want %v", got, want)
 	}
 }
 
-// Helper function for floating point comparison
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
+func TestParser_ClockSkew(t *testing.T) {
+	tmpDir := t.TempDir()
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	at := func(seconds int) string { return start.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339) }
+
+	// The clock went back 3s between the first reply and the second prompt
+	data := `{"uuid":"u1","type":"user","timestamp":"` + at(0) + `","message":{"role":"user","content":"hi"}}` + "\n" +
+		`{"uuid":"a1","parentUuid":"u1","type":"assistant","timestamp":"` + at(5) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n" +
+		`{"uuid":"u2","parentUuid":"a1","type":"user","timestamp":"` + at(2) + `","message":{"role":"user","content":"again"}}` + "\n" +
+		`{"uuid":"a2","parentUuid":"u2","type":"assistant","timestamp":"` + at(10) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.ClockSkewStats{OutOfOrder: 1, ParentAfterChild: 1, Sessions: 1}
+	if analysis.ClockSkew != want {
+		t.Errorf("ClockSkew = %+v, want %+v", analysis.ClockSkew, want)
+	}
+	if len(analysis.ResponseTimes) != 2 || len(analysis.TurnTimes) != 2 {
+		t.Errorf("got %v response and %v turn times, want both turns measured", analysis.ResponseTimes, analysis.TurnTimes)
+	}
+
+	analysis, err = New(30, tmpDir, WithExcludeSkewed(true)).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Excluded = 2
+	if analysis.ClockSkew != want {
+		t.Errorf("with WithExcludeSkewed, ClockSkew = %+v, want %+v", analysis.ClockSkew, want)
+	}
+	if !reflect.DeepEqual(analysis.ResponseTimes, []time.Duration{5 * time.Second}) ||
+		!reflect.DeepEqual(analysis.TurnTimes, []time.Duration{5 * time.Second}) {
+		t.Errorf("with WithExcludeSkewed, got %v response and %v turn times, want only the first turn's", analysis.ResponseTimes, analysis.TurnTimes)
 	}
-	return x
 }
 
-// Example of table-driven test with setup
-func TestParser_Integration(t *testing.T) {
-	// Skip integration tests in short mode
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
+func TestParser_SkipsUnmodifiedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	reply := `{"type":"assistant","timestamp":"` + now.Add(-time.Hour).UTC().Format(time.RFC3339) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+
+	// An entry can't be newer than its file, so a file last written long
+	// before the cutoff isn't read even though this one's entry is in range.
+	// The margin admits files written shortly before it.
+	mtimes := map[string]time.Time{
+		"recent.jsonl": now,
+		"margin.jsonl": now.Add(-24*time.Hour - time.Hour),
+		"old.jsonl":    now.Add(-30 * 24 * time.Hour),
+	}
+	for name, mtime := range mtimes {
+		path := filepath.Join(tmpDir, "projects", "app", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(reply), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	// Create temporary directory for test
+	var stats ReadStats
+	analysis, err := New(1, tmpDir, WithNow(now), WithReadStats(&stats)).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Files != 2 || len(analysis.Sessions) != 2 || analysis.Sessions["old"] != nil {
+		t.Errorf("read %d files, sessions %v; want recent and margin only", stats.Files, analysis.Sessions)
+	}
+}
+
+func TestParser_SeeksToCutoff(t *testing.T) {
 	tmpDir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := func(uuid string, at time.Time) string {
+		return `{"uuid":"` + uuid + `","sessionId":"s1","type":"assistant","timestamp":"` + at.Format(time.RFC3339) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
 
-	// Create test JSONL file
-	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
-	err := os.MkdirAll(filepath.Dir(testFile), 0755)
+	// A long-lived session: months of history, then a few recent replies
+	var data strings.Builder
+	data.WriteString(`{"type":"summary","summary":"Long session","leafUuid":"r2"}` + "\n")
+	old := now.Add(-90 * 24 * time.Hour)
+	for i := 0; i < 5000; i++ {
+		data.WriteString(entry("o"+strconv.Itoa(i), old.Add(time.Duration(i)*time.Minute)))
+	}
+	for i := 0; i < 3; i++ {
+		data.WriteString(entry("r"+strconv.Itoa(i), now.Add(time.Duration(i-3)*time.Hour)))
+	}
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(threshold int64) { seekThreshold = threshold }(seekThreshold)
+	parse := func(threshold int64) (*models.CostAnalysis, ReadStats) {
+		seekThreshold = threshold
+		var stats ReadStats
+		analysis, err := New(7, tmpDir, WithNow(now), WithReadStats(&stats)).ParseAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return analysis, stats
+	}
+
+	full, fullStats := parse(1 << 40)
+	seeked, seekedStats := parse(0)
+	if seekedStats.Lines*10 > fullStats.Lines {
+		t.Errorf("read %d of %d lines, want the search to skip most", seekedStats.Lines, fullStats.Lines)
+	}
+	if seeked.TotalOutputTokens != 15 || seeked.TotalOutputTokens != full.TotalOutputTokens {
+		t.Errorf("output tokens = %d, want 15 as when read in full (%d)", seeked.TotalOutputTokens, full.TotalOutputTokens)
+	}
+	if session := seeked.Sessions["s1"]; session == nil || session.Title != "Long session" {
+		t.Errorf("session = %+v, want it titled from the summary at the head", session)
+	}
+}
+
+func TestParser_SeekKeepsAccount(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := func(uuid string, at time.Time) string {
+		return `{"uuid":"` + uuid + `","sessionId":"s1","type":"assistant","timestamp":"` + at.Format(time.RFC3339) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
+	prompt := func(account string, at time.Time) string {
+		return `{"type":"user","sessionId":"s1","userID":"` + account + `","timestamp":"` + at.Format(time.RFC3339) + `"}` + "\n"
+	}
+
+	// The account is named long before the cutoff, and changes once
+	old := now.Add(-90 * 24 * time.Hour)
+	var data strings.Builder
+	data.WriteString(entry("head", old))
+	data.WriteString(prompt("acct-1", old))
+	for i := 0; i < 5000; i++ {
+		if i == 2500 {
+			data.WriteString(prompt("acct-2", old.Add(time.Duration(i)*time.Minute)))
+		}
+		data.WriteString(entry("o"+strconv.Itoa(i), old.Add(time.Duration(i)*time.Minute)))
+	}
+	data.WriteString(entry("recent", now.Add(-time.Hour)))
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(threshold int64) { seekThreshold = threshold }(seekThreshold)
+	accounts := func(threshold int64) []string {
+		seekThreshold = threshold
+		analysis, err := New(7, tmpDir, WithNow(now)).ParseAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for name := range analysis.Accounts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	full, seeked := accounts(1<<40), accounts(0)
+	if !reflect.DeepEqual(full, []string{"acct-2"}) || !reflect.DeepEqual(seeked, full) {
+		t.Errorf("accounts = %v seeked, %v read in full, want [acct-2] both ways", seeked, full)
+	}
+}
+
+func TestParser_SkipsDuplicateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	at := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	reply := func(uuid string) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + at + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
+
+	// A synced copy, a backup taken before the last reply, and a different
+	// session that happens to share the name
+	files := map[string]string{
+		"app/s1.jsonl":        reply("a") + reply("b"),
+		"app-synced/s1.jsonl": reply("a") + reply("b"),
+		"app-backup/s1.jsonl": reply("a"),
+		"other/s1.jsonl":      reply("c"),
+	}
+	for name, data := range files {
+		path := filepath.Join(tmpDir, "projects", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := New(1, tmpDir).ParseAll()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if analysis.TotalOutputTokens != 15 {
+		t.Errorf("output tokens = %d, want 15 with the copies counted once", analysis.TotalOutputTokens)
+	}
+	original := filepath.Join(tmpDir, "projects", "app", "s1.jsonl")
+	want := []models.DuplicateFile{
+		{Path: filepath.Join(tmpDir, "projects", "app-backup", "s1.jsonl"), Original: original},
+		{Path: filepath.Join(tmpDir, "projects", "app-synced", "s1.jsonl"), Original: original},
+	}
+	if !reflect.DeepEqual(analysis.DuplicateFiles, want) {
+		t.Errorf("duplicates = %v, want %v", analysis.DuplicateFiles, want)
+	}
+}
 
-	// Write test data
-	testData := `{"uuid":"123","type":"assistant","timestamp":"2025-06-13T14:30:45.123Z","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"test-session"}
-`
-	err = os.WriteFile(testFile, []byte(testData), 0644)
+func TestParser_WithFS(t *testing.T) {
+	at := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	reply := func(uuid string) []byte {
+		return []byte(`{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + at + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n")
+	}
+	// A Claude directory inside an archive, with a copied session and a
+	// subagent's file a level deeper
+	fsys := fstest.MapFS{
+		"backup/.claude/projects/-srv-app/s1.jsonl":             {Data: append(reply("a"), reply("b")...)},
+		"backup/.claude/projects/-srv-app-copy/s1.jsonl":        {Data: append(reply("a"), reply("b")...)},
+		"backup/.claude/projects/-srv-app/s1/subagents/x.jsonl": {Data: reply("x")},
+		"backup/.claude/projects/-srv-app/s1/agent-2.jsonl":     {Data: reply("c")},
+		"backup/.claude/projects/stray.jsonl":                   {Data: reply("d")},
+	}
+
+	analysis, err := New(1, "backup/.claude", WithFS(fsys)).ParseAll()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if analysis.TotalOutputTokens != 15 {
+		t.Errorf("output tokens = %d, want 15 from s1 and agent-2", analysis.TotalOutputTokens)
+	}
+	want := []models.DuplicateFile{{
+		Path:     "backup/.claude/projects/-srv-app-copy/s1.jsonl",
+		Original: "backup/.claude/projects/-srv-app/s1.jsonl",
+	}}
+	if !reflect.DeepEqual(analysis.DuplicateFiles, want) {
+		t.Errorf("duplicates = %v, want %v", analysis.DuplicateFiles, want)
+	}
+	if _, ok := analysis.Projects["/srv/app"]; !ok || len(analysis.Projects) != 1 {
+		t.Errorf("projects = %v, want /srv/app", analysis.Projects)
+	}
 
-	// Test parsing
-	p := New(30, tmpDir)
-	analysis, err := p.ParseAll()
+	if _, err := New(1, ".", WithFS(fsys)).ParseAll(); !errors.Is(err, ErrNoJSONLFiles) {
+		t.Errorf("root without projects: err = %v, want ErrNoJSONLFiles", err)
+	}
+}
+
+func TestParser_WithFS_NoHostCalls(t *testing.T) {
+	stat, home := statHost, userHomeDir
+	t.Cleanup(func() { statHost, userHomeDir = stat, home })
+	statHost = func(name string) (os.FileInfo, error) {
+		t.Errorf("stat %s on the host", name)
+		return nil, os.ErrNotExist
+	}
+	userHomeDir = func() (string, error) {
+		t.Error("looked up the host's home directory")
+		return "", os.ErrNotExist
+	}
+
+	at := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	fsys := fstest.MapFS{
+		".claude/projects/-home-alice-src-my-app/s1.jsonl": {Data: []byte(`{"uuid":"a","type":"assistant","timestamp":"` + at + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n")},
+	}
+	analysis, err := New(1, ".claude", WithFS(fsys)).ParseAll()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if _, ok := analysis.Projects["src/my/app"]; !ok || len(analysis.Projects) != 1 {
+		t.Errorf("projects = %v, want src/my/app", analysis.Projects)
+	}
+}
 
-	// Verify results
-	if len(analysis.Sessions) == 0 {
-		t.Error("Expected at least one session")
+func TestParser_WithMemoryLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	at := func(seconds int) string { return start.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339) }
+
+	// The reply is written before its prompt, which is still matched once
+	// the prompt is read
+	data := `{"uuid":"a1","parentUuid":"u1","type":"assistant","timestamp":"` + at(5) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n" +
+		`{"uuid":"u1","type":"user","timestamp":"` + at(0) + `","message":{"role":"user","content":"hi"}}` + "\n"
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	if analysis.TotalCost == 0 {
-		t.Error("Expected non-zero total cost")
+	defer func(live func() uint64) { liveHeap = live }(liveHeap)
+	tests := []struct {
+		name        string
+		heap        uint64
+		wantLimited bool
+		wantTimes   int
+	}{
+		{name: "under the limit", heap: 10 << 20, wantTimes: 1},
+		{name: "near the limit", heap: 80 << 20, wantLimited: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			liveHeap = func() uint64 { return tt.heap }
+			analysis, err := New(30, tmpDir, WithMemoryLimit(100<<20)).ParseAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if analysis.MemoryLimited != tt.wantLimited {
+				t.Errorf("MemoryLimited = %v, want %v", analysis.MemoryLimited, tt.wantLimited)
+			}
+			if len(analysis.ResponseTimes) != tt.wantTimes || len(analysis.TurnTimes) != tt.wantTimes {
+				t.Errorf("got %v response and %v turn times, want %d of each", analysis.ResponseTimes, analysis.TurnTimes, tt.wantTimes)
+			}
+			if analysis.TotalOutputTokens != 5 || analysis.Sessions["session"] == nil {
+				t.Errorf("costs changed: %d output tokens, sessions %v", analysis.TotalOutputTokens, analysis.Sessions)
+			}
+		})
+	}
+}
+
+func TestParser_WithDayBoundary(t *testing.T) {
+	tmpDir := t.TempDir()
+	// A session from 23:30 to 01:30 UTC
+	end := time.Now().UTC().Truncate(24 * time.Hour).Add(-22*time.Hour - 30*time.Minute)
+	var data string
+	for _, ts := range []time.Time{end.Add(-2 * time.Hour), end} {
+		data += `{"type":"assistant","timestamp":"` + ts.Format(time.RFC3339) + `","message":{"usage":{"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		boundary models.DayBoundary
+		days     int
+	}{
+		{models.DayBoundary{UTC: true}, 2},
+		{models.DayBoundary{UTC: true, StartHour: 4}, 1},
+	}
+	for _, tt := range tests {
+		analysis, err := New(30, tmpDir, WithDayBoundary(tt.boundary)).ParseAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(analysis.DailyActivity) != tt.days {
+			t.Errorf("%+v: got days %v, want %d", tt.boundary, analysis.DailyActivity, tt.days)
+		}
+		if analysis.DayBoundary != tt.boundary {
+			t.Errorf("DayBoundary = %+v, want %+v", analysis.DayBoundary, tt.boundary)
+		}
+		day := analysis.DailyActivity[tt.boundary.Date(end)]
+		if day == nil || day.ProjectCosts["app"] == 0 {
+			t.Errorf("%+v: the last day has no project costs: %+v", tt.boundary, day)
+		}
+	}
+}
+
+func TestParser_processToolUses_FileExtensions(t *testing.T) {
+	p := New(30, "/test")
+	analysis := &models.CostAnalysis{FileExtensions: make(map[string]*models.ExtensionStats)}
+	project := &models.ProjectStats{}
+
+	toolUse := func(name string, input map[string]interface{}) interface{} {
+		return map[string]interface{}{"type": "tool_use", "name": name, "input": input}
+	}
+	entry := &models.Entry{Type: "assistant", Message: &models.MessageContent{Content: []interface{}{
+		toolUse("Edit", map[string]interface{}{"file_path": "/src/main.go"}),
+		toolUse("MultiEdit", map[string]interface{}{"file_path": "/src/util.GO"}),
+		toolUse("Write", map[string]interface{}{"file_path": "/src/app.ts"}),
+		toolUse("Read", map[string]interface{}{"file_path": "/src/Makefile"}),
+		toolUse("Bash", map[string]interface{}{"command": "go test ./..."}),
+		map[string]interface{}{"type": "text", "text": "done"},
+	}}}
+
+	p.processToolUses(entry, analysis, project)
+
+	want := map[string]models.ExtensionStats{
+		".go":       {Edits: 2},
+		".ts":       {Writes: 1},
+		noExtension: {Reads: 1},
+	}
+	for _, byExt := range []map[string]*models.ExtensionStats{analysis.FileExtensions, project.FileExtensions} {
+		if len(byExt) != len(want) {
+			t.Errorf("got %d extensions, want %d", len(byExt), len(want))
+		}
+		for ext, stats := range want {
+			if got := byExt[ext]; got == nil || *got != stats {
+				t.Errorf("%s = %+v, want %+v", ext, got, stats)
+			}
+		}
+	}
+}
+
+func TestParser_RejectedCost(t *testing.T) {
+	p := New(30, "/test")
+	p.pendingTools = make(map[string]pendingTool)
+	analysis := &models.CostAnalysis{FileExtensions: make(map[string]*models.ExtensionStats)}
+	project := &models.ProjectStats{}
+
+	toolUse := func(id, name string) interface{} {
+		return map[string]interface{}{"type": "tool_use", "id": id, "name": name, "input": map[string]interface{}{}}
+	}
+	entry := &models.Entry{Type: "assistant", Message: &models.MessageContent{Content: []interface{}{
+		toolUse("a", "Edit"),
+		toolUse("b", "Write"),
+	}}}
+	p.processToolUses(entry, analysis, project)
+	p.priceToolUses(entry, 0.5)
+
+	result := func(id string) map[string]interface{} {
+		return map[string]interface{}{"type": "tool_result", "tool_use_id": id}
+	}
+	p.processToolResult(result("a"), nil, outcomeRejected, analysis, project)
+	p.processToolResult(result("b"), nil, outcomeAccepted, analysis, project)
+
+	// Each call carries half the response's cost
+	for _, byTool := range []map[string]*models.ToolStats{analysis.Tools, project.Tools} {
+		if got := byTool["Edit"].RejectedCost; got != 0.25 {
+			t.Errorf("Edit RejectedCost = %v, want 0.25", got)
+		}
+		if got := byTool["Write"].RejectedCost; got != 0 {
+			t.Errorf("Write RejectedCost = %v, want 0", got)
+		}
+	}
+}
+
+func TestParser_LineChanges(t *testing.T) {
+	p := New(30, "/test")
+	p.pendingTools = make(map[string]pendingTool)
+	analysis := &models.CostAnalysis{FileExtensions: make(map[string]*models.ExtensionStats)}
+	project := &models.ProjectStats{}
+
+	toolUse := func(id, name string, input map[string]interface{}) interface{} {
+		return map[string]interface{}{"type": "tool_use", "id": id, "name": name, "input": input}
+	}
+	entry := &models.Entry{Type: "assistant", Message: &models.MessageContent{Content: []interface{}{
+		// Only the middle line differs
+		toolUse("edit", "Edit", map[string]interface{}{"old_string": "a\nb\nc", "new_string": "a\nB\nB2\nc"}),
+		toolUse("multi", "MultiEdit", map[string]interface{}{"edits": []interface{}{
+			map[string]interface{}{"old_string": "x", "new_string": ""},
+			map[string]interface{}{"old_string": "", "new_string": "y\nz\n"},
+		}}),
+		toolUse("write", "Write", map[string]interface{}{"content": "1\n2\n3\n"}),
+		toolUse("patched", "Edit", map[string]interface{}{"old_string": "p", "new_string": "q"}),
+		toolUse("rejected", "Write", map[string]interface{}{"content": "never\n"}),
+	}}}
+	p.processToolUses(entry, analysis, project)
+
+	result := func(id string) map[string]interface{} {
+		return map[string]interface{}{"type": "tool_result", "tool_use_id": id}
+	}
+	for _, id := range []string{"edit", "multi", "write"} {
+		p.processToolResult(result(id), nil, outcomeAccepted, analysis, project)
+	}
+	// The structured patch wins over the estimate from the input
+	patch := &models.ToolUseResult{StructuredPatch: []models.PatchHunk{{Lines: []string{" p", "-q", "+r", "+s"}}}}
+	p.processToolResult(result("patched"), patch, outcomeAccepted, analysis, project)
+	p.processToolResult(result("rejected"), nil, outcomeRejected, analysis, project)
+
+	// edit 2+1, multi 2+1, write 3+0, patched 2+1
+	want := models.LineChanges{Added: 9, Removed: 3}
+	if analysis.Lines != want || project.Lines != want {
+		t.Errorf("lines = %+v and %+v, want %+v", analysis.Lines, project.Lines, want)
+	}
+}
+
+func TestParser_classifyToolResult(t *testing.T) {
+	p := New(30, "/test", WithRejectionPatterns("blocked by policy hook"))
+
+	tests := []struct {
+		name  string
+		entry *models.Entry
+		item  map[string]interface{}
+		want  toolOutcome
+	}{
+		{
+			name:  "accepted",
+			entry: &models.Entry{},
+			item:  map[string]interface{}{"content": "ok"},
+			want:  outcomeAccepted,
+		},
+		{
+			name:  "built-in rejection text",
+			entry: &models.Entry{},
+			item:  map[string]interface{}{"content": "The user doesn't want to proceed with this tool use.", "is_error": true},
+			want:  outcomeRejected,
+		},
+		{
+			name:  "interrupted",
+			entry: &models.Entry{ToolUseResult: &models.ToolUseResult{Interrupted: true}},
+			item:  map[string]interface{}{"content": "partial output"},
+			want:  outcomeRejected,
+		},
+		{
+			name:  "custom pattern in text blocks",
+			entry: &models.Entry{},
+			item: map[string]interface{}{"content": []interface{}{
+				map[string]interface{}{"type": "text", "text": "Command blocked by policy hook"},
+			}},
+			want: outcomeRejected,
+		},
+		{
+			name:  "tool error",
+			entry: &models.Entry{},
+			item:  map[string]interface{}{"content": "exit status 1", "is_error": true},
+			want:  outcomeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.classifyToolResult(tt.entry, tt.item); got != tt.want {
+				t.Errorf("classifyToolResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandCategory(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"git status", "git"},
+		{"  npm run build", "npm"},
+		{"cd /src/app && go test ./...", "go"},
+		{"GOFLAGS=-count=1 CGO_ENABLED=0 go test ./...", "go"},
+		{"sudo apt-get install jq", "apt-get"},
+		{"/usr/local/bin/pytest -x tests/", "pytest"},
+		{"./gradlew build", "gradlew"},
+		{"", "(other)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := commandCategory(tt.command); got != tt.want {
+				t.Errorf("commandCategory(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkParseTimestamp(b *testing.B) {
+	timestamp := "2025-06-13T14:30:45.123Z"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := parseTimestamp(timestamp)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser_calculateTokenCost(b *testing.B) {
+	p := New(30, "/test")
+	usage := &models.Usage{
+		InputTokens:              1000,
+		OutputTokens:             500,
+		CacheReadInputTokens:     200,
+		CacheCreationInputTokens: 100,
+	}
+	model := "claude-opus-4-20250514"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = p.calculateTokenCost(usage, model)
+	}
+}
+
+func BenchmarkParser_ParseAll(b *testing.B) {
+	opts := fixture.DefaultOptions()
+	opts.Projects, opts.Sessions, opts.Turns = 10, 20, 20
+	dir, _ := fixture.Dir(b, opts)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := New(opts.Days+1, dir).ParseAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchCorpora are the generated corpora BenchmarkParser_Corpus parses.
+// CLAUDE_COSTS_BENCH_CORPORA lists those to run, by name, defaulting to the
+// smallest. The larger take minutes to generate, so all are kept in the
+// user cache directory for later runs.
+var benchCorpora = []struct {
+	name string
+	size int64
+}{
+	{"100MB", 100e6},
+	{"1GB", 1e9},
+	{"10GB", 10e9},
+}
+
+func BenchmarkParser_Corpus(b *testing.B) {
+	selected := strings.Split(os.Getenv("CLAUDE_COSTS_BENCH_CORPORA"), ",")
+	if selected[0] == "" {
+		selected = []string{benchCorpora[0].name}
+	}
+	for _, corpus := range benchCorpora {
+		b.Run(corpus.name, func(b *testing.B) {
+			if !slices.Contains(selected, corpus.name) {
+				b.Skip("not listed in CLAUDE_COSTS_BENCH_CORPORA")
+			}
+			dir, opts, summary := benchCorpus(b, corpus.name, corpus.size)
+			b.SetBytes(summary.Bytes)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := New(opts.Days+1, dir, WithNow(opts.End)).ParseAll(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(summary.Lines)*float64(b.N)/b.Elapsed().Seconds(), "lines/s")
+		})
+	}
+}
+
+// benchCorpus returns the directory of a generated corpus of about size
+// bytes, generating it unless an earlier run finished doing so
+func benchCorpus(b *testing.B, name string, size int64) (string, fixture.Options, fixture.Summary) {
+	b.Helper()
+	opts := fixture.DefaultOptions()
+	opts.Projects, opts.Turns, opts.Size = 20, 40, size
+	opts.Days = 3 * 365
+	opts.End = time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := filepath.Join(cache, "claude-costs", "bench", name)
+	done := filepath.Join(dir, "summary.json")
+
+	var summary fixture.Summary
+	if data, err := os.ReadFile(done); err == nil && json.Unmarshal(data, &summary) == nil {
+		return dir, opts, summary
+	}
+	b.Logf("generating the %s corpus in %s", name, dir)
+	if err := os.RemoveAll(dir); err != nil {
+		b.Fatal(err)
+	}
+	if summary, err = fixture.Generate(dir, opts); err != nil {
+		b.Fatal(err)
+	}
+	data, _ := json.Marshal(summary)
+	if err := os.WriteFile(done, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return dir, opts, summary
+}
+
+// Helper function for floating point comparison
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Example of table-driven test with setup
+func TestParser_Integration(t *testing.T) {
+	// Skip integration tests in short mode
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// Create temporary directory for test
+	tmpDir := t.TempDir()
+
+	// Create test JSONL file
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	err := os.MkdirAll(filepath.Dir(testFile), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write test data
+	testData := `{"uuid":"123","type":"assistant","timestamp":"2025-06-13T14:30:45.123Z","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"test-session"}
+`
+	err = os.WriteFile(testFile, []byte(testData), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test parsing, as of the day the test data was written
+	p := New(30, tmpDir, WithNow(time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)))
+	analysis, err := p.ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify results
+	if len(analysis.Sessions) == 0 {
+		t.Error("Expected at least one session")
+	}
+
+	if analysis.TotalCost == 0 {
+		t.Error("Expected non-zero total cost")
+	}
+}
+
+func TestParser_WithSince(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	line := func(uuid string, ts time.Time) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + ts.UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"s"}` + "\n"
+	}
+	testData := line("before", since.Add(-time.Hour)) +
+		line("at", since) +
+		line("after", since.Add(time.Hour))
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir, WithSince(since)).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := 0
+	for _, count := range analysis.ModelUsage {
+		messages += count
+	}
+	if messages != 1 {
+		t.Errorf("messages = %d, want 1 (only the entry after since)", messages)
+	}
+	if !analysis.EndDate.Equal(since.Add(time.Hour)) {
+		t.Errorf("EndDate = %v, want %v", analysis.EndDate, since.Add(time.Hour))
+	}
+}
+
+func TestParser_WithProjectFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	for _, project := range []string{"-home-user-acme-api", "-home-user-hobby"} {
+		testFile := filepath.Join(tmpDir, "projects", project, "session.jsonl")
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		testData := `{"uuid":"u","type":"assistant","timestamp":"` + ts +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"s"}` + "\n"
+		if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := New(30, tmpDir, WithProjectFilter("*acme*")).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.Projects) != 1 {
+		t.Fatalf("Projects = %d, want 1", len(analysis.Projects))
+	}
+	for name := range analysis.Projects {
+		if !strings.Contains(name, "acme") {
+			t.Errorf("project %q passed the *acme* filter", name)
+		}
+	}
+}
+
+func TestParser_LimitEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	testData := `{"uuid":"1","type":"assistant","timestamp":"` + ts + `","isApiErrorMessage":true,"message":{"model":"<synthetic>","content":[{"type":"text","text":"Claude AI usage limit reached|1751234400"}]}}
+{"uuid":"2","type":"assistant","timestamp":"` + ts + `","isApiErrorMessage":true,"message":{"model":"<synthetic>","content":[{"type":"text","text":"API Error: 429 {\"type\":\"error\",\"error\":{\"type\":\"rate_limit_error\"}}"}]}}
+{"uuid":"3","type":"system","timestamp":"` + ts + `","content":"API Error: Rate limited, retrying in 5 seconds","level":"warning"}
+{"uuid":"4","type":"assistant","timestamp":"` + ts + `","message":{"model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Add a rate limit to the API handler"}],"usage":{"input_tokens":10,"output_tokens":10}}}
+{"uuid":"5","type":"user","timestamp":"` + ts + `","message":{"role":"user","content":"why did I hit the usage limit reached message?"}}
+`
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := make(map[models.LimitKind]int)
+	for _, event := range analysis.LimitEvents {
+		kinds[event.Kind]++
+	}
+	if kinds[models.UsageLimit] != 1 || kinds[models.RateLimit] != 2 || len(analysis.LimitEvents) != 3 {
+		t.Errorf("LimitEvents = %+v, want 1 usage limit and 2 rate limits", analysis.LimitEvents)
+	}
+}
+
+func TestParser_OpusDowngrades(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	line := func(offset time.Duration, model, extra string) string {
+		return `{"uuid":"` + offset.String() + `","type":"assistant","timestamp":"` + start.Add(offset).UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"` + model + `"}` + extra + `}` + "\n"
+	}
+	testData := line(0, "claude-sonnet-4-20250514", "") + // Before any Opus: not a downgrade
+		line(time.Minute, "claude-opus-4-20250514", "") +
+		line(2*time.Minute, "claude-opus-4-20250514", "") +
+		line(3*time.Minute, "claude-3-5-haiku-20241022", "") + // Background model: ignored
+		line(4*time.Minute, "claude-sonnet-4-20250514", "") + // Switch
+		line(5*time.Minute, "claude-sonnet-4-20250514", `,"isSidechain":true`) +
+		line(6*time.Minute, "claude-sonnet-4-20250514", "") +
+		`{"uuid":"cmd","type":"user","timestamp":"` + start.Add(7*time.Minute).UTC().Format(time.RFC3339) +
+		`","message":{"role":"user","content":"<command-name>/model</command-name>"}}` + "\n" +
+		line(8*time.Minute, "claude-sonnet-4-20250514", "") // Chosen with /model
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opus, downgraded, switches := 0, 0, 0
+	for _, day := range analysis.DailyActivity {
+		opus += len(day.OpusMinutes)
+		downgraded += len(day.DowngradedMinutes)
+		switches += day.ModelSwitches
+	}
+	if opus != 2 || downgraded != 2 || switches != 1 {
+		t.Errorf("opus = %d, downgraded = %d, switches = %d; want 2, 2, 1", opus, downgraded, switches)
+	}
+}
+
+func TestParser_CacheExpiry(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	line := func(offset time.Duration, read, write int, extra string) string {
+		return `{"uuid":"` + offset.String() + `","type":"assistant","timestamp":"` + start.Add(offset).UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":10,"output_tokens":50,"cache_read_input_tokens":` + strconv.Itoa(read) +
+			`,"cache_creation_input_tokens":` + strconv.Itoa(write) + `},"model":"claude-sonnet-4-20250514"}` + extra + `}` + "\n"
+	}
+	testData := line(0, 0, 20_000, "") +
+		line(4*time.Minute, 20_000, 500, "") + // Warm
+		line(10*time.Minute, 0, 1_000_000, `,"isSidechain":true`) + // A subagent's own cache
+		line(10*time.Minute+30*time.Second, 0, 1_000_000, "") + // 6.5 minutes idle: a near miss
+		line(12*time.Minute, 1_000_000, 500, "") +
+		line(18*time.Minute, 1_000_000, 500, "") + // Past the TTL, yet still read
+		line(60*time.Minute, 0, 1_000_000, "") // Long gone: not a near miss
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiry := analysis.CacheExpiry
+	if expiry.Rewrites != 1 || expiry.Tokens != 1_000_000 || expiry.Gaps[6] != 1 {
+		t.Errorf("CacheExpiry = %+v, want one 6-minute gap rewriting 1M tokens", expiry)
+	}
+	// Sonnet 4 writes at $3.75 and reads at $0.30 per million tokens
+	if abs(expiry.ExtraCost-3.45) > 1e-9 {
+		t.Errorf("ExtraCost = %v, want 3.45", expiry.ExtraCost)
+	}
+}
+
+func TestParser_Images(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	image := `{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw0KGgo="}}`
+	user := func(content string) string {
+		return `{"type":"user","timestamp":"` + ts + `","message":{"role":"user","content":` + content + `}}` + "\n"
+	}
+	// 1M input tokens at $3 and 1M cache reads at $0.30 per million
+	assistant := `{"type":"assistant","timestamp":"` + ts + `","message":{"model":"claude-sonnet-4-20250514",` +
+		`"usage":{"input_tokens":1000000,"output_tokens":1000,"cache_read_input_tokens":1000000}}}` + "\n"
+	testData := user(`"no images yet"`) + assistant +
+		user(`[{"type":"text","text":"what's wrong here?"},`+image+`,`+image+`]`) + assistant +
+		user(`[{"type":"tool_result","tool_use_id":"t1","content":[`+image+`]}]`) + assistant + // Same turn
+		user(`"thanks"`) + assistant
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.Projects) != 1 {
+		t.Fatalf("Projects = %v, want one", analysis.Projects)
+	}
+	var images models.ImageStats
+	for _, project := range analysis.Projects {
+		images = project.Images
+	}
+	if images.Images != 3 || images.Turns != 1 || abs(images.InputCost-6.6) > 1e-9 {
+		t.Errorf("Images = %+v, want 3 images in 1 turn costing $6.60 of input", images)
+	}
+}
+
+func TestParser_ContextOverhead(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	line := func(input, read, write, output int, extra string) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":` +
+			strconv.Itoa(input) + `,"cache_read_input_tokens":` + strconv.Itoa(read) + `,"cache_creation_input_tokens":` +
+			strconv.Itoa(write) + `,"output_tokens":` + strconv.Itoa(output) + `}}` + extra + `}` + "\n"
+	}
+	testData := line(100, 0, 10_000, 500, "") + // All novel
+		line(200, 10_000, 800, 300, "") + // 10,600 re-sent of 11,000
+		line(5_000, 0, 0, 100, `,"isSidechain":true`) +
+		line(50, 0, 2_000, 100, "") // After /compact: all re-sent
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := analysis.Sessions["session"]
+	if session == nil {
+		t.Fatal("no session")
+	}
+	if session.ContextTokens != 23_150 || session.RepeatedTokens != 12_650 {
+		t.Errorf("context = %d, repeated = %d; want 23150, 12650", session.ContextTokens, session.RepeatedTokens)
+	}
+	// Sonnet 4 input at $3, cache reads at $0.30, and writes at $3.75 per
+	// million tokens
+	want := (200*3.0+10_000*0.30+800*3.75)/1e6*10_600/11_000 + (50*3.0+2_000*3.75)/1e6
+	if abs(session.RepeatedCost-want) > 1e-12 {
+		t.Errorf("RepeatedCost = %v, want %v", session.RepeatedCost, want)
+	}
+}
+
+func TestParser_OpusTokensAndPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	user := func(content, extra string) string {
+		return `{"type":"user","timestamp":"` + ts + `","message":{"role":"user","content":` + content + `}` + extra + `}` + "\n"
+	}
+	assistant := func(model string, output int) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"model":"` + model +
+			`","usage":{"input_tokens":10,"cache_creation_input_tokens":1000,"output_tokens":` + strconv.Itoa(output) + `}}}` + "\n"
+	}
+	testData := user(`"explain this"`, "") +
+		assistant("claude-opus-4-20250514", 200) +
+		user(`[{"type":"tool_result","content":"ok"}]`, "") +
+		assistant("claude-opus-4-20250514", 100) +
+		user(`"subagent task"`, `,"isSidechain":true`) +
+		assistant("claude-3-5-haiku-20241022", 50) +
+		user(`"thanks"`, "")
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := analysis.Sessions["session"]
+	if session == nil {
+		t.Fatal("no session")
+	}
+	if session.Prompts != 2 {
+		t.Errorf("Prompts = %d, want 2 (not tool results or subagent prompts)", session.Prompts)
+	}
+	want := models.TokenCounts{Input: 20, Output: 300, CacheWrite: 2000}
+	if session.OpusTokens != want {
+		t.Errorf("OpusTokens = %+v, want %+v", session.OpusTokens, want)
+	}
+	if abs(session.OpusCost-want.CostAt(models.ModelPricing["claude-opus-4-20250514"])) > 1e-12 {
+		t.Errorf("OpusCost = %v, want the Opus responses only", session.OpusCost)
+	}
+}
+
+func TestParser_BackgroundTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	assistant := func(model, extra string) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"model":"` + model +
+			`","usage":{"input_tokens":100,"output_tokens":10}}` + extra + `}` + "\n"
+	}
+	haiku := "claude-3-5-haiku-20241022"
+	testData := assistant(haiku, "") +
+		assistant("claude-sonnet-4-20250514", "") +
+		assistant(haiku, "") +
+		assistant(haiku, `,"isSidechain":true`)
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := analysis.Background.Messages[haiku]; got != 2 {
+		t.Errorf("Background.Messages = %d, want 2", got)
+	}
+	if got := analysis.HaikuSubagents.Messages[haiku]; got != 1 {
+		t.Errorf("HaikuSubagents.Messages = %d, want 1", got)
+	}
+	if abs(analysis.Background.Cost-2*analysis.HaikuSubagents.Cost) > 1e-12 || analysis.Background.Cost == 0 {
+		t.Errorf("Background.Cost = %v, want twice the subagent's %v", analysis.Background.Cost, analysis.HaikuSubagents.Cost)
+	}
+	if analysis.ModelUsage[haiku] != 3 {
+		t.Errorf("ModelUsage = %v, want all Haiku responses counted", analysis.ModelUsage)
+	}
+}
+
+func TestParser_Interrupts(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	user := func(content, extra string) string {
+		return `{"type":"user","timestamp":"` + ts + `","message":{"role":"user","content":` + content + `}` + extra + `}` + "\n"
+	}
+	assistant := func(content string) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"model":"claude-sonnet-4-20250514","content":` + content +
+			`,"usage":{"input_tokens":1000,"output_tokens":100}}}` + "\n"
+	}
+	toolUse := func(id string) string {
+		return `[{"type":"tool_use","id":"` + id + `","name":"Bash","input":{"command":"ls"}}]`
+	}
+	toolResult := func(id string) string { return `[{"type":"tool_result","tool_use_id":"` + id + `","content":"ok"}]` }
+	files := map[string]string{
+		// Stopped twice in the second turn, which counts once
+		"stopped": user(`"first"`, "") + assistant(`"done"`) +
+			user(`"second"`, "") + assistant(toolUse("t1")) +
+			user(toolResult("t1"), `,"toolUseResult":{"interrupted":true}`) +
+			user(`[{"type":"text","text":"[Request interrupted by user for tool use]"}]`, "") +
+			user(`"third"`, "") + assistant(`"done"`),
+		// Ended waiting on a tool call
+		"abandoned": user(`"first"`, "") + assistant(toolUse("t2")),
+	}
+	for session, data := range files {
+		testFile := filepath.Join(tmpDir, "projects", "test-project", session+".jsonl")
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var project *models.ProjectStats
+	for _, proj := range analysis.Projects {
+		project = proj
+	}
+	got := project.Interrupts
+	if got.Turns != 4 || got.Interrupted != 1 || got.Abandoned != 1 {
+		t.Errorf("Interrupts = %+v, want 4 turns, 1 interrupted, 1 abandoned", got)
+	}
+	// Each turn cut short had one response
+	if perResponse := analysis.TotalCost / 4; abs(got.Cost-2*perResponse) > 1e-12 {
+		t.Errorf("Interrupts.Cost = %v, want %v", got.Cost, 2*perResponse)
+	}
+	if prompts := analysis.Sessions["stopped"].Prompts; prompts != 3 {
+		t.Errorf("Prompts = %d, want 3 (not the interruption notice)", prompts)
+	}
+}
+
+func TestResponseCategory(t *testing.T) {
+	content := func(types ...string) *models.MessageContent {
+		var items []interface{}
+		for _, typ := range types {
+			items = append(items, map[string]interface{}{"type": typ})
+		}
+		return &models.MessageContent{Content: items}
+	}
+	sonnet, haiku := "claude-sonnet-4-20250514", "claude-3-5-haiku-20241022"
+	tests := []struct {
+		name  string
+		entry models.Entry
+		model string
+		want  string
+	}{
+		{"housekeeping", models.Entry{Message: content("text")}, haiku, models.CategoryHousekeeping},
+		{"haiku subagent", models.Entry{IsSidechain: true, Message: content("text")}, haiku, models.CategorySubagent},
+		{"subagent tool call", models.Entry{IsSidechain: true, Message: content("tool_use")}, sonnet, models.CategorySubagent},
+		{"tool call after thinking", models.Entry{Message: content("thinking", "tool_use")}, sonnet, models.CategoryToolCall},
+		{"thinking", models.Entry{Message: content("thinking", "text")}, sonnet, models.CategoryThinking},
+		{"reply", models.Entry{Message: content("text")}, sonnet, models.CategoryReply},
+	}
+	for _, tt := range tests {
+		if got := responseCategory(&tt.entry, tt.model); got != tt.want {
+			t.Errorf("%s: responseCategory() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParser_SessionTitles(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	entry := func(uuid string) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + ts +
+			`","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":10}}}` + "\n"
+	}
+	summary := func(title, leaf string) string {
+		return `{"type":"summary","summary":"` + title + `","leafUuid":"` + leaf + `"}` + "\n"
+	}
+	files := map[string]string{
+		// A resumed session repeats the title of the one it continues
+		"resumed":  summary("Fix flaky uploader tests", "a2") + summary("Add retry to uploader", "b1") + entry("b1"),
+		"first":    summary("Fix flaky uploader tests", "a2") + entry("a1") + entry("a2"),
+		"other":    summary("Earlier conversation", "elsewhere") + entry("c1"),
+		"untitled": entry("d1"),
+	}
+	for session, data := range files {
+		testFile := filepath.Join(tmpDir, "projects", "test-project", session+".jsonl")
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"resumed":  "Add retry to uploader",
+		"first":    "Fix flaky uploader tests",
+		"other":    "Earlier conversation",
+		"untitled": "",
+	}
+	for session, title := range want {
+		if got := analysis.Sessions[session]; got == nil || got.Title != title {
+			t.Errorf("session %s = %+v, want title %q", session, got, title)
+		}
+	}
+}
+
+func TestParser_Accounts(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	line := func(uuid, extra string) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + ts + `"` + extra +
+			`,"message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	}
+	files := map[string]string{
+		// The first entry predates the account mention and is attributed to it
+		"work": line("w1", "") + line("w2", `,"accountUuid":"acct-work"`) + line("w3", ""),
+		"home": line("h1", `,"userID":"user-home"`) + line("h2", ""),
+		"anon": line("a1", ""),
+	}
+	for project, data := range files {
+		testFile := filepath.Join(tmpDir, "projects", project, "session.jsonl")
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := map[string]string{"acct-work": "Work"}
+
+	analysis, err := New(30, tmpDir, WithAccountNames(names)).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"Work": 3, "user-home": 2, UnknownAccount: 1}
+	for account, messages := range want {
+		if stats := analysis.Accounts[account]; stats == nil || stats.MessageCount != messages {
+			t.Errorf("account %q = %+v, want %d messages", account, stats, messages)
+		}
+	}
+
+	for _, filter := range []string{"Work", "acct-work"} {
+		analysis, err := New(30, tmpDir, WithAccountNames(names), WithAccount(filter)).ParseAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(analysis.Accounts) != 1 || analysis.Accounts["Work"] == nil || len(analysis.Projects) != 1 {
+			t.Errorf("WithAccount(%q): accounts = %v, projects = %d, want only Work", filter, analysis.Accounts, len(analysis.Projects))
+		}
+	}
+}
+
+func TestParser_WithMessageHandler(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	data := `{"uuid":"m1","type":"assistant","timestamp":"` + ts + `","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"}}` + "\n" +
+		`{"uuid":"u1","type":"user","timestamp":"` + ts + `","message":{"role":"user","content":"hi"}}` + "\n" +
+		`{"type":"assistant","timestamp":"` + ts + `","message":{"usage":{"input_tokens":10,"output_tokens":5},"model":"claude-opus-4-20250514"}}` + "\n"
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []Message
+	analysis, err := New(30, tmpDir, WithMessageHandler(func(m Message) { messages = append(messages, m) })).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want the 2 assistant responses", len(messages))
+	}
+	if m := messages[0]; m.ID != "m1" || m.Project != "app" || m.SessionID != "session" || m.Model != "claude-sonnet-4-20250514" || m.InputTokens != 100 {
+		t.Errorf("first message = %+v", m)
+	}
+	if id := messages[1].ID; id != "session@"+ts {
+		t.Errorf("message without a UUID has ID %q", id)
+	}
+	if total := messages[0].Cost + messages[1].Cost; math.Abs(total-analysis.TotalCost) > 1e-9 {
+		t.Errorf("message costs sum to %v, want TotalCost %v", total, analysis.TotalCost)
+	}
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	testData := `{"type":"summary","summary":"Entries without timestamps are not errors"}
+
+{"uuid":"1","type":"assistant","timestamp":"` + timestamp + `","message":{"usage":{"input_tokens":1}}}
+{"uuid":"2","type":"assistant","timestamp":"yesterday"}
+{"uuid":"3","type":
+`
+	if err := os.WriteFile(testFile, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.ParseErrors != 2 {
+		t.Errorf("ParseErrors = %d, want 2 (invalid timestamp and truncated line)", analysis.ParseErrors)
+	}
+}
+
+func TestParser_EntryTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	ts := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	data := `{"uuid":"u1","type":"user","timestamp":"` + ts + `","message":{"role":"user","content":"hi"}}` + "\n" +
+		`{"uuid":"a1","type":"assistant","timestamp":"` + ts + `","message":{"usage":{"input_tokens":10,"output_tokens":5},"model":"claude-sonnet-4-20250514"}}` + "\n" +
+		`{"type":"file-history-snapshot","messageId":"u1"}` + "\n" +
+		`{"type":"file-history-snapshot","messageId":"a1"}` + "\n" +
+		`{"type":"queue-operation","timestamp":"` + ts + `"}` + "\n" +
+		`{"type":"summary","summary":"Greeting","leafUuid":"a1"}` + "\n"
+	testFile := filepath.Join(tmpDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := New(30, tmpDir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"file-history-snapshot": 2, "queue-operation": 1}
+	if !reflect.DeepEqual(analysis.UnknownEntryTypes, want) {
+		t.Errorf("UnknownEntryTypes = %v, want %v", analysis.UnknownEntryTypes, want)
+	}
+	if types := analysis.UnknownTypes(); !reflect.DeepEqual(types, []string{"file-history-snapshot", "queue-operation"}) {
+		t.Errorf("UnknownTypes() = %v, want most frequent first", types)
+	}
+
+	// Handled types are passed on instead of counted, with or without a
+	// timestamp, and built-in types still reach their handlers
+	var handled []string
+	record := func(entry models.Entry, sessionID, project string) {
+		handled = append(handled, entry.Type+" "+sessionID+" "+project)
+	}
+	analysis, err = New(30, tmpDir,
+		WithEntryHandler("file-history-snapshot", record),
+		WithEntryHandler("queue-operation", record),
+		WithEntryHandler("summary", record),
+	).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analysis.UnknownEntryTypes) != 0 {
+		t.Errorf("UnknownEntryTypes = %v, want none with handlers", analysis.UnknownEntryTypes)
+	}
+	wantHandled := []string{
+		"file-history-snapshot session app",
+		"file-history-snapshot session app",
+		"queue-operation session app",
+		"summary session app",
+	}
+	if !reflect.DeepEqual(handled, wantHandled) {
+		t.Errorf("handled %q, want %q", handled, wantHandled)
+	}
+	if session := analysis.Sessions["session"]; session == nil || session.Title != "Greeting" {
+		t.Errorf("summary handler replaced the parser's own reading: session = %+v", session)
+	}
+}
+
+func TestTail(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	line := func(uuid string) string {
+		return `{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + time.Now().UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"s"}` + "\n"
+	}
+	partial := line("3")
+	if err := os.WriteFile(testFile, []byte(line("1")+line("2")+partial[:20]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tail := NewTail(time.Now().Add(-time.Hour))
+	parse := func() []string {
+		var ids []string
+		_, err := New(30, tmpDir, WithLineSource(tail), WithMessageHandler(func(m Message) { ids = append(ids, m.ID) })).ParseAll()
+		if err != nil && !errors.Is(err, ErrNoJSONLFiles) {
+			t.Fatal(err)
+		}
+		return ids
+	}
+
+	if got := parse(); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("first parse = %v, want [1 2]", got)
+	}
+	if got := parse(); len(got) != 0 {
+		t.Errorf("unchanged file = %v, want nothing", got)
+	}
+
+	// The partial line is read once it's finished
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(partial[20:] + line("4")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := parse(); !reflect.DeepEqual(got, []string{"3", "4"}) {
+		t.Errorf("after append = %v, want [3 4]", got)
+	}
+
+	// Lines longer than the read buffer are read whole
+	long := strings.Replace(line("5"), `"sessionId":"s"`, `"sessionId":"s","summary":"`+strings.Repeat("x", 100_000)+`"`, 1)
+	f, err = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(long + line("6")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := parse(); !reflect.DeepEqual(got, []string{"5", "6"}) {
+		t.Errorf("after a long line = %v, want [5 6]", got)
+	}
+
+	// Files last written before the tail's start are never read
+	if files, _ := NewTail(time.Now().Add(time.Hour)).Files(tmpDir); len(files) != 0 {
+		t.Errorf("Files = %v, want none modified since", files)
+	}
+
+	// A new tail resumes where this one's offsets left off
+	offsets := tail.Offsets()
+	f, err = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(line("7")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	tail = NewTail(time.Now().Add(-time.Hour))
+	tail.Resume(offsets)
+	if got := parse(); !reflect.DeepEqual(got, []string{"7"}) {
+		t.Errorf("after resuming = %v, want [7]", got)
+	}
+}
+
+func TestTail_Limit(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "projects", "test-project", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines strings.Builder
+	for _, uuid := range []string{"1", "2", "3", "4", "5"} {
+		lines.WriteString(`{"uuid":"` + uuid + `","type":"assistant","timestamp":"` + time.Now().UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"},"sessionId":"s"}` + "\n")
+	}
+	if err := os.WriteFile(testFile, []byte(lines.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tail := NewTail(time.Now().Add(-time.Hour))
+	tail.Limit(2)
+	parse := func() []string {
+		var ids []string
+		_, err := New(30, tmpDir, WithLineSource(tail), WithMessageHandler(func(m Message) { ids = append(ids, m.ID) })).ParseAll()
+		if err != nil && !errors.Is(err, ErrNoJSONLFiles) {
+			t.Fatal(err)
+		}
+		return ids
+	}
+
+	// Each parse picks up where the limit stopped the last one
+	for _, want := range [][]string{{"1", "2"}, {"3", "4"}, {"5"}, nil} {
+		if got := parse(); !reflect.DeepEqual(got, want) {
+			t.Errorf("parse = %v, want %v", got, want)
+		}
 	}
 }
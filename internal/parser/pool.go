@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// watch, top, serve, and the daemon parse continuously, so the allocations
+// made for every line and every file are reused rather than left for the
+// garbage collector
+
+// entryPool holds decoded entries, reset before reuse. Only the Entry
+// itself is reused: the message and content it points to are decoded
+// afresh, so handlers given a copy may keep it.
+var entryPool = sync.Pool{New: func() any { return new(models.Entry) }}
+
+// bufferPool holds the initial 64KB buffers lines are read into
+var bufferPool = sync.Pool{New: func() any {
+	buf := make([]byte, 0, 64*1024)
+	return &buf
+}}
+
+// getEntry returns a zero entry to decode a line into
+func getEntry() *models.Entry {
+	return entryPool.Get().(*models.Entry)
+}
+
+// putEntry returns an entry once nothing refers to it, dropping what it
+// points to
+func putEntry(entry *models.Entry) {
+	*entry = models.Entry{}
+	entryPool.Put(entry)
+}
@@ -0,0 +1,23 @@
+package parser
+
+// ReadStats counts what a parse read, to measure its throughput
+type ReadStats struct {
+	Files int
+	Lines int
+	Bytes int64 // Of the lines read, with their newlines
+}
+
+// WithReadStats adds the files, lines, and bytes ParseAll reads to stats
+func WithReadStats(stats *ReadStats) Option {
+	return func(p *Parser) {
+		p.readStats = stats
+	}
+}
+
+// countRead counts a line read
+func (p *Parser) countRead(line []byte) {
+	if p.readStats != nil {
+		p.readStats.Lines++
+		p.readStats.Bytes += int64(len(line)) + 1
+	}
+}
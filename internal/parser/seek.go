@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// seekThreshold is the smallest file worth seeking into rather than reading
+// from the start. It's a variable so tests can seek into small files.
+var seekThreshold int64 = 1 << 20
+
+// seekWindow is how close the binary search narrows in before reading on
+// line by line
+const seekWindow = 64 << 10
+
+// seekHeadLines bounds the lines read looking for a file's first timestamp;
+// files with none that early are read in full
+const seekHeadLines = 1000
+
+// seeks reports whether files may be read from near the cutoff rather than
+// from the start. Entry handlers see every entry, and an account filter
+// needs each file's account, which may be named only before the cutoff, so
+// both read files in full.
+func (p *Parser) seeks(cutoff time.Time) bool {
+	if p.source != nil || cutoff.IsZero() || p.accountFilter != "" {
+		return false
+	}
+	for _, handlers := range p.entryTypes {
+		if len(handlers) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// seekCutoff positions a large file at the first line timestamped within
+// clockMargin of the cutoff, found by binary search on the assumption that
+// entries are appended in time order. Lines up to and including the first
+// timestamped one are passed to fn first, as they carry the summaries that
+// title resumed sessions and the session ID, followed by the last skipped
+// line that names an account, since that account applies to the entries
+// read. Entries skipped this way are all before the cutoff, so only the
+// parse errors and unknown types among them go uncounted.
+func seekCutoff(file *os.File, cutoff time.Time, fn func(line []byte)) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() < seekThreshold {
+		return err
+	}
+
+	head, first, err := readHead(file, fn)
+	if err != nil {
+		return err
+	}
+	target := cutoff.Add(-clockMargin)
+	if first.IsZero() || !first.Before(target) {
+		_, err = file.Seek(head, io.SeekStart)
+		return err
+	}
+
+	lo, hi := head, info.Size()
+	for hi-lo > seekWindow {
+		mid := lo + (hi-lo)/2
+		start, ts, err := nextTimedLine(file, mid, hi)
+		if err != nil {
+			return err
+		}
+		if !ts.IsZero() && ts.Before(target) {
+			lo = start
+		} else {
+			hi = mid
+		}
+	}
+
+	account, err := lastAccountLine(file, head, lo)
+	if err != nil {
+		return err
+	}
+	if account != nil {
+		fn(account)
+	}
+	_, err = file.Seek(lo, io.SeekStart)
+	return err
+}
+
+// lastAccountLine returns the last line between the line starts head and
+// end that names an account, or nil if none does. It reads backward a
+// window at a time, so it stops soon after the usual frequent mentions.
+func lastAccountLine(file *os.File, head, end int64) ([]byte, error) {
+	start := end
+	for end > head {
+		start = max(head, start-seekWindow)
+		scanner, offset := offsetScanner(io.NewSectionReader(file, start, end-start))
+		// Unless at the head, start is most likely mid-line, so skip to
+		// the next
+		if start > head && !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		first := start + *offset
+		var found []byte
+		for scanner.Scan() {
+			if lineNamesAccount(scanner.Bytes()) {
+				found = append(found[:0], scanner.Bytes()...)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+		// A line longer than the window has no start within it; widen the
+		// window until one does
+		if first < end {
+			end, start = first, first
+		}
+	}
+	return nil, nil
+}
+
+// lineNamesAccount reports whether a line names an account, decoding only
+// lines that mention one of its fields
+func lineNamesAccount(line []byte) bool {
+	if !bytes.Contains(line, []byte(`"userID"`)) && !bytes.Contains(line, []byte(`"accountUuid"`)) {
+		return false
+	}
+	var entry struct {
+		UserID      string `json:"userID"`
+		AccountUUID string `json:"accountUuid"`
+	}
+	if json.Unmarshal(line, &entry) != nil {
+		return false
+	}
+	return entry.UserID != "" || entry.AccountUUID != ""
+}
+
+// readHead passes lines to fn up to and including the first with a
+// timestamp, returning the offset after the lines passed and that
+// timestamp. The timestamp is zero if none of the first seekHeadLines lines
+// has one.
+func readHead(file *os.File, fn func(line []byte)) (int64, time.Time, error) {
+	scanner, offset := offsetScanner(file)
+	for i := 0; i < seekHeadLines && scanner.Scan(); i++ {
+		fn(scanner.Bytes())
+		if ts, ok := lineTimestamp(scanner.Bytes()); ok {
+			return *offset, ts, nil
+		}
+	}
+	return *offset, time.Time{}, scanner.Err()
+}
+
+// nextTimedLine returns the start and timestamp of the first timestamped
+// line that starts after pos and before end, or a zero time if there's none
+func nextTimedLine(file *os.File, pos, end int64) (int64, time.Time, error) {
+	scanner, offset := offsetScanner(io.NewSectionReader(file, pos, end-pos))
+	// pos is most likely mid-line, so skip to the next
+	if !scanner.Scan() {
+		return 0, time.Time{}, scanner.Err()
+	}
+	for {
+		start := pos + *offset
+		if !scanner.Scan() {
+			return 0, time.Time{}, scanner.Err()
+		}
+		if ts, ok := lineTimestamp(scanner.Bytes()); ok {
+			return start, ts, nil
+		}
+	}
+}
+
+// offsetScanner scans lines of r, tracking the offset after the last line
+// scanned
+func offsetScanner(r io.Reader) (*bufio.Scanner, *int64) {
+	var offset int64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, MaxLineSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		offset += int64(advance)
+		return advance, token, err
+	})
+	return scanner, &offset
+}
+
+// lineTimestamp decodes only the timestamp of a line
+func lineTimestamp(line []byte) (time.Time, bool) {
+	var entry struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if json.Unmarshal(line, &entry) != nil {
+		return time.Time{}, false
+	}
+	ts, err := parseTimestamp(entry.Timestamp)
+	return ts, err == nil
+}
@@ -0,0 +1,41 @@
+package parser
+
+import "github.com/photostructure/go-claude-costs/internal/models"
+
+// WithExcludeSkewed discards response and turn times involving entries
+// timestamped before the entry preceding them, rather than measuring across
+// the clock change
+func WithExcludeSkewed(exclude bool) Option {
+	return func(p *Parser) {
+		p.excludeSkewed = exclude
+	}
+}
+
+// checkClockSkew reports whether an entry is timestamped before the one
+// processed before it, and counts those and entries timestamped before
+// their parent
+func (p *Parser) checkClockSkew(f *fileState, entry *models.Entry) bool {
+	timestamp := entry.ParsedTimestamp
+	skewed := f.processed > 0 && timestamp.Before(f.previous)
+	f.previous = timestamp
+	if skewed {
+		f.outOfOrder++
+	}
+	if parent, ok := p.chains[p.dir][entry.ParentUUID]; ok && parent.time.After(timestamp) {
+		f.parentAfterChild++
+	}
+	return skewed
+}
+
+// finishClockSkew adds a file's timestamps that ran backwards to the
+// analysis
+func (p *Parser) finishClockSkew(f *fileState, analysis *models.CostAnalysis) {
+	if f.outOfOrder == 0 && f.parentAfterChild == 0 {
+		return
+	}
+
+	p.logger.Debug("timestamps run backwards", "file", f.name, "out_of_order", f.outOfOrder, "parent_after_child", f.parentAfterChild)
+	analysis.ClockSkew.OutOfOrder += f.outOfOrder
+	analysis.ClockSkew.ParentAfterChild += f.parentAfterChild
+	analysis.ClockSkew.Sessions++
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// modelCommand marks a user entry that ran /model, which switches models
+// deliberately
+const modelCommand = "<command-name>/model</command-name>"
+
+// modelFamily returns "opus", "sonnet", or "" for other models such as the
+// Haiku model Claude Code uses for background tasks
+func modelFamily(model string) string {
+	switch {
+	case strings.Contains(model, "opus"):
+		return "opus"
+	case strings.Contains(model, "sonnet"):
+		return "sonnet"
+	}
+	return ""
+}
+
+// trackModelSwitch records Opus responses, and Sonnet responses later in a
+// session that used Opus. Claude Code falls back from Opus to Sonnet as plan
+// limits approach, so those Sonnet minutes were meant to run on Opus.
+// Subagent (sidechain) entries are skipped because they choose their own
+// model.
+func (p *Parser) trackModelSwitch(entry *models.Entry, analysis *models.CostAnalysis, model string, cost float64, timestamp time.Time) {
+	if entry.IsSidechain {
+		return
+	}
+	day := analysis.DailyActivity[p.days.Date(timestamp)]
+	if day == nil {
+		return
+	}
+
+	family := modelFamily(model)
+	switch {
+	case family == "opus":
+		if day.OpusMinutes == nil {
+			day.OpusMinutes = make(map[int64]bool)
+		}
+		day.OpusMinutes[activeMinute(timestamp)] = true
+		p.sessionOpus = true
+	case family == "sonnet" && p.sessionOpus:
+		if day.DowngradedMinutes == nil {
+			day.DowngradedMinutes = make(map[int64]bool)
+		}
+		day.DowngradedMinutes[activeMinute(timestamp)] = true
+		day.DowngradedCost += cost
+		if p.lastFamily == "opus" {
+			day.ModelSwitches++
+		}
+	}
+	if family != "" {
+		p.lastFamily = family
+	}
+}
+
+// processModelCommand forgets the session's Opus use when the user picks a
+// model with /model, so the switch isn't counted as a fallback
+func (p *Parser) processModelCommand(entry *models.Entry) {
+	if entry.Message == nil {
+		return
+	}
+	if s, ok := entry.Message.Content.(string); ok && strings.Contains(s, modelCommand) {
+		p.sessionOpus = false
+		p.lastFamily = ""
+	}
+}
+
+// trackOpus totals a session's Opus responses, subagents' included, so
+// short Opus sessions can be priced at cheaper models
+func (p *Parser) trackOpus(analysis *models.CostAnalysis, sessionID, model string, cost float64, tokens tokenData) {
+	if modelFamily(model) != "opus" {
+		return
+	}
+	session := p.getOrCreateSession(analysis, sessionID)
+	session.OpusCost += cost
+	session.OpusTokens.Input += tokens.inputTokens
+	session.OpusTokens.Output += tokens.outputTokens
+	session.OpusTokens.CacheWrite += tokens.cacheWriteTokens
+	session.OpusTokens.CacheRead += tokens.cacheReadTokens
+}
+
+// countPrompt counts a human prompt in the current file. Subagent
+// (sidechain) prompts are written by the main thread, not the user, and
+// interruption notices by Claude Code.
+func (p *Parser) countPrompt(entry *models.Entry) {
+	if !entry.IsSidechain && isPrompt(entry) && !isInterruption(entry) {
+		p.prompts++
+	}
+}
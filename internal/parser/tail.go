@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// readerPool holds the readers tails read files with, since live views
+// read every grown file on each refresh
+var readerPool = sync.Pool{New: func() any { return bufio.NewReaderSize(nil, 64*1024) }}
+
+// Tail is a line source that supplies only the lines appended to each file
+// since the previous parse, for live views that parse every few seconds.
+// The first parse reads files in full. A line still being written is held
+// back until its newline arrives.
+type Tail struct {
+	modifiedSince time.Time
+	findOpts      []FindOption
+	offsets       map[string]int64 // Bytes of each file already read
+	limit         int              // Lines each parse may read; zero for all
+	read          int              // Lines read by the current parse
+}
+
+// NewTail creates a tail of the files modified at or after modifiedSince;
+// older files are never read
+func NewTail(modifiedSince time.Time, opts ...FindOption) *Tail {
+	return &Tail{
+		modifiedSince: modifiedSince,
+		findOpts:      opts,
+		offsets:       make(map[string]int64),
+	}
+}
+
+// Limit bounds the lines each parse reads across all files to n, leaving
+// the rest for later parses, so a backlog is taken a piece at a time. Zero
+// reads everything.
+func (t *Tail) Limit(n int) {
+	t.limit = n
+}
+
+// Files returns the JSONL files under claudeDir that have grown since they
+// were last read. It returns no files when nothing has changed, which
+// ParseAll reports as ErrNoJSONLFiles.
+func (t *Tail) Files(claudeDir string) ([]string, error) {
+	// Each parse starts by listing the files
+	t.read = 0
+	files, err := FindFiles(claudeDir, t.findOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	grown := files[:0]
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.ModTime().Before(t.modifiedSince) || info.Size() <= t.offsets[file] {
+			continue
+		}
+		grown = append(grown, file)
+	}
+	return grown, nil
+}
+
+// EachLine calls fn with the complete lines of file after those already
+// read. The cutoff is left to the parser.
+func (t *Tail) EachLine(file string, cutoff time.Time, fn func(line []byte)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset := t.offsets[file]
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(f)
+	defer func() {
+		reader.Reset(nil)
+		readerPool.Put(reader)
+	}()
+	for t.limit == 0 || t.read < t.limit {
+		line, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// Lines longer than the reader's buffer are gathered up
+			long := append([]byte(nil), line...)
+			for err == bufio.ErrBufferFull {
+				line, err = reader.ReadSlice('\n')
+				long = append(long, line...)
+			}
+			line = long
+		}
+		if err == io.EOF {
+			// Any partial line is read again once it's complete
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+		t.offsets[file] = offset
+		t.read++
+		if len(line) > MaxLineSize {
+			continue
+		}
+		fn(bytes.TrimSuffix(line, []byte("\n")))
+	}
+	return nil
+}
+
+// Offsets returns how far into each file the tail has read, for Resume to
+// continue from in a later run
+func (t *Tail) Offsets() map[string]int64 {
+	offsets := make(map[string]int64, len(t.offsets))
+	for file, offset := range t.offsets {
+		offsets[file] = offset
+	}
+	return offsets
+}
+
+// Resume continues from offsets returned by Offsets, so only lines written
+// since are read. Files not in offsets are read in full.
+func (t *Tail) Resume(offsets map[string]int64) {
+	for file, offset := range offsets {
+		t.offsets[file] = offset
+	}
+}
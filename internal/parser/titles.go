@@ -0,0 +1,25 @@
+package parser
+
+// summary is what's kept of a summary entry
+type summary struct {
+	title string
+	leaf  string // UUID of the conversation's last message
+}
+
+// sessionTitle picks the title of a session from the summary entries in the
+// current file. Claude Code names a conversation by its leaf message, and a
+// resumed session's file repeats the summaries of the conversations it
+// continues, so a summary whose leaf is in this file is preferred, then the
+// last one.
+func (p *Parser) sessionTitle(summaries []summary) string {
+	links := p.chains[p.dir]
+	for i := len(summaries) - 1; i >= 0; i-- {
+		if l, ok := links[summaries[i].leaf]; ok && l.file == p.files {
+			return summaries[i].title
+		}
+	}
+	if len(summaries) > 0 {
+		return summaries[len(summaries)-1].title
+	}
+	return ""
+}
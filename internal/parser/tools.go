@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// noExtension is the extension key used for files like Makefile or LICENSE
+const noExtension = "(none)"
+
+// DefaultRejectionPatterns are tool_result substrings Claude Code writes when
+// the user declines a tool use
+var DefaultRejectionPatterns = []string{
+	"user doesn't want to proceed",
+	"tool use was rejected",
+}
+
+// toolOutcome classifies a tool_result
+type toolOutcome int
+
+const (
+	outcomeAccepted toolOutcome = iota
+	outcomeRejected             // Declined by the user or interrupted
+	outcomeError                // The tool ran and reported an error
+)
+
+// pendingTool is a tool_use block whose tool_result hasn't been seen yet
+type pendingTool struct {
+	name    string
+	command string  // Bash command category, if name is Bash
+	target  string  // What the call acted on: command category or file extension
+	cost    float64 // Share of the cost of the response that proposed it
+	// lines is the input's estimate of the lines a file tool changes
+	lines models.LineChanges
+}
+
+// processToolUses records statistics about the tool_use blocks in an
+// assistant entry
+func (p *Parser) processToolUses(entry *models.Entry, analysis *models.CostAnalysis, project *models.ProjectStats) {
+	for _, item := range contentItems(entry) {
+		if item["type"] != "tool_use" {
+			continue
+		}
+		name, _ := item["name"].(string)
+		input, _ := item["input"].(map[string]interface{})
+
+		p.trackFileTool(name, input, analysis, project)
+
+		pending := pendingTool{name: name, lines: estimateLines(name, input)}
+		if name == "Bash" {
+			command, _ := input["command"].(string)
+			pending.command = commandCategory(command)
+			pending.target = pending.command
+			p.bashStats(analysis, pending.command).Count++
+		} else if path := toolFilePath(input); path != "" {
+			pending.target = fileExtension(path)
+		}
+
+		toolStats(&analysis.Tools, name).Uses++
+		toolStats(&project.Tools, name).Uses++
+
+		if id, ok := item["id"].(string); ok && p.pendingTools != nil {
+			p.pendingTools[id] = pending
+		}
+	}
+}
+
+// priceToolUses gives the pending tool calls of an assistant entry equal
+// shares of its cost, which is wasted if they're rejected
+func (p *Parser) priceToolUses(entry *models.Entry, cost float64) {
+	var ids []string
+	for _, item := range contentItems(entry) {
+		if id, ok := item["id"].(string); ok && item["type"] == "tool_use" {
+			if _, pending := p.pendingTools[id]; pending {
+				ids = append(ids, id)
+			}
+		}
+	}
+	for _, id := range ids {
+		pending := p.pendingTools[id]
+		pending.cost = cost / float64(len(ids))
+		p.pendingTools[id] = pending
+	}
+}
+
+// classifyToolResult decides whether a tool_result was accepted, rejected by
+// the user, or an error. All rejection detection goes through here.
+func (p *Parser) classifyToolResult(entry *models.Entry, item map[string]interface{}) toolOutcome {
+	if entry.ToolUseResult != nil && entry.ToolUseResult.Interrupted {
+		return outcomeRejected
+	}
+
+	content := toolResultText(item)
+	for _, pattern := range p.rejectionPats {
+		if strings.Contains(content, pattern) {
+			return outcomeRejected
+		}
+	}
+
+	if isError, _ := item["is_error"].(bool); isError {
+		return outcomeError
+	}
+	return outcomeAccepted
+}
+
+// processToolResult matches a tool_result block to its tool_use and records
+// the outcome. result is the entry's toolUseResult when item is the entry's
+// only tool_result, and nil otherwise.
+func (p *Parser) processToolResult(item map[string]interface{}, result *models.ToolUseResult, outcome toolOutcome, analysis *models.CostAnalysis, project *models.ProjectStats) {
+	id, _ := item["tool_use_id"].(string)
+	pending, ok := p.pendingTools[id]
+	if !ok {
+		return
+	}
+	delete(p.pendingTools, id)
+
+	// Claude Code's own diff of the change is exact, where the input is an
+	// estimate
+	if outcome == outcomeAccepted {
+		lines := pending.lines
+		if result != nil && len(result.StructuredPatch) > 0 {
+			lines = patchLines(result.StructuredPatch)
+		}
+		analysis.Lines.Add(lines)
+		project.Lines.Add(lines)
+	}
+
+	var bash *models.BashCommandStats
+	if pending.name == "Bash" {
+		bash = p.bashStats(analysis, pending.command)
+	}
+
+	switch outcome {
+	case outcomeRejected:
+		toolStats(&analysis.Tools, pending.name).Rejected++
+		toolStats(&project.Tools, pending.name).Rejected++
+		toolStats(&analysis.Tools, pending.name).RejectedCost += pending.cost
+		toolStats(&project.Tools, pending.name).RejectedCost += pending.cost
+		if bash != nil {
+			bash.Rejected++
+		}
+
+		pattern := pending.name
+		if pending.target != "" {
+			pattern += " " + pending.target
+		}
+		if analysis.RejectedPatterns == nil {
+			analysis.RejectedPatterns = make(map[string]int)
+		}
+		analysis.RejectedPatterns[pattern]++
+	case outcomeError:
+		toolStats(&analysis.Tools, pending.name).Errors++
+		toolStats(&project.Tools, pending.name).Errors++
+		if bash != nil {
+			bash.Failures++
+		}
+	}
+}
+
+// toolStats returns the stats for a tool, creating the map and entry
+func toolStats(byTool *map[string]*models.ToolStats, name string) *models.ToolStats {
+	if *byTool == nil {
+		*byTool = make(map[string]*models.ToolStats)
+	}
+	stats := (*byTool)[name]
+	if stats == nil {
+		stats = &models.ToolStats{}
+		(*byTool)[name] = stats
+	}
+	return stats
+}
+
+// bashStats returns the stats for a Bash command category, creating them
+func (p *Parser) bashStats(analysis *models.CostAnalysis, command string) *models.BashCommandStats {
+	if analysis.BashCommands == nil {
+		analysis.BashCommands = make(map[string]*models.BashCommandStats)
+	}
+	stats := analysis.BashCommands[command]
+	if stats == nil {
+		stats = &models.BashCommandStats{}
+		analysis.BashCommands[command] = stats
+	}
+	return stats
+}
+
+// toolResultText returns the text of a tool_result, whose content is either a
+// string or an array of text blocks
+func toolResultText(item map[string]interface{}) string {
+	switch content := item["content"].(type) {
+	case string:
+		return content
+	case []interface{}:
+		var sb strings.Builder
+		for _, block := range content {
+			if blockMap, ok := block.(map[string]interface{}); ok {
+				if text, ok := blockMap["text"].(string); ok {
+					sb.WriteString(text)
+					sb.WriteByte('\n')
+				}
+			}
+		}
+		return sb.String()
+	}
+	return ""
+}
+
+// commandPrefixes are wrappers skipped when categorizing a command
+var commandPrefixes = map[string]bool{
+	"sudo": true, "time": true, "nice": true, "env": true, "command": true, "exec": true,
+}
+
+// commandCategory returns the program a shell command runs, e.g. "git" for
+// "cd repo && GIT_PAGER=cat git log". Unknown or empty commands are "(other)".
+func commandCategory(command string) string {
+	command = strings.TrimSpace(command)
+
+	// "cd dir && cmd" is how the agent changes directory; categorize cmd
+	if strings.HasPrefix(command, "cd ") {
+		if idx := strings.Index(command, "&&"); idx >= 0 {
+			command = strings.TrimSpace(command[idx+2:])
+		}
+	}
+
+	for _, field := range strings.Fields(command) {
+		// Skip environment assignments and wrapper commands
+		if strings.Contains(field, "=") && !strings.HasPrefix(field, "=") {
+			continue
+		}
+		if commandPrefixes[field] {
+			continue
+		}
+		name := filepath.Base(strings.Trim(field, `"'(`))
+		if name == "" || name == "." || name == "/" {
+			break
+		}
+		return name
+	}
+
+	return "(other)"
+}
+
+// trackFileTool counts file reads, edits, and writes by extension
+func (p *Parser) trackFileTool(name string, input map[string]interface{}, analysis *models.CostAnalysis, project *models.ProjectStats) {
+	var field func(*models.ExtensionStats) *int
+	switch name {
+	case "Read":
+		field = func(s *models.ExtensionStats) *int { return &s.Reads }
+	case "Edit", "MultiEdit", "NotebookEdit":
+		field = func(s *models.ExtensionStats) *int { return &s.Edits }
+	case "Write":
+		field = func(s *models.ExtensionStats) *int { return &s.Writes }
+	default:
+		return
+	}
+
+	path := toolFilePath(input)
+	if path == "" {
+		return
+	}
+	ext := fileExtension(path)
+
+	if analysis.FileExtensions == nil {
+		analysis.FileExtensions = make(map[string]*models.ExtensionStats)
+	}
+	if project.FileExtensions == nil {
+		project.FileExtensions = make(map[string]*models.ExtensionStats)
+	}
+
+	for _, byExt := range []map[string]*models.ExtensionStats{analysis.FileExtensions, project.FileExtensions} {
+		stats := byExt[ext]
+		if stats == nil {
+			stats = &models.ExtensionStats{}
+			byExt[ext] = stats
+		}
+		*field(stats)++
+	}
+}
+
+// toolFilePath returns the file a file tool operated on
+func toolFilePath(input map[string]interface{}) string {
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if path, ok := input[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// fileExtension returns the lowercased extension of path, including the dot
+func fileExtension(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" || ext == "." {
+		return noExtension
+	}
+	return ext
+}
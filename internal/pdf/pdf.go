@@ -0,0 +1,260 @@
+// Package pdf renders the analysis as a paginated PDF report, presentable
+// enough to attach to an expense claim or client invoice.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/photostructure/go-claude-costs/internal/chart"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+// Page layout in millimeters on A4 portrait
+const (
+	margin     = 15.0
+	pageWidth  = 210.0 - 2*margin
+	lineHeight = 6.0
+)
+
+// column is one column of a table
+type column struct {
+	title string
+	width float64
+	align string // fpdf alignment: "L" or "R"
+}
+
+// document wraps fpdf with the report's fonts and helpers
+type document struct {
+	*fpdf.Fpdf
+	tr func(string) string // UTF-8 to the core fonts' cp1252
+}
+
+// Write renders analysis to w as a PDF: a summary page, the project table,
+// and a page of charts. rep is analysis as built by report.New.
+func Write(w io.Writer, analysis *models.CostAnalysis, rep *report.Report) error {
+	doc := newDocument(rep.GeneratedAt)
+
+	doc.summary(rep)
+	doc.projects(rep.Projects)
+	if err := doc.charts(analysis); err != nil {
+		return err
+	}
+
+	return doc.Output(w)
+}
+
+func newDocument(generated time.Time) *document {
+	f := fpdf.New("P", "mm", "A4", "")
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(true, margin+5)
+	f.SetTitle("Claude Code usage report", true)
+	f.SetCreator("claude-costs", true)
+	f.SetCreationDate(generated)
+	f.AliasNbPages("")
+
+	doc := &document{Fpdf: f, tr: f.UnicodeTranslatorFromDescriptor("")}
+	f.SetFooterFunc(func() {
+		f.SetY(-margin)
+		f.SetFont("Helvetica", "I", 8)
+		f.SetTextColor(128, 128, 128)
+		f.CellFormat(pageWidth/2, 5, "Generated "+generated.Format("2006-01-02 15:04 MST"), "", 0, "L", false, 0, "")
+		f.CellFormat(pageWidth/2, 5, fmt.Sprintf("Page %d of {nb}", f.PageNo()), "", 0, "R", false, 0, "")
+		f.SetTextColor(0, 0, 0)
+	})
+	return doc
+}
+
+// summary is the first page: the period, headline totals, and models
+func (d *document) summary(rep *report.Report) {
+	d.AddPage()
+	d.SetFont("Helvetica", "B", 18)
+	d.CellFormat(pageWidth, 10, "Claude Code usage report", "", 1, "L", false, 0, "")
+	d.SetFont("Helvetica", "", 11)
+	d.CellFormat(pageWidth, lineHeight, period(rep.Start, rep.End), "", 1, "L", false, 0, "")
+	d.Ln(6)
+
+	totals := rep.Totals
+	d.heading("Summary")
+	rows := [][2]string{
+		{"API value", currency(totals.Cost)},
+		{"Cache savings", currency(totals.CacheSavings)},
+		{"Sessions", fmt.Sprintf("%d", totals.Sessions)},
+		{"Projects", fmt.Sprintf("%d", len(rep.Projects))},
+		{"Input tokens", tokens(totals.InputTokens)},
+		{"Output tokens", tokens(totals.OutputTokens)},
+		{"Cache read tokens", tokens(totals.CacheReadTokens)},
+		{"Cache write tokens", tokens(totals.CacheWriteTokens)},
+		{"Cache hit rate", fmt.Sprintf("%.1f%%", totals.CacheHitRate)},
+	}
+	if totals.UsageLimits > 0 || totals.RateLimits > 0 {
+		rows = append(rows, [2]string{"Limits hit", fmt.Sprintf("%d usage, %d rate", totals.UsageLimits, totals.RateLimits)})
+	}
+	d.SetFont("Helvetica", "", 10)
+	for i, row := range rows {
+		d.SetFillColor(245, 245, 245)
+		d.CellFormat(60, lineHeight, d.tr(row[0]), "", 0, "L", i%2 == 1, 0, "")
+		d.CellFormat(40, lineHeight, d.tr(row[1]), "", 1, "R", i%2 == 1, 0, "")
+	}
+	d.Ln(6)
+
+	if len(rep.Models) > 0 {
+		d.heading("Models")
+		cols := []column{
+			{"Model", 100, "L"},
+			{"Messages", 25, "R"},
+			{"Share", 25, "R"},
+			{"Cost", pageWidth - 150, "R"},
+		}
+		d.tableHeader(cols)
+		for i, m := range rep.Models {
+			d.tableRow(cols, i, m.Model, fmt.Sprintf("%d", m.Messages), fmt.Sprintf("%.1f%%", m.Share), currency(m.Cost))
+		}
+	}
+
+	d.Ln(6)
+	d.SetFont("Helvetica", "I", 8)
+	d.MultiCell(pageWidth, 4, "Costs are the API value of the tokens used, estimated from local Claude Code "+
+		"logs at published per-token prices. Subscription plans are billed differently.", "", "L", false)
+}
+
+// projects is the table of every project, continuing over as many pages as
+// it needs
+func (d *document) projects(projects []report.Project) {
+	d.AddPage()
+	d.heading("Projects")
+	if len(projects) == 0 {
+		d.SetFont("Helvetica", "", 10)
+		d.CellFormat(pageWidth, lineHeight, "No project activity in this period.", "", 1, "L", false, 0, "")
+		return
+	}
+
+	cols := []column{
+		{"Project", 90, "L"},
+		{"Sessions", 20, "R"},
+		{"Active days", 22, "R"},
+		{"Tokens", 24, "R"},
+		{"Cost", pageWidth - 156, "R"},
+	}
+	d.tableHeader(cols)
+	var total float64
+	for i, p := range projects {
+		// Repeat the header at the top of each continuation page
+		if d.GetY()+lineHeight > d.pageBottom() {
+			d.AddPage()
+			d.tableHeader(cols)
+		}
+		d.tableRow(cols, i, p.Name, fmt.Sprintf("%d", p.Sessions), fmt.Sprintf("%d", p.ActiveDays), tokens(p.Tokens), currency(p.Cost))
+		total += p.Cost
+	}
+	d.SetFont("Helvetica", "B", 10)
+	d.CellFormat(pageWidth-cols[len(cols)-1].width, lineHeight, "Total", "T", 0, "L", false, 0, "")
+	d.CellFormat(cols[len(cols)-1].width, lineHeight, currency(total), "T", 1, "R", false, 0, "")
+}
+
+// charts is a page of the daily cost, model mix, and project charts. Charts
+// without enough data are left out.
+func (d *document) charts(analysis *models.CostAnalysis) error {
+	// Rendered at about 225 dpi so they stay sharp when printed
+	const width, height = 1600, 500
+	imageHeight := pageWidth * height / width
+
+	d.AddPage()
+	d.heading("Charts")
+	for _, chartType := range chart.Types {
+		var buf bytes.Buffer
+		err := chart.Render(&buf, analysis, chartType, "png", chart.Options{Width: width, Height: height})
+		if errors.Is(err, chart.ErrNotEnoughData) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("%s chart: %w", chartType, err)
+		}
+
+		if d.GetY()+imageHeight > d.pageBottom() {
+			d.AddPage()
+		}
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		d.RegisterImageOptionsReader(chartType, options, &buf)
+		d.ImageOptions(chartType, margin, d.GetY(), pageWidth, imageHeight, true, options, 0, "")
+		d.Ln(4)
+	}
+	return d.Error()
+}
+
+func (d *document) heading(text string) {
+	d.SetFont("Helvetica", "B", 13)
+	d.CellFormat(pageWidth, 8, d.tr(text), "B", 1, "L", false, 0, "")
+	d.Ln(2)
+}
+
+func (d *document) tableHeader(cols []column) {
+	d.SetFont("Helvetica", "B", 10)
+	d.SetFillColor(220, 228, 240)
+	for _, col := range cols {
+		d.CellFormat(col.width, lineHeight+1, col.title, "", 0, col.align, true, 0, "")
+	}
+	d.Ln(-1)
+	d.SetFont("Helvetica", "", 10)
+}
+
+// tableRow writes one row, shading every other one and shortening cells
+// that don't fit
+func (d *document) tableRow(cols []column, index int, values ...string) {
+	d.SetFillColor(245, 245, 245)
+	for i, col := range cols {
+		d.CellFormat(col.width, lineHeight, d.fit(d.tr(values[i]), col.width-2), "", 0, col.align, index%2 == 1, 0, "")
+	}
+	d.Ln(-1)
+}
+
+// fit shortens text from the left until it fits in width, keeping the end of
+// long project paths where they differ
+func (d *document) fit(text string, width float64) string {
+	if d.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && d.GetStringWidth("..."+text) > width {
+		text = text[1:]
+	}
+	return "..." + text
+}
+
+// pageBottom is where automatic page breaks happen
+func (d *document) pageBottom() float64 {
+	_, height := d.GetPageSize()
+	_, _, _, bottom := d.GetMargins()
+	return height - bottom
+}
+
+// period describes the report's date range
+func period(start, end time.Time) string {
+	if start.IsZero() || end.IsZero() {
+		return "No activity"
+	}
+	const layout = "January 2, 2006"
+	if start.Format("2006-01-02") == end.Format("2006-01-02") {
+		return start.Format(layout)
+	}
+	return start.Format(layout) + " to " + end.Format(layout)
+}
+
+func currency(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+// tokens formats n with thousands separators
+func tokens(n int) string {
+	s := fmt.Sprintf("%d", n)
+	if n < 0 {
+		return "-" + tokens(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
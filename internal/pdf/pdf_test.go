@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+func testAnalysis() *models.CostAnalysis {
+	start := time.Date(2025, 6, 12, 9, 0, 0, 0, time.Local)
+	return &models.CostAnalysis{
+		StartDate: start,
+		EndDate:   start.Add(30 * time.Hour),
+		TotalCost: 3.25,
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 4, Cost: 2},
+			"2025-06-13": {MessageCount: 3, Cost: 1.25},
+		},
+		HourlyActivity: map[int]*models.HourlyActivity{
+			9:  {MessageCount: 5},
+			14: {MessageCount: 2},
+		},
+		Models: map[string]*models.ModelStats{
+			"claude-opus-4-20250514":   {Cost: 2.5},
+			"claude-sonnet-4-20250514": {Cost: 0.75},
+		},
+		Projects: map[string]*models.ProjectStats{
+			"/home/me/src/app":  {Cost: 2},
+			"/home/me/src/café": {Cost: 1.25},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	analysis := testAnalysis()
+	rep := report.New(analysis, analysis.EndDate, 5*time.Hour)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, analysis, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Errorf("output doesn't start with a PDF header: %q", buf.Bytes()[:min(buf.Len(), 16)])
+	}
+	if pages := bytes.Count(buf.Bytes(), []byte("/Type /Page\n")); pages != 3 {
+		t.Errorf("pages = %d, want summary, projects, and charts", pages)
+	}
+}
+
+func TestWrite_Empty(t *testing.T) {
+	analysis := &models.CostAnalysis{}
+	rep := report.New(analysis, time.Now(), 5*time.Hour)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, analysis, rep); err != nil {
+		t.Fatalf("no activity should still make a report: %v", err)
+	}
+}
+
+func TestProjects_Paginates(t *testing.T) {
+	var projects []report.Project
+	for i := range 100 {
+		projects = append(projects, report.Project{Name: fmt.Sprintf("/home/me/src/project-%d", i), Cost: 1})
+	}
+
+	doc := newDocument(time.Now())
+	doc.projects(projects)
+	if doc.PageNo() < 3 {
+		t.Errorf("100 projects fit on %d pages, want them to continue over several", doc.PageNo())
+	}
+	if err := doc.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFit(t *testing.T) {
+	doc := newDocument(time.Now())
+	doc.AddPage()
+	doc.SetFont("Helvetica", "", 10)
+
+	if got := doc.fit("short", 50); got != "short" {
+		t.Errorf("fit(short) = %q, want it unchanged", got)
+	}
+	long := "/home/someone/src/github.com/example/a-very-long-repository-name"
+	got := doc.fit(long, 40)
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "repository-name") {
+		t.Errorf("fit(long) = %q, want the start elided", got)
+	}
+	if doc.GetStringWidth(got) > 40 {
+		t.Errorf("fit(long) = %q is wider than 40mm", got)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := map[int]string{
+		0:        "0",
+		999:      "999",
+		1000:     "1,000",
+		1234567:  "1,234,567",
+		-1234567: "-1,234,567",
+	}
+	for n, want := range tests {
+		if got := tokens(n); got != want {
+			t.Errorf("tokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	day := time.Date(2025, 6, 12, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		start, end time.Time
+		want       string
+	}{
+		{time.Time{}, time.Time{}, "No activity"},
+		{day, day.Add(time.Hour), "June 12, 2025"},
+		{day, day.AddDate(0, 0, 3), "June 12, 2025 to June 15, 2025"},
+	}
+	for _, tt := range tests {
+		if got := period(tt.start, tt.end); got != tt.want {
+			t.Errorf("period(%v, %v) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Options selects which profiles to collect. Empty paths are disabled.
+type Options struct {
+	CPUProfile string
+	MemProfile string
+	Trace      string
+}
+
+// Session is an active profiling session started by Start
+type Session struct {
+	opts      Options
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+// Start begins CPU profiling and execution tracing as requested by opts.
+// The heap profile is written when the session is stopped.
+func Start(opts Options) (*Session, error) {
+	s := &Session{opts: opts}
+
+	if opts.CPUProfile != "" {
+		f, err := os.Create(opts.CPUProfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		s.cpuFile = f
+	}
+
+	if opts.Trace != "" {
+		f, err := os.Create(opts.Trace)
+		if err != nil {
+			s.stopCPU()
+			return nil, fmt.Errorf("failed to create trace file: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			s.stopCPU()
+			return nil, fmt.Errorf("failed to start trace: %w", err)
+		}
+		s.traceFile = f
+	}
+
+	return s, nil
+}
+
+// Stop flushes and closes all profiles. It is safe to call more than once.
+func (s *Session) Stop() error {
+	s.stopCPU()
+
+	if s.traceFile != nil {
+		trace.Stop()
+		s.traceFile.Close()
+		s.traceFile = nil
+	}
+
+	if s.opts.MemProfile != "" {
+		path := s.opts.MemProfile
+		s.opts.MemProfile = ""
+
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create memory profile: %w", err)
+		}
+		defer f.Close()
+
+		// Get up-to-date statistics for the heap profile
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return fmt.Errorf("failed to write memory profile: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// stopCPU flushes and closes the CPU profile, if one is running
+func (s *Session) stopCPU() {
+	if s.cpuFile != nil {
+		pprof.StopCPUProfile()
+		s.cpuFile.Close()
+		s.cpuFile = nil
+	}
+}
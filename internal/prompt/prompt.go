@@ -0,0 +1,69 @@
+// Package prompt formats the short, colored cost segment that shell prompts
+// such as starship and powerlevel10k embed.
+package prompt
+
+import (
+	"fmt"
+)
+
+// Format is how a segment's color is written, since each shell and prompt
+// framework escapes it differently
+type Format string
+
+const (
+	ANSI  Format = "ansi"  // Raw escape sequences, as starship and fish read them
+	Zsh   Format = "zsh"   // %F{color} prompt escapes, as zsh and powerlevel10k read them
+	Bash  Format = "bash"  // Escape sequences inside \[ \] so PS1 measures its width
+	Tmux  Format = "tmux"  // #[fg=color] status line styles
+	Plain Format = "plain" // No color
+)
+
+// Formats lists the formats, for flag validation
+var Formats = []Format{ANSI, Zsh, Bash, Tmux, Plain}
+
+// Level is how close spend is to its thresholds
+type Level int
+
+const (
+	OK Level = iota
+	Warn
+	Alert
+)
+
+// LevelOf returns the level of cost against the warn and alert thresholds.
+// A zero threshold is disabled.
+func LevelOf(cost, warn, alert float64) Level {
+	switch {
+	case alert > 0 && cost >= alert:
+		return Alert
+	case warn > 0 && cost >= warn:
+		return Warn
+	}
+	return OK
+}
+
+// colors are each level's color name and ANSI foreground code
+var colors = map[Level]struct {
+	name string
+	code int
+}{
+	OK:    {"green", 32},
+	Warn:  {"yellow", 33},
+	Alert: {"red", 31},
+}
+
+// Segment colors text by level in format
+func Segment(text string, level Level, format Format) string {
+	c := colors[level]
+	switch format {
+	case ANSI:
+		return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c.code, text)
+	case Zsh:
+		return fmt.Sprintf("%%F{%s}%s%%f", c.name, text)
+	case Bash:
+		return fmt.Sprintf("\\[\x1b[%dm\\]%s\\[\x1b[0m\\]", c.code, text)
+	case Tmux:
+		return fmt.Sprintf("#[fg=%s]%s#[default]", c.name, text)
+	}
+	return text
+}
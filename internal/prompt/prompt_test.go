@@ -0,0 +1,40 @@
+package prompt
+
+import "testing"
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		cost, warn, alert float64
+		want              Level
+	}{
+		{cost: 5, warn: 10, alert: 25, want: OK},
+		{cost: 10, warn: 10, alert: 25, want: Warn},
+		{cost: 30, warn: 10, alert: 25, want: Alert},
+		{cost: 30, warn: 10, want: Warn},
+		{cost: 30, want: OK},
+	}
+	for _, tt := range tests {
+		if got := LevelOf(tt.cost, tt.warn, tt.alert); got != tt.want {
+			t.Errorf("LevelOf(%v, %v, %v) = %v, want %v", tt.cost, tt.warn, tt.alert, got, tt.want)
+		}
+	}
+}
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		format Format
+		level  Level
+		want   string
+	}{
+		{ANSI, OK, "\x1b[32m$3.82\x1b[0m"},
+		{Zsh, Warn, "%F{yellow}$3.82%f"},
+		{Bash, Alert, "\\[\x1b[31m\\]$3.82\\[\x1b[0m\\]"},
+		{Tmux, Alert, "#[fg=red]$3.82#[default]"},
+		{Plain, Alert, "$3.82"},
+	}
+	for _, tt := range tests {
+		if got := Segment("$3.82", tt.level, tt.format); got != tt.want {
+			t.Errorf("Segment(%s, %v) = %q, want %q", tt.format, tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/photostructure/go-claude-costs/internal/rules"
+)
+
+// icsLineLength is the most octets an iCalendar content line may hold
+// before it's folded onto the next
+const icsLineLength = 75
+
+// WriteICS writes the report's sessions as an iCalendar (RFC 5545) file,
+// one event per session, for overlaying Claude usage on a calendar
+func (r *Report) WriteICS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+
+	stamp := icsTime(r.GeneratedAt)
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//photostructure//go-claude-costs//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "Claude Code sessions")
+	for _, s := range r.Sessions {
+		// Calendars hide events without a duration
+		end := s.End
+		if end.Sub(s.Start) < time.Minute {
+			end = s.Start.Add(time.Minute)
+		}
+		summary := s.Title
+		if summary == "" {
+			summary = "Claude Code: " + path.Base(s.Project)
+		}
+		description := []string{
+			fmt.Sprintf("Cost: $%.2f", s.Cost),
+			"Project: " + s.Project,
+		}
+		if s.GitBranch != "" {
+			description = append(description, "Branch: "+s.GitBranch)
+		}
+		description = append(description,
+			fmt.Sprintf("Messages: %d", s.Messages),
+			fmt.Sprintf("Tokens: %d", s.Tokens),
+			fmt.Sprintf("Active minutes: %d", s.ActiveMinutes),
+			"Session: "+s.ID)
+
+		line("BEGIN", "VEVENT")
+		line("UID", s.ID+"@claude-costs")
+		line("DTSTAMP", stamp)
+		line("DTSTART", icsTime(s.Start))
+		line("DTEND", icsTime(end))
+		line("SUMMARY", icsText(summary))
+		line("DESCRIPTION", icsText(strings.Join(description, "\n")))
+		var tags []string
+		for _, tag := range s.Tags {
+			if tag != rules.Untagged {
+				tags = append(tags, icsText(tag))
+			}
+		}
+		if len(tags) > 0 {
+			line("CATEGORIES", strings.Join(tags, ","))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// icsTime formats t as an iCalendar UTC date-time
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes s as an iCalendar TEXT value
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace
+
+// writeICSLine writes a content line ending in CRLF, folding it so that no
+// line exceeds icsLineLength octets, without splitting a UTF-8 character
+func writeICSLine(w *bufio.Writer, s string) {
+	limit := icsLineLength
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation counts toward its length
+		limit = icsLineLength - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
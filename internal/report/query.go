@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+// Query is a compiled jq expression for export --query
+type Query struct {
+	code *gojq.Code
+}
+
+// ParseQuery compiles a jq expression, such as
+// '.projects[] | select(.cost > 10)'
+func ParseQuery(expr string) (*Query, error) {
+	parsed, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	return &Query{code: code}, nil
+}
+
+// Write runs the query over v as it would be exported as JSON, and writes
+// each result as indented JSON, like jq
+func (q *Query) Write(w io.Writer, v interface{}) error {
+	// The query sees exactly the exported JSON: its field names, and
+	// numbers as written
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var input interface{}
+	if err := dec.Decode(&input); err != nil {
+		return err
+	}
+
+	iter := q.code.Run(input)
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := result.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return nil
+			}
+			return fmt.Errorf("query: %w", err)
+		}
+		if err := WriteJSON(w, result); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	r := New(testAnalysis(), time.Date(2025, 6, 13, 10, 0, 0, 0, time.UTC), 5*time.Hour)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{".totals.cost", "1.5\n"},
+		{".projects[] | select(.cost > 1) | .name", "\"api\"\n"},
+		{".projects[] | select(.cost > 10) | .name", ""},
+		{"[.daily[].messages] | add", "3\n"},
+		{".sessions[0].tags", "[\n  \"client\",\n  \"ops\"\n]\n"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		var out strings.Builder
+		if err := q.Write(&out, r); err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.expr, out.String(), tt.want)
+		}
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	if _, err := ParseQuery(".projects[] | select("); err == nil {
+		t.Error("syntax error: expected error")
+	}
+	if _, err := ParseQuery("nosuchfunc(1)"); err == nil {
+		t.Error("unknown function: expected error")
+	}
+
+	q, err := ParseQuery(".totals.cost | keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := q.Write(&out, New(testAnalysis(), time.Now(), 5*time.Hour)); err == nil {
+		t.Error("keys of a number: expected error")
+	}
+}
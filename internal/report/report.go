@@ -0,0 +1,331 @@
+// Package report converts an analysis into the machine-readable form shared
+// by the export and serve commands.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// Tables lists the tables available as CSV and JSON Lines
+var Tables = []string{"daily", "projects", "sessions", "models", "blocks"}
+
+// Report is an analysis in JSON-friendly form. Its JSON is described by
+// JSONSchema and versioned by SchemaVersion. Times are in UTC, and projects
+// and sessions carry identifiers that survive renames and aliases.
+type Report struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Totals        Totals    `json:"totals"`
+	Daily         []Day     `json:"daily"`
+	Projects      []Project `json:"projects"`
+	Sessions      []Session `json:"sessions"`
+	Models        []Model   `json:"models"`
+	Blocks        []Block   `json:"blocks"`
+}
+
+// Totals are the headline numbers
+type Totals struct {
+	Cost             float64 `json:"cost"`
+	CacheSavings     float64 `json:"cache_savings"`
+	Sessions         int     `json:"sessions"`
+	InputTokens      int     `json:"input_tokens"`
+	OutputTokens     int     `json:"output_tokens"`
+	CacheReadTokens  int     `json:"cache_read_tokens"`
+	CacheWriteTokens int     `json:"cache_write_tokens"`
+	CacheHitRate     float64 `json:"cache_hit_rate"`
+	UsageLimits      int     `json:"usage_limits"`
+	RateLimits       int     `json:"rate_limits"`
+}
+
+// Day is the activity of one date
+type Day struct {
+	Date         string  `json:"date"`
+	Messages     int     `json:"messages"`
+	Cost         float64 `json:"cost"`
+	CacheHitRate float64 `json:"cache_hit_rate"`
+	UsageLimits  int     `json:"usage_limits"`
+	RateLimits   int     `json:"rate_limits"`
+	// Models are the models used that day, most expensive first
+	Models []DayModel `json:"models"`
+	// Avg7 and Avg30 are rolling averages of daily cost
+	Avg7  float64 `json:"avg_7d"`
+	Avg30 float64 `json:"avg_30d"`
+}
+
+// DayModel is one model's part of a day's cost
+type DayModel struct {
+	Model string  `json:"model"`
+	Cost  float64 `json:"cost"`
+	Share float64 `json:"share"`
+}
+
+// Project is the cost of one project or alias
+type Project struct {
+	Name       string   `json:"name"`
+	Cost       float64  `json:"cost"`
+	Sessions   int      `json:"sessions"`
+	Tokens     int      `json:"tokens"`
+	ActiveDays int      `json:"active_days"`
+	Dirs       []string `json:"dirs"`
+}
+
+// Session is the cost of one session
+type Session struct {
+	ID            string    `json:"id"`
+	Project       string    `json:"project"`
+	ProjectDir    string    `json:"project_dir"`
+	GitBranch     string    `json:"git_branch,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Messages      int       `json:"messages"`
+	Tokens        int       `json:"tokens"`
+	ActiveMinutes int       `json:"active_minutes"`
+	Cost          float64   `json:"cost"`
+}
+
+// Model is the usage of one model
+type Model struct {
+	Model    string  `json:"model"`
+	Messages int     `json:"messages"`
+	Share    float64 `json:"share"`
+	Cost     float64 `json:"cost"`
+}
+
+// Block is one billing block
+type Block struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Messages    int       `json:"messages"`
+	Tokens      int       `json:"tokens"`
+	Cost        float64   `json:"cost"`
+	UsageLimits int       `json:"usage_limits"`
+	RateLimits  int       `json:"rate_limits"`
+	Active      bool      `json:"active"`
+}
+
+// New builds a report from analysis. now marks the active billing block,
+// and blockLength is the billing block length.
+func New(analysis *models.CostAnalysis, now time.Time, blockLength time.Duration) *Report {
+	stats := calculator.New(analysis)
+	limitHits := stats.GetLimitHits(blockLength, now)
+
+	r := &Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   now.UTC(),
+		Start:         analysis.StartDate.UTC(),
+		End:           analysis.EndDate.UTC(),
+		Totals: Totals{
+			Cost:             analysis.TotalCost,
+			CacheSavings:     analysis.CacheSavings,
+			Sessions:         len(analysis.Sessions),
+			InputTokens:      analysis.TotalInputTokens,
+			OutputTokens:     analysis.TotalOutputTokens,
+			CacheReadTokens:  analysis.TotalCacheRead,
+			CacheWriteTokens: analysis.TotalCacheWrite,
+			CacheHitRate:     stats.GetCacheHitRate(),
+			UsageLimits:      limitHits.UsageLimits,
+			RateLimits:       limitHits.RateLimits,
+		},
+		Daily:    []Day{},
+		Projects: []Project{},
+		Sessions: []Session{},
+		Models:   []Model{},
+		Blocks:   []Block{},
+	}
+
+	dayLimits := make(map[string]calculator.LimitDay, len(limitHits.Days))
+	for _, day := range limitHits.Days {
+		dayLimits[day.Date] = day
+	}
+	for _, day := range stats.GetDailyTrend() {
+		dayModels := make([]DayModel, 0, len(day.Models))
+		for _, m := range day.Models {
+			dayModels = append(dayModels, DayModel{Model: m.Model, Cost: m.Cost, Share: m.Percentage})
+		}
+		r.Daily = append(r.Daily, Day{
+			Date:         day.Date,
+			Messages:     day.Messages,
+			Cost:         day.Cost,
+			CacheHitRate: day.CacheHitRate,
+			UsageLimits:  dayLimits[day.Date].UsageLimits,
+			RateLimits:   dayLimits[day.Date].RateLimits,
+			Models:       dayModels,
+			Avg7:         day.Avg7,
+			Avg30:        day.Avg30,
+		})
+	}
+
+	for _, proj := range stats.GetTopProjects(0) {
+		dirs := proj.Dirs
+		if dirs == nil {
+			dirs = []string{}
+		}
+		r.Projects = append(r.Projects, Project{
+			Name:       proj.Name,
+			Cost:       proj.Cost,
+			Sessions:   proj.Sessions,
+			Tokens:     proj.InputTokens + proj.OutputTokens + proj.CacheReadTokens + proj.CacheWriteTokens,
+			ActiveDays: proj.ActiveDays,
+			Dirs:       dirs,
+		})
+	}
+
+	for _, session := range stats.GetSessions("time", 0) {
+		r.Sessions = append(r.Sessions, Session{
+			ID:            session.SessionID,
+			Project:       session.Project,
+			ProjectDir:    session.ProjectDir,
+			GitBranch:     session.GitBranch,
+			Title:         session.Title,
+			Tags:          session.Tags,
+			Start:         session.Start.UTC(),
+			End:           session.End.UTC(),
+			Messages:      session.Messages,
+			Tokens:        session.Tokens,
+			ActiveMinutes: session.ActiveMinutes,
+			Cost:          session.Cost,
+		})
+	}
+
+	for _, model := range stats.GetModelDistribution(0) {
+		m := Model{Model: model.Model, Messages: model.Count, Share: model.Percentage}
+		if modelStats, ok := analysis.Models[model.Model]; ok {
+			m.Cost = modelStats.Cost
+		}
+		r.Models = append(r.Models, m)
+	}
+
+	for _, block := range stats.GetBlocks(blockLength, now) {
+		r.Blocks = append(r.Blocks, Block{
+			Start:       block.Start.UTC(),
+			End:         block.End.UTC(),
+			Messages:    block.Messages,
+			Tokens:      block.Tokens,
+			Cost:        block.Cost,
+			UsageLimits: block.UsageLimits,
+			RateLimits:  block.RateLimits,
+			Active:      block.Active,
+		})
+	}
+
+	return r
+}
+
+// Table returns one of the report's Tables by name
+func (r *Report) Table(name string) (interface{}, error) {
+	switch name {
+	case "daily":
+		return r.Daily, nil
+	case "projects":
+		return r.Projects, nil
+	case "sessions":
+		return r.Sessions, nil
+	case "models":
+		return r.Models, nil
+	case "blocks":
+		return r.Blocks, nil
+	}
+	return nil, fmt.Errorf("unknown table %q: use one of %s", name, strings.Join(Tables, ", "))
+}
+
+// WriteJSON writes v as indented JSON
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteJSONL writes one of the report's Tables as JSON Lines: one compact
+// object per row, so stream processors can consume rows as they arrive
+func (r *Report) WriteJSONL(w io.Writer, table string) error {
+	rows, err := r.Table(table)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	v := reflect.ValueOf(rows)
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes one of the report's Tables as CSV with a header row
+func (r *Report) WriteCSV(w io.Writer, table string) error {
+	var rows [][]string
+	switch table {
+	case "daily":
+		rows = append(rows, []string{"date", "messages", "cost", "cache_hit_rate", "usage_limits", "rate_limits",
+			"avg_7d", "avg_30d"})
+		for _, day := range r.Daily {
+			rows = append(rows, []string{day.Date, itoa(day.Messages), money(day.Cost), percent(day.CacheHitRate),
+				itoa(day.UsageLimits), itoa(day.RateLimits), money(day.Avg7), money(day.Avg30)})
+		}
+	case "projects":
+		rows = append(rows, []string{"project", "cost", "sessions", "tokens", "active_days", "dirs"})
+		for _, proj := range r.Projects {
+			rows = append(rows, []string{proj.Name, money(proj.Cost), itoa(proj.Sessions), itoa(proj.Tokens), itoa(proj.ActiveDays),
+				strings.Join(proj.Dirs, ";")})
+		}
+	case "sessions":
+		rows = append(rows, []string{"session_id", "project", "git_branch", "tags", "start", "end", "messages", "tokens", "active_minutes", "cost",
+			"title", "project_dir"})
+		for _, s := range r.Sessions {
+			rows = append(rows, []string{s.ID, s.Project, s.GitBranch, strings.Join(s.Tags, ";"),
+				timestamp(s.Start), timestamp(s.End), itoa(s.Messages), itoa(s.Tokens), itoa(s.ActiveMinutes), money(s.Cost),
+				s.Title, s.ProjectDir})
+		}
+	case "models":
+		rows = append(rows, []string{"model", "messages", "share", "cost"})
+		for _, m := range r.Models {
+			rows = append(rows, []string{m.Model, itoa(m.Messages), percent(m.Share), money(m.Cost)})
+		}
+	case "blocks":
+		rows = append(rows, []string{"start", "end", "messages", "tokens", "cost", "usage_limits", "rate_limits", "active"})
+		for _, b := range r.Blocks {
+			rows = append(rows, []string{timestamp(b.Start), timestamp(b.End), itoa(b.Messages), itoa(b.Tokens), money(b.Cost),
+				itoa(b.UsageLimits), itoa(b.RateLimits), strconv.FormatBool(b.Active)})
+		}
+	default:
+		return fmt.Errorf("unknown table %q: use one of %s", table, strings.Join(Tables, ", "))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+// money keeps sub-cent precision so sums stay accurate in spreadsheets
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 6, 64)
+}
+
+func percent(p float64) string {
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
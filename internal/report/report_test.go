@@ -0,0 +1,169 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func testAnalysis() *models.CostAnalysis {
+	start := time.Date(2025, 6, 13, 9, 0, 0, 0, time.UTC)
+	return &models.CostAnalysis{
+		StartDate: start,
+		EndDate:   start.Add(time.Hour),
+		TotalCost: 1.5,
+		Sessions: map[string]*models.SessionStats{
+			"s1": {Project: "api", StartTime: start, EndTime: start.Add(time.Hour), Cost: 1.5, MessageCount: 3, Tags: []string{"client", "ops"}},
+		},
+		Projects: map[string]*models.ProjectStats{
+			"api": {Cost: 1.5, Sessions: 1},
+		},
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {MessageCount: 3, Cost: 1.5},
+		},
+		ModelUsage: map[string]int{"claude-sonnet-4-20250514": 3},
+		Models: map[string]*models.ModelStats{
+			"claude-sonnet-4-20250514": {Cost: 1.5},
+		},
+		Minutes: map[int64]*models.MinuteActivity{
+			start.Unix() / 60: {MessageCount: 3, Cost: 1.5, Tokens: 300},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	now := time.Date(2025, 6, 13, 10, 0, 0, 0, time.UTC)
+	r := New(testAnalysis(), now, 5*time.Hour)
+
+	if r.Totals.Cost != 1.5 || r.Totals.Sessions != 1 {
+		t.Errorf("Totals = %+v", r.Totals)
+	}
+	if len(r.Blocks) != 1 || !r.Blocks[0].Active {
+		t.Errorf("Blocks = %+v, want one active block", r.Blocks)
+	}
+	if len(r.Models) != 1 || r.Models[0].Cost != 1.5 {
+		t.Errorf("Models = %+v", r.Models)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"totals", "daily", "projects", "sessions", "models", "blocks"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("JSON missing %q", key)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	r := New(testAnalysis(), time.Now(), 5*time.Hour)
+
+	for _, table := range Tables {
+		var buf bytes.Buffer
+		if err := r.WriteCSV(&buf, table); err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+		if len(rows) != 2 {
+			t.Errorf("%s: got %d rows, want header and one row", table, len(rows))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf, "sessions"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("client;ops")) {
+		t.Errorf("sessions CSV missing joined tags:\n%s", buf.String())
+	}
+
+	if err := r.WriteCSV(&buf, "bogus"); err == nil {
+		t.Error("expected error for unknown table")
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	r := New(testAnalysis(), time.Now(), 5*time.Hour)
+
+	for _, table := range Tables {
+		var buf bytes.Buffer
+		if err := r.WriteJSONL(&buf, table); err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+		lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+		if len(lines) != 1 {
+			t.Fatalf("%s: got %d lines, want one", table, len(lines))
+		}
+		var row map[string]interface{}
+		if err := json.Unmarshal(lines[0], &row); err != nil {
+			t.Errorf("%s: %v", table, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSONL(&buf, "sessions"); err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(buf.Bytes(), &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.ID != "s1" || session.Cost != 1.5 {
+		t.Errorf("got session %+v", session)
+	}
+
+	if err := r.WriteJSONL(&buf, "bogus"); err == nil {
+		t.Error("expected error for unknown table")
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	analysis := testAnalysis()
+	analysis.Sessions["s1"].Title = "Fix the login flow; add tests, and a very long title that needs folding ✓✓✓"
+	r := New(analysis, time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC), 5*time.Hour)
+
+	var buf bytes.Buffer
+	if err := r.WriteICS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > icsLineLength {
+			t.Errorf("line is %d octets, want at most %d: %q", len(line), icsLineLength, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a character: %q", line)
+		}
+	}
+
+	// Unfold before looking for values
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:s1@claude-costs\r\n",
+		"DTSTART:20250613T090000Z\r\n",
+		"DTEND:20250613T100000Z\r\n",
+		`SUMMARY:Fix the login flow\; add tests\, and a very long title that needs folding ✓✓✓` + "\r\n",
+		`DESCRIPTION:Cost: $1.50\nProject: api\n`,
+		"CATEGORIES:client,ops\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar missing %q:\n%s", want, unfolded)
+		}
+	}
+}
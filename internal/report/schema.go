@@ -0,0 +1,165 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON output, reported as
+// schema_version. Within a version fields are only ever added. Removing or
+// renaming a field, or changing its type or meaning, increments it.
+const SchemaVersion = 1
+
+// descriptions document fields whose units aren't obvious from the name,
+// keyed by Go type and JSON name
+var descriptions = map[string]string{
+	"Report.schema_version": "Incremented only when fields are removed, renamed, or change type or meaning",
+	"Report.generated_at":   "UTC, as are all other timestamps",
+	"Totals.cost":           "API value in USD",
+	"Totals.cache_savings":  "USD saved by cache reads compared to uncached input",
+	"Totals.cache_hit_rate": "Percentage, 0-100",
+	"Day.cost":              "API value in USD",
+	"Day.cache_hit_rate":    "Percentage, 0-100",
+	"Day.avg_7d":            "Average daily cost in USD over the 7 days ending on date, counting idle days as zero",
+	"Day.avg_30d":           "Average daily cost in USD over the 30 days ending on date, counting idle days as zero",
+	"DayModel.cost":         "API value in USD",
+	"DayModel.share":        "Percentage of the day's cost, 0-100",
+	"Project.cost":          "API value in USD",
+	"Project.dirs":          "Claude Code's encoded directories under ~/.claude/projects, which don't change with aliases",
+	"Session.id":            "Claude Code's session UUID",
+	"Session.project_dir":   "Claude Code's encoded directory under ~/.claude/projects",
+	"Session.cost":          "API value in USD",
+	"Session.title":         "Claude Code's summary of the conversation, when it wrote one",
+	"Model.share":           "Percentage of messages, 0-100",
+	"Model.cost":            "API value in USD",
+	"Block.cost":            "API value in USD",
+	"Block.active":          "Whether the block contains the report's generated_at time",
+}
+
+// schema is a JSON Schema, with fields in the order they're written
+type schema struct {
+	Schema      string      `json:"$schema,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Ref         string      `json:"$ref,omitempty"`
+	Type        string      `json:"type,omitempty"`
+	Format      string      `json:"format,omitempty"`
+	Const       interface{} `json:"const,omitempty"`
+	Items       *schema     `json:"items,omitempty"`
+	Properties  *schemaMap  `json:"properties,omitempty"`
+	Required    []string    `json:"required,omitempty"`
+	Defs        *schemaMap  `json:"$defs,omitempty"`
+}
+
+// schemaMap is a JSON object of schemas that keeps insertion order, so
+// properties are listed as the Go fields are
+type schemaMap struct {
+	names   []string
+	schemas map[string]*schema
+}
+
+func (m *schemaMap) set(name string, s *schema) {
+	if m.schemas == nil {
+		m.schemas = make(map[string]*schema)
+	}
+	if _, ok := m.schemas[name]; !ok {
+		m.names = append(m.names, name)
+	}
+	m.schemas[name] = s
+}
+
+func (m *schemaMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range m.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(m.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// JSONSchema describes the JSON output as a JSON Schema (draft 2020-12),
+// generated from the Report type. Single tables, as from export --table, are
+// arrays of the items in $defs.
+func JSONSchema() ([]byte, error) {
+	defs := &schemaMap{}
+	root := objectSchema(reflect.TypeOf(Report{}), defs)
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "claude-costs report"
+	root.Description = fmt.Sprintf("The JSON output of claude-costs export and serve, schema version %d", SchemaVersion)
+	root.Defs = defs
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// objectSchema describes a struct. Fields without omitempty are required;
+// additional properties are allowed so that adding fields stays compatible.
+func objectSchema(typ reflect.Type, defs *schemaMap) *schema {
+	s := &schema{Type: "object", Properties: &schemaMap{}}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		prop := typeSchema(field.Type, defs)
+		if name == "schema_version" {
+			prop.Const = SchemaVersion
+		}
+		prop.Description = descriptions[typ.Name()+"."+name]
+		s.Properties.set(name, prop)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// typeSchema describes a field's type, adding structs to defs
+func typeSchema(typ reflect.Type, defs *schemaMap) *schema {
+	if typ == reflect.TypeOf(time.Time{}) {
+		return &schema{Type: "string", Format: "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Int, reflect.Int64:
+		return &schema{Type: "integer"}
+	case reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Slice:
+		return &schema{Type: "array", Items: typeSchema(typ.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs.schemas[typ.Name()]; !ok {
+			// Claim the name first; the types aren't recursive, but order
+			// $defs by first use
+			defs.set(typ.Name(), nil)
+			def := objectSchema(typ, defs)
+			def.Title = typ.Name()
+			defs.set(typ.Name(), def)
+		}
+		return &schema{Ref: "#/$defs/" + typ.Name()}
+	}
+	panic(fmt.Sprintf("report: no JSON Schema for %s", typ))
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJSONSchema_MatchesFile(t *testing.T) {
+	generated, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := os.ReadFile("../../schema/report.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, published) {
+		t.Error("schema/report.schema.json is out of date: run make schema")
+	}
+}
+
+func TestJSONSchema_DescribesReport(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type object struct {
+		Properties map[string]struct {
+			Const int `json:"const"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	var doc struct {
+		object
+		Defs map[string]object `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := doc.Properties["schema_version"].Const; got != SchemaVersion {
+		t.Errorf("schema_version const = %d, want %d", got, SchemaVersion)
+	}
+
+	// Every field of real output is described, and every required field
+	// is present
+	out, err := json.Marshal(New(testAnalysis(), time.Now(), 5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var top map[string]json.RawMessage
+	var report struct {
+		Totals   map[string]json.RawMessage   `json:"totals"`
+		Sessions []map[string]json.RawMessage `json:"sessions"`
+	}
+	if err := json.Unmarshal(out, &top); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, schema object, value map[string]json.RawMessage) {
+		for key := range value {
+			if _, ok := schema.Properties[key]; !ok {
+				t.Errorf("%s.%s isn't in the schema", name, key)
+			}
+		}
+		for _, key := range schema.Required {
+			if _, ok := value[key]; !ok {
+				t.Errorf("%s.%s is required but missing from the output", name, key)
+			}
+		}
+	}
+	check("Report", doc.object, top)
+	check("Totals", doc.Defs["Totals"], report.Totals)
+	check("Session", doc.Defs["Session"], report.Sessions[0])
+}
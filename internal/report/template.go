@@ -0,0 +1,58 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the helpers available to export --template, beyond
+// text/template's builtins
+var TemplateFuncs = template.FuncMap{
+	// currency formats dollars with cents: {{currency .Totals.Cost}}
+	"currency": func(amount float64) string {
+		sign := ""
+		if amount < 0 {
+			sign, amount = "-", -amount
+		}
+		cents := strconv.FormatFloat(amount, 'f', 2, 64)
+		return sign + "$" + thousands(cents[:len(cents)-3]) + cents[len(cents)-3:]
+	},
+	// tokens formats a count with thousands separators
+	"tokens": func(n int) string {
+		if n < 0 {
+			return "-" + thousands(strconv.Itoa(-n))
+		}
+		return thousands(strconv.Itoa(n))
+	},
+	// percent formats a percentage such as CacheHitRate or Share, which are
+	// already scaled to 0-100
+	"percent": func(p float64) string {
+		return fmt.Sprintf("%.1f%%", p)
+	},
+	// date formats a time as YYYY-MM-DD in the local time zone
+	"date": func(t time.Time) string {
+		return t.Local().Format("2006-01-02")
+	},
+}
+
+// ParseTemplate reads a text/template file for export --template. The
+// template is executed with the *Report, so fields are the Go names:
+// {{.Totals.Cost}}, {{range .Projects}}{{.Name}}{{end}}.
+func ParseTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// thousands inserts commas into a string of digits
+func thousands(digits string) string {
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return digits
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	tmpl := "Total: {{currency .Totals.Cost}}\n" +
+		"{{range .Projects}}{{.Name}} {{currency .Cost}}\n{{end}}" +
+		"{{range .Models}}{{.Model}} {{percent .Share}}\n{{end}}"
+	if err := os.WriteFile(path, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := parsed.Execute(&out, New(testAnalysis(), time.Now(), 5*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	want := "Total: $1.50\napi $1.50\nclaude-sonnet-4-20250514 100.0%\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	if _, err := ParseTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("missing file: expected error")
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	os.WriteFile(path, []byte("{{range .Projects}}"), 0o644)
+	if _, err := ParseTemplate(path); err == nil {
+		t.Error("unclosed range: expected error")
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	currency := TemplateFuncs["currency"].(func(float64) string)
+	tokens := TemplateFuncs["tokens"].(func(int) string)
+	percent := TemplateFuncs["percent"].(func(float64) string)
+
+	tests := []struct{ got, want string }{
+		{currency(0), "$0.00"},
+		{currency(1234.567), "$1,234.57"},
+		{currency(-12.5), "-$12.50"},
+		{tokens(999), "999"},
+		{tokens(1234567), "1,234,567"},
+		{tokens(-1000), "-1,000"},
+		{percent(93.34), "93.3%"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"path"
+	"strings"
+)
+
+// Alias groups project paths matching Pattern under a business-level Name
+type Alias struct {
+	Pattern string `toml:"pattern"`
+	Name    string `toml:"name"`
+}
+
+// Aliases is an ordered list of alias rules; the first match wins
+type Aliases []Alias
+
+// Resolve returns the alias name for project, or project itself when no rule
+// matches
+func (a Aliases) Resolve(project string) string {
+	for _, alias := range a {
+		if MatchProject(alias.Pattern, project) {
+			return alias.Name
+		}
+	}
+	return project
+}
+
+// MatchProject reports whether a glob pattern matches a project path. The
+// pattern is matched against every run of consecutive path segments, so
+// "photostructure/*" matches "photostructure/app", "src/photostructure/app",
+// and "src/photostructure/app/web".
+func MatchProject(pattern, project string) bool {
+	pattern = strings.Trim(pattern, "/")
+	project = strings.Trim(project, "/")
+	if pattern == "" {
+		return false
+	}
+
+	parts := strings.Split(project, "/")
+	depth := strings.Count(pattern, "/") + 1
+
+	for start := 0; start+depth <= len(parts); start++ {
+		window := strings.Join(parts[start:start+depth], "/")
+		if ok, _ := path.Match(pattern, window); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// PricingRule prices the responses of projects matching Project at
+// negotiated rates, such as through an enterprise gateway. Models sets the
+// rates of the models it names; Discount takes a percentage off the list
+// price of the others.
+type PricingRule struct {
+	Project  string                        `toml:"project"`  // Glob, as for aliases
+	Discount float64                       `toml:"discount"` // Percent off list prices
+	Models   map[string]models.PricingTier `toml:"models"`   // Per million tokens, keyed by model ID or glob
+}
+
+// PricingRules is an ordered list of pricing rules; the first rule matching
+// a project applies
+type PricingRules []PricingRule
+
+// Validate reports the first malformed rule
+func (p PricingRules) Validate() error {
+	for i, rule := range p {
+		if rule.Project == "" {
+			return fmt.Errorf("pricing rule %d needs a project", i+1)
+		}
+		if rule.Discount < 0 || rule.Discount >= 100 {
+			return fmt.Errorf("pricing rule %q: discount must be at least 0 and under 100", rule.Project)
+		}
+		for model := range rule.Models {
+			if _, err := path.Match(model, ""); err != nil {
+				return fmt.Errorf("pricing rule %q: invalid model pattern %q", rule.Project, model)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule matching any of the project names (e.g. its
+// raw path and alias), or nil
+func (p PricingRules) Match(projects ...string) *PricingRule {
+	for i := range p {
+		for _, project := range projects {
+			if MatchProject(p[i].Project, project) {
+				return &p[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Tier returns the rule's pricing for model, given its list pricing. An
+// exact model ID takes precedence over globs, which are tried in
+// alphabetical order.
+// A nil rule returns the list pricing.
+func (r *PricingRule) Tier(model string, list models.PricingTier) models.PricingTier {
+	if r == nil {
+		return list
+	}
+	if tier, ok := r.Models[model]; ok {
+		return tier
+	}
+
+	patterns := make([]string, 0, len(r.Models))
+	for pattern := range r.Models {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, model); ok {
+			return r.Models[pattern]
+		}
+	}
+
+	scale := 1 - r.Discount/100
+	return models.PricingTier{
+		Input:      list.Input * scale,
+		Output:     list.Output * scale,
+		CacheWrite: list.CacheWrite * scale,
+		CacheRead:  list.CacheRead * scale,
+	}
+}
@@ -0,0 +1,217 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func TestMatchProject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		project string
+		want    bool
+	}{
+		{"photostructure/*", "photostructure/app", true},
+		{"photostructure/*", "src/photostructure/app", true},
+		{"photostructure/*", "src/photostructure/app/web", true},
+		{"photostructure/*", "src/photostructure", false},
+		{"src/clients/*", "src/clients/acme", true},
+		{"src/clients/*", "src/personal/blog", false},
+		{"/home/*/work", "/home/user/work", true},
+		{"", "anything", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.project, func(t *testing.T) {
+			if got := MatchProject(tt.pattern, tt.project); got != tt.want {
+				t.Errorf("MatchProject(%q, %q) = %v, want %v", tt.pattern, tt.project, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAliases_Resolve(t *testing.T) {
+	aliases := Aliases{
+		{Pattern: "photostructure/*", Name: "PhotoStructure"},
+		{Pattern: "src/clients/*", Name: "Client work"},
+		{Pattern: "src/*", Name: "Other source"},
+	}
+
+	tests := map[string]string{
+		"src/photostructure/app": "PhotoStructure",
+		"src/clients/acme":       "Client work",
+		"src/blog":               "Other source",
+		"notes":                  "notes",
+	}
+	for project, want := range tests {
+		if got := aliases.Resolve(project); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", project, got, want)
+		}
+	}
+}
+
+func TestTagRules_Tags(t *testing.T) {
+	rules := TagRules{
+		{Tag: "work", Project: "src/clients/*"},
+		{Tag: "work", Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}, Hours: "09:00-18:00"},
+		{Tag: "oncall", Branch: "hotfix/*"},
+		{Tag: "night", Hours: "22:00-04:00"},
+	}
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	monday := func(hour int) time.Time { return time.Date(2025, 6, 16, hour, 30, 0, 0, time.Local) }
+	saturday := time.Date(2025, 6, 14, 11, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		project string
+		branch  string
+		start   time.Time
+		want    []string
+	}{
+		{"client project on weekend", "src/clients/acme", "main", saturday, []string{"work"}},
+		{"weekday office hours", "src/blog", "main", monday(10), []string{"work"}},
+		{"hotfix late at night", "src/blog", "hotfix/login", monday(23), []string{"night", "oncall"}},
+		{"after midnight", "src/blog", "main", monday(1), []string{"night"}},
+		{"personal weekend", "src/blog", "main", saturday, []string{Untagged}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Tags(tt.branch, tt.start, tt.project)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Tags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagRules_Validate(t *testing.T) {
+	invalid := []TagRules{
+		{{Project: "src/*"}},
+		{{Tag: "work", Hours: "9-5"}},
+		{{Tag: "work", Weekdays: []string{"funday"}}},
+	}
+	for _, rules := range invalid {
+		if err := rules.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", rules)
+		}
+	}
+}
+
+func TestPricingRules(t *testing.T) {
+	list := models.PricingTier{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}
+	negotiated := models.PricingTier{Input: 10, Output: 50}
+	pricing := PricingRules{
+		{Project: "clients/acme", Models: map[string]models.PricingTier{"claude-opus-4*": negotiated}, Discount: 50},
+		{Project: "clients/*", Discount: 10},
+	}
+	if err := pricing.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	acme := pricing.Match("/home/me/clients/acme")
+	if acme == nil || acme.Project != "clients/acme" {
+		t.Fatalf("Match = %+v, want the acme rule first", acme)
+	}
+	if got := acme.Tier("claude-opus-4-20250514", list); got != negotiated {
+		t.Errorf("Tier(opus) = %+v, want the negotiated rates", got)
+	}
+	if got := acme.Tier("claude-sonnet-4-20250514", list); got != (models.PricingTier{Input: 1.5, Output: 7.5, CacheWrite: 1.875, CacheRead: 0.15}) {
+		t.Errorf("Tier(sonnet) = %+v, want 50%% off list", got)
+	}
+
+	if rule := pricing.Match("personal/blog"); rule != nil || rule.Tier("any", list) != list {
+		t.Errorf("unmatched project = %+v, want list prices", rule)
+	}
+
+	for _, bad := range []PricingRules{
+		{{Discount: 10}},
+		{{Project: "x", Discount: 100}},
+		{{Project: "x", Models: map[string]models.PricingTier{"[": {}}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", bad)
+		}
+	}
+}
+
+func TestParseWorkHours(t *testing.T) {
+	tests := []struct {
+		hours, days string
+		want        string
+	}{
+		{"09:00-18:00", "mon-fri", "09:00-18:00 Mon-Fri"},
+		{"9:30-17:00", "mon,wed,fri", "09:30-17:00 Mon,Wed,Fri"},
+		{"08:00-24:00", "sat-sun", "08:00-24:00 Sun,Sat"},
+		{"22:00-06:00", "fri-mon", "22:00-06:00 Sun,Mon,Fri,Sat"},
+		{"10:00-16:00", "Tue, Thu", "10:00-16:00 Tue,Thu"},
+	}
+	for _, tt := range tests {
+		w, err := ParseWorkHours(tt.hours, tt.days)
+		if err != nil {
+			t.Errorf("ParseWorkHours(%q, %q): %v", tt.hours, tt.days, err)
+			continue
+		}
+		if got := w.String(); got != tt.want {
+			t.Errorf("ParseWorkHours(%q, %q) = %s, want %s", tt.hours, tt.days, got, tt.want)
+		}
+	}
+
+	for _, bad := range [][2]string{
+		{"9-5", "mon-fri"},
+		{"09:00", "mon-fri"},
+		{"09:00-09:00", "mon-fri"},
+		{"09:00-25:00", "mon-fri"},
+		{"09:00-18:00", "weekdays"},
+		{"09:00-18:00", "mon-"},
+	} {
+		if _, err := ParseWorkHours(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseWorkHours(%q, %q): expected error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestWorkHours_Contains(t *testing.T) {
+	// June 13, 2025 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	office, _ := ParseWorkHours("09:00-18:00", "mon-fri")
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(13, 9, 0), true},
+		{at(13, 17, 59), true},
+		{at(13, 18, 0), false},
+		{at(13, 8, 59), false},
+		{at(14, 12, 0), false}, // Saturday
+	}
+	for _, tt := range tests {
+		if got := office.Contains(tt.t); got != tt.want {
+			t.Errorf("office hours contain %s = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	night, _ := ParseWorkHours("22:00-06:00", "fri")
+	for _, tt := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(13, 23, 0), true}, // Friday night
+		{at(14, 5, 59), true}, // Saturday morning, after Friday's shift
+		{at(14, 23, 0), false},
+		{at(13, 5, 0), false}, // Friday morning follows Thursday
+	} {
+		if got := night.Contains(tt.t); got != tt.want {
+			t.Errorf("night shift contains %s = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
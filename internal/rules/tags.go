@@ -0,0 +1,166 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Untagged is the group for sessions that match no tag rule
+const Untagged = "untagged"
+
+// TagRule attaches Tag to sessions matching every condition that is set.
+// A rule with no conditions matches all sessions.
+type TagRule struct {
+	Tag      string   `toml:"tag"`
+	Project  string   `toml:"project"`  // Glob, as for aliases
+	Branch   string   `toml:"branch"`   // Glob matched against the git branch
+	Hours    string   `toml:"hours"`    // Local time window, e.g. "09:00-18:00"
+	Weekdays []string `toml:"weekdays"` // e.g. ["mon", "tue"]
+}
+
+// TagRules is a list of tag rules; every matching rule contributes its tag
+type TagRules []TagRule
+
+// Validate reports the first malformed rule
+func (t TagRules) Validate() error {
+	for i, rule := range t {
+		if rule.Tag == "" {
+			return fmt.Errorf("tag rule %d needs a tag", i+1)
+		}
+		if rule.Hours != "" {
+			if _, err := ParseTimeWindow(rule.Hours); err != nil {
+				return fmt.Errorf("tag rule %q: %w", rule.Tag, err)
+			}
+		}
+		for _, day := range rule.Weekdays {
+			if _, err := ParseWeekday(day); err != nil {
+				return fmt.Errorf("tag rule %q: %w", rule.Tag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a session with the given git branch, start time,
+// and project names (e.g. its raw path and alias) satisfies the rule
+func (r TagRule) Matches(branch string, start time.Time, projects ...string) bool {
+	if r.Project != "" {
+		matched := false
+		for _, project := range projects {
+			if MatchProject(r.Project, project) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.Branch != "" {
+		if ok, _ := path.Match(r.Branch, branch); !ok {
+			return false
+		}
+	}
+	if r.Hours != "" {
+		window, err := ParseTimeWindow(r.Hours)
+		if err != nil || !window.Contains(start) {
+			return false
+		}
+	}
+	if len(r.Weekdays) > 0 {
+		matched := false
+		for _, name := range r.Weekdays {
+			if day, err := ParseWeekday(name); err == nil && day == start.Weekday() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Tags returns the sorted, de-duplicated tags for a session, or Untagged
+func (t TagRules) Tags(branch string, start time.Time, projects ...string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, rule := range t {
+		if !seen[rule.Tag] && rule.Matches(branch, start, projects...) {
+			seen[rule.Tag] = true
+			tags = append(tags, rule.Tag)
+		}
+	}
+	if len(tags) == 0 {
+		return []string{Untagged}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// TimeWindow is a daily local time range. End before Start wraps past
+// midnight.
+type TimeWindow struct {
+	Start time.Duration // Offset from midnight
+	End   time.Duration
+}
+
+// ParseTimeWindow parses "HH:MM-HH:MM"
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return TimeWindow{}, fmt.Errorf("invalid time window %q: want HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(startStr)
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	return TimeWindow{Start: start, End: end}, nil
+}
+
+// Contains reports whether t's local time of day falls in the window
+func (w TimeWindow) Contains(t time.Time) bool {
+	offset := timeOfDay(t)
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// timeOfDay is t's local time as an offset from midnight
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		if strings.TrimSpace(s) == "24:00" {
+			return 24 * time.Hour, nil
+		}
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses a weekday name or three-letter abbreviation
+func ParseWeekday(name string) (time.Weekday, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if lower == full || (len(lower) == 3 && strings.HasPrefix(full, lower)) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", name)
+}
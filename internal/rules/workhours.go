@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkHours is a weekly schedule of working hours, such as 09:00-18:00
+// Monday to Friday. Hours past midnight, as in 22:00-06:00, belong to the
+// day they start on.
+type WorkHours struct {
+	Window TimeWindow
+	Days   [7]bool // Indexed by time.Weekday
+}
+
+// ParseWorkHours parses hours such as "09:00-18:00" and days such as
+// "mon-fri" or "mon,wed,fri"
+func ParseWorkHours(hours, days string) (WorkHours, error) {
+	var w WorkHours
+	var err error
+	if w.Window, err = ParseTimeWindow(hours); err != nil {
+		return w, err
+	}
+	if w.Window.Start == w.Window.End {
+		return w, fmt.Errorf("invalid time window %q: start and end are the same", hours)
+	}
+
+	for _, part := range strings.Split(days, ",") {
+		firstName, lastName, isRange := strings.Cut(part, "-")
+		first, err := ParseWeekday(firstName)
+		if err != nil {
+			return w, fmt.Errorf("invalid work days %q: %w", days, err)
+		}
+		last := first
+		if isRange {
+			if last, err = ParseWeekday(lastName); err != nil {
+				return w, fmt.Errorf("invalid work days %q: %w", days, err)
+			}
+		}
+		// Ranges may wrap around the weekend, as in fri-mon
+		for day := first; ; day = (day + 1) % 7 {
+			w.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return w, nil
+}
+
+// Contains reports whether t's local time falls in working hours
+func (w WorkHours) Contains(t time.Time) bool {
+	if !w.Window.Contains(t) {
+		return false
+	}
+	day := t.Weekday()
+	if w.Window.End < w.Window.Start && timeOfDay(t) < w.Window.End {
+		// The early hours of an overnight shift that began yesterday
+		day = (day + 6) % 7
+	}
+	return w.Days[day]
+}
+
+// String describes the schedule, as in "09:00-18:00 Mon-Fri"
+func (w WorkHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+
+	var days []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if !w.Days[day] {
+			continue
+		}
+		// Name runs of three or more consecutive days by their ends
+		end := day
+		for end < time.Saturday && w.Days[end+1] {
+			end++
+		}
+		switch {
+		case end-day >= 2:
+			days = append(days, day.String()[:3]+"-"+end.String()[:3])
+		case end > day:
+			days = append(days, day.String()[:3], end.String()[:3])
+		default:
+			days = append(days, day.String()[:3])
+		}
+		day = end
+	}
+	return clock(w.Window.Start) + "-" + clock(w.Window.End) + " " + strings.Join(days, ",")
+}
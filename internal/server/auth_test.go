@@ -0,0 +1,133 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServer_Auth(t *testing.T) {
+	handler := New(Options{Token: "secret", BasicUser: "me", BasicPassword: "pw"}).Handler()
+
+	tests := []struct {
+		name string
+		path string
+		set  func(r *http.Request)
+		want int
+	}{
+		{"no credentials", "/api/summary", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"health is open", "/healthz", func(r *http.Request) {}, http.StatusOK},
+		{"bearer", "/api/summary", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusServiceUnavailable},
+		{"wrong bearer", "/api/summary", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secrets") }, http.StatusUnauthorized},
+		{"basic", "/api/summary", func(r *http.Request) { r.SetBasicAuth("me", "pw") }, http.StatusServiceUnavailable},
+		{"wrong password", "/api/summary", func(r *http.Request) { r.SetBasicAuth("me", "secret") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			tt.set(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestServer_Refresh(t *testing.T) {
+	refreshed := 0
+	refresh := func() { refreshed++ }
+
+	post := func(opts Options) int {
+		rec := httptest.NewRecorder()
+		New(opts).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/refresh", nil))
+		return rec.Code
+	}
+
+	if code := post(Options{Refresh: refresh}); code != http.StatusOK || refreshed != 1 {
+		t.Errorf("refresh: status = %d, refreshed %d times", code, refreshed)
+	}
+	if code := post(Options{Refresh: refresh, ReadOnly: true}); code != http.StatusForbidden || refreshed != 1 {
+		t.Errorf("read-only refresh: status = %d, refreshed %d times", code, refreshed)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	if config, err := TLSConfig("", "", ""); config != nil || err != nil {
+		t.Errorf("TLSConfig without a certificate = %+v, %v, want nil", config, err)
+	}
+
+	certFile, keyFile := writeTestCert(t)
+	config, err := TLSConfig(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Certificates) != 1 || config.ClientCAs != nil {
+		t.Errorf("TLSConfig = %+v, want one certificate and no client CAs", config)
+	}
+
+	config, err = TLSConfig(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, want RequireAndVerifyClientCert", config.ClientAuth)
+	}
+
+	if _, err := TLSConfig(certFile, keyFile, keyFile); err == nil {
+		t.Error("TLSConfig accepted a client CA file without certificates")
+	}
+}
+
+// writeTestCert writes a self-signed certificate for localhost and its key
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+// feedProjects is how many of each day's most expensive projects its feed
+// entry lists
+const feedProjects = 5
+
+// atomFeed is an Atom (RFC 4287) feed
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// handleFeed serves an Atom feed with an entry for each day with activity,
+// newest first
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	analysis, updated := s.analysis, s.updated
+	s.mu.RUnlock()
+	if analysis == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "analysis not ready")
+		return
+	}
+	rep := report.New(analysis, updated, s.opts.BlockLength)
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	self := scheme + "://" + r.Host + r.URL.Path
+	feed := atomFeed{
+		ID:      self,
+		Title:   "Claude Code daily costs",
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "claude-costs"},
+		Link:    atomLink{Rel: "self", Href: self},
+	}
+	for i := len(rep.Daily) - 1; i >= 0; i-- {
+		day := rep.Daily[i]
+		if day.Messages == 0 {
+			continue
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      self + "#" + day.Date,
+			Title:   fmt.Sprintf("%s: $%.2f across %d messages", day.Date, day.Cost, day.Messages),
+			Updated: dayUpdated(analysis.DayBoundary, day.Date, updated).UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: daySummaryHTML(day, analysis.DailyActivity[day.Date])},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		s.opts.Logger.Debug("failed to write response", "error", err)
+	}
+}
+
+// dayUpdated is when the entry for date last changed: the end of the day,
+// or the last update while the day is still going
+func dayUpdated(days models.DayBoundary, date string, updated time.Time) time.Time {
+	loc := time.Local
+	if days.UTC {
+		loc = time.UTC
+	}
+	start, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return updated
+	}
+	end := start.Add(time.Duration(days.StartHour)*time.Hour).AddDate(0, 0, 1)
+	if updated.Before(end) {
+		return updated
+	}
+	return end
+}
+
+// daySummaryHTML describes a day's cost, its models, and its most
+// expensive projects
+func daySummaryHTML(day report.Day, activity *models.DailyActivity) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<p>$%.2f API value across %d messages; 7-day average $%.2f per day. Cache hit rate %.1f%%.",
+		day.Cost, day.Messages, day.Avg7, day.CacheHitRate)
+	if day.UsageLimits > 0 {
+		fmt.Fprintf(&b, " Reached the usage limit %d times.", day.UsageLimits)
+	}
+	b.WriteString("</p>")
+
+	if len(day.Models) > 0 {
+		b.WriteString("<h3>Models</h3><ul>")
+		for _, m := range day.Models {
+			fmt.Fprintf(&b, "<li>%s: $%.2f</li>", html.EscapeString(m.Model), m.Cost)
+		}
+		b.WriteString("</ul>")
+	}
+
+	if activity != nil && len(activity.ProjectCosts) > 0 {
+		type projectCost struct {
+			name string
+			cost float64
+		}
+		projects := make([]projectCost, 0, len(activity.ProjectCosts))
+		for name, cost := range activity.ProjectCosts {
+			projects = append(projects, projectCost{name, cost})
+		}
+		sort.Slice(projects, func(i, j int) bool {
+			if projects[i].cost != projects[j].cost {
+				return projects[i].cost > projects[j].cost
+			}
+			return projects[i].name < projects[j].name
+		})
+		b.WriteString("<h3>Projects</h3><ul>")
+		for _, p := range projects[:min(len(projects), feedProjects)] {
+			fmt.Fprintf(&b, "<li>%s: $%.2f</li>", html.EscapeString(p.name), p.cost)
+		}
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
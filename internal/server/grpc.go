@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/report"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/costspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer returns a gRPC server answering costspb.CostsService and the
+// standard health service. It requires the same credentials as Handler,
+// sent as "authorization" metadata; health checks stay open. tlsConfig may
+// be nil to serve without TLS.
+func (s *Server) GRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorizeRPC(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorizeRPC(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	g := grpc.NewServer(opts...)
+	costspb.RegisterCostsServiceServer(g, &costsService{s: s})
+	healthpb.RegisterHealthServer(g, health.NewServer())
+	return g
+}
+
+// authorizeRPC checks the "authorization" metadata of a call to method
+func (s *Server) authorizeRPC(ctx context.Context, method string) error {
+	if !s.authRequired() || strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if s.checkAuthorization(value) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// costsService implements costspb.CostsServiceServer over a Server
+type costsService struct {
+	costspb.UnimplementedCostsServiceServer
+	s *Server
+}
+
+// report returns the current report, or Unavailable before the first Update
+func (c *costsService) report() (*report.Report, error) {
+	rep := c.s.report()
+	if rep == nil {
+		return nil, status.Error(codes.Unavailable, "analysis not ready")
+	}
+	return rep, nil
+}
+
+func (c *costsService) GetSummary(ctx context.Context, req *costspb.GetSummaryRequest) (*costspb.GetSummaryResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.GetSummaryResponse{Report: protoReport(rep)}, nil
+}
+
+func (c *costsService) ListDaily(ctx context.Context, req *costspb.ListDailyRequest) (*costspb.ListDailyResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.ListDailyResponse{Days: protoDays(rep.Daily)}, nil
+}
+
+func (c *costsService) ListProjects(ctx context.Context, req *costspb.ListProjectsRequest) (*costspb.ListProjectsResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.ListProjectsResponse{Projects: protoProjects(rep.Projects)}, nil
+}
+
+func (c *costsService) ListSessions(ctx context.Context, req *costspb.ListSessionsRequest) (*costspb.ListSessionsResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.ListSessionsResponse{Sessions: protoSessions(rep.Sessions)}, nil
+}
+
+func (c *costsService) ListModels(ctx context.Context, req *costspb.ListModelsRequest) (*costspb.ListModelsResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.ListModelsResponse{Models: protoModels(rep.Models)}, nil
+}
+
+func (c *costsService) ListBlocks(ctx context.Context, req *costspb.ListBlocksRequest) (*costspb.ListBlocksResponse, error) {
+	rep, err := c.report()
+	if err != nil {
+		return nil, err
+	}
+	return &costspb.ListBlocksResponse{Blocks: protoBlocks(rep.Blocks)}, nil
+}
+
+func (c *costsService) Refresh(ctx context.Context, req *costspb.RefreshRequest) (*costspb.RefreshResponse, error) {
+	if c.s.opts.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, "server is read-only")
+	}
+	if c.s.opts.Refresh == nil {
+		return nil, status.Error(codes.Unimplemented, "refresh not supported")
+	}
+	c.s.opts.Refresh()
+
+	c.s.mu.RLock()
+	updated := c.s.updated
+	c.s.mu.RUnlock()
+	return &costspb.RefreshResponse{Updated: timestamp(updated)}, nil
+}
+
+// WatchEvents streams events like /ws until the client goes away
+func (c *costsService) WatchEvents(req *costspb.WatchEventsRequest, stream costspb.CostsService_WatchEventsServer) error {
+	client := c.s.subscribe()
+	defer c.s.unsubscribe(client)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-client:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "client fell behind")
+			}
+			if err := stream.Send(&costspb.WatchEventsResponse{Event: protoEvent(event)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// timestamp converts t, leaving the zero time unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func protoReport(r *report.Report) *costspb.Report {
+	return &costspb.Report{
+		GeneratedAt: timestamp(r.GeneratedAt),
+		Start:       timestamp(r.Start),
+		End:         timestamp(r.End),
+		Totals: &costspb.Totals{
+			Cost:             r.Totals.Cost,
+			CacheSavings:     r.Totals.CacheSavings,
+			Sessions:         int64(r.Totals.Sessions),
+			InputTokens:      int64(r.Totals.InputTokens),
+			OutputTokens:     int64(r.Totals.OutputTokens),
+			CacheReadTokens:  int64(r.Totals.CacheReadTokens),
+			CacheWriteTokens: int64(r.Totals.CacheWriteTokens),
+			CacheHitRate:     r.Totals.CacheHitRate,
+			UsageLimits:      int64(r.Totals.UsageLimits),
+			RateLimits:       int64(r.Totals.RateLimits),
+		},
+		Daily:    protoDays(r.Daily),
+		Projects: protoProjects(r.Projects),
+		Sessions: protoSessions(r.Sessions),
+		Models:   protoModels(r.Models),
+		Blocks:   protoBlocks(r.Blocks),
+	}
+}
+
+func protoDays(days []report.Day) []*costspb.Day {
+	out := make([]*costspb.Day, 0, len(days))
+	for _, d := range days {
+		out = append(out, &costspb.Day{
+			Date:         d.Date,
+			Messages:     int64(d.Messages),
+			Cost:         d.Cost,
+			CacheHitRate: d.CacheHitRate,
+			UsageLimits:  int64(d.UsageLimits),
+			RateLimits:   int64(d.RateLimits),
+		})
+	}
+	return out
+}
+
+func protoProjects(projects []report.Project) []*costspb.Project {
+	out := make([]*costspb.Project, 0, len(projects))
+	for _, p := range projects {
+		out = append(out, &costspb.Project{
+			Name:       p.Name,
+			Cost:       p.Cost,
+			Sessions:   int64(p.Sessions),
+			Tokens:     int64(p.Tokens),
+			ActiveDays: int64(p.ActiveDays),
+			Dirs:       p.Dirs,
+		})
+	}
+	return out
+}
+
+func protoSessions(sessions []report.Session) []*costspb.Session {
+	out := make([]*costspb.Session, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, &costspb.Session{
+			Id:            s.ID,
+			Project:       s.Project,
+			ProjectDir:    s.ProjectDir,
+			GitBranch:     s.GitBranch,
+			Title:         s.Title,
+			Tags:          s.Tags,
+			Start:         timestamp(s.Start),
+			End:           timestamp(s.End),
+			Messages:      int64(s.Messages),
+			Tokens:        int64(s.Tokens),
+			ActiveMinutes: int64(s.ActiveMinutes),
+			Cost:          s.Cost,
+		})
+	}
+	return out
+}
+
+func protoModels(models []report.Model) []*costspb.Model {
+	out := make([]*costspb.Model, 0, len(models))
+	for _, m := range models {
+		out = append(out, &costspb.Model{
+			Model:    m.Model,
+			Messages: int64(m.Messages),
+			Share:    m.Share,
+			Cost:     m.Cost,
+		})
+	}
+	return out
+}
+
+func protoBlocks(blocks []report.Block) []*costspb.Block {
+	out := make([]*costspb.Block, 0, len(blocks))
+	for i := range blocks {
+		out = append(out, protoBlock(&blocks[i]))
+	}
+	return out
+}
+
+func protoBlock(b *report.Block) *costspb.Block {
+	if b == nil {
+		return nil
+	}
+	return &costspb.Block{
+		Start:       timestamp(b.Start),
+		End:         timestamp(b.End),
+		Messages:    int64(b.Messages),
+		Tokens:      int64(b.Tokens),
+		Cost:        b.Cost,
+		UsageLimits: int64(b.UsageLimits),
+		RateLimits:  int64(b.RateLimits),
+		Active:      b.Active,
+	}
+}
+
+func protoEvent(e Event) *costspb.Event {
+	eventType := costspb.Event_TYPE_UPDATE
+	if e.Type == "snapshot" {
+		eventType = costspb.Event_TYPE_SNAPSHOT
+	}
+	return &costspb.Event{
+		Type:      eventType,
+		Time:      timestamp(e.Time),
+		Messages:  int64(e.Messages),
+		CostDelta: e.CostDelta,
+		TotalCost: e.TotalCost,
+		TodayCost: e.TodayCost,
+		Projects:  e.Projects,
+		Block:     protoBlock(e.Block),
+	}
+}
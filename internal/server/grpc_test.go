@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/costspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves s over an in-memory listener and returns a connection to it
+func dialGRPC(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	g := s.GRPCServer(nil)
+	go g.Serve(listener)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPC(t *testing.T) {
+	s := New(Options{BlockLength: 5 * time.Hour, Token: "secret", ReadOnly: true})
+	conn := dialGRPC(t, s)
+	client := costspb.NewCostsServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.GetSummary(ctx, &costspb.GetSummaryRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("without a token: err = %v, want Unauthenticated", err)
+	}
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || health.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health check without a token = %v, %v", health, err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	if _, err := client.GetSummary(ctx, &costspb.GetSummaryRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("before Update: err = %v, want Unavailable", err)
+	}
+
+	s.Update(&models.CostAnalysis{
+		TotalCost: 2.5,
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {MessageCount: 3, Cost: 2.5},
+		},
+	})
+
+	summary, err := client.GetSummary(ctx, &costspb.GetSummaryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Report.Totals.Cost != 2.5 {
+		t.Errorf("summary cost = %v, want 2.5", summary.Report.Totals.Cost)
+	}
+
+	daily, err := client.ListDaily(ctx, &costspb.ListDailyRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily.Days) != 1 || daily.Days[0].Date != "2025-06-13" || daily.Days[0].Messages != 3 {
+		t.Errorf("daily = %v", daily.Days)
+	}
+
+	if _, err := client.Refresh(ctx, &costspb.RefreshRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("read-only refresh: err = %v, want PermissionDenied", err)
+	}
+}
+
+func TestGRPC_WatchEvents(t *testing.T) {
+	s := New(Options{BlockLength: 5 * time.Hour})
+	s.Update(&models.CostAnalysis{
+		TotalCost: 1,
+		Minutes:   map[int64]*models.MinuteActivity{100: {MessageCount: 2, Cost: 1}},
+	})
+	client := costspb.NewCostsServiceClient(dialGRPC(t, s))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchEvents(ctx, &costspb.WatchEventsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Event.Type != costspb.Event_TYPE_SNAPSHOT || first.Event.Messages != 2 {
+		t.Errorf("first event = %v, want a snapshot of 2 messages", first.Event)
+	}
+
+	s.Update(&models.CostAnalysis{
+		TotalCost: 1.5,
+		Minutes: map[int64]*models.MinuteActivity{
+			100: {MessageCount: 2, Cost: 1},
+			101: {MessageCount: 1, Cost: 0.5},
+		},
+	})
+	update, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Event.Type != costspb.Event_TYPE_UPDATE || update.Event.Messages != 1 || update.Event.CostDelta != 0.5 {
+		t.Errorf("update = %v, want 1 message costing 0.5", update.Event)
+	}
+}
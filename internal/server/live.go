@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/photostructure/go-claude-costs/internal/calculator"
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+// Event is pushed to /ws and gRPC WatchEvents clients: a snapshot when they
+// connect, then an update whenever a refresh finds new activity
+type Event struct {
+	Type string    `json:"type"` // "snapshot" or "update"
+	Time time.Time `json:"time"`
+	// Messages and CostDelta are the activity added since the previous
+	// event; a snapshot carries the totals
+	Messages  int     `json:"messages"`
+	CostDelta float64 `json:"cost_delta"`
+	TotalCost float64 `json:"total_cost"`
+	TodayCost float64 `json:"today_cost"`
+	// Projects holds the cost each project added
+	Projects map[string]float64 `json:"projects,omitempty"`
+	// Block is the active billing block, if any
+	Block *report.Block `json:"block,omitempty"`
+}
+
+// writeTimeout bounds a write to a slow /ws client
+const writeTimeout = 10 * time.Second
+
+// eventBuffer is how many events a client may fall behind before it is
+// disconnected
+const eventBuffer = 16
+
+// snapshot describes the whole analysis
+func (s *Server) snapshot(analysis *models.CostAnalysis, now time.Time) Event {
+	event := s.newEvent("snapshot", analysis, now)
+	for _, minute := range analysis.Minutes {
+		event.Messages += minute.MessageCount
+		event.CostDelta += minute.Cost
+	}
+	return event
+}
+
+// delta describes the activity in current that previous didn't have. Only
+// minutes from previous's last active minute on are compared, so entries
+// aging out of the day window don't count as negative activity.
+func (s *Server) delta(previous, current *models.CostAnalysis, now time.Time) Event {
+	event := s.newEvent("update", current, now)
+
+	var since int64
+	for minute := range previous.Minutes {
+		since = max(since, minute)
+	}
+	for minute, activity := range current.Minutes {
+		if minute >= since {
+			event.Messages += activity.MessageCount
+			event.CostDelta += activity.Cost
+		}
+	}
+	if activity := previous.Minutes[since]; activity != nil {
+		event.Messages -= activity.MessageCount
+		event.CostDelta -= activity.Cost
+	}
+
+	sinceDay := current.DayBoundary.Date(time.Unix(since*60, 0))
+	for date, day := range current.DailyActivity {
+		if date < sinceDay {
+			continue
+		}
+		for project, cost := range day.ProjectCosts {
+			if prev, ok := previous.DailyActivity[date]; ok {
+				cost -= prev.ProjectCosts[project]
+			}
+			if cost > 0 {
+				if event.Projects == nil {
+					event.Projects = make(map[string]float64)
+				}
+				event.Projects[project] += cost
+			}
+		}
+	}
+	return event
+}
+
+// newEvent fills in the fields that describe current state
+func (s *Server) newEvent(eventType string, analysis *models.CostAnalysis, now time.Time) Event {
+	stats := calculator.New(analysis)
+	event := Event{
+		Type:      eventType,
+		Time:      now.UTC(),
+		TotalCost: analysis.TotalCost,
+		TodayCost: stats.GetDailySummary(analysis.DayBoundary.Date(now)).Cost,
+	}
+	blocks := stats.GetBlocks(s.opts.BlockLength, now)
+	if n := len(blocks); n > 0 && blocks[n-1].Active {
+		b := blocks[n-1]
+		event.Block = &report.Block{
+			Start:       b.Start.UTC(),
+			End:         b.End.UTC(),
+			Messages:    b.Messages,
+			Tokens:      b.Tokens,
+			Cost:        b.Cost,
+			UsageLimits: b.UsageLimits,
+			RateLimits:  b.RateLimits,
+			Active:      true,
+		}
+	}
+	return event
+}
+
+// publish sends event to every subscribed client. Clients that have fallen too far
+// behind are dropped. The caller holds s.mu.
+func (s *Server) publish(event Event) {
+	for client := range s.clients {
+		select {
+		case client <- event:
+		default:
+			delete(s.clients, client)
+			close(client)
+		}
+	}
+}
+
+// subscribe registers a client and returns its channel with the current
+// snapshot queued, if there is one yet
+func (s *Server) subscribe() chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client := make(chan Event, eventBuffer)
+	if s.analysis != nil {
+		client <- s.snapshot(s.analysis, time.Now())
+	}
+	if s.clients == nil {
+		s.clients = make(map[chan Event]bool)
+	}
+	s.clients[client] = true
+	return client
+}
+
+// unsubscribe removes a client unless publish already dropped it
+func (s *Server) unsubscribe(client chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[client] {
+		delete(s.clients, client)
+		close(client)
+	}
+}
+
+// handleWS streams events to a WebSocket client until it disconnects
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: s.opts.WSOrigins})
+	if err != nil {
+		s.opts.Logger.Debug("websocket handshake failed", "error", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	// Clients only listen; reading handles their pings and close frames
+	ctx := conn.CloseRead(r.Context())
+
+	client := s.subscribe()
+	defer s.unsubscribe(client)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-client:
+			if !ok {
+				conn.Close(websocket.StatusPolicyViolation, "client fell behind")
+				return
+			}
+			if err := s.writeEvent(ctx, conn, event); err != nil {
+				s.opts.Logger.Debug("failed to write event", "error", err)
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) writeEvent(ctx context.Context, conn *websocket.Conn, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, data)
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// OpenAPI is the OpenAPI 3 description of Handler's routes. Its schemas
+// mirror the report package's JSON, which server tests check.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(OpenAPI); err != nil {
+		s.opts.Logger.Debug("failed to write response", "error", err)
+	}
+}
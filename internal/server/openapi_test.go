@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal(OpenAPI, &doc); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	return doc
+}
+
+// jsonFields returns the JSON names of v's fields
+func jsonFields(v interface{}) []string {
+	var names []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOpenAPI_SchemasMatchTypes(t *testing.T) {
+	doc := loadOpenAPI(t)
+	types := map[string]interface{}{
+		"Report":   report.Report{},
+		"Totals":   report.Totals{},
+		"Day":      report.Day{},
+		"DayModel": report.DayModel{},
+		"Project":  report.Project{},
+		"Session":  report.Session{},
+		"Model":    report.Model{},
+		"Block":    report.Block{},
+		"Event":    Event{},
+	}
+	for name, v := range types {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing", name)
+			continue
+		}
+		var properties []string
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		if want := jsonFields(v); !reflect.DeepEqual(properties, want) {
+			t.Errorf("schema %s properties = %v, want %v", name, properties, want)
+		}
+	}
+}
+
+func TestOpenAPI_PathsAreServed(t *testing.T) {
+	doc := loadOpenAPI(t)
+	s := New(Options{BlockLength: 5 * time.Hour, Refresh: func() {}})
+	s.Update(&models.CostAnalysis{})
+	handler := s.Handler()
+
+	for path, operations := range doc.Paths {
+		if path == "/ws" {
+			continue // Needs a WebSocket handshake; see TestServer_WebSocket
+		}
+		for method := range operations {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(strings.ToUpper(method), path, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s %s: status = %d, want 200", strings.ToUpper(method), path, rec.Code)
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("/openapi.json: status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
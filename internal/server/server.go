@@ -0,0 +1,171 @@
+// Package server serves the latest analysis over HTTP as JSON, and as an
+// Atom feed of daily summaries.
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+// Options configures a Server
+type Options struct {
+	Logger      *slog.Logger
+	BlockLength time.Duration
+	// Pprof mounts the net/http/pprof handlers under /debug/pprof/
+	Pprof bool
+	// WSOrigins are host patterns of other origins allowed to open /ws,
+	// such as "dashboard.example.com" or "*.example.com"
+	WSOrigins []string
+	// Token, when set, is accepted as "Authorization: Bearer <token>"
+	Token string
+	// BasicUser and BasicPassword, when set, are accepted as basic auth
+	BasicUser     string
+	BasicPassword string
+	// Refresh re-reads the Claude directory for POST /api/refresh
+	Refresh func()
+	// ReadOnly rejects requests that change the server's state
+	ReadOnly bool
+}
+
+// Server holds the most recent analysis and serves it over HTTP
+type Server struct {
+	analysis *models.CostAnalysis
+	updated  time.Time
+	clients  map[chan Event]bool // Connected /ws and WatchEvents clients
+	opts     Options
+	mu       sync.RWMutex
+}
+
+// New creates a Server. It serves 503 until the first Update.
+func New(opts Options) *Server {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Server{opts: opts}
+}
+
+// Update replaces the served analysis and pushes any new activity to /ws
+// clients
+func (s *Server) Update(analysis *models.CostAnalysis) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.analysis == nil {
+		s.publish(s.snapshot(analysis, now))
+	} else if event := s.delta(s.analysis, analysis, now); event.Messages != 0 || event.CostDelta != 0 {
+		s.publish(event)
+	}
+	s.analysis = analysis
+	s.updated = now
+}
+
+// Handler returns the HTTP handler:
+//
+//	GET /healthz         liveness and the time of the last update
+//	GET /openapi.json    the OpenAPI description of these routes
+//	GET /api/summary     the full report
+//	GET /api/{table}     one of report.Tables
+//	GET /ws              a WebSocket of Events as new activity is found
+//	GET /feed.xml        an Atom feed with a cost summary for each day
+//	POST /api/refresh    re-read the Claude directory now, unless read-only
+//	/debug/pprof/        runtime profiles, when Options.Pprof is set
+//
+// Every route but /healthz requires the token or basic auth credentials
+// when they're configured.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /api/summary", s.handleSummary)
+	mux.HandleFunc("GET /api/{table}", s.handleTable)
+	mux.HandleFunc("GET /ws", s.handleWS)
+	mux.HandleFunc("GET /feed.xml", s.handleFeed)
+	mux.HandleFunc("POST /api/refresh", s.handleRefresh)
+
+	if s.opts.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	updated := s.updated
+	s.mu.RUnlock()
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"updated": updated.UTC(),
+	})
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if s.opts.ReadOnly {
+		s.writeError(w, http.StatusForbidden, "server is read-only")
+		return
+	}
+	if s.opts.Refresh == nil {
+		s.writeError(w, http.StatusNotFound, "refresh not supported")
+		return
+	}
+	s.opts.Refresh()
+	s.handleHealth(w, r)
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	rep := s.report()
+	if rep == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "analysis not ready")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, rep)
+}
+
+func (s *Server) handleTable(w http.ResponseWriter, r *http.Request) {
+	rep := s.report()
+	if rep == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "analysis not ready")
+		return
+	}
+	table, err := rep.Table(r.PathValue("table"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, table)
+}
+
+// report builds a report from the current analysis, or nil before the
+// first Update
+func (s *Server) report() *report.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.analysis == nil {
+		return nil
+	}
+	return report.New(s.analysis, time.Now(), s.opts.BlockLength)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := report.WriteJSON(w, v); err != nil {
+		s.opts.Logger.Debug("failed to write response", "error", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, map[string]string{"error": message})
+}
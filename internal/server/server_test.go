@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+func TestServer(t *testing.T) {
+	s := New(Options{BlockLength: 5 * time.Hour})
+	handler := s.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/api/summary"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before Update: status = %d, want 503", rec.Code)
+	}
+
+	s.Update(&models.CostAnalysis{
+		TotalCost: 2.5,
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {MessageCount: 3, Cost: 2.5},
+		},
+	})
+
+	rec := get("/api/summary")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("summary: status = %d", rec.Code)
+	}
+	var summary struct {
+		Totals struct{ Cost float64 }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Totals.Cost != 2.5 {
+		t.Errorf("summary cost = %v, want 2.5", summary.Totals.Cost)
+	}
+
+	rec = get("/api/daily")
+	var daily []struct{ Date string }
+	if err := json.Unmarshal(rec.Body.Bytes(), &daily); err != nil {
+		t.Fatal(err)
+	}
+	if len(daily) != 1 || daily[0].Date != "2025-06-13" {
+		t.Errorf("daily = %s", rec.Body.String())
+	}
+
+	if rec := get("/api/bogus"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown table: status = %d, want 404", rec.Code)
+	}
+	if rec := get("/debug/pprof/"); rec.Code != http.StatusNotFound {
+		t.Errorf("pprof disabled: status = %d, want 404", rec.Code)
+	}
+	if rec := get("/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("healthz: status = %d", rec.Code)
+	}
+}
+
+func TestServer_Pprof(t *testing.T) {
+	handler := New(Options{Pprof: true}).Handler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("pprof enabled: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestServer_WebSocket(t *testing.T) {
+	s := New(Options{BlockLength: 5 * time.Hour})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	now := time.Now()
+	minute := now.Add(-10*time.Minute).Unix() / 60
+	today := now.Format("2006-01-02")
+	s.Update(&models.CostAnalysis{
+		TotalCost: 1,
+		Minutes:   map[int64]*models.MinuteActivity{minute: {MessageCount: 2, Cost: 1}},
+		DailyActivity: map[string]*models.DailyActivity{
+			today: {MessageCount: 2, Cost: 1, ProjectCosts: map[string]float64{"app": 1}},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	read := func() Event {
+		t.Helper()
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	if snapshot := read(); snapshot.Type != "snapshot" || snapshot.Messages != 2 || snapshot.TodayCost != 1 || snapshot.Block == nil {
+		t.Errorf("snapshot = %+v, want 2 messages, $1 today, and an active block", snapshot)
+	}
+
+	// The last minute gained a message and a new minute has three more
+	s.Update(&models.CostAnalysis{
+		TotalCost: 2.5,
+		Minutes: map[int64]*models.MinuteActivity{
+			minute:     {MessageCount: 3, Cost: 1.5},
+			minute + 1: {MessageCount: 3, Cost: 1},
+		},
+		DailyActivity: map[string]*models.DailyActivity{
+			today: {MessageCount: 6, Cost: 2.5, ProjectCosts: map[string]float64{"app": 1.25, "lib": 1.25}},
+		},
+	})
+	update := read()
+	if update.Type != "update" || update.Messages != 4 || update.CostDelta != 1.5 || update.TotalCost != 2.5 {
+		t.Errorf("update = %+v, want 4 new messages costing $1.50", update)
+	}
+	if update.Projects["app"] != 0.25 || update.Projects["lib"] != 1.25 {
+		t.Errorf("update projects = %v, want app +$0.25 and lib +$1.25", update.Projects)
+	}
+}
+
+func TestServer_Feed(t *testing.T) {
+	s := New(Options{BlockLength: 5 * time.Hour})
+	handler := s.Handler()
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://costs.example/feed.xml", nil))
+		return rec
+	}
+	if rec := get(); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before Update: status = %d, want 503", rec.Code)
+	}
+
+	s.Update(&models.CostAnalysis{
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-12": {MessageCount: 2, Cost: 1, ProjectCosts: map[string]float64{"<api>": 1}},
+			"2025-06-13": {MessageCount: 3, Cost: 2.5},
+			"2025-06-14": {},
+		},
+	})
+	rec := get()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var feed struct {
+		ID      string `xml:"id"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.ID != "http://costs.example/feed.xml" {
+		t.Errorf("feed id = %q", feed.ID)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("got %d entries, want one per day with activity", len(feed.Entries))
+	}
+	if e := feed.Entries[0]; e.ID != "http://costs.example/feed.xml#2025-06-13" || e.Title != "2025-06-13: $2.50 across 3 messages" {
+		t.Errorf("newest entry = %q %q", e.ID, e.Title)
+	}
+	if content := feed.Entries[1].Content; !strings.Contains(content, "<li>&lt;api&gt;: $1.00</li>") {
+		t.Errorf("entry content = %q, want the escaped project", content)
+	}
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Block is the usage of a finished billing block
+type Block struct {
+	Start    time.Time     `json:"start"`
+	Length   time.Duration `json:"length"`
+	Messages int           `json:"messages"`
+	Tokens   int           `json:"tokens"`
+	// InputOutputTokens excludes cache tokens
+	InputOutputTokens int     `json:"input_output_tokens"`
+	Cost              float64 `json:"cost"`
+}
+
+// BlockHistory is every finished billing block seen by the blocks command,
+// kept so peaks outlive the --days window and Claude Code's transcript
+// cleanup
+type BlockHistory struct {
+	Blocks []Block `json:"blocks"` // By start time
+}
+
+// BlocksPath returns the block history file next to the state file at
+// statePath: state.json becomes state-blocks.json
+func BlocksPath(statePath string) string {
+	if statePath == "" {
+		return ""
+	}
+	ext := filepath.Ext(statePath)
+	return strings.TrimSuffix(statePath, ext) + "-blocks" + ext
+}
+
+// LoadBlocks reads the block history. A missing file yields an empty
+// history.
+func LoadBlocks(path string) (*BlockHistory, error) {
+	h := &BlockHistory{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block history %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse block history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Merge adds blocks to the history, replacing any recorded with the same
+// start and length, and returns how many were new
+func (h *BlockHistory) Merge(blocks []Block) int {
+	type key struct {
+		start  int64
+		length time.Duration
+	}
+	index := make(map[key]int, len(h.Blocks))
+	for i, b := range h.Blocks {
+		index[key{b.Start.Unix(), b.Length}] = i
+	}
+
+	added := 0
+	for _, b := range blocks {
+		k := key{b.Start.Unix(), b.Length}
+		if i, ok := index[k]; ok {
+			h.Blocks[i] = b
+			continue
+		}
+		index[k] = len(h.Blocks)
+		h.Blocks = append(h.Blocks, b)
+		added++
+	}
+	sort.SliceStable(h.Blocks, func(i, j int) bool { return h.Blocks[i].Start.Before(h.Blocks[j].Start) })
+	return added
+}
+
+// Save writes the block history atomically
+func (h *BlockHistory) Save(path string) error {
+	return writeJSON(path, h)
+}
@@ -0,0 +1,50 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EventCheckpoint is how far into each log file daemon --events has
+// delivered, so a restart sends what was written while it was down
+type EventCheckpoint struct {
+	// Offsets are bytes delivered by file; nil before the first delivery
+	Offsets map[string]int64 `json:"offsets"`
+}
+
+// EventsPath returns the event checkpoint file next to the state file at
+// statePath: state.json becomes state-events.json
+func EventsPath(statePath string) string {
+	if statePath == "" {
+		return ""
+	}
+	ext := filepath.Ext(statePath)
+	return strings.TrimSuffix(statePath, ext) + "-events" + ext
+}
+
+// LoadEvents reads the event checkpoint. A missing file yields one with nil
+// Offsets.
+func LoadEvents(path string) (*EventCheckpoint, error) {
+	c := &EventCheckpoint{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event checkpoint %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse event checkpoint %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the event checkpoint atomically
+func (c *EventCheckpoint) Save(path string) error {
+	return writeJSON(path, c)
+}
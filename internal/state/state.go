@@ -0,0 +1,97 @@
+// Package state persists data between claude-costs runs, such as the
+// high-water mark used by report --since-last-run.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// State is the persisted run state. The zero value means no previous run.
+type State struct {
+	// LastRun is the timestamp of the newest entry already reported
+	LastRun time.Time `json:"last_run"`
+}
+
+// DefaultPath returns the default state file location:
+// $XDG_STATE_HOME/claude-costs/state.json, or ~/.local/state/claude-costs/state.json
+func DefaultPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "claude-costs", "state.json")
+}
+
+// ProfilePath returns the state file for a config profile, next to path:
+// state.json becomes state-work.json. Profiles keep separate high-water marks.
+func ProfilePath(path, profile string) string {
+	if path == "" || profile == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + profile + ext
+}
+
+// Load reads the state file. A missing file yields the zero State.
+func Load(path string) (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state file, creating its directory. The file is replaced
+// atomically so an interrupted run never leaves a truncated state.
+func (s *State) Save(path string) error {
+	return writeJSON(path, s)
+}
+
+// writeJSON writes v to path as indented JSON, creating its directory and
+// replacing the file atomically
+func writeJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
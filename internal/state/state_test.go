@@ -0,0 +1,117 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("missing file: unexpected error %v", err)
+	}
+	if !s.LastRun.IsZero() {
+		t.Errorf("missing file: LastRun = %v, want zero", s.LastRun)
+	}
+
+	mark := time.Date(2025, 6, 1, 12, 30, 0, 123, time.UTC)
+	s.LastRun = mark
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.LastRun.Equal(mark) {
+		t.Errorf("LastRun = %v, want %v", loaded.LastRun, mark)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for corrupt state")
+	}
+}
+
+func TestProfilePath(t *testing.T) {
+	tests := []struct{ path, profile, want string }{
+		{"/s/state.json", "work", "/s/state-work.json"},
+		{"/s/state.json", "", "/s/state.json"},
+		{"/s/state", "work", "/s/state-work"},
+		{"", "work", ""},
+	}
+	for _, tt := range tests {
+		if got := ProfilePath(tt.path, tt.profile); got != tt.want {
+			t.Errorf("ProfilePath(%q, %q) = %q, want %q", tt.path, tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestBlockHistory(t *testing.T) {
+	path := BlocksPath(filepath.Join(t.TempDir(), "state-work.json"))
+	if filepath.Base(path) != "state-work-blocks.json" {
+		t.Errorf("BlocksPath = %s, want state-work-blocks.json", path)
+	}
+
+	h, err := LoadBlocks(path)
+	if err != nil || len(h.Blocks) != 0 {
+		t.Fatalf("missing file = %+v, %v; want empty history", h, err)
+	}
+
+	at := func(hour int) time.Time { return time.Date(2025, 6, 1, hour, 0, 0, 0, time.UTC) }
+	h.Merge([]Block{{Start: at(10), Length: 5 * time.Hour, Cost: 1}})
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadBlocks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := loaded.Merge([]Block{
+		{Start: at(16), Length: 5 * time.Hour, Cost: 3},
+		{Start: at(10), Length: 5 * time.Hour, Cost: 2},
+		{Start: at(4), Length: 5 * time.Hour, Cost: 4},
+	})
+	if added != 2 {
+		t.Errorf("Merge added %d, want 2", added)
+	}
+	var costs []float64
+	for _, b := range loaded.Blocks {
+		costs = append(costs, b.Cost)
+	}
+	if len(costs) != 3 || costs[0] != 4 || costs[1] != 2 || costs[2] != 3 {
+		t.Errorf("costs = %v, want [4 2 3]: by start, with the 10:00 block replaced", costs)
+	}
+}
+
+func TestEventCheckpoint(t *testing.T) {
+	path := EventsPath(filepath.Join(t.TempDir(), "state.json"))
+	if filepath.Base(path) != "state-events.json" {
+		t.Errorf("EventsPath = %s, want state-events.json", path)
+	}
+
+	c, err := LoadEvents(path)
+	if err != nil || c.Offsets != nil {
+		t.Fatalf("missing file = %+v, %v; want nil offsets", c, err)
+	}
+
+	c.Offsets = map[string]int64{"a.jsonl": 100}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadEvents(path)
+	if err != nil || loaded.Offsets["a.jsonl"] != 100 {
+		t.Errorf("loaded = %+v, %v; want a.jsonl at 100", loaded, err)
+	}
+}
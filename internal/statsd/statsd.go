@@ -0,0 +1,127 @@
+// Package statsd sends counters to a StatsD or DogStatsD server over UDP.
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats of metric lines
+const (
+	// DogStatsD appends tags as "|#key:value,..."
+	DogStatsD = "dogstatsd"
+	// StatsD folds tag values into the metric name, since plain StatsD has
+	// no tags: "prefix.project.model.name"
+	StatsD = "statsd"
+)
+
+// maxPacket keeps a datagram within a typical Ethernet MTU
+const maxPacket = 1432
+
+// Client buffers counters and sends them in as few packets as possible
+type Client struct {
+	conn   net.Conn
+	prefix string
+	format string
+	buf    bytes.Buffer
+}
+
+// Dial creates a Client sending to addr, such as "localhost:8125". Metric
+// names start with prefix and a dot, unless prefix is empty.
+func Dial(addr, prefix, format string) (*Client, error) {
+	if format != DogStatsD && format != StatsD {
+		return nil, fmt.Errorf("unknown StatsD format %q: use %s or %s", format, DogStatsD, StatsD)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD: %w", err)
+	}
+	return &Client{conn: conn, prefix: prefix, format: format}, nil
+}
+
+// Count adds value to the named counter. Tags are sent in the order of
+// their keys.
+func (c *Client) Count(name string, value float64, tags map[string]string) error {
+	line := c.line(name, value, tags)
+	if c.buf.Len() > 0 && c.buf.Len()+1+len(line) > maxPacket {
+		if err := c.Flush(); err != nil {
+			return err
+		}
+	}
+	if c.buf.Len() > 0 {
+		c.buf.WriteByte('\n')
+	}
+	c.buf.WriteString(line)
+	return nil
+}
+
+// Flush sends the buffered counters
+func (c *Client) Flush() error {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	defer c.buf.Reset()
+	if _, err := c.conn.Write(c.buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to send to StatsD: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the connection
+func (c *Client) Close() error {
+	err := c.Flush()
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// line formats one counter
+func (c *Client) line(name string, value float64, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var path []string
+	if c.prefix != "" {
+		path = append(path, c.prefix)
+	}
+	if c.format == StatsD {
+		for _, key := range keys {
+			path = append(path, sanitize(tags[key], "-_"))
+		}
+	}
+	path = append(path, name)
+
+	line := strings.Join(path, ".") + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|c"
+	if c.format == DogStatsD && len(keys) > 0 {
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+":"+sanitize(tags[key], "-_./"))
+		}
+		line += "|#" + strings.Join(pairs, ",")
+	}
+	return line
+}
+
+// sanitize replaces characters other than letters, digits, and those in
+// allowed with underscores, dropping leading and trailing ones, so
+// "/home/me/app" becomes "home_me_app" in a StatsD name
+func sanitize(s, allowed string) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(allowed, r) {
+			return r
+		}
+		return '_'
+	}, s), "_")
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
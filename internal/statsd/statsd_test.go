@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Line(t *testing.T) {
+	tags := map[string]string{"project": "/home/me/my app", "model": "claude-sonnet-4-20250514"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{DogStatsD, "claude.cost:0.25|c|#model:claude-sonnet-4-20250514,project:/home/me/my_app"},
+		{StatsD, "claude.claude-sonnet-4-20250514.home_me_my_app.cost:0.25|c"},
+	}
+	for _, tt := range tests {
+		c := &Client{prefix: "claude", format: tt.format}
+		if got := c.line("cost", 0.25, tags); got != tt.want {
+			t.Errorf("%s line = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	c := &Client{format: DogStatsD}
+	if got := c.line("messages", 1, map[string]string{"model": ""}); got != "messages:1|c|#model:unknown" {
+		t.Errorf("line without prefix = %q", got)
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	c, err := Dial(server.LocalAddr().String(), "claude", DogStatsD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Enough counters to need more than one packet
+	for i := 0; i < 100; i++ {
+		if err := c.Count("messages", 1, map[string]string{"project": "app"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines, packets int
+	buf := make([]byte, 65536)
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for lines < 100 {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("after %d lines: %v", lines, err)
+		}
+		if n > maxPacket {
+			t.Errorf("packet of %d bytes exceeds %d", n, maxPacket)
+		}
+		packets++
+		lines += strings.Count(string(buf[:n]), "\n") + 1
+	}
+	if packets < 2 {
+		t.Errorf("sent %d packets, want the counters split across several", packets)
+	}
+}
+
+func TestDial_UnknownFormat(t *testing.T) {
+	if _, err := Dial("127.0.0.1:8125", "claude", "graphite"); err == nil {
+		t.Error("Dial accepted an unknown format")
+	}
+}
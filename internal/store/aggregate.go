@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+// dayFormat is how days are keyed in aggregates and rollups
+const dayFormat = "2006-01-02"
+
+// Totals are the usage of a file or a day
+type Totals struct {
+	Messages int                `json:"messages"` // Responses with usage or a recorded cost
+	Tokens   models.TokenCounts `json:"tokens"`
+	// Cost is the cost Claude Code recorded, or else the tokens at list
+	// prices; pricing rules are applied only when reports parse the lines
+	Cost float64 `json:"cost"`
+}
+
+// IsZero reports whether t counts nothing. Cost is ignored, since what's
+// left of it after subtracting every message is rounding.
+func (t Totals) IsZero() bool {
+	return t.Messages == 0 && t.Tokens == models.TokenCounts{}
+}
+
+// Add returns t plus o
+func (t Totals) Add(o Totals) Totals {
+	return Totals{
+		Messages: t.Messages + o.Messages,
+		Tokens: models.TokenCounts{
+			Input:      t.Tokens.Input + o.Tokens.Input,
+			Output:     t.Tokens.Output + o.Tokens.Output,
+			CacheWrite: t.Tokens.CacheWrite + o.Tokens.CacheWrite,
+			CacheRead:  t.Tokens.CacheRead + o.Tokens.CacheRead,
+		},
+		Cost: t.Cost + o.Cost,
+	}
+}
+
+// Sub returns t minus o
+func (t Totals) Sub(o Totals) Totals {
+	return t.Add(Totals{
+		Messages: -o.Messages,
+		Tokens: models.TokenCounts{
+			Input:      -o.Tokens.Input,
+			Output:     -o.Tokens.Output,
+			CacheWrite: -o.Tokens.CacheWrite,
+			CacheRead:  -o.Tokens.CacheRead,
+		},
+		Cost: -o.Cost,
+	})
+}
+
+// Aggregate is the usage in a file's stored lines by UTC day, so totals
+// can be read without parsing the lines. Copies of a file are each
+// counted, unlike in reports.
+type Aggregate struct {
+	// Ingested is how many bytes of the file the totals cover; it lags
+	// the stored lines only if an ingest stopped between storing the two
+	Ingested int64             `json:"ingested"`
+	Days     map[string]Totals `json:"days"`
+}
+
+// Total returns the file's usage across all days
+func (a Aggregate) Total() Totals {
+	var total Totals
+	for _, day := range a.Days {
+		total = total.Add(day)
+	}
+	return total
+}
+
+// clone returns a copy of a that doesn't share its days
+func (a Aggregate) clone() Aggregate {
+	a.Days = maps.Clone(a.Days)
+	return a
+}
+
+// add counts line's usage, if it has any
+func (a *Aggregate) add(line []byte) {
+	day, totals, ok := lineTotals(line)
+	if !ok {
+		return
+	}
+	if a.Days == nil {
+		a.Days = make(map[string]Totals)
+	}
+	a.Days[day] = a.Days[day].Add(totals)
+}
+
+// lineTotals returns the UTC day and usage of a response line. Lines
+// without a timestamp or usage have none.
+func lineTotals(line []byte) (string, Totals, bool) {
+	entry, err := parser.ParseEntryLine(line)
+	if err != nil || entry.ParsedTimestamp.IsZero() {
+		return "", Totals{}, false
+	}
+	day := entry.ParsedTimestamp.UTC().Format(dayFormat)
+	if entry.CostUSD > 0 {
+		return day, Totals{Messages: 1, Cost: entry.CostUSD}, true
+	}
+	if entry.Message == nil || entry.Message.Usage == nil || entry.Message.Model == "<synthetic>" {
+		return "", Totals{}, false
+	}
+
+	usage := entry.Message.Usage
+	tokens := models.TokenCounts{
+		Input:      usage.InputTokens,
+		Output:     usage.OutputTokens,
+		CacheWrite: usage.CacheCreationInputTokens,
+		CacheRead:  usage.CacheReadInputTokens,
+	}
+	tier, ok := models.ModelPricing[entry.Message.Model]
+	if !ok {
+		tier = models.DefaultPricing
+	}
+	return day, Totals{Messages: 1, Tokens: tokens, Cost: tokens.CostAt(tier)}, true
+}
+
+// Aggregate returns the usage in path's stored lines
+func (s *Store) Aggregate(path string) (Aggregate, error) {
+	agg, _, err := s.backend.GetAggregate(context.Background(), path)
+	return agg, err
+}
+
+// Rollup returns the usage on day, a UTC date such as 2025-06-01, across
+// every stored file
+func (s *Store) Rollup(day string) (Totals, error) {
+	totals, _, err := s.backend.GetRollup(context.Background(), day)
+	return totals, err
+}
+
+// Rollups returns the usage of each UTC day from since on, across every
+// stored file
+func (s *Store) Rollups(since time.Time) (map[string]Totals, error) {
+	ctx := context.Background()
+	days, err := s.backend.Days(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := since.UTC().Format(dayFormat)
+	rollups := make(map[string]Totals)
+	for _, day := range days {
+		if day < from {
+			continue
+		}
+		totals, _, err := s.backend.GetRollup(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		rollups[day] = totals
+	}
+	return rollups, nil
+}
+
+// syncAggregate returns path's aggregate, first rebuilding it from the
+// stored lines when it doesn't cover the ingested bytes, as after an
+// interrupted ingest or with an index from before aggregates were kept
+func (s *Store) syncAggregate(ctx context.Context, path string, ingested int64) (Aggregate, error) {
+	agg, _, err := s.backend.GetAggregate(ctx, path)
+	if err != nil || agg.Ingested == ingested {
+		return agg, err
+	}
+	fresh := Aggregate{Ingested: ingested}
+	if err := s.backend.Lines(ctx, path, time.Time{}, fresh.add); err != nil {
+		return agg, err
+	}
+	return fresh, s.replaceAggregate(ctx, path, agg, fresh)
+}
+
+// replaceAggregate stores path's aggregate and moves the daily rollups by
+// how it differs from old
+func (s *Store) replaceAggregate(ctx context.Context, path string, old, new Aggregate) error {
+	if err := s.backend.PutAggregate(ctx, path, new); err != nil {
+		return err
+	}
+	days := maps.Clone(new.Days)
+	if days == nil {
+		days = make(map[string]Totals)
+	}
+	for day := range old.Days {
+		days[day] = Totals{}
+	}
+	for _, day := range slices.Sorted(maps.Keys(days)) {
+		delta := new.Days[day].Sub(old.Days[day])
+		if delta.IsZero() {
+			continue
+		}
+		rollup, _, err := s.backend.GetRollup(ctx, day)
+		if err != nil {
+			return err
+		}
+		if err := s.backend.PutRollup(ctx, day, rollup.Add(delta)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildRollups replaces the daily rollups with the sum of the stored
+// aggregates, undoing any drift from interrupted ingests
+func (s *Store) rebuildRollups(ctx context.Context) error {
+	rollups := make(map[string]Totals)
+	paths, err := s.backend.Paths(ctx)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		agg, _, err := s.backend.GetAggregate(ctx, path)
+		if err != nil {
+			return err
+		}
+		for day, totals := range agg.Days {
+			rollups[day] = rollups[day].Add(totals)
+		}
+	}
+
+	days, err := s.backend.Days(ctx)
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		if _, ok := rollups[day]; !ok {
+			if err := s.backend.PutRollup(ctx, day, Totals{}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, day := range slices.Sorted(maps.Keys(rollups)) {
+		if err := s.backend.PutRollup(ctx, day, rollups[day]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
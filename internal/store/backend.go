@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Backend keeps the index: the lines of each JSONL file and how many of its
+// bytes have been read, each file's aggregate usage, and daily rollups
+// across files. Reports parse the lines, because their window, filters,
+// and cross-file turns depend on the entries themselves; the aggregates and
+// rollups give totals without parsing. SQLite, a JSON file, and memory are
+// provided; another database can stand in by implementing it.
+type Backend interface {
+	// Ingested returns how many bytes of path have been read, zero for a
+	// file not yet stored
+	Ingested(ctx context.Context, path string) (int64, error)
+	// Append adds lines read from path, replacing any stored at the same
+	// offsets, and records that ingested bytes of it have been read. Both
+	// happen or neither does. Backends may keep the lines' Data.
+	Append(ctx context.Context, path string, lines []Line, ingested int64) error
+	// Delete forgets path, its lines, and its aggregate
+	Delete(ctx context.Context, path string) error
+	// Paths returns the stored files in lexical order
+	Paths(ctx context.Context) ([]string, error)
+	// Lines calls fn with the lines of path in order, skipping those
+	// timestamped before cutoff to the second. fn doesn't keep a line once
+	// it returns.
+	Lines(ctx context.Context, path string, cutoff time.Time, fn func(line []byte)) error
+	// LineCount returns how many lines of path are stored
+	LineCount(ctx context.Context, path string) (int, error)
+	// Count returns the number of files and lines stored
+	Count(ctx context.Context) (Stats, error)
+	// GetAggregate returns path's aggregate, and false for a file without
+	// one
+	GetAggregate(ctx context.Context, path string) (Aggregate, bool, error)
+	// PutAggregate replaces path's aggregate. Backends may keep its Days.
+	PutAggregate(ctx context.Context, path string, agg Aggregate) error
+	// GetRollup returns the totals of day, and false for a day without one
+	GetRollup(ctx context.Context, day string) (Totals, bool, error)
+	// PutRollup replaces the totals of day; zero totals remove it
+	PutRollup(ctx context.Context, day string, totals Totals) error
+	// Days returns the days with rollups in order
+	Days(ctx context.Context) ([]string, error)
+	Close() error
+}
+
+// Line is a stored JSONL line
+type Line struct {
+	Offset    int64     // Where it starts in its file
+	Timestamp time.Time // Zero for lines without one
+	Type      string
+	Data      []byte // With its newline
+}
+
+// flusher is implemented by backends that hold the index in memory and save
+// it after each ingest
+type flusher interface {
+	Flush() error
+}
+
+// before reports whether a line is timestamped before cutoff, comparing
+// whole seconds as the SQLite backend does
+func (l Line) before(cutoff time.Time) bool {
+	return !l.Timestamp.IsZero() && l.Timestamp.Unix() < cutoff.Unix()
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JSONFile is a backend that keeps the index in memory and saves it to a
+// JSON file after each ingest. Every run loads the whole file, so it suits
+// small histories and setups without SQLite.
+type JSONFile struct {
+	*Memory
+	path  string
+	dirty bool
+}
+
+// jsonIndex is the file's format
+type jsonIndex struct {
+	Files   []jsonIndexFile   `json:"files"`
+	Rollups map[string]Totals `json:"rollups,omitempty"`
+}
+
+type jsonIndexFile struct {
+	Path      string          `json:"path"`
+	Ingested  int64           `json:"ingested"`
+	Lines     []jsonIndexLine `json:"lines"`
+	Aggregate *Aggregate      `json:"aggregate,omitempty"`
+}
+
+type jsonIndexLine struct {
+	Offset    int64      `json:"offset"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Type      string     `json:"type,omitempty"`
+	Data      string     `json:"data"`
+}
+
+// OpenJSON loads the index at path, or starts an empty one if there's none
+func OpenJSON(path string) (*JSONFile, error) {
+	j := &JSONFile{Memory: NewMemory(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", path, err)
+	}
+
+	var index jsonIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", path, err)
+	}
+	for _, file := range index.Files {
+		f := &memoryFile{ingested: file.Ingested, lines: make([]Line, len(file.Lines))}
+		for i, line := range file.Lines {
+			f.lines[i] = Line{Offset: line.Offset, Type: line.Type, Data: []byte(line.Data)}
+			if line.Timestamp != nil {
+				f.lines[i].Timestamp = *line.Timestamp
+			}
+		}
+		j.files[file.Path] = f
+		if file.Aggregate != nil {
+			j.aggregates[file.Path] = *file.Aggregate
+		}
+	}
+	for day, totals := range index.Rollups {
+		j.rollups[day] = totals
+	}
+	return j, nil
+}
+
+// Append implements Backend
+func (j *JSONFile) Append(ctx context.Context, path string, lines []Line, ingested int64) error {
+	j.dirty = true
+	return j.Memory.Append(ctx, path, lines, ingested)
+}
+
+// Delete implements Backend
+func (j *JSONFile) Delete(ctx context.Context, path string) error {
+	j.dirty = true
+	return j.Memory.Delete(ctx, path)
+}
+
+// PutAggregate implements Backend
+func (j *JSONFile) PutAggregate(ctx context.Context, path string, agg Aggregate) error {
+	j.dirty = true
+	return j.Memory.PutAggregate(ctx, path, agg)
+}
+
+// PutRollup implements Backend
+func (j *JSONFile) PutRollup(ctx context.Context, day string, totals Totals) error {
+	j.dirty = true
+	return j.Memory.PutRollup(ctx, day, totals)
+}
+
+// Flush saves the index if it changed, replacing the file atomically
+func (j *JSONFile) Flush() error {
+	if !j.dirty {
+		return nil
+	}
+
+	paths, _ := j.Paths(context.Background())
+	index := jsonIndex{Files: make([]jsonIndexFile, 0, len(paths)), Rollups: make(map[string]Totals)}
+	j.mu.RLock()
+	for _, path := range paths {
+		f := j.files[path]
+		file := jsonIndexFile{Path: path, Ingested: f.ingested, Lines: make([]jsonIndexLine, len(f.lines))}
+		for i, line := range f.lines {
+			file.Lines[i] = jsonIndexLine{Offset: line.Offset, Type: line.Type, Data: string(line.Data)}
+			if !line.Timestamp.IsZero() {
+				timestamp := line.Timestamp
+				file.Lines[i].Timestamp = &timestamp
+			}
+		}
+		if agg, ok := j.aggregates[path]; ok {
+			file.Aggregate = &agg
+		}
+		index.Files = append(index.Files, file)
+	}
+	for day, totals := range j.rollups {
+		index.Rollups[day] = totals
+	}
+	j.mu.RUnlock()
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".index-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	j.dirty = false
+	return nil
+}
+
+// Close implements Backend, saving the index
+func (j *JSONFile) Close() error {
+	return j.Flush()
+}
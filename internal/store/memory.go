@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory is a backend that keeps the index in memory, for tests and
+// libraries that parse repeatedly within one process
+type Memory struct {
+	mu         sync.RWMutex
+	files      map[string]*memoryFile
+	aggregates map[string]Aggregate
+	rollups    map[string]Totals
+}
+
+type memoryFile struct {
+	ingested int64
+	lines    []Line // In offset order
+}
+
+// NewMemory creates an empty in-memory backend
+func NewMemory() *Memory {
+	return &Memory{
+		files:      make(map[string]*memoryFile),
+		aggregates: make(map[string]Aggregate),
+		rollups:    make(map[string]Totals),
+	}
+}
+
+// Ingested implements Backend
+func (m *Memory) Ingested(ctx context.Context, path string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.files[path]; ok {
+		return f.ingested, nil
+	}
+	return 0, nil
+}
+
+// Append implements Backend
+func (m *Memory) Append(ctx context.Context, path string, lines []Line, ingested int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[path]
+	if !ok {
+		f = &memoryFile{}
+		m.files[path] = f
+	}
+
+	// Lines arrive in offset order, so any they replace are at the end
+	if len(lines) > 0 {
+		first := lines[0].Offset
+		keep := sort.Search(len(f.lines), func(i int) bool { return f.lines[i].Offset >= first })
+		f.lines = append(f.lines[:keep], lines...)
+	}
+	f.ingested = ingested
+	return nil
+}
+
+// Delete implements Backend
+func (m *Memory) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, path)
+	delete(m.aggregates, path)
+	return nil
+}
+
+// Paths implements Backend
+func (m *Memory) Paths(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Lines implements Backend
+func (m *Memory) Lines(ctx context.Context, path string, cutoff time.Time, fn func(line []byte)) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f, ok := m.files[path]
+	if !ok {
+		return nil
+	}
+	for _, line := range f.lines {
+		if !line.before(cutoff) {
+			fn(line.Data)
+		}
+	}
+	return nil
+}
+
+// LineCount implements Backend
+func (m *Memory) LineCount(ctx context.Context, path string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.files[path]; ok {
+		return len(f.lines), nil
+	}
+	return 0, nil
+}
+
+// Count implements Backend
+func (m *Memory) Count(ctx context.Context) (Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := Stats{Files: len(m.files)}
+	for _, f := range m.files {
+		stats.Lines += len(f.lines)
+	}
+	return stats, nil
+}
+
+// GetAggregate implements Backend
+func (m *Memory) GetAggregate(ctx context.Context, path string) (Aggregate, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	agg, ok := m.aggregates[path]
+	return agg.clone(), ok, nil
+}
+
+// PutAggregate implements Backend
+func (m *Memory) PutAggregate(ctx context.Context, path string, agg Aggregate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.aggregates[path] = agg.clone()
+	return nil
+}
+
+// GetRollup implements Backend
+func (m *Memory) GetRollup(ctx context.Context, day string) (Totals, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	totals, ok := m.rollups[day]
+	return totals, ok, nil
+}
+
+// PutRollup implements Backend
+func (m *Memory) PutRollup(ctx context.Context, day string, totals Totals) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if totals.IsZero() {
+		delete(m.rollups, day)
+	} else {
+		m.rollups[day] = totals
+	}
+	return nil
+}
+
+// Days implements Backend
+func (m *Memory) Days(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	days := make([]string, 0, len(m.rollups))
+	for day := range m.rollups {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// Close implements Backend
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	// Registers the pure Go "sqlite" driver
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS files (
+	path     TEXT PRIMARY KEY,
+	ingested INTEGER NOT NULL -- Bytes read so far; always ends on a line
+);
+CREATE TABLE IF NOT EXISTS lines (
+	path        TEXT NOT NULL,
+	byte_offset INTEGER NOT NULL,
+	timestamp   INTEGER, -- Unix seconds, NULL for entries without one
+	type        TEXT,
+	data        BLOB NOT NULL,
+	PRIMARY KEY (path, byte_offset)
+);
+CREATE INDEX IF NOT EXISTS lines_by_time ON lines (path, timestamp);
+CREATE TABLE IF NOT EXISTS aggregates (
+	path     TEXT PRIMARY KEY,
+	ingested INTEGER NOT NULL -- Bytes the totals cover
+);
+CREATE TABLE IF NOT EXISTS aggregate_days (
+	path        TEXT NOT NULL,
+	day         TEXT NOT NULL, -- UTC date, as 2025-06-01
+	messages    INTEGER NOT NULL,
+	input       INTEGER NOT NULL,
+	output      INTEGER NOT NULL,
+	cache_write INTEGER NOT NULL,
+	cache_read  INTEGER NOT NULL,
+	cost        REAL NOT NULL,
+	PRIMARY KEY (path, day)
+);
+CREATE TABLE IF NOT EXISTS rollups (
+	day         TEXT PRIMARY KEY,
+	messages    INTEGER NOT NULL,
+	input       INTEGER NOT NULL,
+	output      INTEGER NOT NULL,
+	cache_write INTEGER NOT NULL,
+	cache_read  INTEGER NOT NULL,
+	cost        REAL NOT NULL
+);
+`
+
+// SQLite is the default backend, a database that reports can read while
+// the daemon writes
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens or creates the database at path
+func OpenSQLite(path string) (*SQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	// WAL lets reports read while the daemon writes
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Ingested implements Backend
+func (s *SQLite) Ingested(ctx context.Context, path string) (int64, error) {
+	var ingested int64
+	err := s.db.QueryRowContext(ctx, `SELECT ingested FROM files WHERE path = ?`, path).Scan(&ingested)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return ingested, err
+}
+
+// Append implements Backend
+func (s *SQLite) Append(ctx context.Context, path string, lines []Line, ingested int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx,
+		`INSERT OR REPLACE INTO lines (path, byte_offset, timestamp, type, data) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, line := range lines {
+		var timestamp sql.NullInt64
+		if !line.Timestamp.IsZero() {
+			timestamp = sql.NullInt64{Int64: line.Timestamp.Unix(), Valid: true}
+		}
+		entryType := sql.NullString{String: line.Type, Valid: line.Type != ""}
+		if _, err := insert.ExecContext(ctx, path, line.Offset, timestamp, entryType, line.Data); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO files (path, ingested) VALUES (?, ?) ON CONFLICT (path) DO UPDATE SET ingested = excluded.ingested`,
+		path, ingested); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete implements Backend
+func (s *SQLite) Delete(ctx context.Context, path string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, table := range []string{"lines", "files", "aggregate_days", "aggregates"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE path = ?`, path); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Paths implements Backend
+func (s *SQLite) Paths(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path FROM files ORDER BY path`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// Lines implements Backend
+func (s *SQLite) Lines(ctx context.Context, path string, cutoff time.Time, fn func(line []byte)) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT data FROM lines WHERE path = ? AND (timestamp IS NULL OR timestamp >= ?) ORDER BY byte_offset`,
+		path, cutoff.Unix())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Scanning into RawBytes reuses the driver's memory rather than copying
+	// each line; it's valid until the next row, and fn doesn't keep it
+	for rows.Next() {
+		var line sql.RawBytes
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		fn(line)
+	}
+	return rows.Err()
+}
+
+// LineCount implements Backend
+func (s *SQLite) LineCount(ctx context.Context, path string) (int, error) {
+	var lines int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM lines WHERE path = ?`, path).Scan(&lines)
+	return lines, err
+}
+
+// Count implements Backend
+func (s *SQLite) Count(ctx context.Context) (Stats, error) {
+	var stats Stats
+	err := s.db.QueryRowContext(ctx, `SELECT (SELECT COUNT(*) FROM files), (SELECT COUNT(*) FROM lines)`).Scan(&stats.Files, &stats.Lines)
+	return stats, err
+}
+
+// GetAggregate implements Backend
+func (s *SQLite) GetAggregate(ctx context.Context, path string) (Aggregate, bool, error) {
+	var agg Aggregate
+	err := s.db.QueryRowContext(ctx, `SELECT ingested FROM aggregates WHERE path = ?`, path).Scan(&agg.Ingested)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Aggregate{}, false, nil
+	}
+	if err != nil {
+		return Aggregate{}, false, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT day, messages, input, output, cache_write, cache_read, cost FROM aggregate_days WHERE path = ?`, path)
+	if err != nil {
+		return Aggregate{}, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day string
+		var totals Totals
+		if err := rows.Scan(&day, &totals.Messages, &totals.Tokens.Input, &totals.Tokens.Output,
+			&totals.Tokens.CacheWrite, &totals.Tokens.CacheRead, &totals.Cost); err != nil {
+			return Aggregate{}, false, err
+		}
+		if agg.Days == nil {
+			agg.Days = make(map[string]Totals)
+		}
+		agg.Days[day] = totals
+	}
+	return agg, true, rows.Err()
+}
+
+// PutAggregate implements Backend
+func (s *SQLite) PutAggregate(ctx context.Context, path string, agg Aggregate) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM aggregate_days WHERE path = ?`, path); err != nil {
+		return err
+	}
+	insert, err := tx.PrepareContext(ctx,
+		`INSERT INTO aggregate_days (path, day, messages, input, output, cache_write, cache_read, cost) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for day, totals := range agg.Days {
+		if _, err := insert.ExecContext(ctx, path, day, totals.Messages, totals.Tokens.Input, totals.Tokens.Output,
+			totals.Tokens.CacheWrite, totals.Tokens.CacheRead, totals.Cost); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO aggregates (path, ingested) VALUES (?, ?) ON CONFLICT (path) DO UPDATE SET ingested = excluded.ingested`,
+		path, agg.Ingested); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRollup implements Backend
+func (s *SQLite) GetRollup(ctx context.Context, day string) (Totals, bool, error) {
+	var totals Totals
+	err := s.db.QueryRowContext(ctx,
+		`SELECT messages, input, output, cache_write, cache_read, cost FROM rollups WHERE day = ?`, day).Scan(
+		&totals.Messages, &totals.Tokens.Input, &totals.Tokens.Output, &totals.Tokens.CacheWrite, &totals.Tokens.CacheRead, &totals.Cost)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Totals{}, false, nil
+	}
+	return totals, err == nil, err
+}
+
+// PutRollup implements Backend
+func (s *SQLite) PutRollup(ctx context.Context, day string, totals Totals) error {
+	if totals.IsZero() {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM rollups WHERE day = ?`, day)
+		return err
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO rollups (day, messages, input, output, cache_write, cache_read, cost) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		day, totals.Messages, totals.Tokens.Input, totals.Tokens.Output, totals.Tokens.CacheWrite, totals.Tokens.CacheRead, totals.Cost)
+	return err
+}
+
+// Days implements Backend
+func (s *SQLite) Days(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT day FROM rollups ORDER BY day`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []string
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+// Close implements Backend
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
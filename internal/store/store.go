@@ -0,0 +1,343 @@
+// Package store keeps Claude Code's JSONL lines in an index, so reports read
+// only the lines in their window instead of every file. Alongside the lines
+// it keeps each file's usage by day and daily rollups across files, for
+// totals that don't need the lines parsed. The daemon command keeps it
+// current, and each report catches up on what was appended since. The index
+// is a SQLite database by default; see Backend for the others.
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/internal/state"
+)
+
+// Store is the line index, kept in a Backend
+type Store struct {
+	backend Backend
+}
+
+// New creates a store kept in backend
+func New(backend Backend) *Store {
+	return &Store{backend: backend}
+}
+
+// Stats describes an ingest run or the store's contents
+type Stats struct {
+	Files int
+	Lines int
+}
+
+// DefaultPath returns the default database location, next to the state file
+func DefaultPath() string {
+	statePath := state.DefaultPath()
+	if statePath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(statePath), "index.db")
+}
+
+// Exists reports whether a database has been created at path
+func Exists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open opens or creates the index at path: a JSON file when path ends in
+// .json, and a SQLite database otherwise
+func Open(path string) (*Store, error) {
+	var backend Backend
+	var err error
+	if filepath.Ext(path) == ".json" {
+		backend, err = OpenJSON(path)
+	} else {
+		backend, err = OpenSQLite(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return New(backend), nil
+}
+
+// Close closes the backend, saving what it holds in memory
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+// Ingest adds the lines appended to the JSONL files under claudeDir since
+// the last ingest. A file that shrank was rewritten and is read again from
+// the start. Files that are deleted keep their lines, so history outlives
+// Claude Code's transcript cleanup. opts configure how the files are found.
+func (s *Store) Ingest(ctx context.Context, claudeDir string, opts ...parser.FindOption) (Stats, error) {
+	files, err := parser.FindFiles(claudeDir, opts...)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	var stats Stats
+	var errs []error
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		lines, err := s.ingestFile(ctx, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if lines > 0 {
+			stats.Files++
+			stats.Lines += lines
+		}
+	}
+	errs = append(errs, s.flush())
+	return stats, errors.Join(errs...)
+}
+
+// Reindex discards what is stored for the JSONL files under claudeDir and
+// ingests them again from the start, then rebuilds the daily rollups from
+// every file's aggregate, even when some files fail to ingest. Lines of
+// files that no longer exist can't be read again and are kept.
+func (s *Store) Reindex(ctx context.Context, claudeDir string, opts ...parser.FindOption) (Stats, error) {
+	files, err := parser.FindFiles(claudeDir, opts...)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	for _, path := range files {
+		if err := s.forget(ctx, path); err != nil {
+			return Stats{}, err
+		}
+	}
+
+	stats, err := s.Ingest(ctx, claudeDir, opts...)
+	return stats, errors.Join(err, s.rebuildRollups(ctx), s.flush())
+}
+
+// forget deletes path, first taking its usage out of the daily rollups
+func (s *Store) forget(ctx context.Context, path string) error {
+	agg, _, err := s.backend.GetAggregate(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := s.replaceAggregate(ctx, path, agg, Aggregate{}); err != nil {
+		return err
+	}
+	return s.backend.Delete(ctx, path)
+}
+
+// flush saves a backend that holds the index in memory
+func (s *Store) flush() error {
+	if f, ok := s.backend.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Discrepancy is a file whose complete lines don't match what is stored
+type Discrepancy struct {
+	Path       string
+	FileLines  int
+	IndexLines int
+}
+
+// Verify compares the number of complete lines in each JSONL file under
+// claudeDir with the number stored
+func (s *Store) Verify(ctx context.Context, claudeDir string, opts ...parser.FindOption) ([]Discrepancy, error) {
+	files, err := parser.FindFiles(claudeDir, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var discrepancies []Discrepancy
+	for _, path := range files {
+		fileLines, err := countLines(path)
+		if err != nil {
+			return nil, err
+		}
+		indexLines, err := s.backend.LineCount(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if fileLines != indexLines {
+			discrepancies = append(discrepancies, Discrepancy{Path: path, FileLines: fileLines, IndexLines: indexLines})
+		}
+	}
+	return discrepancies, nil
+}
+
+// countLines counts the newline-terminated lines of a file that Ingest
+// would store
+func countLines(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	lines := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(line) <= parser.MaxLineSize {
+			lines++
+		}
+	}
+}
+
+// ingestBatch is how many lines are appended to the backend at a time
+const ingestBatch = 1000
+
+// ingestFile adds the complete lines after the file's ingested offset,
+// counting their usage in the file's aggregate and the daily rollups, and
+// returns how many were added
+func (s *Store) ingestFile(ctx context.Context, path string) (int, error) {
+	ingested, err := s.backend.Ingested(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	agg, err := s.syncAggregate(ctx, path, ingested)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() == ingested {
+		return 0, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if info.Size() < ingested {
+		if err := s.forget(ctx, path); err != nil {
+			return 0, err
+		}
+		ingested, agg = 0, Aggregate{}
+	}
+	if _, err := file.Seek(ingested, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	// Each batch is appended with the offset it reaches, so an interrupted
+	// ingest resumes after the last batch stored
+	reader := bufio.NewReaderSize(file, 64*1024)
+	added := 0
+	var batch []Line
+	appendBatch := func() error {
+		if err := s.backend.Append(ctx, path, batch, ingested); err != nil {
+			return err
+		}
+		next := agg.clone()
+		next.Ingested = ingested
+		for _, line := range batch {
+			next.add(line.Data)
+		}
+		if err := s.replaceAggregate(ctx, path, agg, next); err != nil {
+			return err
+		}
+		agg = next
+		added += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A partial last line is still being written; read it next time
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		offset := ingested
+		ingested += int64(len(line))
+		if len(line) > parser.MaxLineSize {
+			continue
+		}
+		batch = append(batch, newLine(offset, line))
+		if len(batch) == ingestBatch {
+			if err := appendBatch(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := appendBatch(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// newLine extracts the indexed fields of a line. Lines that don't parse are
+// stored anyway so the parser counts them as it would from the file.
+func newLine(offset int64, data []byte) Line {
+	line := Line{Offset: offset, Data: data}
+	var entry struct {
+		Timestamp string `json:"timestamp"`
+		Type      string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return line
+	}
+	if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		line.Timestamp = t
+	}
+	line.Type = entry.Type
+	return line
+}
+
+// Files returns the stored JSONL files under claudeDir. It implements
+// parser.LineSource.
+func (s *Store) Files(claudeDir string) ([]string, error) {
+	prefix := filepath.Join(claudeDir, "projects") + string(filepath.Separator)
+	paths, err := s.backend.Paths(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range paths {
+		if strings.HasPrefix(path, prefix) {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// EachLine calls fn with the stored lines of file in order, skipping lines
+// with a timestamp before cutoff. It implements parser.LineSource.
+func (s *Store) EachLine(file string, cutoff time.Time, fn func(line []byte)) error {
+	return s.backend.Lines(context.Background(), file, cutoff, fn)
+}
+
+// Count returns the number of files and lines stored
+func (s *Store) Count() (Stats, error) {
+	return s.backend.Count(context.Background())
+}
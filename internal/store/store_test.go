@@ -0,0 +1,375 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// backends opens an empty store of each kind
+func backends(t *testing.T) map[string]func() *Store {
+	open := func(path string) func() *Store {
+		return func() *Store {
+			s, err := Open(filepath.Join(t.TempDir(), path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}
+	}
+	return map[string]func() *Store{
+		"sqlite": open("index.db"),
+		"json":   open("index.json"),
+		"memory": func() *Store { return New(NewMemory()) },
+	}
+}
+
+func TestStore_Ingest(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) { testIngest(t, open()) })
+	}
+}
+
+func testIngest(t *testing.T, s *Store) {
+	claudeDir := t.TempDir()
+	file := filepath.Join(claudeDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	old := `{"type":"user","timestamp":"2025-06-01T10:00:00Z"}` + "\n"
+	recent := `{"type":"assistant","timestamp":"2025-06-10T10:00:00Z"}` + "\n"
+	summary := `{"type":"summary","summary":"no timestamp"}` + "\n"
+	partial := `{"type":"assistant","timest`
+	if err := os.WriteFile(file, []byte(old+recent+summary+partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ingest := func(want int) {
+		t.Helper()
+		stats, err := s.Ingest(context.Background(), claudeDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Lines != want {
+			t.Errorf("ingested %d lines, want %d", stats.Lines, want)
+		}
+	}
+	lines := func(cutoff time.Time) []string {
+		t.Helper()
+		var got []string
+		if err := s.EachLine(file, cutoff, func(line []byte) { got = append(got, string(line)) }); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// The partial line waits until it's complete
+	ingest(3)
+	ingest(0)
+	if got := lines(time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)); len(got) != 2 || got[0] != recent {
+		t.Errorf("lines after cutoff = %q, want the recent entry and the summary", got)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`amp":"2025-06-11T10:00:00Z"}` + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	ingest(1)
+	if got := lines(time.Time{}); len(got) != 4 {
+		t.Errorf("got %d lines, want 4 after the partial line completed", len(got))
+	}
+
+	// A rewritten, shorter file is read again from the start
+	if err := os.WriteFile(file, []byte(recent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ingest(1)
+	if got := lines(time.Time{}); len(got) != 1 {
+		t.Errorf("got %d lines, want 1 after the file shrank", len(got))
+	}
+
+	files, err := s.Files(claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != file {
+		t.Errorf("Files = %v, want [%s]", files, file)
+	}
+	if other, _ := s.Files(t.TempDir()); len(other) != 0 {
+		t.Errorf("Files of another Claude directory = %v, want none", other)
+	}
+
+	// Lines outlive the file
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	ingest(0)
+	if count, err := s.Count(); err != nil || count.Files != 1 || count.Lines != 1 {
+		t.Errorf("Count = %+v, %v, want 1 file and 1 line", count, err)
+	}
+}
+
+func TestStore_ReindexVerify(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) { testReindexVerify(t, open()) })
+	}
+}
+
+func testReindexVerify(t *testing.T, s *Store) {
+	claudeDir := t.TempDir()
+	file := filepath.Join(claudeDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"user","timestamp":"2025-06-01T10:00:00Z"}` + "\n"
+	if err := os.WriteFile(file, []byte(line+line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if _, err := s.Ingest(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := s.Verify(ctx, claudeDir); err != nil || len(d) != 0 {
+		t.Fatalf("Verify = %+v, %v, want no discrepancies", d, err)
+	}
+
+	// Simulate corruption: a line missing from the index
+	if err := s.backend.Delete(ctx, file); err != nil {
+		t.Fatal(err)
+	}
+	second := newLine(int64(len(line)), []byte(line))
+	if err := s.backend.Append(ctx, file, []Line{second}, int64(2*len(line))); err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.Verify(ctx, claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 1 || d[0].FileLines != 2 || d[0].IndexLines != 1 {
+		t.Errorf("Verify = %+v, want one file with 2 lines on disk and 1 indexed", d)
+	}
+
+	stats, err := s.Reindex(ctx, claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Lines != 2 {
+		t.Errorf("Reindex stored %d lines, want 2", stats.Lines)
+	}
+	if d, err := s.Verify(ctx, claudeDir); err != nil || len(d) != 0 {
+		t.Errorf("Verify after Reindex = %+v, %v, want no discrepancies", d, err)
+	}
+}
+
+func TestStore_Aggregates(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) { testAggregates(t, open()) })
+	}
+}
+
+func testAggregates(t *testing.T, s *Store) {
+	claudeDir := t.TempDir()
+	file := filepath.Join(claudeDir, "projects", "app", "session.jsonl")
+	other := filepath.Join(claudeDir, "projects", "web", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(other), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	reply := func(timestamp string, output int) string {
+		return `{"type":"assistant","timestamp":"` + timestamp + `","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":` + strconv.Itoa(output) + `}}}` + "\n"
+	}
+	prompt := `{"type":"user","timestamp":"2025-06-01T09:00:00Z"}` + "\n"
+	june1, june2 := reply("2025-06-01T10:00:00Z", 100), reply("2025-06-02T10:00:00Z", 200)
+	if err := os.WriteFile(file, []byte(prompt+june1+june2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte(reply("2025-06-02T11:00:00Z", 50)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := s.Ingest(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	rollup := func(day string) Totals {
+		t.Helper()
+		totals, err := s.Rollup(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return totals
+	}
+
+	agg, err := s.Aggregate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := agg.Total(); total.Messages != 2 || total.Tokens.Output != 300 || total.Tokens.Input != 20 {
+		t.Errorf("file total = %+v, want 2 messages with 300 output and 20 input tokens", total)
+	}
+	if got := agg.Days["2025-06-01"]; got.Messages != 1 || math.Abs(got.Cost-(10*3+100*15)/1e6) > 1e-12 {
+		t.Errorf("June 1 = %+v, want 1 message at Sonnet's list price", got)
+	}
+	if got := rollup("2025-06-02"); got.Messages != 2 || got.Tokens.Output != 250 {
+		t.Errorf("June 2 rollup = %+v, want 2 messages with 250 output tokens across files", got)
+	}
+
+	// A rewritten, shorter file moves its usage out of the rollups
+	if err := os.WriteFile(file, []byte(june2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ingest(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if got := rollup("2025-06-01"); !got.IsZero() {
+		t.Errorf("June 1 rollup after the rewrite = %+v, want none", got)
+	}
+	if got := rollup("2025-06-02"); got.Messages != 2 || got.Tokens.Output != 250 {
+		t.Errorf("June 2 rollup after the rewrite = %+v, want it unchanged", got)
+	}
+	if rollups, err := s.Rollups(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)); err != nil || len(rollups) != 1 {
+		t.Errorf("Rollups = %v, %v, want only June 2", rollups, err)
+	}
+
+	// An aggregate behind its lines, as an interrupted ingest leaves it, is
+	// rebuilt from them, and Reindex rebuilds the rollups
+	if err := s.backend.PutAggregate(ctx, other, Aggregate{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.backend.PutRollup(ctx, "2025-06-03", Totals{Messages: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ingest(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if agg, err := s.Aggregate(other); err != nil || agg.Total().Tokens.Output != 50 {
+		t.Errorf("rebuilt aggregate = %+v, %v, want 50 output tokens", agg, err)
+	}
+	if _, err := s.Reindex(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if got := rollup("2025-06-03"); !got.IsZero() {
+		t.Errorf("June 3 rollup after Reindex = %+v, want none", got)
+	}
+	if got := rollup("2025-06-02"); got.Messages != 2 || got.Tokens.Output != 250 {
+		t.Errorf("June 2 rollup after Reindex = %+v, want 2 messages with 250 output tokens", got)
+	}
+}
+
+// failingAppend is a memory backend whose Append fails for one path
+type failingAppend struct {
+	*Memory
+	path string
+}
+
+func (f *failingAppend) Append(ctx context.Context, path string, lines []Line, ingested int64) error {
+	if path == f.path {
+		return errors.New("disk full")
+	}
+	return f.Memory.Append(ctx, path, lines, ingested)
+}
+
+func TestStore_ReindexFailure(t *testing.T) {
+	claudeDir := t.TempDir()
+	reply := `{"type":"assistant","timestamp":"2025-06-01T10:00:00Z","message":{"model":"claude-sonnet-4-20250514","usage":{"output_tokens":10}}}` + "\n"
+	var files []string
+	for _, project := range []string{"app", "web"} {
+		file := filepath.Join(claudeDir, "projects", project, "session.jsonl")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(reply), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	backend := &failingAppend{Memory: NewMemory()}
+	s := New(backend)
+	ctx := context.Background()
+	if _, err := s.Ingest(ctx, claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if totals, _ := s.Rollup("2025-06-01"); totals.Messages != 2 {
+		t.Fatalf("rollup = %+v, want 2 messages", totals)
+	}
+
+	// The failed file's usage leaves the rollups along with its lines
+	backend.path = files[1]
+	if _, err := s.Reindex(ctx, claudeDir); err == nil {
+		t.Fatal("Reindex succeeded with a failing file")
+	}
+	if count, _ := s.Count(); count.Lines != 1 {
+		t.Errorf("Count = %+v, want 1 line", count)
+	}
+	if totals, _ := s.Rollup("2025-06-01"); totals.Messages != 1 {
+		t.Errorf("rollup after a failed Reindex = %+v, want 1 message", totals)
+	}
+}
+
+func TestJSONFile_Reopen(t *testing.T) {
+	claudeDir := t.TempDir()
+	file := filepath.Join(claudeDir, "projects", "app", "session.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"type":"user","timestamp":"2025-06-01T10:00:00Z"}` + "\n" +
+		`{"type":"assistant","timestamp":"2025-06-01T10:00:05Z","message":{"model":"claude-sonnet-4-20250514","usage":{"output_tokens":10}}}` + "\n" +
+		`{"type":"summary"}` + "\n"
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Ingest(context.Background(), claudeDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lines, their timestamps, the offset read, and the totals all
+	// survive
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if agg, err := s.Aggregate(file); err != nil || agg.Ingested != int64(len(data)) || agg.Total().Tokens.Output != 10 {
+		t.Errorf("Aggregate after reopening = %+v, %v, want 10 output tokens over the whole file", agg, err)
+	}
+	if totals, err := s.Rollup("2025-06-01"); err != nil || totals.Messages != 1 {
+		t.Errorf("Rollup after reopening = %+v, %v, want 1 message", totals, err)
+	}
+	if stats, err := s.Ingest(context.Background(), claudeDir); err != nil || stats.Lines != 0 {
+		t.Errorf("Ingest after reopening = %+v, %v, want nothing new", stats, err)
+	}
+	var got []string
+	if err := s.EachLine(file, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), func(line []byte) { got = append(got, string(line)) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != `{"type":"summary"}`+"\n" {
+		t.Errorf("lines after cutoff = %q, want only the summary", got)
+	}
+}
@@ -0,0 +1,180 @@
+// Package termimg draws images inline in terminals that support the kitty,
+// iTerm2, or sixel graphics protocols.
+package termimg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Protocol is a terminal graphics protocol
+type Protocol string
+
+const (
+	None  Protocol = "none"
+	Kitty Protocol = "kitty"
+	ITerm Protocol = "iterm"
+	Sixel Protocol = "sixel"
+)
+
+// Protocols lists the protocols, for flag validation
+var Protocols = []Protocol{Kitty, ITerm, Sixel, None}
+
+// Detect guesses the protocol of the terminal from its environment, as read
+// by getenv. Terminals are only detected by their own variables, since
+// asking the terminal itself needs raw mode; TERM containing "sixel" opts in
+// to sixel elsewhere.
+func Detect(getenv func(string) string) Protocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+
+	// Inside tmux or screen the outer terminal never sees the escapes
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return None
+	}
+
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty":
+		return Kitty
+	case program == "ghostty" || program == "WezTerm":
+		return Kitty
+	case program == "iTerm.app" || getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm
+	case term == "foot" || strings.HasPrefix(term, "foot-") || term == "mlterm" ||
+		strings.Contains(term, "sixel") || program == "mintty":
+		return Sixel
+	}
+	return None
+}
+
+// Write draws the PNG image data to w with protocol p, followed by a
+// newline. None writes nothing.
+func Write(w io.Writer, p Protocol, pngData []byte) error {
+	switch p {
+	case Kitty:
+		return writeKitty(w, pngData)
+	case ITerm:
+		return writeITerm(w, pngData)
+	case Sixel:
+		img, err := png.Decode(bytes.NewReader(pngData))
+		if err != nil {
+			return err
+		}
+		return writeSixel(w, img)
+	case None:
+		return nil
+	}
+	return fmt.Errorf("unknown graphics protocol %q", p)
+}
+
+// writeKitty sends the PNG as-is in base64 chunks of at most 4096 bytes,
+// the largest the protocol allows
+func writeKitty(w io.Writer, pngData []byte) error {
+	const chunkSize = 4096
+	encoded := base64.StdEncoding.EncodeToString(pngData)
+
+	bw := bufio.NewWriter(w)
+	for first := true; first || len(encoded) > 0; first = false {
+		chunk := encoded[:min(chunkSize, len(encoded))]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if len(encoded) > 0 {
+			more = 1
+		}
+		if first {
+			// f=100 is PNG, a=T transmits and displays it
+			fmt.Fprintf(bw, "\x1b_Gf=100,a=T,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(bw, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// writeITerm sends the PNG with iTerm2's inline file escape
+func writeITerm(w io.Writer, pngData []byte) error {
+	_, err := fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n",
+		len(pngData), base64.StdEncoding.EncodeToString(pngData))
+	return err
+}
+
+// writeSixel encodes img as sixels in the 216 web-safe colors, which is
+// plenty for charts and within the 256 color registers terminals provide
+func writeSixel(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.WebSafe)
+	draw.Draw(paletted, paletted.Bounds(), img, bounds.Min, draw.Src)
+	width, height := paletted.Bounds().Dx(), paletted.Bounds().Dy()
+
+	bw := bufio.NewWriter(w)
+	// P2=1 leaves unset pixels alone; the raster attributes give 1:1
+	// pixels and the image size
+	fmt.Fprintf(bw, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	// Each band is six rows; each color used in the band is drawn across
+	// it in turn, returning to the band's start with "$"
+	band := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		used := make(map[uint8]bool)
+		for y := top; y < min(top+6, height); y++ {
+			for x := 0; x < width; x++ {
+				used[paletted.ColorIndexAt(x, y)] = true
+			}
+		}
+
+		firstColor := true
+		for index := range len(paletted.Palette) {
+			if !used[uint8(index)] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if paletted.ColorIndexAt(x, top+dy) == uint8(index) {
+						bits |= 1 << dy
+					}
+				}
+				band[x] = '?' + bits
+			}
+			if !firstColor {
+				bw.WriteByte('$')
+			}
+			firstColor = false
+			fmt.Fprintf(bw, "#%d", index)
+			writeRuns(bw, band)
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\x1b\\\n")
+	return bw.Flush()
+}
+
+// writeRuns writes sixel characters, compressing runs of four or more into
+// the "!count" repeat form
+func writeRuns(w *bufio.Writer, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		j := i
+		for j < len(sixels) && sixels[j] == sixels[i] {
+			j++
+		}
+		if run := j - i; run >= 4 {
+			fmt.Fprintf(w, "!%d%c", run, sixels[i])
+		} else {
+			w.Write(sixels[i:j])
+		}
+		i = j
+	}
+}
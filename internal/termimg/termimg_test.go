@@ -0,0 +1,125 @@
+package termimg
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Protocol
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, None},
+		{map[string]string{"TERM": "xterm-kitty", "KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, ITerm},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-sixel"}, Sixel},
+		{map[string]string{"TERM": "screen-256color", "TERM_PROGRAM": "iTerm.app"}, None},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-1000/default,1,0"}, None},
+	}
+	for _, tt := range tests {
+		if got := Detect(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("Detect(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			if x < width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 0xff, 0xff})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWrite_Kitty(t *testing.T) {
+	// Noise doesn't compress, so this needs several chunks
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.UintN(256))
+	}
+	var data bytes.Buffer
+	png.Encode(&data, img)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Kitty, data.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1b_Gf=100,a=T,m=1;") {
+		t.Errorf("first chunk = %q, want a PNG transmit with more to come", out[:min(len(out), 24)])
+	}
+	chunks := strings.Count(out, "\x1b_G")
+	if chunks < 2 || strings.Count(out, "m=0;") != 1 || !strings.HasSuffix(out, "\x1b\\\n") {
+		t.Errorf("got %d chunks; want several, with only the last marked m=0", chunks)
+	}
+}
+
+func TestWrite_ITerm(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, ITerm, testPNG(t, 4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "\x1b]1337;File=inline=1;") || !strings.HasSuffix(out, "\a\n") {
+		t.Errorf("unexpected iTerm2 escape %q", out)
+	}
+}
+
+func TestWrite_Sixel(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Sixel, testPNG(t, 8, 12)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1bP0;1;0q\"1;1;8;12") || !strings.HasSuffix(out, "\x1b\\\n") {
+		t.Errorf("unexpected sixel framing %q", out)
+	}
+	// Two bands of six rows, each with the white and blue halves drawn
+	// as runs
+	if bands := strings.Count(out, "-"); bands != 2 {
+		t.Errorf("bands = %d, want 2", bands)
+	}
+	if !strings.Contains(out, "!4~") {
+		t.Errorf("expected full runs of four columns in %q", out)
+	}
+}
+
+func TestWrite_None(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, None, testPNG(t, 4, 4)); err != nil || buf.Len() != 0 {
+		t.Errorf("None wrote %q, err %v", buf.String(), err)
+	}
+	if err := Write(&buf, "blink", nil); err == nil {
+		t.Error("unknown protocol: expected error")
+	}
+}
+
+func TestWriteRuns(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeRuns(w, []byte("???~~~~~@@"))
+	w.Flush()
+	if got, want := buf.String(), "???!5~@@"; got != want {
+		t.Errorf("writeRuns = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package tokens
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 2},          // The space joins "world"
+		{"internationalization", 3}, // 20 letters
+		{"1234567", 3},
+		{"if (x) {", 5}, // if, " (", x, ")", " {"
+		{"\n\n\t\tx", 2},
+		{"héllo", 3}, // h, é, llo
+	}
+	for _, tt := range tests {
+		if got := Count([]byte(tt.text)); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("small.txt", "one")
+	write("src/large.go", "package main\n\nfunc main() {}\n")
+	write("image.png", "\x89PNG\x00\x00")
+	write(".git/config", "[core]")
+	write(".env", "SECRET=1")
+
+	files, err := CountFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("files = %+v, want small.txt and src/large.go", files)
+	}
+	if files[0].Path != filepath.Join(dir, "src", "large.go") || files[1].Path != filepath.Join(dir, "small.txt") {
+		t.Errorf("files = %+v, want most tokens first", files)
+	}
+	if files[1].Bytes != 3 || files[1].Tokens != 1 {
+		t.Errorf("small.txt = %+v, want 3 bytes and 1 token", files[1])
+	}
+
+	// Named explicitly, hidden files are counted
+	if files, err := CountFiles([]string{filepath.Join(dir, ".env")}); err != nil || len(files) != 1 {
+		t.Errorf("CountFiles(.env) = %+v, %v", files, err)
+	}
+	if _, err := CountFiles([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected error for a missing path")
+	}
+}
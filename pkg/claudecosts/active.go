@@ -0,0 +1,49 @@
+package claudecosts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/live"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+// ActiveSession is a session with recent activity: its cost so far, the
+// model of its latest response, and its current output token rate
+type ActiveSession = live.Session
+
+// ActiveSessions returns the sessions in ~/.claude with a response within
+// the last within, busiest first, for prompts and status lines. Only the
+// log files written in that time are read, so it is cheap to call often.
+func ActiveSessions(within time.Duration) ([]ActiveSession, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoClaudeDir, err)
+	}
+	claudeDir := filepath.Join(home, ".claude")
+	if _, err := os.Stat(claudeDir); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoClaudeDir, claudeDir)
+	}
+
+	now := time.Now()
+	tracker := live.NewTracker()
+	analysis, err := parser.New(30, claudeDir,
+		parser.WithLineSource(parser.NewTail(now.Add(-within))),
+		parser.WithMessageHandler(tracker.Add),
+	).ParseAll()
+	if errors.Is(err, ErrNoJSONLFiles) {
+		// No log was written in the window
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for id, session := range analysis.Sessions {
+		tracker.SetTitle(id, session.Title)
+	}
+	return tracker.Active(now, within), nil
+}
@@ -0,0 +1,49 @@
+package claudecosts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestActiveSessions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := ActiveSessions(time.Hour); err == nil {
+		t.Error("missing ~/.claude: expected error")
+	}
+
+	dir := filepath.Join(home, ".claude", "projects", "-home-user-api")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := func(ts time.Time, model string) string {
+		return `{"type":"assistant","timestamp":"` + ts.UTC().Format(time.RFC3339) +
+			`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"` + model + `"}}` + "\n"
+	}
+	now := time.Now()
+	data := line(now.Add(-2*time.Hour), "claude-opus-4-20250514") + line(now.Add(-time.Minute), "claude-sonnet-4-20250514")
+	if err := os.WriteFile(filepath.Join(dir, "active.jsonl"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := line(now.Add(-3*time.Hour), "claude-sonnet-4-20250514")
+	if err := os.WriteFile(filepath.Join(dir, "idle.jsonl"), []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "idle.jsonl"), now.Add(-3*time.Hour), now.Add(-3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := ActiveSessions(10 * time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "active" {
+		t.Fatalf("sessions = %+v, want only active", sessions)
+	}
+	if s := sessions[0]; s.Messages != 2 || s.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("active = %d messages with %s, want 2 with the latest model", s.Messages, s.Model)
+	}
+}
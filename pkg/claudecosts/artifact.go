@@ -0,0 +1,69 @@
+package claudecosts
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// AnalysisVersion is the version of the format MarshalAnalysis writes.
+// Fields added to Analysis don't change it; removing, renaming, or changing
+// the meaning of one does, so older versions refuse what they can't read.
+const AnalysisVersion = 1
+
+// artifact is the saved form of an analysis, gzipped
+type artifact struct {
+	Version  int       `json:"version"`
+	Analysis *Analysis `json:"analysis"`
+}
+
+// MarshalAnalysis saves an analysis as a compressed, versioned artifact, so
+// one computed in CI or on another host can be reported on or compared
+// later without the logs it came from. Artifacts can't be merged, since
+// percentiles and turns need the entries behind them.
+func MarshalAnalysis(analysis *Analysis) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(artifact{Version: AnalysisVersion, Analysis: analysis}); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalAnalysis reads an analysis saved by MarshalAnalysis. Artifacts of
+// a newer version fail with ErrAnalysisVersion.
+func UnmarshalAnalysis(data []byte) (*Analysis, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not a saved analysis: %w", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("not a saved analysis: %w", err)
+	}
+
+	// Check the version before decoding an analysis it may not describe
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("not a saved analysis: %w", err)
+	}
+	if header.Version < 1 || header.Version > AnalysisVersion {
+		return nil, fmt.Errorf("%w: %d (this build reads up to %d)", ErrAnalysisVersion, header.Version, AnalysisVersion)
+	}
+
+	var saved artifact
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		return nil, fmt.Errorf("not a saved analysis: %w", err)
+	}
+	if saved.Analysis == nil {
+		return nil, fmt.Errorf("not a saved analysis: no analysis")
+	}
+	return saved.Analysis, nil
+}
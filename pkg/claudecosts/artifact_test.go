@@ -0,0 +1,52 @@
+package claudecosts_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/testutil"
+)
+
+func TestMarshalAnalysis(t *testing.T) {
+	analysis := testutil.Analyze(t, fixture.DefaultOptions())
+	data, err := claudecosts.MarshalAnalysis(analysis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := claudecosts.UnmarshalAnalysis(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A saved analysis reports exactly as the one it was saved from
+	if want, got := testutil.Render(t, analysis, true), testutil.Render(t, loaded, true); got != want {
+		t.Errorf("rendering the loaded analysis differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnmarshalAnalysis_Invalid(t *testing.T) {
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	if _, err := claudecosts.UnmarshalAnalysis(gzipped(`{"version":99,"analysis":{}}`)); !errors.Is(err, claudecosts.ErrAnalysisVersion) {
+		t.Errorf("newer version: err = %v, want ErrAnalysisVersion", err)
+	}
+	for name, data := range map[string][]byte{
+		"not gzipped": []byte(`{"version":1,"analysis":{}}`),
+		"not JSON":    gzipped("costs"),
+		"no analysis": gzipped(`{"version":1}`),
+	} {
+		if _, err := claudecosts.UnmarshalAnalysis(data); err == nil || errors.Is(err, claudecosts.ErrAnalysisVersion) {
+			t.Errorf("%s: err = %v, want an invalid artifact", name, err)
+		}
+	}
+}
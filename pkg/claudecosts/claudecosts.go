@@ -0,0 +1,87 @@
+package claudecosts
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+// Analysis holds the complete results of parsing a Claude directory
+type Analysis = models.CostAnalysis
+
+// DayBoundary decides which day a time counts toward in daily totals
+type DayBoundary = models.DayBoundary
+
+// Entry is one line of a Claude Code log
+type Entry = models.Entry
+
+// EntryHandler is called with an entry of the type it was registered for,
+// along with its session ID and project name
+type EntryHandler = parser.EntryHandler
+
+// Options configures a library analysis run
+type Options struct {
+	// Logger receives parse warnings and diagnostics. Defaults to slog.Default().
+	Logger *slog.Logger
+	// ClaudeDir is the Claude metadata directory, usually ~/.claude. With
+	// FS, it's a path within FS, and defaults to its root.
+	ClaudeDir string
+	// FS, when set, is read instead of the disk, so embedded fixtures, zip
+	// archives, and fstest.MapFS trees can be analyzed
+	FS fs.FS
+	// Days is the number of days to analyze. Defaults to 30.
+	Days int
+	// DayBoundary divides daily totals into days. Defaults to local
+	// calendar days.
+	DayBoundary DayBoundary
+	// EntryHandlers are called with the entries of each type, keyed by type,
+	// including types the analysis doesn't read itself. Types without a
+	// handler that the analysis doesn't read are counted in
+	// Analysis.UnknownEntryTypes.
+	EntryHandlers map[string]EntryHandler
+	// MaxMemory is the live heap, in bytes, that parsing stays within by
+	// skipping response and turn times as it nears it, which
+	// Analysis.MemoryLimited reports. Zero means no limit.
+	MaxMemory int64
+	// NoSymlinks skips symlinked project directories and files rather than
+	// following them
+	NoSymlinks bool
+}
+
+// Analyze parses the Claude directory described by opts
+func Analyze(opts Options) (*Analysis, error) {
+	if opts.Days <= 0 {
+		opts.Days = 30
+	}
+	if opts.FS != nil {
+		if opts.ClaudeDir == "" {
+			opts.ClaudeDir = "."
+		}
+		if _, err := fs.Stat(opts.FS, opts.ClaudeDir); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrNoClaudeDir, opts.ClaudeDir)
+		}
+	} else if _, err := os.Stat(opts.ClaudeDir); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoClaudeDir, opts.ClaudeDir)
+	}
+
+	parserOpts := []parser.Option{
+		parser.WithLogger(opts.Logger),
+		parser.WithDayBoundary(opts.DayBoundary),
+		parser.WithMemoryLimit(opts.MaxMemory),
+	}
+	if opts.FS != nil {
+		parserOpts = append(parserOpts, parser.WithFS(opts.FS))
+	}
+	if opts.NoSymlinks {
+		parserOpts = append(parserOpts, parser.WithFindOptions(parser.SkipSymlinks()))
+	}
+	for typ, fn := range opts.EntryHandlers {
+		parserOpts = append(parserOpts, parser.WithEntryHandler(typ, fn))
+	}
+	p := parser.New(opts.Days, opts.ClaudeDir, parserOpts...)
+	return p.ParseAll()
+}
@@ -0,0 +1,28 @@
+package claudecosts
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestAnalyze_FS(t *testing.T) {
+	line := `{"type":"assistant","timestamp":"` + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) +
+		`","message":{"usage":{"input_tokens":100,"output_tokens":50},"model":"claude-sonnet-4-20250514"}}` + "\n"
+	fsys := fstest.MapFS{
+		"projects/-srv-api/session.jsonl": {Data: []byte(line)},
+	}
+
+	analysis, err := Analyze(Options{FS: fsys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.TotalOutputTokens != 50 || len(analysis.Sessions) != 1 {
+		t.Errorf("got %d output tokens in %d sessions, want 50 in 1", analysis.TotalOutputTokens, len(analysis.Sessions))
+	}
+
+	if _, err := Analyze(Options{FS: fsys, ClaudeDir: "missing"}); !errors.Is(err, ErrNoClaudeDir) {
+		t.Errorf("missing ClaudeDir: err = %v, want ErrNoClaudeDir", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package client reads a claude-costs serve API. It follows the OpenAPI
+// description the server publishes at /openapi.json.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/report"
+)
+
+// Response types, as described by the OpenAPI schemas of the same names
+type (
+	Report  = report.Report
+	Totals  = report.Totals
+	Day     = report.Day
+	Project = report.Project
+	Session = report.Session
+	Model   = report.Model
+	Block   = report.Block
+)
+
+// Health is the response of /healthz and /api/refresh
+type Health struct {
+	Status  string    `json:"status"`
+	Updated time.Time `json:"updated"`
+}
+
+// Error is returned for responses other than 200 OK
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("claude-costs server: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client calls a claude-costs server
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	user       string
+	password   string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, e.g. one with client certificates for
+// a server run with --tls-client-ca. Defaults to http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken sends the bearer token of a server run with --token
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithBasicAuth sends the credentials of a server run with --basic-auth
+func WithBasicAuth(user, password string) Option {
+	return func(c *Client) { c.user, c.password = user, password }
+}
+
+// New creates a Client for the server at baseURL, such as
+// "http://127.0.0.1:8080"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid server URL %q: use http or https", baseURL)
+	}
+
+	c := &Client{baseURL: u, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Health reports whether the server is up and when it last refreshed
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	return &health, c.do(ctx, http.MethodGet, "/healthz", &health)
+}
+
+// Summary returns the full report
+func (c *Client) Summary(ctx context.Context) (*Report, error) {
+	var rep Report
+	return &rep, c.do(ctx, http.MethodGet, "/api/summary", &rep)
+}
+
+// Daily returns the cost per day, oldest first
+func (c *Client) Daily(ctx context.Context) ([]Day, error) {
+	var days []Day
+	return days, c.do(ctx, http.MethodGet, "/api/daily", &days)
+}
+
+// Projects returns the cost per project, most expensive first
+func (c *Client) Projects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+	return projects, c.do(ctx, http.MethodGet, "/api/projects", &projects)
+}
+
+// Sessions returns the cost per session
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	return sessions, c.do(ctx, http.MethodGet, "/api/sessions", &sessions)
+}
+
+// Models returns the messages and cost per model
+func (c *Client) Models(ctx context.Context) ([]Model, error) {
+	var models []Model
+	return models, c.do(ctx, http.MethodGet, "/api/models", &models)
+}
+
+// Blocks returns the billing blocks, oldest first
+func (c *Client) Blocks(ctx context.Context) ([]Block, error) {
+	var blocks []Block
+	return blocks, c.do(ctx, http.MethodGet, "/api/blocks", &blocks)
+}
+
+// Refresh asks the server to re-read the Claude directory now. It fails
+// with a 403 Error when the server is read-only.
+func (c *Client) Refresh(ctx context.Context) (*Health, error) {
+	var health Health
+	return &health, c.do(ctx, http.MethodPost, "/api/refresh", &health)
+}
+
+// do sends a request and decodes the JSON response into v
+func (c *Client) do(ctx context.Context, method, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL.String()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var body struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+	"github.com/photostructure/go-claude-costs/internal/server"
+)
+
+func TestClient(t *testing.T) {
+	srv := server.New(server.Options{BlockLength: 5 * time.Hour, Token: "secret", ReadOnly: true})
+	srv.Update(&models.CostAnalysis{
+		TotalCost: 2.5,
+		DailyActivity: map[string]*models.DailyActivity{
+			"2025-06-13": {MessageCount: 3, Cost: 2.5},
+		},
+	})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	ctx := context.Background()
+
+	c, err := New(ts.URL+"/", WithToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := c.Summary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Totals.Cost != 2.5 {
+		t.Errorf("Summary cost = %v, want 2.5", rep.Totals.Cost)
+	}
+
+	days, err := c.Daily(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 || days[0].Date != "2025-06-13" || days[0].Messages != 3 {
+		t.Errorf("Daily = %+v", days)
+	}
+
+	var apiErr *Error
+	if _, err := c.Refresh(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Refresh on a read-only server: err = %v, want 403", err)
+	}
+
+	anonymous, err := New(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := anonymous.Health(ctx); err != nil {
+		t.Errorf("Health without credentials: %v", err)
+	}
+	if _, err := anonymous.Models(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "unauthorized" {
+		t.Errorf("Models without credentials: err = %v, want 401", err)
+	}
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, baseURL := range []string{"localhost:8080", "ftp://example.com", "://"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("New(%q): expected error", baseURL)
+		}
+	}
+}
@@ -3,14 +3,23 @@ package claudecosts
 import (
 	"errors"
 	"fmt"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
 )
 
 // Common errors
 var (
 	ErrNoClaudeDir   = errors.New("claude directory not found")
-	ErrNoJSONLFiles  = errors.New("no JSONL files found")
+	ErrNoJSONLFiles  = parser.ErrNoJSONLFiles
 	ErrInvalidConfig = errors.New("invalid configuration")
 	ErrParsingFailed = errors.New("failed to parse JSONL files")
+	// ErrNoData is returned when no activity falls inside the analysis window
+	ErrNoData = errors.New("no activity")
+	// ErrBudgetExceeded is returned when spend is over the --fail-over cap
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrAnalysisVersion is returned when a saved analysis was written by a
+	// newer format than UnmarshalAnalysis reads
+	ErrAnalysisVersion = errors.New("unsupported analysis version")
 )
 
 // ParseError represents an error during file parsing
@@ -0,0 +1,251 @@
+// Package fixture generates synthetic Claude directories: project folders
+// of session JSONL files shaped like Claude Code's, with prompts, tool calls,
+// growing cached context, titles, and optionally malformed lines. They are
+// for benchmarks, tests, and reproducing bugs without sharing real
+// transcripts.
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/models"
+)
+
+// Options describes the tree to generate. Start from DefaultOptions.
+type Options struct {
+	// Seed makes generation repeatable: the same options write the same tree
+	Seed uint64
+	// Projects is the number of project directories, each with Sessions
+	// session files of Turns prompts
+	Projects int
+	Sessions int
+	Turns    int
+	// Days spreads session starts over this many days before End; a zero
+	// End means now
+	Days int
+	End  time.Time
+	// Models are picked from at random for each session
+	Models []string
+	// CacheHitRate is the share of each request's context read from the
+	// prompt cache, 0 to 1; the rest is written to it
+	CacheHitRate float64
+	// MalformedRate is the share of lines written truncated, 0 to 1
+	MalformedRate float64
+	// Size, when set, writes sessions until the tree is about this many
+	// bytes, spread evenly over the projects, in place of Sessions
+	Size int64
+}
+
+// DefaultOptions returns a small month of mixed Opus and Sonnet use
+func DefaultOptions() Options {
+	return Options{
+		Seed:         1,
+		Projects:     3,
+		Sessions:     4,
+		Turns:        8,
+		Days:         30,
+		Models:       []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514"},
+		CacheHitRate: 0.9,
+	}
+}
+
+// Summary describes a generated tree
+type Summary struct {
+	Files     int
+	Lines     int
+	Bytes     int64
+	Malformed int
+	// Cost is the list price of the responses on intact lines, which is
+	// what parsing the tree should report
+	Cost float64
+}
+
+// tools are the tool calls responses make, with their inputs
+var tools = []struct {
+	name  string
+	input map[string]string
+}{
+	{"Read", map[string]string{"file_path": "main.go"}},
+	{"Edit", map[string]string{"file_path": "handler.go", "old_string": "a", "new_string": "b"}},
+	{"Bash", map[string]string{"command": "go test ./..."}},
+	{"Grep", map[string]string{"pattern": "TODO"}},
+}
+
+// Generate writes a tree under dir/projects, as Claude Code lays out
+// ~/.claude, so dir can be passed as --claude-dir
+func Generate(dir string, opts Options) (Summary, error) {
+	var summary Summary
+	switch {
+	case opts.Projects <= 0 || opts.Turns <= 0 || opts.Days <= 0 || (opts.Sessions <= 0 && opts.Size <= 0):
+		return summary, errors.New("fixture: projects, sessions or size, turns, and days must be positive")
+	case len(opts.Models) == 0:
+		return summary, errors.New("fixture: no models")
+	case opts.CacheHitRate < 0 || opts.CacheHitRate > 1 || opts.MalformedRate < 0 || opts.MalformedRate > 1:
+		return summary, errors.New("fixture: rates must be between 0 and 1")
+	}
+	if opts.End.IsZero() {
+		opts.End = time.Now()
+	}
+
+	g := &generator{opts: opts, rng: rand.New(rand.NewPCG(opts.Seed, opts.Seed))}
+	for p := 1; p <= opts.Projects; p++ {
+		projectDir := filepath.Join(dir, "projects", fmt.Sprintf("-home-user-src-project%d", p))
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			return summary, err
+		}
+		// With Size, each project writes its share of it, in at least one
+		// session
+		target := opts.Size / int64(opts.Projects) * int64(p)
+		more := func(s int) bool {
+			if opts.Size > 0 {
+				return s == 0 || summary.Bytes < target
+			}
+			return s < opts.Sessions
+		}
+		for s := 0; more(s); s++ {
+			id := g.uuid()
+			lines := g.session(id, fmt.Sprintf("/home/user/src/project%d", p))
+
+			var buf strings.Builder
+			for _, line := range lines {
+				if g.rng.Float64() < opts.MalformedRate {
+					line.text = line.text[:len(line.text)/2]
+					summary.Malformed++
+				} else {
+					summary.Cost += line.cost
+				}
+				buf.WriteString(line.text)
+				buf.WriteByte('\n')
+			}
+			if err := os.WriteFile(filepath.Join(projectDir, id+".jsonl"), []byte(buf.String()), 0o644); err != nil {
+				return summary, err
+			}
+			summary.Files++
+			summary.Lines += len(lines)
+			summary.Bytes += int64(buf.Len())
+		}
+	}
+	return summary, nil
+}
+
+// Dir generates a tree in a temporary directory removed when the test
+// ends, and returns the directory
+func Dir(tb testing.TB, opts Options) (string, Summary) {
+	tb.Helper()
+	dir := tb.TempDir()
+	summary, err := Generate(dir, opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return dir, summary
+}
+
+type generator struct {
+	opts Options
+	rng  *rand.Rand
+}
+
+// line is one JSONL line and the cost of the response on it, if any
+type line struct {
+	text string
+	cost float64
+}
+
+// session returns the lines of one session: each turn is a prompt, a
+// response calling a tool, its result, and a closing reply
+func (g *generator) session(id, cwd string) []line {
+	span := time.Duration(g.opts.Days) * 24 * time.Hour
+	at := g.opts.End.Add(-time.Duration(g.rng.Int64N(int64(span)))).Truncate(time.Second)
+	model := g.opts.Models[g.rng.IntN(len(g.opts.Models))]
+	pricing, ok := models.ModelPricing[model]
+	if !ok {
+		pricing = models.DefaultPricing
+	}
+
+	var lines []line
+	parent := ""
+	context := 8000 + g.rng.IntN(8000) // System prompt and tool definitions
+	add := func(entry map[string]interface{}, cost float64) {
+		entry["uuid"] = g.uuid()
+		entry["parentUuid"] = parent
+		entry["sessionId"] = id
+		entry["timestamp"] = at.UTC().Format(time.RFC3339Nano)
+		entry["cwd"] = cwd
+		entry["gitBranch"] = "main"
+		data, _ := json.Marshal(entry)
+		lines = append(lines, line{text: string(data), cost: cost})
+		parent = entry["uuid"].(string)
+	}
+	respond := func(content []interface{}) {
+		output := 50 + g.rng.IntN(750)
+		cacheRead := int(float64(context) * g.opts.CacheHitRate)
+		input := 3 + g.rng.IntN(20)
+		cacheWrite := max(context-cacheRead-input, 0)
+		usage := map[string]int{
+			"input_tokens":                input,
+			"output_tokens":               output,
+			"cache_creation_input_tokens": cacheWrite,
+			"cache_read_input_tokens":     cacheRead,
+		}
+		add(map[string]interface{}{
+			"type":    "assistant",
+			"message": map[string]interface{}{"role": "assistant", "model": model, "content": content, "usage": usage},
+		}, pricing.Cost(input, output, cacheWrite, cacheRead))
+		context += output
+	}
+
+	for turn := 0; turn < g.opts.Turns; turn++ {
+		if turn > 0 {
+			at = at.Add(time.Minute + time.Duration(g.rng.IntN(240))*time.Second)
+		}
+		prompt := fmt.Sprintf("Please look at step %d of the change", turn+1)
+		add(map[string]interface{}{
+			"type":    "user",
+			"message": map[string]interface{}{"role": "user", "content": prompt},
+		}, 0)
+		context += 20 + g.rng.IntN(200)
+
+		at = at.Add(time.Duration(3+g.rng.IntN(20)) * time.Second)
+		tool := tools[g.rng.IntN(len(tools))]
+		toolID := "toolu_" + strings.ReplaceAll(g.uuid(), "-", "")[:24]
+		respond([]interface{}{
+			map[string]interface{}{"type": "text", "text": "Let me check."},
+			map[string]interface{}{"type": "tool_use", "id": toolID, "name": tool.name, "input": tool.input},
+		})
+
+		at = at.Add(time.Duration(1+g.rng.IntN(5)) * time.Second)
+		result := 100 + g.rng.IntN(3000)
+		add(map[string]interface{}{
+			"type": "user",
+			"message": map[string]interface{}{"role": "user", "content": []interface{}{
+				map[string]interface{}{"type": "tool_result", "tool_use_id": toolID, "content": strings.Repeat("x", result/10)},
+			}},
+		}, 0)
+		context += result
+
+		at = at.Add(time.Duration(3+g.rng.IntN(30)) * time.Second)
+		respond([]interface{}{map[string]interface{}{"type": "text", "text": "Done."}})
+	}
+
+	data, _ := json.Marshal(map[string]string{
+		"type":     "summary",
+		"summary":  fmt.Sprintf("Synthetic session %s", id[:8]),
+		"leafUuid": parent,
+	})
+	return append(lines, line{text: string(data)})
+}
+
+// uuid returns a random version 4 UUID from the generator's source
+func (g *generator) uuid() string {
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+		g.rng.Uint32(), g.rng.Uint32()&0xffff, g.rng.Uint32()&0xfff,
+		g.rng.Uint32()&0x3fff|0x8000, g.rng.Uint64()&0xffffffffffff)
+}
@@ -0,0 +1,98 @@
+package fixture
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/photostructure/go-claude-costs/internal/parser"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := DefaultOptions()
+	opts.End = time.Now()
+	opts.MalformedRate = 0.05
+	dir, summary := Dir(t, opts)
+
+	if summary.Files != 12 || summary.Lines != 12*(8*4+1) {
+		t.Errorf("summary = %+v, want 12 files of 33 lines", summary)
+	}
+	if summary.Malformed == 0 {
+		t.Error("expected some malformed lines at a 5% rate")
+	}
+
+	analysis, err := parser.New(opts.Days+1, dir).ParseAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(analysis.TotalCost-summary.Cost) > 1e-6 {
+		t.Errorf("parsed cost %v, want %v", analysis.TotalCost, summary.Cost)
+	}
+	if analysis.ParseErrors != summary.Malformed {
+		t.Errorf("ParseErrors = %d, want %d", analysis.ParseErrors, summary.Malformed)
+	}
+	if len(analysis.Projects) != 3 {
+		t.Errorf("got %d projects, want 3", len(analysis.Projects))
+	}
+	if rate := float64(analysis.TotalCacheRead) / float64(analysis.TotalCacheRead+analysis.TotalCacheWrite+analysis.TotalInputTokens); rate < 0.85 || rate > 0.95 {
+		t.Errorf("cache read share = %.2f, want about 0.9", rate)
+	}
+}
+
+func TestGenerate_Repeatable(t *testing.T) {
+	opts := DefaultOptions()
+	opts.End = time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	first, _ := Dir(t, opts)
+	second, _ := Dir(t, opts)
+
+	files, err := filepath.Glob(filepath.Join(first, "projects", "*", "*.jsonl"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no files generated: %v", err)
+	}
+	for _, file := range files {
+		rel, _ := filepath.Rel(first, file)
+		a, _ := os.ReadFile(file)
+		b, err := os.ReadFile(filepath.Join(second, rel))
+		if err != nil || string(a) != string(b) {
+			t.Errorf("%s differs between runs with the same seed", rel)
+		}
+	}
+}
+
+func TestGenerate_Size(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = 1 << 20
+	dir, summary := Dir(t, opts)
+
+	// Generation stops after the session that reaches each project's share
+	if summary.Bytes < opts.Size || summary.Bytes > opts.Size*11/10 {
+		t.Errorf("wrote %d bytes, want about %d", summary.Bytes, opts.Size)
+	}
+	var written int64
+	files, _ := filepath.Glob(filepath.Join(dir, "projects", "*", "*.jsonl"))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written += info.Size()
+	}
+	if written != summary.Bytes || len(files) != summary.Files {
+		t.Errorf("%d files of %d bytes on disk, summary says %d of %d", len(files), written, summary.Files, summary.Bytes)
+	}
+}
+
+func TestGenerate_InvalidOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.CacheHitRate = 1.5
+	if _, err := Generate(t.TempDir(), opts); err == nil {
+		t.Error("expected error for a cache hit rate over 1")
+	}
+	opts = DefaultOptions()
+	opts.Models = nil
+	if _, err := Generate(t.TempDir(), opts); err == nil {
+		t.Error("expected error without models")
+	}
+}
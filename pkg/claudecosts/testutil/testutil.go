@@ -0,0 +1,97 @@
+// Package testutil helps integrations snapshot-test claude-costs output. It
+// renders reports deterministically, with a fixed clock, the local time zone
+// pinned to UTC, colors and inline images off, and ties in every ranking
+// broken by name, and compares them against golden files.
+//
+// Helpers that pin the time zone or turn colors off change process-wide
+// state for the rest of the test, so don't run them in parallel tests.
+package testutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/photostructure/go-claude-costs/internal/display"
+	"github.com/photostructure/go-claude-costs/internal/parser"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts"
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
+)
+
+// Now is the fixed clock that Analyze and Render report as of
+var Now = time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+
+// UpdateEnv is the environment variable that makes Golden rewrite golden
+// files instead of comparing against them, as in UPDATE_GOLDEN=1 go test
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Analyze generates a fixture tree ending at Now and analyzes its last
+// opts.Days days as of Now, without looking at anything else on disk
+func Analyze(tb testing.TB, opts fixture.Options) *claudecosts.Analysis {
+	tb.Helper()
+	pinLocal(tb)
+	opts.End = Now
+	dir, _ := fixture.Dir(tb, opts)
+	// Reading through an fs.FS keeps project names from depending on the
+	// host's home directory and which of its paths exist
+	analysis, err := parser.New(opts.Days, ".", parser.WithFS(os.DirFS(dir)), parser.WithNow(Now)).ParseAll()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return analysis
+}
+
+// Render returns the summary report for analysis as claude-costs prints
+// it, with verbose adding every row and section
+func Render(tb testing.TB, analysis *claudecosts.Analysis, verbose bool) string {
+	tb.Helper()
+	pinLocal(tb)
+	text.DisableColors()
+	tb.Cleanup(text.EnableColors)
+
+	verbosity := display.Normal
+	if verbose {
+		verbosity = display.Verbose
+	}
+	var buf bytes.Buffer
+	d := display.New(analysis, verbosity, false)
+	d.SetOutput(&buf)
+	d.SetClock(func() time.Time { return Now })
+	d.SetClaudeDir("~/.claude")
+	d.ShowAll()
+	return buf.String()
+}
+
+// Golden fails the test when got differs from the contents of path. With
+// UpdateEnv set, it writes got to path instead, creating its directory.
+func Golden(tb testing.TB, path string, got string) {
+	tb.Helper()
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("%v; run with %s=1 to create it", err, UpdateEnv)
+	}
+	if got != string(want) {
+		tb.Errorf("output differs from %s; run with %s=1 to update it\n--- got\n%s\n--- want\n%s", path, UpdateEnv, got, want)
+	}
+}
+
+// pinLocal sets the local time zone to UTC until the test ends, since
+// days and hours are bucketed in local time
+func pinLocal(tb testing.TB) {
+	local := time.Local
+	time.Local = time.UTC
+	tb.Cleanup(func() { time.Local = local })
+}
@@ -0,0 +1,26 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/photostructure/go-claude-costs/pkg/claudecosts/fixture"
+)
+
+func TestRender_Golden(t *testing.T) {
+	analysis := Analyze(t, fixture.DefaultOptions())
+	got := Render(t, analysis, false)
+	Golden(t, "testdata/summary.golden", got)
+
+	if strings.Contains(got, "\x1b[") {
+		t.Error("rendered output contains ANSI escapes")
+	}
+}
+
+func TestRender_Repeatable(t *testing.T) {
+	first := Render(t, Analyze(t, fixture.DefaultOptions()), true)
+	second := Render(t, Analyze(t, fixture.DefaultOptions()), true)
+	if first != second {
+		t.Error("rendering the same fixture twice differs")
+	}
+}